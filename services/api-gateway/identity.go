@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// userIDHeader tells services which user made a request. Only the gateway
+// sets it, from a verified access token; a value sent by the client is dropped.
+const userIDHeader = "X-User-ID"
+
+// jwksRefreshInterval limits how often a token signed with an unknown key
+// makes the gateway fetch the keys of the user service again
+const jwksRefreshInterval = time.Minute
+
+// userVerifier checks access tokens of the user service against the keys it
+// publishes at /.well-known/jwks.json. Like the other services it only checks
+// the signature, issuer, audience and expiry; a token of a session revoked
+// since it was issued stays valid until it expires.
+type userVerifier struct {
+	jwksURL string
+	client  *http.Client
+	parser  *jwt.Parser
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// newUserVerifier returns a verifier that fetches keys from jwksURL with client
+func newUserVerifier(client *http.Client, jwksURL, issuer, audience string) *userVerifier {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	return &userVerifier{
+		jwksURL: jwksURL,
+		client:  client,
+		parser:  jwt.NewParser(options...),
+		keys:    make(map[string]*rsa.PublicKey),
+	}
+}
+
+// identityMiddleware replaces any X-User-ID sent by the client with the ID of
+// the user whose access token the request carries, if it is valid
+func identityMiddleware(users *userVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Header.Del(userIDHeader)
+		if userID, ok := users.UserID(c.GetHeader("Authorization")); ok {
+			c.Request.Header.Set(userIDHeader, userID)
+		}
+		c.Next()
+	}
+}
+
+// UserID returns the subject of the bearer token in authorization, and false
+// if there is none or it is not a valid access token
+func (v *userVerifier) UserID(authorization string) (string, bool) {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || token == "" {
+		return "", false
+	}
+
+	var claims jwt.RegisteredClaims
+	_, err := v.parser.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		// OAuth access tokens are signed with the same keys but are issued
+		// to clients, not users of the API
+		if typ, _ := token.Header["typ"].(string); typ == "at+jwt" {
+			return nil, errors.New("oauth access token")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return v.key(kid)
+	})
+	if err != nil {
+		return "", false
+	}
+	if _, err := strconv.Atoi(claims.Subject); err != nil {
+		return "", false
+	}
+	return claims.Subject, true
+}
+
+// key returns the verification key kid, fetching the key set again if it is
+// unknown and the last fetch is old enough. The fetch runs without holding
+// v.mu, so tokens signed with known keys are not held up by a slow one.
+func (v *userVerifier) key(kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	if key, ok := v.keys[kid]; ok {
+		v.mu.Unlock()
+		return key, nil
+	}
+	if time.Since(v.fetchedAt) < jwksRefreshInterval {
+		v.mu.Unlock()
+		return nil, errors.New("unknown signing key")
+	}
+	// Claim the fetch so that concurrent requests do not start their own
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+
+	keys, err := v.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+// fetchKeys downloads the RSA keys of the user service
+func (v *userVerifier) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	resp, err := v.client.Get(v.jwksURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user service responded with status %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.KeyType != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", jwk.KeyID, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", jwk.KeyID, err)
+		}
+		keys[jwk.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestIdentityMiddleware(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"keys":[{"kty":"RSA","kid":"k1","n":"` +
+			base64.RawURLEncoding.EncodeToString(key.N.Bytes()) + `","e":"` +
+			base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()) + `"}]}`))
+	}))
+	defer jwks.Close()
+
+	sign := func(typ, issuer string, expiresIn time.Duration) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   "42",
+			Audience:  jwt.ClaimStrings{"app-microservice"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		})
+		token.Header["kid"] = "k1"
+		if typ != "" {
+			token.Header["typ"] = typ
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString() error = %v", err)
+		}
+		return signed
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(identityMiddleware(newUserVerifier(jwks.Client(), jwks.URL, "user-service", "app-microservice")))
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetHeader(userIDHeader))
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          string
+	}{
+		{"valid token", "Bearer " + sign("", "user-service", time.Minute), "42"},
+		{"no token", "", ""},
+		{"expired token", "Bearer " + sign("", "user-service", -time.Minute), ""},
+		{"other issuer", "Bearer " + sign("", "someone-else", time.Minute), ""},
+		{"oauth access token", "Bearer " + sign("at+jwt", "user-service", time.Minute), ""},
+		{"malformed token", "Bearer not-a-jwt", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(userIDHeader, "admin")
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("X-User-ID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserVerifier_KnownKeysDoNotWaitForAFetch(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	release := make(chan struct{})
+	fetching := make(chan struct{}, 1)
+	fetches := 0
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches++; fetches > 1 {
+			fetching <- struct{}{}
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"keys":[{"kty":"RSA","kid":"k1","n":"` +
+			base64.RawURLEncoding.EncodeToString(key.N.Bytes()) + `","e":"` +
+			base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()) + `"}]}`))
+	}))
+	defer jwks.Close()
+	defer close(release)
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+			Subject:   "42",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString() error = %v", err)
+		}
+		return "Bearer " + signed
+	}
+
+	users := newUserVerifier(jwks.Client(), jwks.URL, "", "")
+	if _, ok := users.UserID(sign("k1")); !ok {
+		t.Fatal("UserID() with a known key failed")
+	}
+
+	// A token with an unknown key after the refresh interval starts a fetch
+	// that hangs until released
+	users.mu.Lock()
+	users.fetchedAt = time.Time{}
+	users.mu.Unlock()
+	go users.UserID(sign("k2"))
+	<-fetching
+
+	done := make(chan bool)
+	go func() {
+		_, ok := users.UserID(sign("k1"))
+		done <- ok
+	}()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("UserID() with a known key during a fetch failed")
+		}
+	case <-time.After(time.Second):
+		t.Error("UserID() with a known key waited for the fetch of another key")
+	}
+}
@@ -10,20 +10,45 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"app-microservice/shared/config"
+	"app-microservice/shared/svcauth"
+	"app-microservice/shared/tlsconfig"
 
 	"github.com/gin-gonic/gin"
 )
 
-const (
-	USER_SERVICE_URL    = "http://localhost:8081"
-	PRODUCT_SERVICE_URL = "http://localhost:8082"
-)
-
 func main() {
 	cfg := config.LoadConfig()
+	userServiceURL := getEnv("USER_SERVICE_URL", "http://localhost:8081")
+	productServiceURL := getEnv("PRODUCT_SERVICE_URL", "http://localhost:8082")
+
+	// Calls to the services are signed, so they can refuse traffic that does
+	// not come through the gateway
+	var auth *svcauth.Authenticator
+	if keyFile := os.Getenv("SERVICE_AUTH_KEY_FILE"); keyFile != "" {
+		var err error
+		auth, err = svcauth.Load(getEnv("SERVICE_NAME", "api-gateway"), keyFile, os.Getenv("SERVICE_AUTH_PEERS"))
+		if err != nil {
+			log.Fatalf("Failed to load service auth keys: %v", err)
+		}
+	} else {
+		log.Println("SERVICE_AUTH_KEY_FILE not set, calls to services are not authenticated")
+	}
+
+	// Services may be called over HTTPS, with certificates of a CA of our own
+	clientTLS, err := tlsconfig.Client(os.Getenv("TLS_CA_FILE"), getEnv("TLS_MIN_VERSION", "1.2"))
+	if err != nil {
+		log.Fatalf("Failed to load TLS CA: %v", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = clientTLS
+
+	userService := newServiceClient(auth, transport, "user-service")
+	productService := newServiceClient(auth, transport, "product-service")
 
 	r := gin.Default()
 
@@ -32,6 +57,12 @@ func main() {
 	r.Use(gin.Recovery())
 	r.Use(corsMiddleware())
 
+	// Services learn who the caller is from X-User-ID, which the gateway
+	// sets from the access token rather than trusting the client
+	users := newUserVerifier(userService, userServiceURL+"/.well-known/jwks.json",
+		getEnv("JWT_ISSUER", "user-service"), getEnv("JWT_AUDIENCE", "app-microservice"))
+	r.Use(identityMiddleware(users))
+
 	// API Documentation Hub
 	r.GET("/docs", func(c *gin.Context) {
 		c.Header("Content-Type", "text/html")
@@ -96,7 +127,55 @@ func main() {
             <li>GET /users/{id} - Get user by ID</li>
             <li>POST /users - Create new user</li>
             <li>PUT /users/{id} - Update user</li>
+            <li>PATCH /users/{id} - Partially update user (merge patch)</li>
             <li>DELETE /users/{id} - Delete user</li>
+            <li>POST /users/{id}/restore - Restore deleted user</li>
+            <li>PATCH /users/{id}/status - Change user status</li>
+            <li>GET /users/{id}/roles - Get user roles</li>
+            <li>POST /users/{id}/roles - Assign role</li>
+            <li>DELETE /users/{id}/roles/{role} - Revoke role</li>
+            <li>DELETE /users/{id}/mfa - Reset user MFA</li>
+            <li>POST /users/{id}/unlock - Unlock a locked out user</li>
+            <li>GET /users/{id}/lockouts - List user lockout events</li>
+            <li>GET /users/{id}/status-history - List user status changes</li>
+            <li>GET /users/{id}/sessions - List user sessions</li>
+            <li>DELETE /users/{id}/sessions/{session_id} - Revoke a user session</li>
+            <li>DELETE /users/{id}/sessions - Log a user out everywhere</li>
+            <li>GET /api/v1/roles - List roles</li>
+            <li>GET /api/v1/audit - Search the audit log</li>
+            <li>GET /api/v1/audit/verify - Verify the audit log hash chain</li>
+            <li>POST /api/v1/webhooks - Create a webhook</li>
+            <li>GET /api/v1/webhooks - List webhooks</li>
+            <li>GET/PUT/DELETE /api/v1/webhooks/{id} - Manage a webhook</li>
+            <li>GET /api/v1/webhooks/{id}/deliveries - Webhook delivery log</li>
+            <li>POST /api/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver - Redeliver an event</li>
+        </ul>
+        <p>User and role endpoints require an <code>Authorization: Bearer</code> access token.</p>
+
+        <h3>Auth API:</h3>
+        <ul>
+            <li>POST /api/v1/auth/register - Register with email and password</li>
+            <li>POST /api/v1/auth/verify-email - Verify email with the emailed token</li>
+            <li>POST /api/v1/auth/verify-email/resend - Resend verification email</li>
+            <li>POST /api/v1/auth/password/forgot - Request password reset email</li>
+            <li>POST /api/v1/auth/password/reset - Reset password with the emailed token</li>
+            <li>POST /api/v1/auth/login - Log in with email and password</li>
+            <li>POST /api/v1/auth/mfa/verify - Complete login with a TOTP or recovery code</li>
+            <li>POST /api/v1/auth/mfa/enroll - Start TOTP enrolment</li>
+            <li>POST /api/v1/auth/mfa/confirm - Enable MFA and get recovery codes</li>
+            <li>POST /api/v1/auth/refresh - Rotate refresh token</li>
+            <li>POST /api/v1/auth/logout - End the session of a refresh token</li>
+            <li>GET /.well-known/jwks.json - Token signing keys</li>
+            <li>GET /api/v1/me/sessions - List my sessions</li>
+            <li>DELETE /api/v1/me/sessions/{id} - Revoke one of my sessions</li>
+            <li>DELETE /api/v1/me/sessions - Log out everywhere</li>
+            <li>GET /.well-known/openid-configuration - OpenID Connect discovery</li>
+            <li>GET /oauth2/authorize - Sign in with the authorization code flow (PKCE)</li>
+            <li>POST /oauth2/token - Exchange a code or client credentials for tokens</li>
+            <li>GET /oauth2/userinfo - Claims about the signed-in user</li>
+            <li>POST /api/v1/oauth/clients - Register an OAuth client</li>
+            <li>GET /api/v1/oauth/clients - List OAuth clients</li>
+            <li>DELETE /api/v1/oauth/clients/{client_id} - Delete an OAuth client</li>
         </ul>
         
         <h3>Products API:</h3>
@@ -105,7 +184,22 @@ func main() {
             <li>GET /products/{id} - Get product by ID</li>
             <li>POST /products - Create new product</li>
             <li>PUT /products/{id} - Update product</li>
+            <li>PATCH /products/{id} - Partially update product (merge patch)</li>
             <li>DELETE /products/{id} - Delete product</li>
+            <li>POST /products/{id}/restore - Restore deleted product</li>
+            <li>PUT /products/{id}/categories - Set product categories</li>
+            <li>POST /products/import - Bulk import products (CSV/NDJSON)</li>
+            <li>GET /products/export - Export products (CSV/NDJSON)</li>
+        </ul>
+
+        <h3>Categories API:</h3>
+        <ul>
+            <li>GET /categories?tree=true - Get category tree</li>
+            <li>GET /categories/{id} - Get category by ID</li>
+            <li>GET /categories/{id}/breadcrumb - Get category ancestor path</li>
+            <li>POST /categories - Create new category</li>
+            <li>PUT /categories/{id} - Update category</li>
+            <li>DELETE /categories/{id} - Delete category</li>
         </ul>
     </div>
 </body>
@@ -122,17 +216,32 @@ func main() {
 	})
 
 	// Routes to User Service
-	r.Any("/users/*path", proxyToService(USER_SERVICE_URL))
-	r.Any("/users", proxyToService(USER_SERVICE_URL))
+	r.Any("/users/*path", proxyToService(userService, userServiceURL))
+	r.Any("/users", proxyToService(userService, userServiceURL))
+	r.Any("/api/v1/auth/*path", proxyToService(userService, userServiceURL))
+	r.Any("/api/v1/me/*path", proxyToService(userService, userServiceURL))
+	r.GET("/api/v1/roles", proxyToService(userService, userServiceURL))
+	r.GET("/api/v1/audit", proxyToService(userService, userServiceURL))
+	r.GET("/api/v1/audit/*path", proxyToService(userService, userServiceURL))
+	r.Any("/api/v1/webhooks", proxyToService(userService, userServiceURL))
+	r.Any("/api/v1/webhooks/*path", proxyToService(userService, userServiceURL))
+	r.GET("/.well-known/jwks.json", proxyToService(userService, userServiceURL))
+	r.GET("/.well-known/openid-configuration", proxyToService(userService, userServiceURL))
+	r.Any("/oauth2/*path", proxyToService(userService, userServiceURL))
+	r.Any("/api/v1/oauth/*path", proxyToService(userService, userServiceURL))
 
 	// Routes to Product Service
-	r.Any("/products/*path", proxyToService(PRODUCT_SERVICE_URL))
-	r.Any("/products", proxyToService(PRODUCT_SERVICE_URL))
+	r.Any("/products/*path", proxyToService(productService, productServiceURL))
+	r.Any("/products", proxyToService(productService, productServiceURL))
+	r.Any("/categories/*path", proxyToService(productService, productServiceURL))
+	r.Any("/categories", proxyToService(productService, productServiceURL))
+	r.Any("/inventory/*path", proxyToService(productService, productServiceURL))
+	r.GET("/media/*path", proxyToService(productService, productServiceURL))
 
 	// Service health checks
 	r.GET("/services/health", func(c *gin.Context) {
-		userHealth := checkServiceHealth(USER_SERVICE_URL + "/health")
-		productHealth := checkServiceHealth(PRODUCT_SERVICE_URL + "/health")
+		userHealth := checkServiceHealth(transport, userServiceURL+"/health")
+		productHealth := checkServiceHealth(transport, productServiceURL+"/health")
 
 		c.JSON(http.StatusOK, gin.H{
 			"gateway": "ok",
@@ -149,6 +258,16 @@ func main() {
 		port = cfg.Port
 	}
 
+	if certFile := os.Getenv("TLS_CERT_FILE"); certFile != "" {
+		serverTLS, err := tlsconfig.Server(certFile, os.Getenv("TLS_KEY_FILE"), getEnv("TLS_MIN_VERSION", "1.2"))
+		if err != nil {
+			log.Fatalf("Failed to load TLS certificate: %v", err)
+		}
+		server := &http.Server{Addr: ":" + port, Handler: r, TLSConfig: serverTLS}
+		log.Printf("API Gateway đang chạy trên port %s (HTTPS)", port)
+		log.Fatal(server.ListenAndServeTLS("", ""))
+	}
+
 	log.Printf("API Gateway đang chạy trên port %s", port)
 	log.Fatal(http.ListenAndServe(":"+port, r))
 }
@@ -156,8 +275,9 @@ func main() {
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, If-Match")
+		c.Header("Access-Control-Expose-Headers", "ETag")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -168,7 +288,25 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
-func proxyToService(serviceURL string) gin.HandlerFunc {
+// newServiceClient returns the client for calls to service over transport,
+// which signs them when auth is configured
+func newServiceClient(auth *svcauth.Authenticator, transport http.RoundTripper, service string) *http.Client {
+	// Redirects are passed on to the caller, since they are meant for the
+	// browser, such as the OAuth redirects back to a client
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	if auth != nil {
+		client.Transport = auth.Transport(service, transport)
+	}
+	return client
+}
+
+func proxyToService(client *http.Client, serviceURL string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Construct target URL
 		targetURL := serviceURL + c.Request.URL.Path
@@ -193,11 +331,23 @@ func proxyToService(serviceURL string) gin.HandlerFunc {
 			}
 		}
 
-		// Make request to service
-		client := &http.Client{
-			Timeout: 30 * time.Second,
+		// Append the caller's address so services that trust the gateway can
+		// tell clients apart, for instance to throttle failed logins per IP
+		forwardedFor := c.RemoteIP()
+		if prior := c.Request.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+			forwardedFor = strings.Join(prior, ", ") + ", " + forwardedFor
+		}
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+
+		// Tell services whether the client connected over HTTPS, so they only
+		// send HSTS when it did
+		proto := "http"
+		if c.Request.TLS != nil {
+			proto = "https"
 		}
+		req.Header.Set("X-Forwarded-Proto", proto)
 
+		// Make request to service
 		resp, err := client.Do(req)
 		if err != nil {
 			c.JSON(http.StatusServiceUnavailable, gin.H{
@@ -221,9 +371,10 @@ func proxyToService(serviceURL string) gin.HandlerFunc {
 	}
 }
 
-func checkServiceHealth(healthURL string) string {
+func checkServiceHealth(transport http.RoundTripper, healthURL string) string {
 	client := &http.Client{
-		Timeout: 5 * time.Second,
+		Transport: transport,
+		Timeout:   5 * time.Second,
 	}
 
 	resp, err := client.Get(healthURL)
@@ -237,3 +388,10 @@ func checkServiceHealth(healthURL string) string {
 	}
 	return "down"
 }
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
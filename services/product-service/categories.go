@@ -0,0 +1,623 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"app-microservice/shared/etag"
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var categories = []models.Category{
+	{ID: 1, Name: "Điện tử", Slug: "dien-tu", Description: "Thiết bị điện tử", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	{ID: 2, Name: "Laptop", Slug: "laptop", Description: "Máy tính xách tay", ParentID: intPtr(1), CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	{ID: 3, Name: "Điện thoại", Slug: "dien-thoai", Description: "Điện thoại di động", ParentID: intPtr(1), CreatedAt: time.Now(), UpdatedAt: time.Now()},
+}
+
+var nextCategoryID = len(categories) + 1
+
+// CategoryRequest represents the payload for creating or updating a category
+// @Description Category create/update payload
+type CategoryRequest struct {
+	Name        string `json:"name" binding:"required,min=2,max=100" example:"Laptop"`
+	Slug        string `json:"slug" binding:"omitempty,max=100" example:"laptop"`
+	Description string `json:"description" example:"Máy tính xách tay"`
+	ParentID    *int   `json:"parent_id" example:"1"`
+}
+
+// ProductCategoriesRequest represents the payload for replacing product categories
+// @Description Product category membership payload
+type ProductCategoriesRequest struct {
+	CategoryIDs []int `json:"category_ids" example:"1,2"`
+}
+
+func setupCategoryRoutes(r *gin.Engine) {
+	api := r.Group("/categories")
+	{
+		api.GET("", getCategories)
+		api.GET("/:id", getCategoryByID)
+		api.GET("/:id/breadcrumb", getCategoryBreadcrumb)
+		api.POST("", createCategory)
+		api.PUT("/:id", updateCategory)
+		api.DELETE("/:id", deleteCategory)
+	}
+}
+
+// GetCategories godoc
+// @Summary Get all categories
+// @Description Get list of all categories, either flat or as a nested tree
+// @Tags categories
+// @Produce json
+// @Param tree query bool false "Return categories as a nested tree"
+// @Success 200 {object} models.Response
+// @Router /categories [get]
+func getCategories(c *gin.Context) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	var data interface{} = categories
+	if c.Query("tree") == "true" {
+		data = buildCategoryTree()
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy danh sách categories thành công",
+		Data:    data,
+	})
+}
+
+// GetCategoryByID godoc
+// @Summary Get category by ID
+// @Description Get a category by ID
+// @Tags categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /categories/{id} [get]
+func getCategoryByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	i := findCategory(id)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy category",
+			Error:   "Category not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy thông tin category thành công",
+		Data:    categories[i],
+	})
+}
+
+// GetCategoryBreadcrumb godoc
+// @Summary Get category breadcrumb
+// @Description Get the ancestor path of a category, from the root down to the category itself
+// @Tags categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /categories/{id}/breadcrumb [get]
+func getCategoryBreadcrumb(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	if findCategory(id) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy category",
+			Error:   "Category not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy breadcrumb category thành công",
+		Data:    categoryBreadcrumb(id),
+	})
+}
+
+// CreateCategory godoc
+// @Summary Create a new category
+// @Description Create a new category, optionally nested under a parent category
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body CategoryRequest true "Category data"
+// @Success 201 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /categories [post]
+func createCategory(c *gin.Context) {
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	category := models.Category{
+		ID:          nextCategoryID,
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	}
+	if category.Slug == "" {
+		category.Slug = slugify(category.Name)
+	}
+
+	if status, err := validateCategory(&category); err != nil {
+		c.JSON(status, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = time.Now()
+	nextCategoryID++
+
+	categories = append(categories, category)
+
+	c.JSON(http.StatusCreated, models.Response{
+		Status:  "success",
+		Message: "Tạo category thành công",
+		Data:    category,
+	})
+}
+
+// UpdateCategory godoc
+// @Summary Update a category
+// @Description Update category data by ID. Moving a category under one of its own descendants is rejected.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param category body CategoryRequest true "Updated category data"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /categories/{id} [put]
+func updateCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	i := findCategory(id)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy category",
+			Error:   "Category not found",
+		})
+		return
+	}
+
+	category := categories[i]
+	category.Name = req.Name
+	category.Slug = req.Slug
+	category.Description = req.Description
+	category.ParentID = req.ParentID
+	if category.Slug == "" {
+		category.Slug = slugify(category.Name)
+	}
+
+	if status, err := validateCategory(&category); err != nil {
+		c.JSON(status, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	category.UpdatedAt = time.Now()
+	categories[i] = category
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Cập nhật category thành công",
+		Data:    category,
+	})
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Delete category by ID. Categories that still have children cannot be deleted.
+// @Tags categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /categories/{id} [delete]
+func deleteCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	i := findCategory(id)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy category",
+			Error:   "Category not found",
+		})
+		return
+	}
+
+	for _, category := range categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Status:  "error",
+				Message: "Không thể xóa category còn category con",
+				Error:   "Category has children",
+			})
+			return
+		}
+	}
+
+	categories = append(categories[:i], categories[i+1:]...)
+
+	// Detach the deleted category from every product that referenced it
+	now := time.Now()
+	for p := range products {
+		if containsInt(products[p].CategoryIDs, id) {
+			products[p].CategoryIDs = removeInt(products[p].CategoryIDs, id)
+			touchProduct(p, now)
+			if products[p].DeletedAt == nil {
+				raiseProductEvent(EventProductUpdated, products[p], now)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Xóa category thành công",
+		Data:    nil,
+	})
+}
+
+// SetProductCategories godoc
+// @Summary Set product categories
+// @Description Replace the set of categories a product belongs to
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param categories body ProductCategoriesRequest true "Category IDs"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 428 {object} models.ErrorResponse
+// @Router /products/{id}/categories [put]
+func setProductCategories(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req ProductCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	i := findProduct(id)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+	if !checkIfMatch(c, products[i].Version) {
+		return
+	}
+
+	categoryIDs, err := normalizeCategoryIDs(req.CategoryIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	products[i].CategoryIDs = categoryIDs
+	touchProduct(i, now)
+	raiseProductEvent(EventProductUpdated, products[i], now)
+
+	c.Header("ETag", etag.Format(products[i].Version))
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Cập nhật categories của product thành công",
+		Data:    withVariants(products[i]),
+	})
+}
+
+// The helpers below expect the caller to hold catalogMu.
+
+// findCategory returns the index of the category with the given ID, or -1
+func findCategory(id int) int {
+	for i, category := range categories {
+		if category.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// findCategoryByRef resolves a category by numeric ID or by slug
+func findCategoryByRef(ref string) int {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return findCategory(id)
+	}
+	for i, category := range categories {
+		if category.Slug == ref {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateCategory checks that the slug is unique and not a number, which
+// findCategoryByRef would take for an ID, and that the parent exists and does
+// not introduce a cycle. It returns the HTTP status to report on failure.
+func validateCategory(category *models.Category) (int, error) {
+	if category.Slug == "" {
+		return http.StatusBadRequest, fmt.Errorf("category slug is required")
+	}
+	// References that parse as a number are looked up by ID
+	if _, err := strconv.Atoi(category.Slug); err == nil {
+		return http.StatusBadRequest, fmt.Errorf("category slug %q must not be a number", category.Slug)
+	}
+
+	for _, other := range categories {
+		if other.ID != category.ID && other.Slug == category.Slug {
+			return http.StatusConflict, fmt.Errorf("category slug %q already exists", category.Slug)
+		}
+	}
+
+	if category.ParentID == nil {
+		return 0, nil
+	}
+
+	if findCategory(*category.ParentID) < 0 {
+		return http.StatusBadRequest, fmt.Errorf("parent category %d not found", *category.ParentID)
+	}
+
+	for _, descendantID := range categoryDescendantIDs(category.ID) {
+		if descendantID == *category.ParentID {
+			return http.StatusConflict, fmt.Errorf("category cannot be moved under itself or one of its descendants")
+		}
+	}
+
+	return 0, nil
+}
+
+// categoryBreadcrumb returns the ancestor path of a category, root first
+func categoryBreadcrumb(id int) []models.Category {
+	var path []models.Category
+	for i := findCategory(id); i >= 0; {
+		path = append([]models.Category{categories[i]}, path...)
+		if categories[i].ParentID == nil {
+			break
+		}
+		i = findCategory(*categories[i].ParentID)
+	}
+	return path
+}
+
+// categoryDescendantIDs returns the ID of a category and all of its descendants
+func categoryDescendantIDs(id int) []int {
+	ids := []int{id}
+	for i := 0; i < len(ids); i++ {
+		for _, category := range categories {
+			if category.ParentID != nil && *category.ParentID == ids[i] {
+				ids = append(ids, category.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// buildCategoryTree arranges the flat category list into nested root nodes
+func buildCategoryTree() []*models.CategoryNode {
+	nodes := make(map[int]*models.CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &models.CategoryNode{Category: category, Children: []*models.CategoryNode{}}
+	}
+
+	roots := []*models.CategoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		if parent, ok := nodes[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	return roots
+}
+
+// normalizeCategoryIDs removes duplicates and verifies every category exists
+func normalizeCategoryIDs(ids []int) ([]int, error) {
+	var result []int
+	for _, id := range ids {
+		if findCategory(id) < 0 {
+			return nil, fmt.Errorf("category %d not found", id)
+		}
+		if !containsInt(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// productInCategories reports whether a product belongs to any of the given categories
+func productInCategories(product models.Product, categoryIDs []int) bool {
+	for _, id := range product.CategoryIDs {
+		if containsInt(categoryIDs, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// slugify turns a category name into a URL-friendly slug
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(foldVietnamese(name)) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// foldVietnamese strips Vietnamese diacritics so slugs stay ASCII
+func foldVietnamese(s string) string {
+	replacer := strings.NewReplacer(
+		"à", "a", "á", "a", "ả", "a", "ã", "a", "ạ", "a",
+		"ă", "a", "ằ", "a", "ắ", "a", "ẳ", "a", "ẵ", "a", "ặ", "a",
+		"â", "a", "ầ", "a", "ấ", "a", "ẩ", "a", "ẫ", "a", "ậ", "a",
+		"è", "e", "é", "e", "ẻ", "e", "ẽ", "e", "ẹ", "e",
+		"ê", "e", "ề", "e", "ế", "e", "ể", "e", "ễ", "e", "ệ", "e",
+		"ì", "i", "í", "i", "ỉ", "i", "ĩ", "i", "ị", "i",
+		"ò", "o", "ó", "o", "ỏ", "o", "õ", "o", "ọ", "o",
+		"ô", "o", "ồ", "o", "ố", "o", "ổ", "o", "ỗ", "o", "ộ", "o",
+		"ơ", "o", "ờ", "o", "ớ", "o", "ở", "o", "ỡ", "o", "ợ", "o",
+		"ù", "u", "ú", "u", "ủ", "u", "ũ", "u", "ụ", "u",
+		"ư", "u", "ừ", "u", "ứ", "u", "ử", "u", "ữ", "u", "ự", "u",
+		"ỳ", "y", "ý", "y", "ỷ", "y", "ỹ", "y", "ỵ", "y",
+		"đ", "d", "Đ", "d",
+	)
+	return replacer.Replace(strings.ToLower(s))
+}
+
+func containsInt(slice []int, item int) bool {
+	for _, v := range slice {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
+
+// removeInt returns a copy of slice without item; slice itself is left as it
+// is, since it may be shared with a copy of the product handed out earlier
+func removeInt(slice []int, item int) []int {
+	result := make([]int, 0, len(slice))
+	for _, v := range slice {
+		if v != item {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resetCategories replaces the catalog with electronics > laptop > gaming
+// laptop and a phone category under electronics
+func resetCategories() {
+	now := time.Now()
+	categories = []models.Category{
+		{ID: 1, Name: "Điện tử", Slug: "dien-tu", CreatedAt: now, UpdatedAt: now},
+		{ID: 2, Name: "Laptop", Slug: "laptop", ParentID: intPtr(1), CreatedAt: now, UpdatedAt: now},
+		{ID: 3, Name: "Điện thoại", Slug: "dien-thoai", ParentID: intPtr(1), CreatedAt: now, UpdatedAt: now},
+		{ID: 4, Name: "Laptop gaming", Slug: "laptop-gaming", ParentID: intPtr(2), CreatedAt: now, UpdatedAt: now},
+	}
+	nextCategoryID = 5
+}
+
+func TestValidateCategory(t *testing.T) {
+	resetCategories()
+
+	tests := []struct {
+		name     string
+		category models.Category
+		want     int
+	}{
+		{"new root", models.Category{ID: 5, Slug: "phu-kien"}, 0},
+		{"new child", models.Category{ID: 5, Slug: "tai-nghe", ParentID: intPtr(3)}, 0},
+		{"keeps own slug", models.Category{ID: 2, Slug: "laptop", ParentID: intPtr(1)}, 0},
+		{"moves to sibling", models.Category{ID: 4, Slug: "laptop-gaming", ParentID: intPtr(3)}, 0},
+		{"empty slug", models.Category{ID: 5}, http.StatusBadRequest},
+		{"numeric slug", models.Category{ID: 5, Slug: "2024"}, http.StatusBadRequest},
+		{"duplicate slug", models.Category{ID: 5, Slug: "laptop"}, http.StatusConflict},
+		{"missing parent", models.Category{ID: 5, Slug: "phu-kien", ParentID: intPtr(99)}, http.StatusBadRequest},
+		{"under itself", models.Category{ID: 2, Slug: "laptop", ParentID: intPtr(2)}, http.StatusConflict},
+		{"under child", models.Category{ID: 2, Slug: "laptop", ParentID: intPtr(4)}, http.StatusConflict},
+		{"under grandchild", models.Category{ID: 1, Slug: "dien-tu", ParentID: intPtr(4)}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := validateCategory(&tt.category)
+			if status != tt.want || (err != nil) != (tt.want != 0) {
+				t.Errorf("validateCategory() = %d, %v, want %d", status, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCategoryByRef(t *testing.T) {
+	resetCategories()
+
+	tests := []struct {
+		ref  string
+		want int
+	}{
+		{"2", 1},
+		{"laptop-gaming", 3},
+		{"99", -1},
+		{"phu-kien", -1},
+	}
+	for _, tt := range tests {
+		if got := findCategoryByRef(tt.ref); got != tt.want {
+			t.Errorf("findCategoryByRef(%q) = %d, want %d", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryBreadcrumb(t *testing.T) {
+	resetCategories()
+
+	path := categoryBreadcrumb(4)
+	if len(path) != 3 || path[0].ID != 1 || path[1].ID != 2 || path[2].ID != 4 {
+		t.Errorf("categoryBreadcrumb(4) = %+v, want 1 > 2 > 4", path)
+	}
+	if path := categoryBreadcrumb(1); len(path) != 1 || path[0].ID != 1 {
+		t.Errorf("categoryBreadcrumb(1) = %+v, want only the root", path)
+	}
+	if path := categoryBreadcrumb(99); len(path) != 0 {
+		t.Errorf("categoryBreadcrumb(99) = %+v, want empty", path)
+	}
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	resetCategories()
+
+	roots := buildCategoryTree()
+	if len(roots) != 1 || roots[0].ID != 1 {
+		t.Fatalf("buildCategoryTree() = %d roots, want only category 1", len(roots))
+	}
+	children := roots[0].Children
+	if len(children) != 2 || children[0].ID != 2 || children[1].ID != 3 {
+		t.Fatalf("children of 1 = %+v, want 2 and 3", children)
+	}
+	if len(children[0].Children) != 1 || children[0].Children[0].ID != 4 || len(children[1].Children) != 0 {
+		t.Errorf("grandchildren = %+v and %+v, want 4 under 2 only", children[0].Children, children[1].Children)
+	}
+
+	descendants := categoryDescendantIDs(1)
+	if len(descendants) != 4 {
+		t.Errorf("categoryDescendantIDs(1) = %v, want all 4 categories", descendants)
+	}
+}
+
+func TestSetProductCategories(t *testing.T) {
+	resetCategories()
+	resetInventory(5)
+	products[0].Version = 3
+	eventQueue = make(chan productEvent, 10)
+	defer func() { eventQueue = nil }()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/products/:id/categories", setProductCategories)
+
+	tests := []struct {
+		name       string
+		ifMatch    string
+		wantStatus int
+	}{
+		{"stale version", `"2"`, http.StatusPreconditionFailed},
+		{"current version", `"3"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/products/1/categories", strings.NewReader(`{"category_ids":[2]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", tt.ifMatch)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: PUT = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+
+	if len(products[0].CategoryIDs) != 1 || products[0].Version != 4 {
+		t.Errorf("product = %+v, want category 2 at version 4", products[0])
+	}
+	if len(eventQueue) != 1 {
+		t.Fatalf("raised %d events, want 1", len(eventQueue))
+	}
+	if event := <-eventQueue; event.Type != EventProductUpdated {
+		t.Errorf("event type = %q, want %q", event.Type, EventProductUpdated)
+	}
+}
+
+func TestDeleteCategory_DetachesProducts(t *testing.T) {
+	resetCategories()
+	resetInventory(5)
+	products[0].CategoryIDs = []int{3, 4}
+	products = append(products, models.Product{ID: 2, Name: "iPhone 14", CategoryIDs: []int{3}})
+	handedOut := products[0]
+	eventQueue = make(chan productEvent, 10)
+	defer func() { eventQueue = nil }()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	setupCategoryRoutes(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/categories/4", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("DELETE = %d, want 200", w.Code)
+	}
+
+	if ids := products[0].CategoryIDs; len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("category IDs = %v, want [3]", ids)
+	}
+	if ids := handedOut.CategoryIDs; len(ids) != 2 || ids[0] != 3 || ids[1] != 4 {
+		t.Errorf("category IDs of the copy handed out = %v, want [3 4] untouched", ids)
+	}
+	if len(eventQueue) != 1 {
+		t.Fatalf("raised %d events, want 1 for the product that lost the category", len(eventQueue))
+	}
+	if event := <-eventQueue; event.Type != EventProductUpdated || event.AggregateID != "1" {
+		t.Errorf("event = %+v, want product.updated of product 1", event)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Điện thoại":         "dien-thoai",
+		"  Laptop & PC  ":    "laptop-pc",
+		"Tai nghe Bluetooth": "tai-nghe-bluetooth",
+	}
+	for name, want := range tests {
+		if got := slugify(name); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
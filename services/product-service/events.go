@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"app-microservice/shared/models"
+	"app-microservice/shared/svcauth"
+	"app-microservice/shared/tlsconfig"
+
+	"github.com/google/uuid"
+)
+
+// Product events sent to the user service, which delivers them to webhooks
+// and the event broker
+const (
+	EventProductCreated  = "product.created"
+	EventProductUpdated  = "product.updated"
+	EventProductDeleted  = "product.deleted"
+	EventProductRestored = "product.restored"
+)
+
+// eventQueueSize is how many events may wait to be sent. Events raised while
+// the queue is full are dropped.
+const eventQueueSize = 1000
+
+// eventRetryBase and eventRetryMax bound the delay between attempts to send
+// an event
+var (
+	eventRetryBase = time.Second
+	eventRetryMax  = time.Minute
+)
+
+// productEvent is a change to a product, in the format the user service
+// accepts at /internal/events
+type productEvent struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Data          json.RawMessage `json:"data"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// eventQueue holds the events waiting to be sent. It is nil, and events are
+// not raised, unless EVENTS_URL and service auth are configured.
+var eventQueue chan productEvent
+
+// startEventForwarder sends product events to url, signed for the user
+// service by auth, until the process exits. Like the catalog, queued events
+// are lost on restart.
+func startEventForwarder(auth *svcauth.Authenticator, url string) error {
+	clientTLS, err := tlsconfig.Client(getEnv("TLS_CA_FILE", ""), getEnv("TLS_MIN_VERSION", "1.2"))
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = clientTLS
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: auth.Transport("user-service", transport),
+	}
+
+	eventQueue = make(chan productEvent, eventQueueSize)
+	go forwardEvents(client, url, eventQueue)
+	return nil
+}
+
+// raiseProductEvent queues an event of eventType carrying product. It never
+// blocks, so it may be called while holding catalogMu.
+func raiseProductEvent(eventType string, product models.Product, now time.Time) {
+	if eventQueue == nil {
+		return
+	}
+
+	product.Variants = nil
+	data, err := json.Marshal(product)
+	if err != nil {
+		log.Printf("Không thể tạo event %s cho product %d: %v", eventType, product.ID, err)
+		return
+	}
+	event := productEvent{
+		ID:            uuid.NewString(),
+		Type:          eventType,
+		AggregateType: "product",
+		AggregateID:   strconv.Itoa(product.ID),
+		Data:          data,
+		OccurredAt:    now.UTC(),
+	}
+
+	select {
+	case eventQueue <- event:
+	default:
+		log.Printf("Hàng đợi event đã đầy, bỏ qua event %s cho product %d", eventType, product.ID)
+	}
+}
+
+// forwardEvents sends each queued event in turn, retrying with a growing
+// delay until the user service accepts it. Events it rejects as invalid are
+// dropped, since sending them again would not help.
+func forwardEvents(client *http.Client, url string, events <-chan productEvent) {
+	for event := range events {
+		delay := eventRetryBase
+		for {
+			retry, err := sendEvent(client, url, event)
+			if err == nil {
+				break
+			}
+			if !retry {
+				log.Printf("Event %s (%s) bị từ chối: %v", event.ID, event.Type, err)
+				break
+			}
+			log.Printf("Không thể gửi event %s (%s), thử lại sau %s: %v", event.ID, event.Type, delay, err)
+			time.Sleep(delay)
+			delay = min(delay*2, eventRetryMax)
+		}
+	}
+}
+
+// sendEvent posts event to url and reports whether a failure is worth
+// retrying
+func sendEvent(client *http.Client, url string, event productEvent) (bool, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return false, err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return false, fmt.Errorf("user service responded with status %d", resp.StatusCode)
+	default:
+		return true, fmt.Errorf("user service responded with status %d", resp.StatusCode)
+	}
+}
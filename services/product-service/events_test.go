@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReplaceProduct_RaisesUpdatedEvent(t *testing.T) {
+	resetInventory(5)
+	eventQueue = make(chan productEvent, 1)
+	defer func() { eventQueue = nil }()
+
+	updated := products[0]
+	updated.Name = "Laptop Dell 2"
+	if _, _, err := replaceProduct(0, updated, "test"); err != nil {
+		t.Fatalf("replaceProduct() error = %v", err)
+	}
+
+	select {
+	case event := <-eventQueue:
+		if event.Type != EventProductUpdated || event.AggregateType != "product" || event.AggregateID != "1" || event.ID == "" {
+			t.Errorf("event = %+v, want product.updated of product 1", event)
+		}
+		var data struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(event.Data, &data); err != nil || data.Name != "Laptop Dell 2" {
+			t.Errorf("event data = %s, want the updated product", event.Data)
+		}
+	default:
+		t.Fatal("replaceProduct() raised no event")
+	}
+}
+
+func TestForwardEvents_RetriesUntilAccepted(t *testing.T) {
+	eventRetryBase, eventRetryMax = time.Millisecond, time.Millisecond
+	defer func() { eventRetryBase, eventRetryMax = time.Second, time.Minute }()
+
+	var calls atomic.Int32
+	received := make(chan productEvent, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event productEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		switch {
+		case event.Type == "product.unknown":
+			w.WriteHeader(http.StatusBadRequest)
+		case calls.Add(1) < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			received <- event
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	events := make(chan productEvent, 2)
+	events <- productEvent{ID: "rejected", Type: "product.unknown"}
+	events <- productEvent{ID: "accepted", Type: EventProductCreated}
+	close(events)
+	forwardEvents(server.Client(), server.URL, events)
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("accepted event was sent %d times, want 3", got)
+	}
+	if len(received) != 1 || (<-received).ID != "accepted" {
+		t.Error("want only the accepted event delivered; rejected events are dropped, not retried")
+	}
+}
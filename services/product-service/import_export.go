@@ -0,0 +1,722 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Import job statuses
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+)
+
+// Supported import/export formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+const (
+	maxImportSize        = 20 << 20
+	asyncImportThreshold = 1000 // imports with more rows run in the background
+	importBatchSize      = 200  // rows applied per catalogMu acquisition
+	importJobRetention   = 24 * time.Hour
+)
+
+var errUnsupportedFormat = errors.New("unsupported format, use csv or ndjson")
+
+// importColumns is the CSV header written on export and accepted on import
+var importColumns = []string{"sku", "name", "description", "price", "stock", "category_ids"}
+
+// importJobsMu guards importJobs independently of catalogMu so progress can be
+// polled while a batch is being applied
+var (
+	importJobs   = map[string]*models.ImportJob{}
+	importJobsMu sync.RWMutex
+)
+
+// ProductImportRequest holds the query options of an import
+type ProductImportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv ndjson"`
+	DryRun bool   `form:"dry_run"`
+	Async  *bool  `form:"async"`
+}
+
+// productRow is a single product line of an import or export file
+type productRow struct {
+	Line        int     `json:"-"`
+	SKU         string  `json:"sku"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Stock       *int    `json:"stock"` // omitted on update leaves stock untouched
+	CategoryIDs []int   `json:"category_ids"`
+}
+
+func setupImportExportRoutes(api *gin.RouterGroup) {
+	api.POST("/import", importProducts)
+	api.GET("/import/jobs/:jobId", getImportJob)
+	api.GET("/export", exportProducts)
+}
+
+// ImportProducts godoc
+// @Summary Bulk import products
+// @Description Upsert products by SKU from a CSV (header: sku,name,description,price,stock,category_ids) or NDJSON file.
+// @Description The file may be sent as the raw request body or as the multipart field "file". Category IDs in CSV are separated by ";".
+// @Description Each row is validated and applied on its own; rejected rows are listed in the job report. Stock changes are recorded as inventory adjustments.
+// @Description Imports larger than 1000 rows run as a background job unless async=false.
+// @Tags import-export
+// @Accept text/csv,application/x-ndjson,multipart/form-data
+// @Produce json
+// @Param format query string false "File format, detected from Content-Type or file name when omitted" Enums(csv, ndjson)
+// @Param dry_run query bool false "Validate only, do not change the catalogue"
+// @Param async query bool false "Force background (true) or inline (false) processing"
+// @Param file formData file false "Import file"
+// @Success 200 {object} models.Response{data=models.ImportJob}
+// @Success 202 {object} models.Response{data=models.ImportJob}
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Router /products/import [post]
+func importProducts(c *gin.Context) {
+	var req ProductImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Tham số không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	data, format, err := readImportFile(c, req.Format)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+				Status:  "error",
+				Message: "File import quá lớn",
+				Error:   fmt.Sprintf("import file exceeds %d bytes", maxImportSize),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "File import không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	rows, rowErrors, err := parseImportRows(bytes.NewReader(data), format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "File import không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	job, err := newImportJob(format, req.DryRun, len(rows)+len(rowErrors), rowErrors, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không thể tạo job import",
+			Error:   err.Error(),
+		})
+		return
+	}
+	actor := priceActor(c)
+
+	async := len(rows) > asyncImportThreshold
+	if req.Async != nil {
+		async = *req.Async
+	}
+	if async {
+		go runImport(job, rows, actor)
+		c.JSON(http.StatusAccepted, models.Response{
+			Status:  "success",
+			Message: "Đã tạo job import, theo dõi tiến độ qua /products/import/jobs/" + job.ID,
+			Data:    importJobSnapshot(job),
+		})
+		return
+	}
+
+	runImport(job, rows, actor)
+
+	message := "Import products thành công"
+	if req.DryRun {
+		message = "Kiểm tra file import thành công (dry run)"
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: message,
+		Data:    importJobSnapshot(job),
+	})
+}
+
+// GetImportJob godoc
+// @Summary Get import job progress
+// @Description Get the progress and row error report of an import job
+// @Tags import-export
+// @Produce json
+// @Param jobId path string true "Import job ID"
+// @Success 200 {object} models.Response{data=models.ImportJob}
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/import/jobs/{jobId} [get]
+func getImportJob(c *gin.Context) {
+	importJobsMu.RLock()
+	job, ok := importJobs[c.Param("jobId")]
+	importJobsMu.RUnlock()
+
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy job import",
+			Error:   "Import job not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy tiến độ import thành công",
+		Data:    importJobSnapshot(job),
+	})
+}
+
+// ExportProducts godoc
+// @Summary Export products
+// @Description Stream the whole catalogue as CSV or NDJSON, in the same layout accepted by /products/import
+// @Tags import-export
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "Export format" Enums(csv, ndjson) default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse
+// @Router /products/export [get]
+func exportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", FormatCSV)
+	if format != FormatCSV && format != FormatNDJSON {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Định dạng không hợp lệ",
+			Error:   errUnsupportedFormat.Error(),
+		})
+		return
+	}
+
+	catalogMu.RLock()
+	rows := make([]productRow, 0, len(products))
+	for _, product := range products {
+		if product.DeletedAt != nil {
+			continue
+		}
+		rows = append(rows, productRow{
+			SKU:         product.SKU,
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       product.Price,
+			Stock:       intPtr(product.Stock),
+			CategoryIDs: append([]int(nil), product.CategoryIDs...),
+		})
+	}
+	catalogMu.RUnlock()
+
+	filename := "products-" + time.Now().Format("20060102-150405") + "." + format
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	var err error
+	if format == FormatCSV {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		err = writeCSV(c.Writer, rows)
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		err = writeNDJSON(c.Writer, rows)
+	}
+	if err != nil {
+		// Headers are already sent, so the client only sees a truncated stream
+		_ = c.Error(err)
+	}
+}
+
+// readImportFile returns the uploaded file, taken from the multipart field
+// "file" or the raw body, together with its resolved format
+func readImportFile(c *gin.Context, format string) ([]byte, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+	if mediaType == "multipart/form-data" {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", fmt.Errorf("missing multipart field \"file\": %w", err)
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+
+		if format == "" {
+			format = detectImportFormat(fileHeader.Header.Get("Content-Type"), fileHeader.Filename)
+		}
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, "", err
+		}
+		if format == "" {
+			return nil, "", errUnsupportedFormat
+		}
+		return data, format, nil
+	}
+
+	if format == "" {
+		format = detectImportFormat(c.GetHeader("Content-Type"), "")
+	}
+	if format == "" {
+		return nil, "", errUnsupportedFormat
+	}
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, format, nil
+}
+
+// detectImportFormat infers the format from a content type or file extension
+func detectImportFormat(contentType, filename string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return FormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return FormatNDJSON
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	}
+	return ""
+}
+
+// parseImportRows decodes and validates every row of an import file. Rows that
+// fail are reported individually; only a structurally unreadable file is an error.
+func parseImportRows(r io.Reader, format string) ([]productRow, []models.ImportRowError, error) {
+	var (
+		rows []productRow
+		errs []models.ImportRowError
+		err  error
+	)
+	switch format {
+	case FormatCSV:
+		rows, errs, err = decodeCSV(r)
+	case FormatNDJSON:
+		rows, errs, err = decodeNDJSON(r)
+	default:
+		return nil, nil, errUnsupportedFormat
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	valid := rows[:0]
+	firstLine := map[string]int{}
+	for _, row := range rows {
+		row.SKU = normalizeSKU(row.SKU)
+		row.Name = strings.TrimSpace(row.Name)
+
+		if err := validateImportRow(row); err != nil {
+			errs = append(errs, models.ImportRowError{Line: row.Line, SKU: row.SKU, Error: err.Error()})
+			continue
+		}
+		if line, ok := firstLine[row.SKU]; ok {
+			errs = append(errs, models.ImportRowError{Line: row.Line, SKU: row.SKU, Error: fmt.Sprintf("duplicate sku, first seen on line %d", line)})
+			continue
+		}
+		firstLine[row.SKU] = row.Line
+		valid = append(valid, row)
+	}
+	return valid, errs, nil
+}
+
+func validateImportRow(row productRow) error {
+	switch {
+	case row.SKU == "":
+		return errors.New("sku is required")
+	case len(row.SKU) > 64:
+		return errors.New("sku must be at most 64 characters")
+	case row.Name == "":
+		return errors.New("name is required")
+	case row.Price < 0:
+		return errors.New("price must not be negative")
+	case row.Stock != nil && *row.Stock < 0:
+		return errors.New("stock must not be negative")
+	}
+	return nil
+}
+
+func decodeCSV(r io.Reader) ([]productRow, []models.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !containsString(importColumns, name) {
+			return nil, nil, fmt.Errorf("unknown column %q, expected %s", name, strings.Join(importColumns, ","))
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"sku", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	var (
+		rows []productRow
+		errs []models.ImportRowError
+	)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) && parseErr.Err == csv.ErrFieldCount {
+				errs = append(errs, models.ImportRowError{Line: parseErr.StartLine, Error: "wrong number of fields"})
+				continue
+			}
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := productRow{
+			Line:        line,
+			SKU:         field("sku"),
+			Name:        field("name"),
+			Description: field("description"),
+		}
+		if err := parseCSVFields(&row, field("price"), field("stock"), field("category_ids")); err != nil {
+			errs = append(errs, models.ImportRowError{Line: line, SKU: normalizeSKU(row.SKU), Error: err.Error()})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, errs, nil
+}
+
+func parseCSVFields(row *productRow, price, stock, categoryIDs string) error {
+	if price != "" {
+		value, err := strconv.ParseFloat(price, 64)
+		if err != nil {
+			return fmt.Errorf("invalid price %q", price)
+		}
+		row.Price = value
+	}
+	if stock != "" {
+		value, err := strconv.Atoi(stock)
+		if err != nil {
+			return fmt.Errorf("invalid stock %q", stock)
+		}
+		row.Stock = &value
+	}
+	for _, part := range strings.Split(categoryIDs, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return fmt.Errorf("invalid category id %q", part)
+		}
+		row.CategoryIDs = append(row.CategoryIDs, id)
+	}
+	return nil
+}
+
+func decodeNDJSON(r io.Reader) ([]productRow, []models.ImportRowError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var (
+		rows []productRow
+		errs []models.ImportRowError
+		line int
+	)
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var row productRow
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row); err != nil {
+			errs = append(errs, models.ImportRowError{Line: line, Error: "invalid json: " + err.Error()})
+			continue
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return rows, errs, nil
+}
+
+func writeCSV(w io.Writer, rows []productRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(importColumns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		categoryIDs := make([]string, len(row.CategoryIDs))
+		for i, id := range row.CategoryIDs {
+			categoryIDs[i] = strconv.Itoa(id)
+		}
+		record := []string{
+			row.SKU,
+			row.Name,
+			row.Description,
+			strconv.FormatFloat(row.Price, 'f', -1, 64),
+			strconv.Itoa(*row.Stock),
+			strings.Join(categoryIDs, ";"),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeNDJSON(w io.Writer, rows []productRow) error {
+	encoder := json.NewEncoder(w)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newImportJob(format string, dryRun bool, total int, rowErrors []models.ImportRowError, now time.Time) (*models.ImportJob, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	job := &models.ImportJob{
+		ID:        id,
+		Status:    ImportPending,
+		Format:    format,
+		DryRun:    dryRun,
+		Total:     total,
+		Processed: len(rowErrors),
+		Failed:    len(rowErrors),
+		Errors:    rowErrors,
+		CreatedAt: now,
+	}
+
+	importJobsMu.Lock()
+	defer importJobsMu.Unlock()
+	for id, old := range importJobs {
+		if old.FinishedAt != nil && now.Sub(*old.FinishedAt) > importJobRetention {
+			delete(importJobs, id)
+		}
+	}
+	importJobs[job.ID] = job
+	return job, nil
+}
+
+// importJobSnapshot returns a copy of the job that is safe to serialise while
+// the import is still running
+func importJobSnapshot(job *models.ImportJob) models.ImportJob {
+	importJobsMu.RLock()
+	defer importJobsMu.RUnlock()
+
+	snapshot := *job
+	snapshot.Errors = append([]models.ImportRowError{}, job.Errors...)
+	return snapshot
+}
+
+// runImport applies the rows in batches so that reads are not blocked for the
+// whole duration of a large import
+func runImport(job *models.ImportJob, rows []productRow, actor string) {
+	importJobsMu.Lock()
+	job.Status = ImportRunning
+	importJobsMu.Unlock()
+
+	for start := 0; start < len(rows); start += importBatchSize {
+		end := min(start+importBatchSize, len(rows))
+
+		var created, updated int
+		var errs []models.ImportRowError
+
+		catalogMu.Lock()
+		now := time.Now()
+		for _, row := range rows[start:end] {
+			isNew, err := importProductRow(row, actor, job.DryRun, now)
+			switch {
+			case err != nil:
+				errs = append(errs, models.ImportRowError{Line: row.Line, SKU: row.SKU, Error: err.Error()})
+			case isNew:
+				created++
+			default:
+				updated++
+			}
+		}
+		catalogMu.Unlock()
+
+		importJobsMu.Lock()
+		job.Processed += end - start
+		job.Created += created
+		job.Updated += updated
+		job.Failed += len(errs)
+		job.Errors = append(job.Errors, errs...)
+		importJobsMu.Unlock()
+	}
+
+	importJobsMu.Lock()
+	finishedAt := time.Now()
+	sort.SliceStable(job.Errors, func(i, j int) bool { return job.Errors[i].Line < job.Errors[j].Line })
+	job.Status = ImportCompleted
+	job.FinishedAt = &finishedAt
+	importJobsMu.Unlock()
+}
+
+// The helpers below expect the caller to hold catalogMu.
+
+// importProductRow creates or updates the product with the row's SKU. Every
+// check runs before the first change so a rejected row leaves no trace; in dry
+// run mode nothing is changed at all.
+func importProductRow(row productRow, actor string, dryRun bool, now time.Time) (bool, error) {
+	categoryIDs, err := normalizeCategoryIDs(row.CategoryIDs)
+	if err != nil {
+		return false, err
+	}
+
+	i := findProductBySKU(row.SKU)
+	if i >= 0 && products[i].DeletedAt != nil {
+		return false, fmt.Errorf("sku %q belongs to deleted product %d, restore it first", row.SKU, products[i].ID)
+	}
+	if i < 0 {
+		if skuInUse(row.SKU, 0, 0) {
+			return false, fmt.Errorf("sku %q already belongs to a variant", row.SKU)
+		}
+		if dryRun {
+			return true, nil
+		}
+
+		product := models.Product{
+			ID:          nextProductID,
+			SKU:         row.SKU,
+			Name:        row.Name,
+			Description: row.Description,
+			Price:       row.Price,
+			CategoryIDs: categoryIDs,
+			Version:     1,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if row.Stock != nil {
+			product.Stock = *row.Stock
+		}
+		nextProductID++
+
+		products = append(products, product)
+		if product.Stock > 0 {
+			recordMovement(product.ID, nil, MovementReceipt, product.Stock, nil, "opening balance (import)", now)
+		}
+		recordPriceChange(product.ID, 0, product.Price, actor, "initial price", now)
+		raiseProductEvent(EventProductCreated, product, now)
+		return true, nil
+	}
+
+	product := products[i]
+	if row.Stock != nil && *row.Stock < stockReserved(product.ID, nil) {
+		return false, fmt.Errorf("%w: %d units are reserved", errInsufficientStock, stockReserved(product.ID, nil))
+	}
+	if dryRun {
+		return false, nil
+	}
+
+	if row.Stock != nil && *row.Stock != product.Stock {
+		if _, err := applyStockChange(product.ID, nil, MovementAdjustment, *row.Stock-product.Stock, "import", now); err != nil {
+			return false, err
+		}
+	}
+	if row.Price != product.Price {
+		recordPriceChange(product.ID, product.Price, row.Price, actor, "import", now)
+	}
+
+	product = products[i] // pick up the stock written by applyStockChange
+	product.Name = row.Name
+	product.Description = row.Description
+	product.Price = row.Price
+	product.CategoryIDs = categoryIDs
+	products[i] = product
+	touchProduct(i, now)
+	raiseProductEvent(EventProductUpdated, products[i], now)
+	return false, nil
+}
+
+// findProductBySKU returns the index of the product with the given SKU, or -1
+func findProductBySKU(sku string) int {
+	for i, product := range products {
+		if product.SKU == sku {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseImportRows_ReportsRowErrors(t *testing.T) {
+	input := "sku,name,price,stock,category_ids\n" +
+		"a-1,Alpha,10,1,\n" +
+		"A-1,Alpha again,10,1,\n" +
+		"B-1,,10,1,\n" +
+		"C-1,Gamma,abc,1,\n" +
+		"D-1,Delta,5,,1;2\n"
+
+	rows, errs, err := parseImportRows(strings.NewReader(input), FormatCSV)
+	if err != nil {
+		t.Fatalf("parseImportRows() error = %v", err)
+	}
+	if len(rows) != 2 || rows[0].SKU != "A-1" || rows[1].Stock != nil || len(rows[1].CategoryIDs) != 2 {
+		t.Errorf("rows = %+v, want A-1 and D-1 without stock", rows)
+	}
+
+	wantLines := map[int]bool{3: true, 4: true, 5: true}
+	if len(errs) != len(wantLines) {
+		t.Fatalf("errors = %+v, want lines 3, 4 and 5", errs)
+	}
+	for _, rowErr := range errs {
+		if !wantLines[rowErr.Line] {
+			t.Errorf("unexpected error on line %d: %s", rowErr.Line, rowErr.Error)
+		}
+	}
+
+	if _, _, err := parseImportRows(strings.NewReader("sku,colour\n"), FormatCSV); err == nil {
+		t.Error("parseImportRows() with unknown column succeeded, want error")
+	}
+}
+
+func TestImportProductRow_UpsertsBySKU(t *testing.T) {
+	resetInventory(5)
+	products[0].SKU = "DELL-INS15"
+	nextProductID = 2
+	priceChanges = nil
+	nextPriceChangeID = 1
+	now := time.Now()
+
+	stock := 8
+	created, err := importProductRow(productRow{SKU: "DELL-INS15", Name: "Laptop Dell", Price: 14000000, Stock: &stock}, "admin", true, now)
+	if err != nil || created {
+		t.Fatalf("dry run importProductRow() = %v, %v, want update without error", created, err)
+	}
+	if products[0].Price != 15000000 || products[0].Stock != 5 {
+		t.Errorf("dry run changed the product: %+v", products[0])
+	}
+
+	if _, err := importProductRow(productRow{SKU: "DELL-INS15", Name: "Laptop Dell", Price: 14000000, Stock: &stock}, "admin", false, now); err != nil {
+		t.Fatalf("importProductRow() error = %v", err)
+	}
+	if products[0].Price != 14000000 || stockLevel(1, nil).OnHand != 8 {
+		t.Errorf("product = %+v, want price 14000000 and stock 8", products[0])
+	}
+	if len(stockMovements) != 1 || stockMovements[0].Type != MovementAdjustment || stockMovements[0].Quantity != 3 {
+		t.Errorf("stock movements = %+v, want a single +3 adjustment", stockMovements)
+	}
+
+	created, err = importProductRow(productRow{SKU: "NEW-1", Name: "New", Price: 1}, "admin", false, now)
+	if err != nil || !created {
+		t.Fatalf("importProductRow() = %v, %v, want created", created, err)
+	}
+	if len(products) != 2 || products[1].ID != 2 || products[1].SKU != "NEW-1" {
+		t.Errorf("products = %+v, want NEW-1 with ID 2", products)
+	}
+
+	if _, err := reserveStock(1, nil, 6, "", time.Minute, now); err != nil {
+		t.Fatalf("reserveStock() error = %v", err)
+	}
+	stock = 2
+	if _, err := importProductRow(productRow{SKU: "DELL-INS15", Name: "Laptop Dell", Price: 1, Stock: &stock}, "admin", false, now); err == nil {
+		t.Error("importProductRow() below reserved stock succeeded, want error")
+	}
+	if products[0].Price != 14000000 {
+		t.Errorf("rejected row changed the price to %v", products[0].Price)
+	}
+}
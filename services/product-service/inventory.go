@@ -0,0 +1,647 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Stock movement types recorded in the inventory ledger
+const (
+	MovementReceipt     = "receipt"
+	MovementAdjustment  = "adjustment"
+	MovementReservation = "reservation"
+	MovementRelease     = "release"
+	MovementFulfilment  = "fulfilment"
+)
+
+// Reservation statuses
+const (
+	ReservationActive    = "active"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+const (
+	defaultReservationTTL  = 15 * time.Minute
+	reservationSweepPeriod = 30 * time.Second
+)
+
+var (
+	errInsufficientStock  = errors.New("insufficient stock")
+	errStockTargetMissing = errors.New("product or variant not found")
+	errReservationMissing = errors.New("reservation not found")
+	errReservationClosed  = errors.New("reservation is no longer active")
+)
+
+var (
+	stockMovements    []models.StockMovement
+	reservations      []models.StockReservation
+	nextMovementID    = 1
+	nextReservationID = 1
+)
+
+// StockChangeRequest represents a receipt or adjustment of on-hand stock
+// @Description Stock receipt/adjustment payload
+type StockChangeRequest struct {
+	VariantID *int   `json:"variant_id" example:"1"`
+	Quantity  int    `json:"quantity" binding:"required" example:"5"`
+	Note      string `json:"note" example:"Nhập hàng từ nhà cung cấp"`
+}
+
+// ReserveStockRequest represents a request to hold stock for an order
+// @Description Stock reservation payload
+type ReserveStockRequest struct {
+	ProductID  int    `json:"product_id" binding:"required" example:"2"`
+	VariantID  *int   `json:"variant_id" example:"1"`
+	Quantity   int    `json:"quantity" binding:"required,gt=0" example:"1"`
+	TTLSeconds int    `json:"ttl_seconds" binding:"omitempty,gt=0" example:"900"`
+	Reference  string `json:"reference" example:"order-1001"`
+}
+
+func setupInventoryRoutes(r *gin.Engine, products *gin.RouterGroup) {
+	products.GET("/:id/inventory", getProductInventory)
+	products.GET("/:id/inventory/movements", getProductStockMovements)
+	products.POST("/:id/inventory/receipts", receiveProductStock)
+	products.POST("/:id/inventory/adjustments", adjustProductStock)
+
+	api := r.Group("/inventory")
+	{
+		api.POST("/reservations", createReservation)
+		api.GET("/reservations/:id", getReservation)
+		api.POST("/reservations/:id/commit", commitReservationHandler)
+		api.POST("/reservations/:id/release", releaseReservationHandler)
+	}
+}
+
+// GetProductInventory godoc
+// @Summary Get product stock levels
+// @Description Get on-hand, reserved and available stock for a product and each of its variants
+// @Tags inventory
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/inventory [get]
+func getProductInventory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	expireReservations(time.Now())
+
+	if findProduct(id) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	levels := []models.StockLevel{stockLevel(id, nil)}
+	for _, variant := range productVariants(id) {
+		levels = append(levels, stockLevel(id, intPtr(variant.ID)))
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy thông tin tồn kho thành công",
+		Data:    levels,
+	})
+}
+
+// GetProductStockMovements godoc
+// @Summary Get product stock ledger
+// @Description Get the append-only stock movement ledger of a product, oldest first
+// @Tags inventory
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/inventory/movements [get]
+func getProductStockMovements(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	if findProduct(id) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	result := []models.StockMovement{}
+	for _, movement := range stockMovements {
+		if movement.ProductID == id {
+			result = append(result, movement)
+		}
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy lịch sử tồn kho thành công",
+		Data:    result,
+	})
+}
+
+// ReceiveProductStock godoc
+// @Summary Receive stock
+// @Description Record a stock receipt, increasing on-hand quantity
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param receipt body StockChangeRequest true "Receipt data"
+// @Success 201 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/inventory/receipts [post]
+func receiveProductStock(c *gin.Context) {
+	changeProductStock(c, MovementReceipt)
+}
+
+// AdjustProductStock godoc
+// @Summary Adjust stock
+// @Description Record a signed stock adjustment (stock count, damage, ...). Adjustments that would leave available stock below zero are refused.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param adjustment body StockChangeRequest true "Adjustment data"
+// @Success 201 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /products/{id}/inventory/adjustments [post]
+func adjustProductStock(c *gin.Context) {
+	changeProductStock(c, MovementAdjustment)
+}
+
+func changeProductStock(c *gin.Context, movementType string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req StockChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if movementType == MovementReceipt && req.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   "receipt quantity must be positive",
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	movement, err := applyStockChange(id, req.VariantID, movementType, req.Quantity, req.Note, time.Now())
+	if err != nil {
+		c.JSON(inventoryErrorStatus(err), models.ErrorResponse{
+			Status:  "error",
+			Message: "Không thể cập nhật tồn kho",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Status:  "success",
+		Message: "Cập nhật tồn kho thành công",
+		Data:    movement,
+	})
+}
+
+// CreateReservation godoc
+// @Summary Reserve stock
+// @Description Atomically reserve stock for an order. The reservation expires automatically after its TTL unless committed.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param reservation body ReserveStockRequest true "Reservation data"
+// @Success 201 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /inventory/reservations [post]
+func createReservation(c *gin.Context) {
+	var req ReserveStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ttl := defaultReservationTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	reservation, err := reserveStock(req.ProductID, req.VariantID, req.Quantity, req.Reference, ttl, time.Now())
+	if err != nil {
+		c.JSON(inventoryErrorStatus(err), models.ErrorResponse{
+			Status:  "error",
+			Message: "Không thể giữ hàng",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Status:  "success",
+		Message: "Giữ hàng thành công",
+		Data:    reservation,
+	})
+}
+
+// GetReservation godoc
+// @Summary Get reservation by ID
+// @Description Get a stock reservation by ID
+// @Tags inventory
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /inventory/reservations/{id} [get]
+func getReservation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	expireReservations(time.Now())
+
+	i := findReservation(id)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy reservation",
+			Error:   errReservationMissing.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy thông tin reservation thành công",
+		Data:    reservations[i],
+	})
+}
+
+// CommitReservation godoc
+// @Summary Commit reservation
+// @Description Fulfil a reservation, permanently decrementing on-hand stock
+// @Tags inventory
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /inventory/reservations/{id}/commit [post]
+func commitReservationHandler(c *gin.Context) {
+	finishReservation(c, commitReservation, "Xác nhận xuất kho thành công")
+}
+
+// ReleaseReservation godoc
+// @Summary Release reservation
+// @Description Cancel a reservation and return the held quantity to available stock
+// @Tags inventory
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /inventory/reservations/{id}/release [post]
+func releaseReservationHandler(c *gin.Context) {
+	finishReservation(c, releaseReservation, "Hủy giữ hàng thành công")
+}
+
+func finishReservation(c *gin.Context, finish func(id int, now time.Time) (models.StockReservation, error), message string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	reservation, err := finish(id, time.Now())
+	if err != nil {
+		c.JSON(inventoryErrorStatus(err), models.ErrorResponse{
+			Status:  "error",
+			Message: "Không thể cập nhật reservation",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: message,
+		Data:    reservation,
+	})
+}
+
+func inventoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errStockTargetMissing), errors.Is(err, errReservationMissing):
+		return http.StatusNotFound
+	case errors.Is(err, errInsufficientStock), errors.Is(err, errReservationClosed):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// runReservationSweeper periodically expires reservations whose TTL has passed
+func runReservationSweeper(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		catalogMu.Lock()
+		expireReservations(now)
+		catalogMu.Unlock()
+	}
+}
+
+// The functions below implement the ledger. They expect the caller to hold catalogMu
+// for writing, which is what makes reserve and commit atomic.
+
+// stockOnHand returns a pointer to the on-hand counter of a product or variant
+func stockOnHand(productID int, variantID *int) (*int, error) {
+	if variantID == nil {
+		i := findProduct(productID)
+		if i < 0 {
+			return nil, errStockTargetMissing
+		}
+		return &products[i].Stock, nil
+	}
+
+	i := findVariant(productID, *variantID)
+	if i < 0 {
+		return nil, errStockTargetMissing
+	}
+	return &variants[i].Stock, nil
+}
+
+// stockReserved sums the quantity held by active reservations of a product or variant
+func stockReserved(productID int, variantID *int) int {
+	reserved := 0
+	for _, reservation := range reservations {
+		if reservation.Status == ReservationActive && reservation.ProductID == productID && sameVariant(reservation.VariantID, variantID) {
+			reserved += reservation.Quantity
+		}
+	}
+	return reserved
+}
+
+func stockLevel(productID int, variantID *int) models.StockLevel {
+	level := models.StockLevel{ProductID: productID, VariantID: variantID}
+	if onHand, err := stockOnHand(productID, variantID); err == nil {
+		level.OnHand = *onHand
+	}
+	level.Reserved = stockReserved(productID, variantID)
+	level.Available = level.OnHand - level.Reserved
+	return level
+}
+
+// applyStockChange records a receipt or adjustment and updates on-hand stock
+func applyStockChange(productID int, variantID *int, movementType string, quantity int, note string, now time.Time) (models.StockMovement, error) {
+	onHand, err := stockOnHand(productID, variantID)
+	if err != nil {
+		return models.StockMovement{}, err
+	}
+
+	if *onHand+quantity-stockReserved(productID, variantID) < 0 {
+		return models.StockMovement{}, errInsufficientStock
+	}
+
+	*onHand += quantity
+	stockChanged(productID, variantID, now)
+	return recordMovement(productID, variantID, movementType, quantity, nil, note, now), nil
+}
+
+// stockChanged records that the on-hand stock of a product or variant changed.
+// The product gets a new version and raises product.updated either way, so
+// caches keyed on its ETag see the new stock of the product or its variants.
+func stockChanged(productID int, variantID *int, now time.Time) {
+	if variantID != nil {
+		if i := findVariant(productID, *variantID); i >= 0 {
+			variants[i].UpdatedAt = now
+		}
+	}
+	productChanged(productID, now)
+}
+
+// reserveStock holds stock for an order, refusing to reserve more than is available
+func reserveStock(productID int, variantID *int, quantity int, reference string, ttl time.Duration, now time.Time) (models.StockReservation, error) {
+	expireReservations(now)
+
+	if _, err := stockOnHand(productID, variantID); err != nil {
+		return models.StockReservation{}, err
+	}
+	if stockLevel(productID, variantID).Available < quantity {
+		return models.StockReservation{}, errInsufficientStock
+	}
+
+	reservation := models.StockReservation{
+		ID:        nextReservationID,
+		ProductID: productID,
+		VariantID: variantID,
+		Quantity:  quantity,
+		Status:    ReservationActive,
+		Reference: reference,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	nextReservationID++
+	reservations = append(reservations, reservation)
+
+	recordMovement(productID, variantID, MovementReservation, quantity, intPtr(reservation.ID), reference, now)
+	return reservation, nil
+}
+
+// commitReservation fulfils an active reservation and decrements on-hand stock
+func commitReservation(id int, now time.Time) (models.StockReservation, error) {
+	expireReservations(now)
+
+	i := findReservation(id)
+	if i < 0 {
+		return models.StockReservation{}, errReservationMissing
+	}
+	reservation := &reservations[i]
+	if reservation.Status != ReservationActive {
+		return *reservation, errReservationClosed
+	}
+
+	onHand, err := stockOnHand(reservation.ProductID, reservation.VariantID)
+	if err != nil {
+		// The product or variant was deleted after the stock was reserved;
+		// release the reservation instead of leaving it to hold stock
+		closeReservation(i, ReservationReleased, "stock target deleted", now)
+		return *reservation, err
+	}
+
+	reservation.Status = ReservationCommitted
+	reservation.UpdatedAt = now
+	*onHand -= reservation.Quantity
+	stockChanged(reservation.ProductID, reservation.VariantID, now)
+
+	recordMovement(reservation.ProductID, reservation.VariantID, MovementFulfilment, -reservation.Quantity, intPtr(reservation.ID), reservation.Reference, now)
+	return *reservation, nil
+}
+
+// releaseReservation cancels an active reservation
+func releaseReservation(id int, now time.Time) (models.StockReservation, error) {
+	expireReservations(now)
+
+	i := findReservation(id)
+	if i < 0 {
+		return models.StockReservation{}, errReservationMissing
+	}
+	if reservations[i].Status != ReservationActive {
+		return reservations[i], errReservationClosed
+	}
+
+	closeReservation(i, ReservationReleased, "released", now)
+	return reservations[i], nil
+}
+
+// expireReservations releases every active reservation whose TTL has passed
+func expireReservations(now time.Time) int {
+	expired := 0
+	for i, reservation := range reservations {
+		if reservation.Status == ReservationActive && !now.Before(reservation.ExpiresAt) {
+			closeReservation(i, ReservationExpired, "expired", now)
+			expired++
+		}
+	}
+	return expired
+}
+
+func closeReservation(i int, status, note string, now time.Time) {
+	reservation := &reservations[i]
+	reservation.Status = status
+	reservation.UpdatedAt = now
+
+	recordMovement(reservation.ProductID, reservation.VariantID, MovementRelease, -reservation.Quantity, intPtr(reservation.ID), note, now)
+}
+
+func recordMovement(productID int, variantID *int, movementType string, quantity int, reservationID *int, note string, now time.Time) models.StockMovement {
+	level := stockLevel(productID, variantID)
+	movement := models.StockMovement{
+		ID:            nextMovementID,
+		ProductID:     productID,
+		VariantID:     variantID,
+		Type:          movementType,
+		Quantity:      quantity,
+		OnHand:        level.OnHand,
+		Reserved:      level.Reserved,
+		ReservationID: reservationID,
+		Note:          note,
+		CreatedAt:     now,
+	}
+	nextMovementID++
+	stockMovements = append(stockMovements, movement)
+	return movement
+}
+
+// recordOpeningBalances writes a receipt for the seeded stock so the ledger
+// accounts for every unit on hand
+func recordOpeningBalances(now time.Time) {
+	for _, product := range products {
+		if product.Stock > 0 {
+			recordMovement(product.ID, nil, MovementReceipt, product.Stock, nil, "opening balance", now)
+		}
+	}
+	for _, variant := range variants {
+		if variant.Stock > 0 {
+			recordMovement(variant.ProductID, intPtr(variant.ID), MovementReceipt, variant.Stock, nil, "opening balance", now)
+		}
+	}
+}
+
+func findReservation(id int) int {
+	for i, reservation := range reservations {
+		if reservation.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func sameVariant(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func resetInventory(stock int) {
+	products = []models.Product{{ID: 1, Name: "Laptop Dell", Price: 15000000, Stock: stock}}
+	variants = nil
+	stockMovements = nil
+	reservations = nil
+	nextMovementID = 1
+	nextReservationID = 1
+}
+
+func TestReserveStock_NeverOversells(t *testing.T) {
+	resetInventory(5)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			catalogMu.Lock()
+			_, err := reserveStock(1, nil, 1, "", time.Minute, time.Now())
+			catalogMu.Unlock()
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 5 {
+		t.Errorf("reserveStock() succeeded %d times, want 5", succeeded)
+	}
+	if level := stockLevel(1, nil); level.Available != 0 {
+		t.Errorf("available = %d, want 0", level.Available)
+	}
+}
+
+func TestCommitReservation_DecrementsOnHand(t *testing.T) {
+	resetInventory(5)
+	now := time.Now()
+
+	reservation, err := reserveStock(1, nil, 2, "order-1", time.Minute, now)
+	if err != nil {
+		t.Fatalf("reserveStock() error = %v", err)
+	}
+
+	if _, err := commitReservation(reservation.ID, now); err != nil {
+		t.Fatalf("commitReservation() error = %v", err)
+	}
+	if _, err := commitReservation(reservation.ID, now); err != errReservationClosed {
+		t.Errorf("second commitReservation() error = %v, want %v", err, errReservationClosed)
+	}
+
+	level := stockLevel(1, nil)
+	if level.OnHand != 3 || level.Reserved != 0 || level.Available != 3 {
+		t.Errorf("stock level = %+v, want on_hand=3 reserved=0 available=3", level)
+	}
+}
+
+func TestCommitReservation_ReleasesWhenProductDeleted(t *testing.T) {
+	resetInventory(5)
+	now := time.Now()
+
+	reservation, err := reserveStock(1, nil, 2, "order-1", time.Minute, now)
+	if err != nil {
+		t.Fatalf("reserveStock() error = %v", err)
+	}
+	softDeleteProduct(0, now)
+
+	if _, err := commitReservation(reservation.ID, now); err != errStockTargetMissing {
+		t.Fatalf("commitReservation() error = %v, want %v", err, errStockTargetMissing)
+	}
+	if status := reservations[0].Status; status != ReservationReleased {
+		t.Errorf("reservation status = %q, want %q", status, ReservationReleased)
+	}
+	if reserved := stockReserved(1, nil); reserved != 0 {
+		t.Errorf("reserved = %d, want 0", reserved)
+	}
+	if last := stockMovements[len(stockMovements)-1]; last.Type != MovementRelease || last.Quantity != -2 {
+		t.Errorf("last movement = %+v, want a release of 2", last)
+	}
+}
+
+func TestExpireReservations_ReleasesAfterTTL(t *testing.T) {
+	resetInventory(5)
+	now := time.Now()
+
+	reservation, err := reserveStock(1, nil, 5, "", time.Minute, now)
+	if err != nil {
+		t.Fatalf("reserveStock() error = %v", err)
+	}
+	if _, err := reserveStock(1, nil, 1, "", time.Minute, now); err != errInsufficientStock {
+		t.Errorf("reserveStock() error = %v, want %v", err, errInsufficientStock)
+	}
+
+	if expired := expireReservations(now.Add(2 * time.Minute)); expired != 1 {
+		t.Errorf("expireReservations() = %d, want 1", expired)
+	}
+	if _, err := commitReservation(reservation.ID, now.Add(2*time.Minute)); err != errReservationClosed {
+		t.Errorf("commitReservation() after expiry error = %v, want %v", err, errReservationClosed)
+	}
+	if level := stockLevel(1, nil); level.Available != 5 {
+		t.Errorf("available = %d, want 5", level.Available)
+	}
+}
+
+func TestApplyStockChange_RefusesNegativeAvailable(t *testing.T) {
+	resetInventory(2)
+	now := time.Now()
+
+	if _, err := reserveStock(1, nil, 2, "", time.Minute, now); err != nil {
+		t.Fatalf("reserveStock() error = %v", err)
+	}
+	if _, err := applyStockChange(1, nil, MovementAdjustment, -1, "damaged", now); err != errInsufficientStock {
+		t.Errorf("applyStockChange() error = %v, want %v", err, errInsufficientStock)
+	}
+	if _, err := applyStockChange(1, nil, MovementReceipt, 3, "", now); err != nil {
+		t.Errorf("applyStockChange() error = %v", err)
+	}
+	if level := stockLevel(1, nil); level.OnHand != 5 || level.Available != 3 {
+		t.Errorf("stock level = %+v, want on_hand=5 available=3", level)
+	}
+}
+
+func TestStockChanges_TouchProduct(t *testing.T) {
+	resetInventory(5)
+	eventQueue = make(chan productEvent, 10)
+	defer func() { eventQueue = nil }()
+	now := time.Now()
+
+	if _, err := applyStockChange(1, nil, MovementReceipt, 2, "", now); err != nil {
+		t.Fatalf("applyStockChange() error = %v", err)
+	}
+	reservation, err := reserveStock(1, nil, 3, "order-1", time.Minute, now.Add(time.Second))
+	if err != nil {
+		t.Fatalf("reserveStock() error = %v", err)
+	}
+	if products[0].Version != 1 {
+		t.Errorf("version after reserve = %d, want 1", products[0].Version)
+	}
+	if _, err := commitReservation(reservation.ID, now.Add(2*time.Second)); err != nil {
+		t.Fatalf("commitReservation() error = %v", err)
+	}
+
+	if products[0].Version != 2 || !products[0].UpdatedAt.Equal(now.Add(2*time.Second)) {
+		t.Errorf("version = %d updated_at = %v, want 2 and the commit time", products[0].Version, products[0].UpdatedAt)
+	}
+	if len(eventQueue) != 2 {
+		t.Fatalf("raised %d events, want 2", len(eventQueue))
+	}
+	if event := <-eventQueue; event.Type != EventProductUpdated {
+		t.Errorf("event type = %q, want %q", event.Type, EventProductUpdated)
+	}
+}
+
+func TestGetProductStockMovements_UnknownProduct(t *testing.T) {
+	resetInventory(5)
+	softDeleteProduct(0, time.Now())
+	products = append(products, models.Product{ID: 2, Name: "iPhone 14"})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	setupInventoryRoutes(r, r.Group("/products"))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/products/2/inventory/movements", http.StatusOK},
+		{"/products/1/inventory/movements", http.StatusNotFound},
+		{"/products/99/inventory/movements", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.wantStatus {
+			t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.wantStatus)
+		}
+	}
+}
@@ -7,13 +7,26 @@ package main
 // @BasePath /
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"app-microservice/services/product-service/storage"
 	"app-microservice/shared/config"
+	"app-microservice/shared/etag"
 	"app-microservice/shared/models"
+	"app-microservice/shared/patch"
+	"app-microservice/shared/svcauth"
+	"app-microservice/shared/tlsconfig"
 
 	_ "app-microservice/services/product-service/docs"
 
@@ -23,13 +36,35 @@ import (
 )
 
 var products = []models.Product{
-	{ID: 1, Name: "Laptop Dell", Description: "Laptop Dell Inspiron 15", Price: 15000000, Stock: 10, CreatedAt: time.Now(), UpdatedAt: time.Now()},
-	{ID: 2, Name: "iPhone 14", Description: "Apple iPhone 14 Pro", Price: 25000000, Stock: 5, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	{ID: 1, SKU: "DELL-INS15", Name: "Laptop Dell", Description: "Laptop Dell Inspiron 15", Price: 15000000, Stock: 10, CategoryIDs: []int{2}, Version: 1, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	{ID: 2, SKU: "IP14P", Name: "iPhone 14", Description: "Apple iPhone 14 Pro", Price: 25000000, Stock: 5, CategoryIDs: []int{3}, Version: 1, CreatedAt: time.Now(), UpdatedAt: time.Now()},
 }
 
+var nextProductID = len(products) + 1
+
+// catalogMu guards the in-memory products and categories
+var catalogMu sync.RWMutex
+
+// requireIfMatch makes PUT, PATCH and DELETE on a product fail with 428 unless
+// they carry an If-Match header
+var requireIfMatch bool
+
 func main() {
 	cfg := config.LoadConfig()
 
+	store, err := storage.NewFileSystem(getEnv("MEDIA_DIR", "./data/media"), getEnv("MEDIA_BASE_URL", "/media"))
+	if err != nil {
+		log.Fatalf("Không thể khởi tạo media storage: %v", err)
+	}
+	mediaStore = store
+	requireIfMatch, _ = strconv.ParseBool(getEnv("REQUIRE_IF_MATCH", "false"))
+
+	recordOpeningBalances(time.Now())
+	recordInitialPrices(time.Now())
+	go runReservationSweeper(reservationSweepPeriod)
+	go runPriceScheduler(priceSchedulerPeriod)
+	go runProductPurger(productPurgePeriod, getEnvAsDuration("PRODUCT_RETENTION", defaultProductRetention))
+
 	r := gin.Default()
 
 	// Middleware
@@ -37,6 +72,28 @@ func main() {
 	r.Use(gin.Recovery())
 	r.Use(corsMiddleware())
 
+	// Only the gateway may call the service when service auth is configured
+	var auth *svcauth.Authenticator
+	if keyFile := getEnv("SERVICE_AUTH_KEY_FILE", ""); keyFile != "" {
+		auth, err = svcauth.Load(getEnv("SERVICE_NAME", "product-service"), keyFile, getEnv("SERVICE_AUTH_PEERS", ""))
+		if err != nil {
+			log.Fatalf("Không thể tải service auth keys: %v", err)
+		}
+		r.Use(auth.Middleware("/health"))
+	} else {
+		log.Println("SERVICE_AUTH_KEY_FILE chưa được cấu hình, service chấp nhận mọi request")
+	}
+
+	// Product changes are sent to the user service, which only accepts
+	// events signed by a known service
+	if eventsURL := getEnv("EVENTS_URL", ""); eventsURL != "" {
+		if auth == nil {
+			log.Println("EVENTS_URL cần SERVICE_AUTH_KEY_FILE, không gửi event của product")
+		} else if err := startEventForwarder(auth, eventsURL); err != nil {
+			log.Fatalf("Không thể khởi tạo việc gửi event: %v", err)
+		}
+	}
+
 	// Routes
 	api := r.Group("/products")
 	{
@@ -44,9 +101,20 @@ func main() {
 		api.GET("/:id", getProductByID)
 		api.POST("", createProduct)
 		api.PUT("/:id", updateProduct)
+		api.PATCH("/:id", patchProduct)
 		api.DELETE("/:id", deleteProduct)
+		api.POST("/:id/restore", restoreProduct)
+		api.PUT("/:id/categories", setProductCategories)
+		setupVariantRoutes(api)
+		setupPriceRoutes(api)
+		setupImportExportRoutes(api)
 	}
 
+	setupInventoryRoutes(r, api)
+	setupMediaRoutes(r, api)
+
+	setupCategoryRoutes(r)
+
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -64,15 +132,40 @@ func main() {
 		port = cfg.Port
 	}
 
+	if certFile := getEnv("TLS_CERT_FILE", ""); certFile != "" {
+		tlsConfig, err := tlsconfig.Server(certFile, getEnv("TLS_KEY_FILE", ""), getEnv("TLS_MIN_VERSION", "1.2"))
+		if err != nil {
+			log.Fatalf("Không thể tải TLS certificate: %v", err)
+		}
+		server := &http.Server{Addr: ":" + port, Handler: r, TLSConfig: tlsConfig}
+		log.Printf("Product Service đang chạy trên port %s (HTTPS)", port)
+		log.Fatal(server.ListenAndServeTLS("", ""))
+	}
+
 	log.Printf("Product Service đang chạy trên port %s", port)
 	log.Fatal(http.ListenAndServe(":"+port, r))
 }
 
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if duration, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return duration
+	}
+	return defaultValue
+}
+
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, If-Match")
+		c.Header("Access-Control-Expose-Headers", "ETag")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -85,16 +178,51 @@ func corsMiddleware() gin.HandlerFunc {
 
 // GetProducts godoc
 // @Summary Get all products
-// @Description Get list of all products
+// @Description Search, filter, sort and paginate products
 // @Tags products
 // @Produce json
-// @Success 200 {object} models.Response
+// @Param q query string false "Full-text search across name and description"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param in_stock query bool false "Only products with (true) or without (false) available stock"
+// @Param category query string false "Category ID or slug"
+// @Param include_descendants query bool false "Also match products in descendant categories"
+// @Param sort query string false "Sort field" Enums(price, name, created_at, stock)
+// @Param order query string false "Sort order" Enums(asc, desc) default(asc)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} models.Response{data=models.ProductListResponse}
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
 // @Router /products [get]
 func getProducts(c *gin.Context) {
+	var req ProductSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Tham số truy vấn không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	result, total, err := searchProducts(&req)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy category",
+			Error:   err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, models.Response{
 		Status:  "success",
 		Message: "Lấy danh sách products thành công",
-		Data:    products,
+		Data:    toProductListResponse(result, total, req.Page, req.PageSize),
 	})
 }
 
@@ -105,6 +233,7 @@ func getProducts(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Product ID"
 // @Success 200 {object} models.Response
+// @Header 200 {string} ETag "Current product version"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Router /products/{id} [get]
@@ -119,15 +248,17 @@ func getProductByID(c *gin.Context) {
 		return
 	}
 
-	for _, product := range products {
-		if product.ID == id {
-			c.JSON(http.StatusOK, models.Response{
-				Status:  "success",
-				Message: "Lấy thông tin product thành công",
-				Data:    product,
-			})
-			return
-		}
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	if i := findProduct(id); i >= 0 {
+		c.Header("ETag", etag.Format(products[i].Version))
+		c.JSON(http.StatusOK, models.Response{
+			Status:  "success",
+			Message: "Lấy thông tin product thành công",
+			Data:    withVariants(products[i]),
+		})
+		return
 	}
 
 	c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -146,6 +277,7 @@ func getProductByID(c *gin.Context) {
 // @Param product body models.Product true "Product data"
 // @Success 201 {object} models.Response
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
 // @Router /products [post]
 func createProduct(c *gin.Context) {
 	var newProduct models.Product
@@ -158,13 +290,56 @@ func createProduct(c *gin.Context) {
 		return
 	}
 
+	if err := validateProduct(&newProduct); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	categoryIDs, err := normalizeCategoryIDs(newProduct.CategoryIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+	newProduct.CategoryIDs = categoryIDs
+	newProduct.Variants = nil // variants are managed through /products/{id}/variants
+
+	newProduct.SKU = normalizeSKU(newProduct.SKU)
+	if newProduct.SKU != "" && skuInUse(newProduct.SKU, 0, 0) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Status:  "error",
+			Message: "SKU đã tồn tại",
+			Error:   fmt.Sprintf("sku %q already exists", newProduct.SKU),
+		})
+		return
+	}
+
 	// Generate new ID
-	newProduct.ID = len(products) + 1
+	newProduct.ID = nextProductID
+	newProduct.Version = 1
+	newProduct.DeletedAt = nil
+	nextProductID++
 	newProduct.CreatedAt = time.Now()
 	newProduct.UpdatedAt = time.Now()
 
 	products = append(products, newProduct)
+	if newProduct.Stock > 0 {
+		recordMovement(newProduct.ID, nil, MovementReceipt, newProduct.Stock, nil, "opening balance", newProduct.CreatedAt)
+	}
+	recordPriceChange(newProduct.ID, 0, newProduct.Price, priceActor(c), "initial price", newProduct.CreatedAt)
+	raiseProductEvent(EventProductCreated, newProduct, newProduct.CreatedAt)
 
+	c.Header("ETag", etag.Format(newProduct.Version))
 	c.JSON(http.StatusCreated, models.Response{
 		Status:  "success",
 		Message: "Tạo product thành công",
@@ -174,15 +349,19 @@ func createProduct(c *gin.Context) {
 
 // UpdateProduct godoc
 // @Summary Update a product
-// @Description Update product data by ID
+// @Description Update product data by ID. Stock is managed through the inventory endpoints and is not changed here.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Param product body models.Product true "Updated product data"
 // @Success 200 {object} models.Response
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 428 {object} models.ErrorResponse
 // @Router /products/{id} [put]
 func updateProduct(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -205,38 +384,151 @@ func updateProduct(c *gin.Context) {
 		return
 	}
 
-	for i, product := range products {
-		if product.ID == id {
-			updatedProduct.ID = id
-			updatedProduct.CreatedAt = product.CreatedAt
-			updatedProduct.UpdatedAt = time.Now()
-			products[i] = updatedProduct
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
 
-			c.JSON(http.StatusOK, models.Response{
-				Status:  "success",
-				Message: "Cập nhật product thành công",
-				Data:    updatedProduct,
-			})
-			return
-		}
+	i := findProduct(id)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+	if !checkIfMatch(c, products[i].Version) {
+		return
 	}
 
-	c.JSON(http.StatusNotFound, models.ErrorResponse{
-		Status:  "error",
-		Message: "Không tìm thấy product",
-		Error:   "Product not found",
+	product, status, err := replaceProduct(i, updatedProduct, priceActor(c))
+	if err != nil {
+		c.JSON(status, models.ErrorResponse{
+			Status:  "error",
+			Message: productErrorMessage(status),
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", etag.Format(product.Version))
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Cập nhật product thành công",
+		Data:    product,
+	})
+}
+
+// PatchProduct godoc
+// @Summary Partially update a product
+// @Description Apply a JSON Merge Patch (application/merge-patch+json, RFC 7396) or a JSON Patch (application/json-patch+json, RFC 6902) to a product.
+// @Description Members set to null are cleared. id, stock, variants and timestamps are read-only, and validation runs on the patched product.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param patch body object true "Merge patch or JSON Patch document"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 428 {object} models.ErrorResponse
+// @Router /products/{id} [patch]
+func patchProduct(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	i := findProduct(id)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+	if !checkIfMatch(c, products[i].Version) {
+		return
+	}
+
+	original := products[i]
+	original.Variants = nil
+	doc, err := json.Marshal(original)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không thể cập nhật product",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	patched, err := patch.Apply(c.GetHeader("Content-Type"), doc, body)
+	if err == nil {
+		original, err = decodePatchedProduct(doc, patched)
+	}
+	if err != nil {
+		status := patchErrorStatus(err)
+		c.JSON(status, models.ErrorResponse{
+			Status:  "error",
+			Message: productErrorMessage(status),
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	product, status, err := replaceProduct(i, original, priceActor(c))
+	if err != nil {
+		c.JSON(status, models.ErrorResponse{
+			Status:  "error",
+			Message: productErrorMessage(status),
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", etag.Format(product.Version))
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Cập nhật product thành công",
+		Data:    product,
 	})
 }
 
 // DeleteProduct godoc
 // @Summary Delete a product
-// @Description Delete product by ID
+// @Description Soft-delete product by ID. It can be restored until the purge job removes it after PRODUCT_RETENTION.
 // @Tags products
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Success 200 {object} models.Response
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 428 {object} models.ErrorResponse
 // @Router /products/{id} [delete]
 func deleteProduct(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -249,16 +541,22 @@ func deleteProduct(c *gin.Context) {
 		return
 	}
 
-	for i, product := range products {
-		if product.ID == id {
-			products = append(products[:i], products[i+1:]...)
-			c.JSON(http.StatusOK, models.Response{
-				Status:  "success",
-				Message: "Xóa product thành công",
-				Data:    nil,
-			})
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	if i := findProduct(id); i >= 0 {
+		if !checkIfMatch(c, products[i].Version) {
 			return
 		}
+		now := time.Now()
+		softDeleteProduct(i, now)
+		raiseProductEvent(EventProductDeleted, products[i], now)
+		c.JSON(http.StatusOK, models.Response{
+			Status:  "success",
+			Message: "Xóa product thành công",
+			Data:    nil,
+		})
+		return
 	}
 
 	c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -267,3 +565,142 @@ func deleteProduct(c *gin.Context) {
 		Error:   "Product not found",
 	})
 }
+
+// productReadOnlyFields are managed by the service and cannot be patched
+var productReadOnlyFields = []string{"id", "stock", "variants", "version", "created_at", "updated_at", "deleted_at"}
+
+// checkIfMatch evaluates the If-Match precondition against the current product
+// version and writes a 428 or 412 response when it does not hold
+func checkIfMatch(c *gin.Context, version int) bool {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" && requireIfMatch {
+		c.JSON(http.StatusPreconditionRequired, models.ErrorResponse{
+			Status:  "error",
+			Message: "Thiếu header If-Match",
+			Error:   "If-Match header with the current ETag is required",
+		})
+		return false
+	}
+	if !etag.Match(ifMatch, version) {
+		c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{
+			Status:  "error",
+			Message: "Product đã bị thay đổi bởi người khác",
+			Error:   fmt.Sprintf("product version is %d, If-Match was %s", version, ifMatch),
+		})
+		return false
+	}
+	return true
+}
+
+func patchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, patch.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, patch.ErrTestFailed):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func productErrorMessage(status int) string {
+	switch status {
+	case http.StatusConflict:
+		return "Xung đột dữ liệu product"
+	case http.StatusUnsupportedMediaType:
+		return "Content-Type không được hỗ trợ"
+	default:
+		return "Dữ liệu không hợp lệ"
+	}
+}
+
+// decodePatchedProduct rejects changes to read-only fields and unknown members,
+// then decodes the patched document
+func decodePatchedProduct(original, patched []byte) (models.Product, error) {
+	var before, after map[string]json.RawMessage
+	if err := json.Unmarshal(original, &before); err != nil {
+		return models.Product{}, err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return models.Product{}, fmt.Errorf("%w: patched product must be a JSON object", patch.ErrInvalidPatch)
+	}
+	for _, field := range productReadOnlyFields {
+		if !bytes.Equal(before[field], after[field]) {
+			return models.Product{}, fmt.Errorf("%w: %s is read-only", patch.ErrInvalidPatch, field)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	var product models.Product
+	if err := decoder.Decode(&product); err != nil {
+		return models.Product{}, fmt.Errorf("%w: %v", patch.ErrInvalidPatch, err)
+	}
+	return product, nil
+}
+
+func validateProduct(product *models.Product) error {
+	product.Name = strings.TrimSpace(product.Name)
+	switch {
+	case product.Name == "":
+		return errors.New("name is required")
+	case product.Price < 0:
+		return errors.New("price must not be negative")
+	}
+	return nil
+}
+
+// The helpers below expect the caller to hold catalogMu.
+
+// touchProduct records a change to the product at index i by bumping its
+// version, which invalidates ETags handed out earlier
+func touchProduct(i int, now time.Time) {
+	products[i].Version++
+	products[i].UpdatedAt = now
+}
+
+// productChanged touches the product with the given ID, unless it is deleted,
+// and raises product.updated. It is used for changes made through its stock
+// or variants rather than to the product itself.
+func productChanged(productID int, now time.Time) {
+	if i := findProduct(productID); i >= 0 {
+		touchProduct(i, now)
+		raiseProductEvent(EventProductUpdated, products[i], now)
+	}
+}
+
+// replaceProduct validates updated and stores it at index i. Stock, variants and
+// timestamps are kept from the stored product; stock only changes through the
+// inventory ledger.
+func replaceProduct(i int, updated models.Product, actor string) (models.Product, int, error) {
+	if err := validateProduct(&updated); err != nil {
+		return models.Product{}, http.StatusBadRequest, err
+	}
+
+	categoryIDs, err := normalizeCategoryIDs(updated.CategoryIDs)
+	if err != nil {
+		return models.Product{}, http.StatusBadRequest, err
+	}
+
+	current := products[i]
+	updated.SKU = normalizeSKU(updated.SKU)
+	if updated.SKU != "" && skuInUse(updated.SKU, current.ID, 0) {
+		return models.Product{}, http.StatusConflict, fmt.Errorf("sku %q already exists", updated.SKU)
+	}
+
+	updated.ID = current.ID
+	updated.CategoryIDs = categoryIDs
+	updated.Variants = nil // variants are managed through /products/{id}/variants
+	updated.Stock = current.Stock
+	updated.Version = current.Version + 1
+	updated.CreatedAt = current.CreatedAt
+	updated.UpdatedAt = time.Now()
+	updated.DeletedAt = nil
+	if updated.Price != current.Price {
+		recordPriceChange(current.ID, current.Price, updated.Price, actor, "", updated.UpdatedAt)
+	}
+	products[i] = updated
+	raiseProductEvent(EventProductUpdated, updated, updated.UpdatedAt)
+
+	return withVariants(updated), http.StatusOK, nil
+}
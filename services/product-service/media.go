@@ -0,0 +1,578 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF decoder
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"app-microservice/services/product-service/storage"
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxImageUploadSize = 5 << 20 // 5 MB
+	maxImageDimension  = 8000
+)
+
+// allowedImageTypes lists the sniffed content types accepted for upload and their file extensions
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+var (
+	mediaStore         storage.Storage
+	productImages      []models.ProductImage
+	nextProductImageID = 1
+)
+
+// ImageOrderRequest represents the desired order of a product's images
+// @Description Product image order payload
+type ImageOrderRequest struct {
+	ImageIDs []int `json:"image_ids" binding:"required,min=1" example:"3,1,2"`
+}
+
+func setupMediaRoutes(r *gin.Engine, api *gin.RouterGroup) {
+	api.GET("/:id/images", getProductImages)
+	api.POST("/:id/images", uploadProductImage)
+	api.PUT("/:id/images/order", reorderProductImages)
+	api.PUT("/:id/images/:imageId/primary", setPrimaryProductImage)
+	api.DELETE("/:id/images/:imageId", deleteProductImage)
+
+	r.GET("/media/*key", serveMedia)
+}
+
+// GetProductImages godoc
+// @Summary Get product images
+// @Description Get the images of a product in display order
+// @Tags images
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/images [get]
+func getProductImages(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	if findProduct(id) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy danh sách ảnh thành công",
+		Data:    imagesOfProduct(id),
+	})
+}
+
+// UploadProductImage godoc
+// @Summary Upload a product image
+// @Description Upload a JPEG, PNG or GIF image (max 5 MB). Small, medium and large thumbnails are generated automatically.
+// @Tags images
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param file formData file true "Image file"
+// @Param primary formData bool false "Make this the primary image"
+// @Success 201 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Router /products/{id}/images [post]
+func uploadProductImage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.RLock()
+	exists := findProduct(id) >= 0
+	catalogMu.RUnlock()
+	if !exists {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	// Leave some room for the multipart envelope around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageUploadSize+(1<<20))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		status := http.StatusBadRequest
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, models.ErrorResponse{
+			Status:  "error",
+			Message: "File ảnh không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if fileHeader.Size > maxImageUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Status:  "error",
+			Message: "File ảnh quá lớn",
+			Error:   fmt.Sprintf("image must not exceed %d bytes", maxImageUploadSize),
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "File ảnh không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImageUploadSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "File ảnh không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Trust the bytes, not the client-supplied Content-Type
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{
+			Status:  "error",
+			Message: "Định dạng ảnh không được hỗ trợ",
+			Error:   fmt.Sprintf("unsupported content type %s", contentType),
+		})
+		return
+	}
+
+	img, err := storeProductImage(c.Request.Context(), id, data, contentType, ext)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không thể lưu ảnh",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	// The product may have been deleted while the image was being processed
+	if findProduct(id) < 0 {
+		deleteStoredObjects(c.Request.Context(), img.StorageKeys)
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	existing := imagesOfProduct(id)
+	img.ID = nextProductImageID
+	img.Position = len(existing)
+	img.IsPrimary = len(existing) == 0 || c.PostForm("primary") == "true"
+	nextProductImageID++
+
+	if img.IsPrimary {
+		clearPrimaryImage(id)
+	}
+	productImages = append(productImages, img)
+
+	c.JSON(http.StatusCreated, models.Response{
+		Status:  "success",
+		Message: "Tải ảnh lên thành công",
+		Data:    img,
+	})
+}
+
+// ReorderProductImages godoc
+// @Summary Reorder product images
+// @Description Set the display order of a product's images. image_ids must list every image of the product exactly once.
+// @Tags images
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param order body ImageOrderRequest true "Image IDs in display order"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/images/order [put]
+func reorderProductImages(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req ImageOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	if findProduct(id) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	existing := imagesOfProduct(id)
+	if len(req.ImageIDs) != len(existing) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   "image_ids must list every image of the product exactly once",
+		})
+		return
+	}
+
+	positions := make(map[int]int, len(req.ImageIDs))
+	for position, imageID := range req.ImageIDs {
+		if _, dup := positions[imageID]; dup || findProductImage(id, imageID) < 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Status:  "error",
+				Message: "Dữ liệu không hợp lệ",
+				Error:   "image_ids must list every image of the product exactly once",
+			})
+			return
+		}
+		positions[imageID] = position
+	}
+
+	for i := range productImages {
+		if productImages[i].ProductID == id {
+			productImages[i].Position = positions[productImages[i].ID]
+		}
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Sắp xếp ảnh thành công",
+		Data:    imagesOfProduct(id),
+	})
+}
+
+// SetPrimaryProductImage godoc
+// @Summary Set primary product image
+// @Description Mark an image as the primary image of its product
+// @Tags images
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param imageId path int true "Image ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/images/{imageId}/primary [put]
+func setPrimaryProductImage(c *gin.Context) {
+	id, imageID, err := parseImageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	i := findProductImage(id, imageID)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy ảnh",
+			Error:   "Image not found",
+		})
+		return
+	}
+
+	clearPrimaryImage(id)
+	productImages[i].IsPrimary = true
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Đặt ảnh chính thành công",
+		Data:    productImages[i],
+	})
+}
+
+// DeleteProductImage godoc
+// @Summary Delete a product image
+// @Description Delete an image and its thumbnails
+// @Tags images
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param imageId path int true "Image ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/images/{imageId} [delete]
+func deleteProductImage(c *gin.Context) {
+	id, imageID, err := parseImageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	i := findProductImage(id, imageID)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy ảnh",
+			Error:   "Image not found",
+		})
+		return
+	}
+
+	removed := productImages[i]
+	productImages = append(productImages[:i], productImages[i+1:]...)
+	deleteStoredObjects(c.Request.Context(), removed.StorageKeys)
+
+	// Close the gap in positions and promote a new primary image if needed
+	remaining := imagesOfProduct(id)
+	for position, img := range remaining {
+		j := findProductImage(id, img.ID)
+		productImages[j].Position = position
+		if removed.IsPrimary && position == 0 {
+			productImages[j].IsPrimary = true
+		}
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Xóa ảnh thành công",
+		Data:    nil,
+	})
+}
+
+// serveMedia streams a stored object to the client
+func serveMedia(c *gin.Context) {
+	key := c.Param("key")
+	if len(key) > 0 && key[0] == '/' {
+		key = key[1:]
+	}
+
+	reader, object, err := mediaStore.Get(c.Request.Context(), key)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, storage.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy file",
+			Error:   err.Error(),
+		})
+		return
+	}
+	defer reader.Close()
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.DataFromReader(http.StatusOK, object.Size, object.ContentType, reader, nil)
+}
+
+// storeProductImage decodes an uploaded image, writes the original and its
+// thumbnails to the media store and returns the image metadata (without ID/position)
+func storeProductImage(ctx context.Context, productID int, data []byte, contentType, ext string) (models.ProductImage, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return models.ProductImage{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	if config.Width > maxImageDimension || config.Height > maxImageDimension {
+		return models.ProductImage{}, fmt.Errorf("image dimensions must not exceed %dx%d", maxImageDimension, maxImageDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return models.ProductImage{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	name, err := randomHex(8)
+	if err != nil {
+		return models.ProductImage{}, fmt.Errorf("failed to name image: %w", err)
+	}
+	prefix := fmt.Sprintf("products/%d/%s", productID, name)
+	result := models.ProductImage{
+		ProductID:   productID,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       config.Width,
+		Height:      config.Height,
+		Thumbnails:  make(map[string]string, len(thumbnailSizes)),
+		CreatedAt:   time.Now(),
+	}
+
+	originalKey := prefix + "/original" + ext
+	if err := mediaStore.Put(ctx, originalKey, bytes.NewReader(data), contentType); err != nil {
+		return models.ProductImage{}, err
+	}
+	result.URL = mediaStore.URL(originalKey)
+	result.StorageKeys = append(result.StorageKeys, originalKey)
+
+	for name, size := range thumbnailSizes {
+		encoded, thumbType, thumbExt, err := encodeImage(resizeImage(img, size), contentType)
+		if err != nil {
+			deleteStoredObjects(ctx, result.StorageKeys)
+			return models.ProductImage{}, fmt.Errorf("failed to generate %s thumbnail: %w", name, err)
+		}
+
+		key := prefix + "/" + name + thumbExt
+		if err := mediaStore.Put(ctx, key, bytes.NewReader(encoded), thumbType); err != nil {
+			deleteStoredObjects(ctx, result.StorageKeys)
+			return models.ProductImage{}, err
+		}
+		result.Thumbnails[name] = mediaStore.URL(key)
+		result.StorageKeys = append(result.StorageKeys, key)
+	}
+
+	return result, nil
+}
+
+func parseImageParams(c *gin.Context) (int, int, error) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, err
+	}
+	imageID, err := strconv.Atoi(c.Param("imageId"))
+	if err != nil {
+		return 0, 0, err
+	}
+	return productID, imageID, nil
+}
+
+// deleteStoredObjects removes the given objects from the media store. Nothing
+// refers to them any more, so failures are only logged.
+func deleteStoredObjects(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := mediaStore.Delete(ctx, key); err != nil {
+			log.Printf("Không thể xóa file %s: %v", key, err)
+		}
+	}
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// The helpers below expect the caller to hold catalogMu.
+
+// imagesOfProduct returns a product's images in display order
+func imagesOfProduct(productID int) []models.ProductImage {
+	result := []models.ProductImage{}
+	for _, img := range productImages {
+		if img.ProductID == productID {
+			result = append(result, img)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Position < result[j].Position
+	})
+	return result
+}
+
+func findProductImage(productID, imageID int) int {
+	for i, img := range productImages {
+		if img.ID == imageID && img.ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
+
+func clearPrimaryImage(productID int) {
+	for i := range productImages {
+		if productImages[i].ProductID == productID {
+			productImages[i].IsPrimary = false
+		}
+	}
+}
+
+// deleteProductImages removes every image of a product together with its stored files
+func deleteProductImages(ctx context.Context, productID int) {
+	remaining := productImages[:0]
+	for _, img := range productImages {
+		if img.ProductID == productID {
+			deleteStoredObjects(ctx, img.StorageKeys)
+			continue
+		}
+		remaining = append(remaining, img)
+	}
+	productImages = remaining
+}
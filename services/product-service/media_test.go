@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"app-microservice/services/product-service/storage"
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryStore keeps media objects in memory
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *memoryStore) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) (io.ReadCloser, *storage.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), &storage.Object{Key: key, Size: int64(len(data))}, nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memoryStore) URL(key string) string {
+	return "/media/" + key
+}
+
+// resetMedia gives the inventory product an empty in-memory media store and
+// returns a router serving the media routes
+func resetMedia() (*memoryStore, *gin.Engine) {
+	resetInventory(5)
+	store := &memoryStore{objects: make(map[string][]byte)}
+	mediaStore = store
+	productImages = nil
+	nextProductImageID = 1
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	setupMediaRoutes(r, r.Group("/products"))
+	return store, r
+}
+
+// pngImage encodes a blank PNG of the given size
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// uploadImage posts data as the file of an image upload to product 1
+func uploadImage(t *testing.T, r *gin.Engine, filename string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("CreateFormFile() error = %v", err)
+	}
+	part.Write(data)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/products/1/images", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestUploadProductImage_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		filename   string
+		data       func(t *testing.T) []byte
+		wantStatus int
+	}{
+		{"PNG", "photo.png", func(t *testing.T) []byte { return pngImage(t, 10, 10) }, http.StatusCreated},
+		{"text named as JPEG", "photo.jpg", func(t *testing.T) []byte { return []byte("not an image at all") }, http.StatusUnsupportedMediaType},
+		{"too large", "photo.png", func(t *testing.T) []byte { return make([]byte, maxImageUploadSize+(2<<20)) }, http.StatusRequestEntityTooLarge},
+		{"too wide", "photo.png", func(t *testing.T) []byte { return pngImage(t, maxImageDimension+1, 1) }, http.StatusBadRequest},
+		{"too tall", "photo.png", func(t *testing.T) []byte { return pngImage(t, 1, maxImageDimension+1) }, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, r := resetMedia()
+			w := uploadImage(t, r, tt.filename, tt.data(t))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("POST = %d %s, want %d", w.Code, w.Body.String(), tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusCreated && (len(productImages) != 0 || len(store.objects) != 0) {
+				t.Errorf("rejected upload left %d images and %d objects, want none", len(productImages), len(store.objects))
+			}
+		})
+	}
+}
+
+func TestUploadProductImage_StoresThumbnails(t *testing.T) {
+	store, r := resetMedia()
+
+	w := uploadImage(t, r, "photo.png", pngImage(t, 1000, 500))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST = %d %s, want 201", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data models.ProductImage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response = %s: %v", w.Body.String(), err)
+	}
+	if !resp.Data.IsPrimary || resp.Data.Width != 1000 || resp.Data.Height != 500 {
+		t.Errorf("image = %+v, want the primary 1000x500 image", resp.Data)
+	}
+
+	// The original plus one object per thumbnail
+	if len(store.objects) != 1+len(thumbnailSizes) {
+		t.Fatalf("stored %d objects, want %d", len(store.objects), 1+len(thumbnailSizes))
+	}
+	for name, size := range thumbnailSizes {
+		url, ok := resp.Data.Thumbnails[name]
+		if !ok {
+			t.Errorf("no %s thumbnail", name)
+			continue
+		}
+		config, _, err := image.DecodeConfig(bytes.NewReader(store.objects[strings.TrimPrefix(url, "/media/")]))
+		if err != nil {
+			t.Errorf("%s thumbnail: %v", name, err)
+			continue
+		}
+		if config.Width != size || config.Height != size/2 {
+			t.Errorf("%s thumbnail = %dx%d, want %dx%d", name, config.Width, config.Height, size, size/2)
+		}
+	}
+}
+
+func TestReorderProductImages(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantIDs    []int
+	}{
+		{"every image", `{"image_ids":[3,1,2]}`, http.StatusOK, []int{3, 1, 2}},
+		{"missing an image", `{"image_ids":[3,1]}`, http.StatusBadRequest, []int{1, 2, 3}},
+		{"duplicate image", `{"image_ids":[3,1,1]}`, http.StatusBadRequest, []int{1, 2, 3}},
+		{"image of another product", `{"image_ids":[3,1,4]}`, http.StatusBadRequest, []int{1, 2, 3}},
+		{"empty", `{"image_ids":[]}`, http.StatusBadRequest, []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, r := resetMedia()
+			productImages = []models.ProductImage{
+				{ID: 1, ProductID: 1, Position: 0, IsPrimary: true},
+				{ID: 2, ProductID: 1, Position: 1},
+				{ID: 3, ProductID: 1, Position: 2},
+				{ID: 4, ProductID: 2, Position: 0, IsPrimary: true},
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/products/1/images/order", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("PUT %s = %d, want %d", tt.body, w.Code, tt.wantStatus)
+			}
+
+			ids := []int{}
+			for _, img := range imagesOfProduct(1) {
+				ids = append(ids, img.ID)
+			}
+			if len(ids) != len(tt.wantIDs) || ids[0] != tt.wantIDs[0] || ids[1] != tt.wantIDs[1] || ids[2] != tt.wantIDs[2] {
+				t.Errorf("order = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestDeleteProductImage_RemovesStoredObjects(t *testing.T) {
+	store, r := resetMedia()
+	for i := 0; i < 2; i++ {
+		if w := uploadImage(t, r, "photo.png", pngImage(t, 500, 500)); w.Code != http.StatusCreated {
+			t.Fatalf("POST = %d %s, want 201", w.Code, w.Body.String())
+		}
+	}
+	removed := productImages[0]
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/products/1/images/1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("DELETE = %d, want 200", w.Code)
+	}
+
+	for _, key := range removed.StorageKeys {
+		if _, ok := store.objects[key]; ok {
+			t.Errorf("object %s of the deleted image is still stored", key)
+		}
+	}
+	if len(store.objects) != len(productImages[0].StorageKeys) {
+		t.Errorf("stored %d objects, want only the %d of the remaining image", len(store.objects), len(productImages[0].StorageKeys))
+	}
+	if !productImages[0].IsPrimary || productImages[0].Position != 0 {
+		t.Errorf("remaining image = %+v, want it promoted to primary at position 0", productImages[0])
+	}
+}
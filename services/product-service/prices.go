@@ -0,0 +1,339 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"app-microservice/shared/models"
+	"app-microservice/shared/svcauth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Price change statuses
+const (
+	PriceScheduled = "scheduled"
+	PriceApplied   = "applied"
+	PriceCancelled = "cancelled"
+)
+
+const priceSchedulerPeriod = 15 * time.Second
+
+var (
+	errPriceChangeMissing = errors.New("price change not found")
+	errPriceChangeClosed  = errors.New("only scheduled price changes can be cancelled")
+)
+
+var (
+	priceChanges      []models.PriceChange
+	nextPriceChangeID = 1
+)
+
+// PriceChangeRequest represents a price change, applied now or scheduled for later
+// @Description Price change payload
+type PriceChangeRequest struct {
+	Price         *float64   `json:"price" binding:"required,gte=0" example:"14000000"`
+	EffectiveFrom *time.Time `json:"effective_from" example:"2025-12-01T00:00:00Z"`
+	Reason        string     `json:"reason" example:"Khuyến mãi cuối tuần"`
+}
+
+func setupPriceRoutes(api *gin.RouterGroup) {
+	api.GET("/:id/prices", getProductPrices)
+	api.POST("/:id/prices", createProductPrice)
+	api.DELETE("/:id/prices/:priceId", cancelProductPrice)
+}
+
+// GetProductPrices godoc
+// @Summary Get product price history
+// @Description Get every recorded and scheduled price change of a product, ordered by effective time
+// @Tags prices
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/prices [get]
+func getProductPrices(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	if findProduct(id) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	history := []models.PriceChange{}
+	for _, change := range priceChanges {
+		if change.ProductID == id {
+			history = append(history, change)
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].EffectiveFrom.Before(history[j].EffectiveFrom)
+	})
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy lịch sử giá thành công",
+		Data:    history,
+	})
+}
+
+// CreateProductPrice godoc
+// @Summary Change product price
+// @Description Change the price of a product now, or schedule it for a future effective_from time
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param price body PriceChangeRequest true "Price change data"
+// @Success 201 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/prices [post]
+func createProductPrice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req PriceChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	i := findProduct(id)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	now := time.Now()
+	effectiveFrom := now
+	if req.EffectiveFrom != nil && req.EffectiveFrom.After(now) {
+		effectiveFrom = *req.EffectiveFrom
+	}
+
+	change := schedulePriceChange(id, *req.Price, effectiveFrom, priceActor(c), req.Reason, now)
+	if !effectiveFrom.After(now) {
+		applyPriceChange(len(priceChanges)-1, now)
+		change = priceChanges[len(priceChanges)-1]
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Status:  "success",
+		Message: "Cập nhật giá thành công",
+		Data:    change,
+	})
+}
+
+// CancelProductPrice godoc
+// @Summary Cancel a scheduled price change
+// @Description Cancel a price change that has not taken effect yet
+// @Tags prices
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param priceId path int true "Price change ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /products/{id}/prices/{priceId} [delete]
+func cancelProductPrice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+	priceID, err := strconv.Atoi(c.Param("priceId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	change, err := cancelPriceChange(id, priceID)
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, errPriceChangeClosed) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không thể hủy thay đổi giá",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Hủy thay đổi giá thành công",
+		Data:    change,
+	})
+}
+
+// priceActor identifies who is changing a price. X-User-ID is only trusted on
+// calls signed by the gateway, which sets it from the caller's access token;
+// anyone else could write any name into the price history.
+func priceActor(c *gin.Context) string {
+	if svcauth.Caller(c) != "api-gateway" {
+		return "anonymous"
+	}
+	if actor := c.GetHeader("X-User-ID"); actor != "" {
+		return actor
+	}
+	return "anonymous"
+}
+
+// runPriceScheduler periodically applies scheduled price changes that have become due
+func runPriceScheduler(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		catalogMu.Lock()
+		applyDuePriceChanges(now)
+		catalogMu.Unlock()
+	}
+}
+
+// The functions below expect the caller to hold catalogMu for writing.
+
+// schedulePriceChange appends a scheduled entry to the price history
+func schedulePriceChange(productID int, price float64, effectiveFrom time.Time, actor, reason string, now time.Time) models.PriceChange {
+	change := models.PriceChange{
+		ID:            nextPriceChangeID,
+		ProductID:     productID,
+		Price:         price,
+		EffectiveFrom: effectiveFrom,
+		Status:        PriceScheduled,
+		ChangedBy:     actor,
+		Reason:        reason,
+		CreatedAt:     now,
+	}
+	nextPriceChangeID++
+	priceChanges = append(priceChanges, change)
+	return change
+}
+
+// recordPriceChange records a price that took effect immediately
+func recordPriceChange(productID int, oldPrice, price float64, actor, reason string, now time.Time) {
+	change := schedulePriceChange(productID, price, now, actor, reason, now)
+	i := len(priceChanges) - 1
+	priceChanges[i].OldPrice = oldPrice
+	priceChanges[i].Status = PriceApplied
+	priceChanges[i].AppliedAt = &change.CreatedAt
+}
+
+// applyPriceChange makes the price change at index i the product's current
+// price and reports whether it did. A change of a soft-deleted product stays
+// scheduled until the product is restored; one of a purged product is cancelled.
+func applyPriceChange(i int, now time.Time) bool {
+	change := &priceChanges[i]
+	p := findProduct(change.ProductID)
+	if p < 0 {
+		if findProductIncludingDeleted(change.ProductID) < 0 {
+			change.Status = PriceCancelled
+		}
+		return false
+	}
+
+	change.OldPrice = products[p].Price
+	change.Status = PriceApplied
+	change.AppliedAt = &now
+
+	products[p].Price = change.Price
+	touchProduct(p, now)
+	raiseProductEvent(EventProductUpdated, products[p], now)
+	return true
+}
+
+// applyDuePriceChanges applies every scheduled change whose effective time has passed,
+// oldest first so that the latest one wins, and returns how many it applied
+func applyDuePriceChanges(now time.Time) int {
+	var due []int
+	for i, change := range priceChanges {
+		if change.Status == PriceScheduled && !change.EffectiveFrom.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		return priceChanges[due[a]].EffectiveFrom.Before(priceChanges[due[b]].EffectiveFrom)
+	})
+
+	applied := 0
+	for _, i := range due {
+		if applyPriceChange(i, now) {
+			applied++
+		}
+	}
+	return applied
+}
+
+// cancelPriceChange cancels a scheduled price change of a product
+func cancelPriceChange(productID, id int) (models.PriceChange, error) {
+	for i := range priceChanges {
+		change := &priceChanges[i]
+		if change.ID != id || change.ProductID != productID {
+			continue
+		}
+		if change.Status != PriceScheduled {
+			return *change, errPriceChangeClosed
+		}
+		change.Status = PriceCancelled
+		return *change, nil
+	}
+	return models.PriceChange{}, errPriceChangeMissing
+}
+
+// recordInitialPrices writes the seeded prices as the first history entries
+func recordInitialPrices(now time.Time) {
+	for _, product := range products {
+		recordPriceChange(product.ID, 0, product.Price, "system", "initial price", now)
+	}
+}
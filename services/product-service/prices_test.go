@@ -0,0 +1,156 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"app-microservice/shared/models"
+	"app-microservice/shared/svcauth"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestApplyDuePriceChanges(t *testing.T) {
+	products = []models.Product{{ID: 1, Name: "Laptop Dell", Price: 15000000}}
+	priceChanges = nil
+	nextPriceChangeID = 1
+
+	now := time.Now()
+	schedulePriceChange(1, 13000000, now.Add(2*time.Hour), "admin", "", now)
+	schedulePriceChange(1, 14000000, now.Add(time.Hour), "admin", "", now)
+	cancelled := schedulePriceChange(1, 1, now.Add(time.Hour), "admin", "", now)
+	if _, err := cancelPriceChange(1, cancelled.ID); err != nil {
+		t.Fatalf("cancelPriceChange() error = %v", err)
+	}
+
+	if applied := applyDuePriceChanges(now); applied != 0 {
+		t.Errorf("applyDuePriceChanges() before due = %d, want 0", applied)
+	}
+
+	if applied := applyDuePriceChanges(now.Add(3 * time.Hour)); applied != 2 {
+		t.Errorf("applyDuePriceChanges() = %d, want 2", applied)
+	}
+	if products[0].Price != 13000000 {
+		t.Errorf("price = %v, want the latest effective price 13000000", products[0].Price)
+	}
+	if priceChanges[0].OldPrice != 14000000 || priceChanges[1].OldPrice != 15000000 {
+		t.Errorf("old prices = %v, %v, want 14000000, 15000000", priceChanges[0].OldPrice, priceChanges[1].OldPrice)
+	}
+	if _, err := cancelPriceChange(1, priceChanges[0].ID); err != errPriceChangeClosed {
+		t.Errorf("cancelPriceChange() on applied change error = %v, want %v", err, errPriceChangeClosed)
+	}
+}
+
+func TestApplyDuePriceChanges_WaitsForDeletedProducts(t *testing.T) {
+	now := time.Now()
+	// Product 2 has been purged
+	products = []models.Product{{ID: 1, Name: "Laptop Dell", Price: 15000000, DeletedAt: &now}}
+	priceChanges = nil
+	nextPriceChangeID = 1
+
+	schedulePriceChange(1, 14000000, now.Add(-time.Hour), "admin", "", now.Add(-2*time.Hour))
+	schedulePriceChange(2, 24000000, now.Add(-time.Hour), "admin", "", now.Add(-2*time.Hour))
+
+	if applied := applyDuePriceChanges(now); applied != 0 {
+		t.Errorf("applyDuePriceChanges() while deleted = %d, want 0", applied)
+	}
+	if priceChanges[0].Status != PriceScheduled || priceChanges[1].Status != PriceCancelled {
+		t.Fatalf("statuses = %q, %q, want the deleted product's change scheduled and the purged one's cancelled", priceChanges[0].Status, priceChanges[1].Status)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/products/:id/restore", restoreProduct)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/products/1/restore", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST restore = %d, want 200", w.Code)
+	}
+	if products[0].Price != 14000000 || priceChanges[0].Status != PriceApplied {
+		t.Errorf("price = %v with change %q, want 14000000 applied on restore", products[0].Price, priceChanges[0].Status)
+	}
+}
+
+func TestCreateProductPrice_AcceptsSamePricesAsProducts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	setupPriceRoutes(r.Group("/products"))
+
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantPrice  float64
+	}{
+		{`{"price": 14000000}`, http.StatusCreated, 14000000},
+		{`{"price": 0, "reason": "Quà tặng"}`, http.StatusCreated, 0},
+		{`{"price": -1}`, http.StatusBadRequest, 15000000},
+		{`{"reason": "Thiếu giá"}`, http.StatusBadRequest, 15000000},
+	}
+	for _, tt := range tests {
+		products = []models.Product{{ID: 1, Name: "Laptop Dell", Price: 15000000}}
+		priceChanges = nil
+		nextPriceChangeID = 1
+
+		req := httptest.NewRequest(http.MethodPost, "/products/1/prices", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.wantStatus || products[0].Price != tt.wantPrice {
+			t.Errorf("POST %s = %d with price %v, want %d with price %v", tt.body, w.Code, products[0].Price, tt.wantStatus, tt.wantPrice)
+		}
+	}
+}
+
+func TestPriceActor_TrustsOnlyTheGateway(t *testing.T) {
+	gatewayPublic, gatewayKey, _ := ed25519.GenerateKey(nil)
+	userServicePublic, userServiceKey, _ := ed25519.GenerateKey(nil)
+	_, productKey, _ := ed25519.GenerateKey(nil)
+	auth := svcauth.New("product-service", productKey, map[string]ed25519.PublicKey{
+		"api-gateway":  gatewayPublic,
+		"user-service": userServicePublic,
+	})
+	gateway := svcauth.New("api-gateway", gatewayKey, nil)
+	userService := svcauth.New("user-service", userServiceKey, nil)
+
+	gin.SetMode(gin.TestMode)
+	actor := func(c *gin.Context) { c.String(http.StatusOK, priceActor(c)) }
+	signed := gin.New()
+	signed.Use(auth.Middleware())
+	signed.GET("/", actor)
+	unsigned := gin.New()
+	unsigned.GET("/", actor)
+
+	tests := []struct {
+		name   string
+		router *gin.Engine
+		caller *svcauth.Authenticator
+		want   string
+	}{
+		{"gateway", signed, gateway, "42"},
+		{"other service", signed, userService, "anonymous"},
+		{"service auth not configured", unsigned, nil, "anonymous"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-User-ID", "42")
+			if tt.caller != nil {
+				token, _, err := tt.caller.Sign("product-service")
+				if err != nil {
+					t.Fatalf("Sign() error = %v", err)
+				}
+				req.Header.Set(svcauth.Header, token)
+			}
+			w := httptest.NewRecorder()
+			tt.router.ServeHTTP(w, req)
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("priceActor() = %q (status %d), want %q", got, w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"app-microservice/shared/models"
+)
+
+var errCategoryNotFound = errors.New("category not found")
+
+// ProductSearchRequest represents the query parameters accepted by GET /products
+type ProductSearchRequest struct {
+	Query              string   `form:"q" binding:"omitempty,max=100"`
+	MinPrice           *float64 `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice           *float64 `form:"max_price" binding:"omitempty,gte=0"`
+	InStock            *bool    `form:"in_stock"`
+	Category           string   `form:"category"`
+	IncludeDescendants bool     `form:"include_descendants"`
+	Sort               string   `form:"sort" binding:"omitempty,oneof=price name created_at stock"`
+	Order              string   `form:"order" binding:"omitempty,oneof=asc desc"`
+	Page               int      `form:"page" binding:"omitempty,min=1"`
+	PageSize           int      `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// searchProducts filters, sorts and paginates the catalogue. It returns the
+// requested page together with the total number of matching products.
+// The caller must hold catalogMu.
+func searchProducts(req *ProductSearchRequest) ([]models.Product, int64, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	var categoryIDs []int
+	if req.Category != "" {
+		i := findCategoryByRef(req.Category)
+		if i < 0 {
+			return nil, 0, errCategoryNotFound
+		}
+		categoryIDs = []int{categories[i].ID}
+		if req.IncludeDescendants {
+			categoryIDs = categoryDescendantIDs(categories[i].ID)
+		}
+	}
+
+	query := strings.ToLower(strings.TrimSpace(req.Query))
+
+	matched := []models.Product{}
+	for _, product := range products {
+		if product.DeletedAt != nil {
+			continue
+		}
+		if query != "" &&
+			!strings.Contains(strings.ToLower(product.Name), query) &&
+			!strings.Contains(strings.ToLower(product.Description), query) {
+			continue
+		}
+		if req.MinPrice != nil && product.Price < *req.MinPrice {
+			continue
+		}
+		if req.MaxPrice != nil && product.Price > *req.MaxPrice {
+			continue
+		}
+		if req.InStock != nil && productInStock(product) != *req.InStock {
+			continue
+		}
+		if categoryIDs != nil && !productInCategories(product, categoryIDs) {
+			continue
+		}
+		matched = append(matched, product)
+	}
+
+	sortProducts(matched, req.Sort, req.Order == "desc")
+
+	total := int64(len(matched))
+	start := (req.Page - 1) * req.PageSize
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + req.PageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	return matched[start:end], total, nil
+}
+
+// sortProducts orders products by the given field, keeping catalogue order when field is empty
+func sortProducts(list []models.Product, field string, desc bool) {
+	var less func(a, b models.Product) bool
+	switch field {
+	case "price":
+		less = func(a, b models.Product) bool { return a.Price < b.Price }
+	case "name":
+		less = func(a, b models.Product) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "created_at":
+		less = func(a, b models.Product) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case "stock":
+		less = func(a, b models.Product) bool { return a.Stock < b.Stock }
+	default:
+		return
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if desc {
+			return less(list[j], list[i])
+		}
+		return less(list[i], list[j])
+	})
+}
+
+// productInStock reports whether the product or any of its variants has available stock
+func productInStock(product models.Product) bool {
+	if stockLevel(product.ID, nil).Available > 0 {
+		return true
+	}
+	for _, variant := range productVariants(product.ID) {
+		if stockLevel(product.ID, intPtr(variant.ID)).Available > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// toProductListResponse builds the paginated product list envelope
+func toProductListResponse(list []models.Product, total int64, page, pageSize int) models.ProductListResponse {
+	totalPages := int(total / int64(pageSize))
+	if total%int64(pageSize) > 0 {
+		totalPages++
+	}
+
+	return models.ProductListResponse{
+		Products:   withVariantsAll(list),
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resetSearch fills the catalogue with four products, the last one deleted,
+// spread over the categories of resetCategories
+func resetSearch() {
+	resetInventory(0)
+	resetCategories()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deleted := created.Add(time.Hour)
+	products = []models.Product{
+		{ID: 1, Name: "Laptop Dell", Price: 15000000, Stock: 5, CategoryIDs: []int{2}, CreatedAt: created},
+		{ID: 2, Name: "iPhone 14", Description: "Điện thoại Apple", Price: 25000000, CategoryIDs: []int{3}, CreatedAt: created.Add(time.Minute)},
+		{ID: 3, Name: "chuột gaming", Price: 500000, Stock: 10, CategoryIDs: []int{4}, CreatedAt: created.Add(2 * time.Minute)},
+		{ID: 4, Name: "Bàn phím", Price: 1000000, Stock: 3, CategoryIDs: []int{1}, CreatedAt: created.Add(3 * time.Minute), DeletedAt: &deleted},
+	}
+}
+
+func productIDs(list []models.Product) []int {
+	ids := []int{}
+	for _, product := range list {
+		ids = append(ids, product.ID)
+	}
+	return ids
+}
+
+func TestSearchProducts_Filters(t *testing.T) {
+	price := func(v float64) *float64 { return &v }
+	inStock, outOfStock := true, false
+
+	tests := []struct {
+		name    string
+		req     ProductSearchRequest
+		wantIDs []int
+	}{
+		{"no filters", ProductSearchRequest{}, []int{1, 2, 3}},
+		{"query matches name case-insensitively", ProductSearchRequest{Query: "LAPTOP"}, []int{1}},
+		{"query matches description", ProductSearchRequest{Query: "apple"}, []int{2}},
+		{"min price", ProductSearchRequest{MinPrice: price(15000000)}, []int{1, 2}},
+		{"max price", ProductSearchRequest{MaxPrice: price(15000000)}, []int{1, 3}},
+		{"price range", ProductSearchRequest{MinPrice: price(1000000), MaxPrice: price(20000000)}, []int{1}},
+		{"in stock", ProductSearchRequest{InStock: &inStock}, []int{1, 3}},
+		{"out of stock", ProductSearchRequest{InStock: &outOfStock}, []int{2}},
+		{"category by slug", ProductSearchRequest{Category: "laptop"}, []int{1}},
+		{"category by ID", ProductSearchRequest{Category: "3"}, []int{2}},
+		{"category with descendants", ProductSearchRequest{Category: "laptop", IncludeDescendants: true}, []int{1, 3}},
+		{"sort by price", ProductSearchRequest{Sort: "price"}, []int{3, 1, 2}},
+		{"sort by name ignores case", ProductSearchRequest{Sort: "name"}, []int{3, 2, 1}},
+		{"sort by name desc", ProductSearchRequest{Sort: "name", Order: "desc"}, []int{1, 2, 3}},
+		{"sort by created_at desc", ProductSearchRequest{Sort: "created_at", Order: "desc"}, []int{3, 2, 1}},
+		{"sort by stock", ProductSearchRequest{Sort: "stock"}, []int{2, 1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSearch()
+			got, total, err := searchProducts(&tt.req)
+			if err != nil {
+				t.Fatalf("searchProducts() error = %v", err)
+			}
+			if ids := productIDs(got); !reflect.DeepEqual(ids, tt.wantIDs) || total != int64(len(tt.wantIDs)) {
+				t.Errorf("searchProducts() = %v (total %d), want %v", ids, total, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestSearchProducts_UnknownCategory(t *testing.T) {
+	resetSearch()
+	if _, _, err := searchProducts(&ProductSearchRequest{Category: "khong-co"}); err != errCategoryNotFound {
+		t.Errorf("searchProducts() error = %v, want %v", err, errCategoryNotFound)
+	}
+}
+
+func TestSearchProducts_Pagination(t *testing.T) {
+	tests := []struct {
+		name           string
+		page, pageSize int
+		wantIDs        []int
+		wantTotalPages int
+	}{
+		{"defaults", 0, 0, []int{1, 2, 3}, 1},
+		{"first page", 1, 2, []int{1, 2}, 2},
+		{"last page", 2, 2, []int{3}, 2},
+		{"out of range", 3, 2, []int{}, 2},
+		{"exact fit", 1, 3, []int{1, 2, 3}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSearch()
+			req := ProductSearchRequest{Page: tt.page, PageSize: tt.pageSize}
+			got, total, err := searchProducts(&req)
+			if err != nil {
+				t.Fatalf("searchProducts() error = %v", err)
+			}
+
+			resp := toProductListResponse(got, total, req.Page, req.PageSize)
+			if ids := productIDs(resp.Products); !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("products = %v, want %v", ids, tt.wantIDs)
+			}
+			if resp.Total != 3 || resp.TotalPages != tt.wantTotalPages {
+				t.Errorf("total = %d total_pages = %d, want 3 and %d", resp.Total, resp.TotalPages, tt.wantTotalPages)
+			}
+		})
+	}
+}
+
+func TestGetProducts_RejectsInvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/products", getProducts)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+	}{
+		{"sort=price&order=desc", http.StatusOK},
+		{"sort=rating", http.StatusBadRequest},
+		{"order=random", http.StatusBadRequest},
+		{"page=0", http.StatusOK},
+		{"page=-1", http.StatusBadRequest},
+		{"page_size=100", http.StatusOK},
+		{"page_size=101", http.StatusBadRequest},
+		{"min_price=-1", http.StatusBadRequest},
+		{"category=khong-co", http.StatusNotFound},
+		{"include_deleted=true", http.StatusOK},
+	}
+	for _, tt := range tests {
+		resetSearch()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products?"+tt.query, nil))
+		if w.Code != tt.wantStatus {
+			t.Errorf("GET /products?%s = %d, want %d", tt.query, w.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestGetProducts_NeverListsDeletedProducts(t *testing.T) {
+	resetSearch()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/products", getProducts)
+
+	for _, query := range []string{"", "include_deleted=true", "category=dien-tu"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products?"+query, nil))
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "Bàn phím") {
+			t.Errorf("GET /products?%s = %d %s, want 200 without the deleted product", query, w.Code, w.Body.String())
+		}
+	}
+}
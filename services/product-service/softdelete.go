@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"app-microservice/shared/etag"
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultProductRetention is how long a deleted product can be restored
+	// when PRODUCT_RETENTION is not set
+	defaultProductRetention = 30 * 24 * time.Hour
+	productPurgePeriod      = time.Hour
+)
+
+// RestoreProduct godoc
+// @Summary Restore a deleted product
+// @Description Restore a soft-deleted product together with its variants and images
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} models.Response
+// @Header 200 {string} ETag "Current product version"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /products/{id}/restore [post]
+func restoreProduct(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	i := findProductIncludingDeleted(id)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+	if products[i].DeletedAt == nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Status:  "error",
+			Message: "Product chưa bị xóa",
+			Error:   "Product is not deleted",
+		})
+		return
+	}
+
+	now := time.Now()
+	products[i].DeletedAt = nil
+	touchProduct(i, now)
+	raiseProductEvent(EventProductRestored, products[i], now)
+	// Price changes that fell due while the product was deleted apply now
+	applyDuePriceChanges(now)
+
+	c.Header("ETag", etag.Format(products[i].Version))
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Khôi phục product thành công",
+		Data:    withVariants(products[i]),
+	})
+}
+
+// runProductPurger periodically removes products that were soft-deleted more
+// than retention ago
+func runProductPurger(period, retention time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		catalogMu.Lock()
+		purged := purgeDeletedProducts(now.Add(-retention))
+		catalogMu.Unlock()
+		if purged > 0 {
+			log.Printf("Đã xóa vĩnh viễn %d product bị xóa trước %s", purged, now.Add(-retention).Format(time.RFC3339))
+		}
+	}
+}
+
+// The helpers below expect the caller to hold catalogMu for writing.
+
+// softDeleteProduct hides the product at index i. Its variants, images and
+// history are kept until the product is purged.
+func softDeleteProduct(i int, now time.Time) {
+	products[i].DeletedAt = &now
+	touchProduct(i, now)
+}
+
+// purgeDeletedProducts permanently removes products deleted before the cutoff
+// together with their variants and images. Price history and stock movements
+// are kept for audit.
+func purgeDeletedProducts(before time.Time) int {
+	purged := 0
+	remaining := products[:0]
+	for _, product := range products {
+		if product.DeletedAt != nil && product.DeletedAt.Before(before) {
+			deleteProductVariants(product.ID)
+			deleteProductImages(context.Background(), product.ID)
+			purged++
+			continue
+		}
+		remaining = append(remaining, product)
+	}
+	products = remaining
+	return purged
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"app-microservice/shared/models"
+)
+
+func TestSoftDeleteProduct_HidesUntilPurged(t *testing.T) {
+	resetInventory(5)
+	products[0].SKU = "DELL-INS15"
+	variants = []models.ProductVariant{{ID: 1, ProductID: 1, SKU: "DELL-INS15-16GB"}}
+	productImages = nil
+	deletedAt := time.Now().Add(-48 * time.Hour)
+
+	softDeleteProduct(0, deletedAt)
+	if findProduct(1) >= 0 || findProductIncludingDeleted(1) != 0 {
+		t.Fatal("deleted product is still visible to findProduct")
+	}
+	if !skuInUse("DELL-INS15", 0, 0) {
+		t.Error("SKU of a deleted product was released, want it kept for restore")
+	}
+
+	page, total, err := searchProducts(&ProductSearchRequest{})
+	if err != nil || total != 0 || len(page) != 0 {
+		t.Errorf("searchProducts() = %d products, %v, want none", total, err)
+	}
+
+	if purged := purgeDeletedProducts(deletedAt.Add(-time.Hour)); purged != 0 || len(products) != 1 {
+		t.Errorf("purgeDeletedProducts() inside retention purged %d, want 0", purged)
+	}
+	if purged := purgeDeletedProducts(time.Now()); purged != 1 || len(products) != 0 || len(variants) != 0 {
+		t.Errorf("purgeDeletedProducts() = %d, products = %d, variants = %d, want everything removed", purged, len(products), len(variants))
+	}
+}
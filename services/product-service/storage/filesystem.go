@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileSystem stores objects as files below a root directory
+type FileSystem struct {
+	root    string
+	baseURL string
+}
+
+// NewFileSystem creates a filesystem store rooted at dir. Objects are served
+// to clients below baseURL (for example "/media").
+func NewFileSystem(dir, baseURL string) (*FileSystem, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &FileSystem{
+		root:    dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// Put stores the content under key, replacing any existing object
+func (fs *FileSystem) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	target, err := fs.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+	return nil
+}
+
+// Get opens the object stored under key
+func (fs *FileSystem) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	target, err := fs.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, ErrNotFound
+	}
+
+	return file, &Object{
+		Key:         key,
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		Size:        info.Size(),
+	}, nil
+}
+
+// Delete removes the object stored under key
+func (fs *FileSystem) Delete(ctx context.Context, key string) error {
+	target, err := fs.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// URL returns the public URL of the object
+func (fs *FileSystem) URL(key string) string {
+	return fs.baseURL + "/" + key
+}
+
+// path maps a key to a file below the root, rejecting keys that escape it
+func (fs *FileSystem) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(fs.root, filepath.FromSlash(clean)), nil
+}
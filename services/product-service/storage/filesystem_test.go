@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFileSystem_PutGetDelete(t *testing.T) {
+	fs, err := NewFileSystem(t.TempDir(), "/media/")
+	if err != nil {
+		t.Fatalf("NewFileSystem() error = %v", err)
+	}
+	ctx := context.Background()
+	key := "products/1/abc/original.png"
+
+	if err := fs.Put(ctx, key, strings.NewReader("png-bytes"), "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	reader, object, err := fs.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+
+	if string(data) != "png-bytes" || object.Size != int64(len("png-bytes")) || object.ContentType != "image/png" {
+		t.Errorf("Get() = %q, %+v", data, object)
+	}
+	if got := fs.URL(key); got != "/media/products/1/abc/original.png" {
+		t.Errorf("URL() = %q", got)
+	}
+
+	if err := fs.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, _, err := fs.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want %v", err, ErrNotFound)
+	}
+	if err := fs.Delete(ctx, key); err != nil {
+		t.Errorf("Delete() of missing object error = %v", err)
+	}
+}
+
+func TestFileSystem_RejectsEscapingKeys(t *testing.T) {
+	fs, err := NewFileSystem(t.TempDir(), "/media")
+	if err != nil {
+		t.Fatalf("NewFileSystem() error = %v", err)
+	}
+
+	for _, key := range []string{"", "../secret", "products/../../secret"} {
+		if err := fs.Put(context.Background(), key, strings.NewReader("x"), ""); err == nil {
+			t.Errorf("Put(%q) expected error", key)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when an object does not exist in the store
+var ErrNotFound = errors.New("object not found")
+
+// Object describes a stored object
+type Object struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// Storage abstracts where uploaded media is kept. Keys are slash-separated
+// paths such as "products/1/abc/original.jpg".
+type Storage interface {
+	// Put stores the content under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+
+	// Get opens the object stored under key
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+
+	// Delete removes the object stored under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// URL returns the public URL clients use to fetch the object
+	URL(key string) string
+}
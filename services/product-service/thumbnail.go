@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+)
+
+// thumbnailSizes maps each generated thumbnail to its maximum width/height in pixels
+var thumbnailSizes = map[string]int{
+	"small":  150,
+	"medium": 400,
+	"large":  800,
+}
+
+// resizeImage scales src down so neither side exceeds maxDim, averaging the
+// source pixels covered by each destination pixel. Smaller images are returned unchanged.
+func resizeImage(src image.Image, maxDim int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxDim && h <= maxDim {
+		return src
+	}
+
+	nw, nh := maxDim, h*maxDim/w
+	if h > w {
+		nw, nh = w*maxDim/h, maxDim
+	}
+	if nw < 1 {
+		nw = 1
+	}
+	if nh < 1 {
+		nh = 1
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		sy0 := bounds.Min.Y + y*h/nh
+		sy1 := bounds.Min.Y + (y+1)*h/nh
+		if sy1 == sy0 {
+			sy1++
+		}
+		for x := 0; x < nw; x++ {
+			sx0 := bounds.Min.X + x*w/nw
+			sx1 := bounds.Min.X + (x+1)*w/nw
+			if sx1 == sx0 {
+				sx1++
+			}
+
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// encodeImage encodes img as PNG when the original was PNG (to keep transparency)
+// and as JPEG otherwise. It returns the encoded bytes, content type and file extension.
+func encodeImage(img image.Image, originalType string) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if originalType == "image/png" {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/png", ".png", nil
+	}
+
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/jpeg", ".jpg", nil
+}
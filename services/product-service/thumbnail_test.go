@@ -0,0 +1,45 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestResizeImage(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height         int
+		maxDim                int
+		wantWidth, wantHeight int
+	}{
+		{"smaller than the limit", 100, 50, 150, 100, 50},
+		{"exactly the limit", 150, 150, 150, 150, 150},
+		{"landscape", 1000, 500, 150, 150, 75},
+		{"portrait", 500, 1000, 150, 75, 150},
+		{"thin strip keeps one pixel", 1000, 1, 150, 150, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resizeImage(image.NewGray(image.Rect(0, 0, tt.width, tt.height)), tt.maxDim).Bounds()
+			if got.Dx() != tt.wantWidth || got.Dy() != tt.wantHeight {
+				t.Errorf("resizeImage() = %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestResizeImage_AveragesPixels(t *testing.T) {
+	// A black and a white pixel side by side, not starting at the origin
+	src := image.NewGray(image.Rect(10, 10, 12, 11))
+	src.SetGray(10, 10, color.Gray{Y: 0})
+	src.SetGray(11, 10, color.Gray{Y: 255})
+
+	got := resizeImage(src, 1)
+	if bounds := got.Bounds(); bounds.Dx() != 1 || bounds.Dy() != 1 {
+		t.Fatalf("resizeImage() = %dx%d, want 1x1", bounds.Dx(), bounds.Dy())
+	}
+	if r, g, b, a := got.At(0, 0).RGBA(); r != 0x7fff || g != 0x7fff || b != 0x7fff || a != 0xffff {
+		t.Errorf("pixel = %x %x %x %x, want opaque mid grey", r, g, b, a)
+	}
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var variants = []models.ProductVariant{
+	{ID: 1, ProductID: 2, SKU: "IP14P-128-BLK", Options: map[string]string{"color": "Black", "capacity": "128GB"}, Stock: 3, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	{ID: 2, ProductID: 2, SKU: "IP14P-256-PUR", Options: map[string]string{"color": "Purple", "capacity": "256GB"}, Price: float64Ptr(28000000), Stock: 2, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+}
+
+var nextVariantID = len(variants) + 1
+
+// VariantRequest represents the payload for creating or updating a product variant
+// @Description Product variant create/update payload
+type VariantRequest struct {
+	SKU     string            `json:"sku" binding:"required,max=64" example:"IP14P-128-BLK"`
+	Options map[string]string `json:"options" swaggertype:"object,string" example:"color:Black,capacity:128GB"`
+	Price   *float64          `json:"price" binding:"omitempty,gte=0" example:"25000000"`
+	Stock   int               `json:"stock" binding:"gte=0" example:"3"` // opening stock, only used on create
+}
+
+func setupVariantRoutes(api *gin.RouterGroup) {
+	api.GET("/:id/variants", getProductVariants)
+	api.GET("/:id/variants/:variantId", getProductVariant)
+	api.POST("/:id/variants", createProductVariant)
+	api.PUT("/:id/variants/:variantId", updateProductVariant)
+	api.DELETE("/:id/variants/:variantId", deleteProductVariant)
+}
+
+// GetProductVariants godoc
+// @Summary Get product variants
+// @Description Get all variants of a product
+// @Tags variants
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/variants [get]
+func getProductVariants(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	if findProduct(productID) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy danh sách variants thành công",
+		Data:    productVariants(productID),
+	})
+}
+
+// GetProductVariant godoc
+// @Summary Get product variant by ID
+// @Description Get a single variant of a product
+// @Tags variants
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /products/{id}/variants/{variantId} [get]
+func getProductVariant(c *gin.Context) {
+	productID, variantID, err := parseVariantParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	i := findVariant(productID, variantID)
+	if i < 0 || findProduct(productID) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy variant",
+			Error:   "Variant not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Lấy thông tin variant thành công",
+		Data:    variants[i],
+	})
+}
+
+// CreateProductVariant godoc
+// @Summary Create a product variant
+// @Description Create a new variant with its own SKU, options, price override and stock
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant body VariantRequest true "Variant data"
+// @Success 201 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /products/{id}/variants [post]
+func createProductVariant(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req VariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	if findProduct(productID) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy product",
+			Error:   "Product not found",
+		})
+		return
+	}
+
+	variant := models.ProductVariant{
+		ID:        nextVariantID,
+		ProductID: productID,
+		SKU:       normalizeSKU(req.SKU),
+		Options:   req.Options,
+		Price:     req.Price,
+		Stock:     req.Stock,
+	}
+
+	if status, err := validateVariantSKU(variant); err != nil {
+		c.JSON(status, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	variant.CreatedAt = time.Now()
+	variant.UpdatedAt = time.Now()
+	nextVariantID++
+
+	variants = append(variants, variant)
+	if variant.Stock > 0 {
+		recordMovement(productID, intPtr(variant.ID), MovementReceipt, variant.Stock, nil, "opening balance", variant.CreatedAt)
+	}
+	productChanged(productID, variant.CreatedAt)
+
+	c.JSON(http.StatusCreated, models.Response{
+		Status:  "success",
+		Message: "Tạo variant thành công",
+		Data:    variant,
+	})
+}
+
+// UpdateProductVariant godoc
+// @Summary Update a product variant
+// @Description Update variant data by ID. Stock is managed through the inventory endpoints and is not changed here.
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Param variant body VariantRequest true "Updated variant data"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /products/{id}/variants/{variantId} [put]
+func updateProductVariant(c *gin.Context) {
+	productID, variantID, err := parseVariantParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req VariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	i := findVariant(productID, variantID)
+	if i < 0 || findProduct(productID) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy variant",
+			Error:   "Variant not found",
+		})
+		return
+	}
+
+	variant := variants[i]
+	variant.SKU = normalizeSKU(req.SKU)
+	variant.Options = req.Options
+	variant.Price = req.Price
+
+	if status, err := validateVariantSKU(variant); err != nil {
+		c.JSON(status, models.ErrorResponse{
+			Status:  "error",
+			Message: "Dữ liệu không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	variant.UpdatedAt = time.Now()
+	variants[i] = variant
+	productChanged(productID, variant.UpdatedAt)
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Cập nhật variant thành công",
+		Data:    variant,
+	})
+}
+
+// DeleteProductVariant godoc
+// @Summary Delete a product variant
+// @Description Delete variant by ID. Stock still on hand is written off with an adjustment movement. Variants with stock still reserved cannot be deleted until the reservations are committed or released.
+// @Tags variants
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /products/{id}/variants/{variantId} [delete]
+func deleteProductVariant(c *gin.Context) {
+	productID, variantID, err := parseVariantParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Status:  "error",
+			Message: "ID không hợp lệ",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	i := findVariant(productID, variantID)
+	if i < 0 || findProduct(productID) < 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không tìm thấy variant",
+			Error:   "Variant not found",
+		})
+		return
+	}
+	if reserved := stockReserved(productID, &variantID); reserved > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Status:  "error",
+			Message: "Không thể xóa variant còn hàng đang được giữ",
+			Error:   fmt.Sprintf("variant has %d reserved units", reserved),
+		})
+		return
+	}
+
+	now := time.Now()
+	// Write off the stock left so the ledger still adds up to what is on hand
+	if stock := variants[i].Stock; stock > 0 {
+		variants[i].Stock = 0
+		recordMovement(productID, &variantID, MovementAdjustment, -stock, nil, "variant deleted", now)
+	}
+	variants = append(variants[:i], variants[i+1:]...)
+	productChanged(productID, now)
+
+	c.JSON(http.StatusOK, models.Response{
+		Status:  "success",
+		Message: "Xóa variant thành công",
+		Data:    nil,
+	})
+}
+
+func parseVariantParams(c *gin.Context) (int, int, error) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, err
+	}
+	variantID, err := strconv.Atoi(c.Param("variantId"))
+	if err != nil {
+		return 0, 0, err
+	}
+	return productID, variantID, nil
+}
+
+// The helpers below expect the caller to hold catalogMu.
+
+// findProduct returns the index of the live product with the given ID, or -1.
+// Soft-deleted products are only reachable through findProductIncludingDeleted.
+func findProduct(id int) int {
+	i := findProductIncludingDeleted(id)
+	if i >= 0 && products[i].DeletedAt != nil {
+		return -1
+	}
+	return i
+}
+
+// findProductIncludingDeleted returns the index of the product with the given
+// ID whether or not it has been soft-deleted, or -1
+func findProductIncludingDeleted(id int) int {
+	for i, product := range products {
+		if product.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// findVariant returns the index of a variant belonging to the given product, or -1
+func findVariant(productID, variantID int) int {
+	for i, variant := range variants {
+		if variant.ID == variantID && variant.ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
+
+// productVariants returns all variants of a product
+func productVariants(productID int) []models.ProductVariant {
+	result := []models.ProductVariant{}
+	for _, variant := range variants {
+		if variant.ProductID == productID {
+			result = append(result, variant)
+		}
+	}
+	return result
+}
+
+// withVariants returns a copy of the product with its variants embedded
+func withVariants(product models.Product) models.Product {
+	product.Variants = productVariants(product.ID)
+	if len(product.Variants) == 0 {
+		product.Variants = nil
+	}
+	return product
+}
+
+// withVariantsAll embeds variants into every product of the slice
+func withVariantsAll(list []models.Product) []models.Product {
+	result := make([]models.Product, len(list))
+	for i, product := range list {
+		result[i] = withVariants(product)
+	}
+	return result
+}
+
+// deleteProductVariants removes every variant belonging to a product
+func deleteProductVariants(productID int) {
+	remaining := variants[:0]
+	for _, variant := range variants {
+		if variant.ProductID != productID {
+			remaining = append(remaining, variant)
+		}
+	}
+	variants = remaining
+}
+
+// validateVariantSKU checks that a variant SKU is set, which binding cannot
+// tell for one of only spaces, and unique across the catalogue. It returns the
+// HTTP status to report on failure.
+func validateVariantSKU(variant models.ProductVariant) (int, error) {
+	if variant.SKU == "" {
+		return http.StatusBadRequest, fmt.Errorf("sku is required")
+	}
+	if skuInUse(variant.SKU, 0, variant.ID) {
+		return http.StatusConflict, fmt.Errorf("sku %q already exists", variant.SKU)
+	}
+	return 0, nil
+}
+
+// skuInUse reports whether a SKU is taken by any product other than productID
+// or any variant other than variantID. SKUs of soft-deleted products stay taken
+// so the product can be restored.
+func skuInUse(sku string, productID, variantID int) bool {
+	for _, product := range products {
+		if product.ID != productID && product.SKU == sku {
+			return true
+		}
+	}
+	for _, variant := range variants {
+		if variant.ID != variantID && variant.SKU == sku {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveVariants sends a request to the variant routes and returns the status
+func serveVariants(method, path, body string) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	setupVariantRoutes(r.Group("/products"))
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+// resetVariants gives the inventory product SKU DELL-INS15 and one variant
+func resetVariants() {
+	resetInventory(5)
+	products[0].SKU = "DELL-INS15"
+	products = append(products, models.Product{ID: 2, SKU: "IP14P", Name: "iPhone 14"})
+	variants = []models.ProductVariant{{ID: 1, ProductID: 1, SKU: "DELL-INS15-16GB", Stock: 3}}
+	nextVariantID = 2
+}
+
+func TestValidateVariantSKU_UniqueAcrossProductsAndVariants(t *testing.T) {
+	resetVariants()
+
+	tests := []struct {
+		name       string
+		variant    models.ProductVariant
+		wantStatus int
+	}{
+		{"new SKU", models.ProductVariant{ID: 2, ProductID: 2, SKU: "IP14P-128"}, 0},
+		{"keeps own SKU", models.ProductVariant{ID: 1, ProductID: 1, SKU: "DELL-INS15-16GB"}, 0},
+		{"blank SKU", models.ProductVariant{ID: 2, ProductID: 2, SKU: ""}, http.StatusBadRequest},
+		{"SKU of own product", models.ProductVariant{ID: 2, ProductID: 1, SKU: "DELL-INS15"}, http.StatusConflict},
+		{"SKU of another product", models.ProductVariant{ID: 2, ProductID: 1, SKU: "IP14P"}, http.StatusConflict},
+		{"SKU of another variant", models.ProductVariant{ID: 2, ProductID: 2, SKU: "DELL-INS15-16GB"}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, err := validateVariantSKU(tt.variant); status != tt.wantStatus {
+				t.Errorf("validateVariantSKU() = %d, %v, want %d", status, err, tt.wantStatus)
+			}
+		})
+	}
+
+	// A soft-deleted product keeps its SKU so that it can be restored
+	softDeleteProduct(1, time.Now())
+	if _, err := validateVariantSKU(models.ProductVariant{ID: 2, ProductID: 2, SKU: "DELL-INS15"}); err == nil {
+		t.Error("validateVariantSKU() took the SKU of a deleted product, want error")
+	}
+}
+
+func TestVariantRoutes(t *testing.T) {
+	tests := []struct {
+		name               string
+		method, path, body string
+		wantStatus         int
+	}{
+		{"list", http.MethodGet, "/products/1/variants", "", http.StatusOK},
+		{"get", http.MethodGet, "/products/1/variants/1", "", http.StatusOK},
+		{"get of another product", http.MethodGet, "/products/2/variants/1", "", http.StatusNotFound},
+		{"create", http.MethodPost, "/products/2/variants", `{"sku":"ip14p-128"}`, http.StatusCreated},
+		{"create for unknown product", http.MethodPost, "/products/99/variants", `{"sku":"IP14P-128"}`, http.StatusNotFound},
+		{"create with taken SKU", http.MethodPost, "/products/2/variants", `{"sku":" dell-ins15-16gb "}`, http.StatusConflict},
+		{"create with blank SKU", http.MethodPost, "/products/2/variants", `{"sku":"   "}`, http.StatusBadRequest},
+		{"update", http.MethodPut, "/products/1/variants/1", `{"sku":"DELL-INS15-32GB"}`, http.StatusOK},
+		{"update with blank SKU", http.MethodPut, "/products/1/variants/1", `{"sku":"   "}`, http.StatusBadRequest},
+		{"update unknown variant", http.MethodPut, "/products/1/variants/99", `{"sku":"DELL-INS15-32GB"}`, http.StatusNotFound},
+		{"delete", http.MethodDelete, "/products/1/variants/1", "", http.StatusOK},
+		{"invalid ID", http.MethodDelete, "/products/1/variants/abc", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetVariants()
+			if status := serveVariants(tt.method, tt.path, tt.body); status != tt.wantStatus {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, status, tt.wantStatus)
+			}
+		})
+	}
+
+	resetVariants()
+	serveVariants(http.MethodPost, "/products/2/variants", `{"sku":" ip14p-128 "}`)
+	if len(variants) != 2 || variants[1].SKU != "IP14P-128" {
+		t.Errorf("variants = %+v, want the new SKU normalized to IP14P-128", variants)
+	}
+}
+
+func TestVariantRoutes_HideVariantsOfDeletedProducts(t *testing.T) {
+	resetVariants()
+	softDeleteProduct(0, time.Now())
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{http.MethodGet, "/products/1/variants", ""},
+		{http.MethodGet, "/products/1/variants/1", ""},
+		{http.MethodPost, "/products/1/variants", `{"sku":"DELL-INS15-32GB"}`},
+		{http.MethodPut, "/products/1/variants/1", `{"sku":"DELL-INS15-16GB"}`},
+		{http.MethodDelete, "/products/1/variants/1", ""},
+	}
+	for _, tt := range tests {
+		if status := serveVariants(tt.method, tt.path, tt.body); status != http.StatusNotFound {
+			t.Errorf("%s %s = %d, want 404", tt.method, tt.path, status)
+		}
+	}
+	if len(variants) != 1 {
+		t.Errorf("variants = %+v, want the variant of the deleted product kept", variants)
+	}
+}
+
+func TestDeleteProductVariant_RefusesReservedStock(t *testing.T) {
+	resetVariants()
+	now := time.Now()
+
+	reservation, err := reserveStock(1, intPtr(1), 2, "order-1", time.Minute, now)
+	if err != nil {
+		t.Fatalf("reserveStock() error = %v", err)
+	}
+	if status := serveVariants(http.MethodDelete, "/products/1/variants/1", ""); status != http.StatusConflict {
+		t.Fatalf("DELETE with reserved stock = %d, want 409", status)
+	}
+
+	if _, err := commitReservation(reservation.ID, now); err != nil {
+		t.Fatalf("commitReservation() error = %v", err)
+	}
+	if status := serveVariants(http.MethodDelete, "/products/1/variants/1", ""); status != http.StatusOK || len(variants) != 0 {
+		t.Errorf("DELETE after commit = %d with %d variants left, want 200 and none", status, len(variants))
+	}
+}
+
+func TestDeleteProductVariant_WritesOffStock(t *testing.T) {
+	resetVariants()
+	recordMovement(1, intPtr(1), MovementReceipt, 3, nil, "opening balance", time.Now())
+
+	if status := serveVariants(http.MethodDelete, "/products/1/variants/1", ""); status != http.StatusOK {
+		t.Fatalf("DELETE = %d, want 200", status)
+	}
+
+	total := 0
+	var last models.StockMovement
+	for _, movement := range stockMovements {
+		if movement.VariantID != nil && *movement.VariantID == 1 {
+			total += movement.Quantity
+			last = movement
+		}
+	}
+	if total != 0 || last.Type != MovementAdjustment || last.OnHand != 0 {
+		t.Errorf("variant movements add up to %d ending with %+v, want 0 and an adjustment to 0 on hand", total, last)
+	}
+}
+
+func TestVariantChanges_TouchProduct(t *testing.T) {
+	resetVariants()
+	eventQueue = make(chan productEvent, 10)
+	defer func() { eventQueue = nil }()
+
+	requests := []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/products/1/variants", `{"sku":"DELL-INS15-32GB"}`},
+		{http.MethodPut, "/products/1/variants/1", `{"sku":"DELL-INS15-8GB"}`},
+		{http.MethodDelete, "/products/1/variants/1", ""},
+	}
+	for i, req := range requests {
+		if status := serveVariants(req.method, req.path, req.body); status >= 300 {
+			t.Fatalf("%s %s = %d, want success", req.method, req.path, status)
+		}
+		if products[0].Version != i+1 {
+			t.Errorf("version after %s = %d, want %d", req.method, products[0].Version, i+1)
+		}
+	}
+
+	// Stock of a variant is part of the product too
+	if _, err := applyStockChange(1, intPtr(2), MovementReceipt, 2, "", time.Now()); err != nil {
+		t.Fatalf("applyStockChange() error = %v", err)
+	}
+	if products[0].Version != len(requests)+1 {
+		t.Errorf("version after variant receipt = %d, want %d", products[0].Version, len(requests)+1)
+	}
+
+	if len(eventQueue) != len(requests)+1 {
+		t.Fatalf("raised %d events, want %d", len(eventQueue), len(requests)+1)
+	}
+	for range len(eventQueue) {
+		if event := <-eventQueue; event.Type != EventProductUpdated || event.AggregateID != "1" {
+			t.Errorf("event = %+v, want product.updated of product 1", event)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Command oidc-client is a relying party for trying out the OpenID Connect
+// provider of the user service locally.
+//
+// Register a client with the redirect URI http://localhost:9000/callback,
+// then run
+//
+//	go run ./cmd/oidc-client -client-id <id> [-client-secret <secret>]
+//
+// and open http://localhost:9000 to sign in. The client uses the
+// authorization code flow with PKCE, verifies the ID token against the
+// published keys and shows the claims of the userinfo endpoint. With
+// -client-credentials it gets a token for the client itself and exits.
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// discovery holds the fields of the provider configuration the client uses
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// tokenResponse is a response of the token endpoint
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+// pendingLogin is an authorization request waiting for its callback
+type pendingLogin struct {
+	nonce    string
+	verifier string
+}
+
+type relyingParty struct {
+	provider     discovery
+	clientID     string
+	clientSecret string
+	redirectURI  string
+	scope        string
+
+	mu      sync.Mutex
+	pending map[string]pendingLogin // by state
+}
+
+func main() {
+	issuer := flag.String("issuer", "http://localhost:8081", "URL of the OpenID provider")
+	clientID := flag.String("client-id", "", "client ID")
+	clientSecret := flag.String("client-secret", "", "client secret; leave empty for a public client")
+	listen := flag.String("listen", "localhost:9000", "address to serve the relying party on")
+	scope := flag.String("scope", "", "scopes to request (default openid profile email, or every scope of the client with -client-credentials)")
+	clientCredentials := flag.Bool("client-credentials", false, "get a token with client credentials and exit")
+	flag.Parse()
+
+	if *clientID == "" {
+		log.Fatal("-client-id is required")
+	}
+	if *scope == "" && !*clientCredentials {
+		*scope = "openid profile email"
+	}
+
+	var provider discovery
+	if err := getJSON(strings.TrimRight(*issuer, "/")+"/.well-known/openid-configuration", "", &provider); err != nil {
+		log.Fatalf("Failed to discover provider: %v", err)
+	}
+	if provider.Issuer != strings.TrimRight(*issuer, "/") {
+		log.Fatalf("Provider claims issuer %q, expected %q", provider.Issuer, *issuer)
+	}
+
+	rp := &relyingParty{
+		provider:     provider,
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		redirectURI:  "http://" + *listen + "/callback",
+		scope:        *scope,
+		pending:      make(map[string]pendingLogin),
+	}
+
+	if *clientCredentials {
+		form := url.Values{"grant_type": {"client_credentials"}}
+		if *scope != "" {
+			form.Set("scope", *scope)
+		}
+		tokens, err := rp.token(form)
+		if err != nil {
+			log.Fatalf("Client credentials failed: %v", err)
+		}
+		printJSON(tokens)
+		return
+	}
+
+	http.HandleFunc("/", rp.login)
+	http.HandleFunc("/callback", rp.callback)
+	log.Printf("Open http://%s to sign in (redirect URI %s)", *listen, rp.redirectURI)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
+
+// login starts the authorization code flow with a fresh state, nonce and
+// PKCE verifier
+func (rp *relyingParty) login(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	state, nonce, verifier := randomString(), randomString(), randomString()
+	rp.mu.Lock()
+	rp.pending[state] = pendingLogin{nonce: nonce, verifier: verifier}
+	rp.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {rp.clientID},
+		"redirect_uri":          {rp.redirectURI},
+		"scope":                 {rp.scope},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	http.Redirect(w, r, rp.provider.AuthorizationEndpoint+"?"+query.Encode(), http.StatusFound)
+}
+
+// callback exchanges the code, verifies the ID token and reads the userinfo
+func (rp *relyingParty) callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if e := query.Get("error"); e != "" {
+		http.Error(w, e+": "+query.Get("error_description"), http.StatusBadRequest)
+		return
+	}
+
+	rp.mu.Lock()
+	login, ok := rp.pending[query.Get("state")]
+	delete(rp.pending, query.Get("state"))
+	rp.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown state", http.StatusBadRequest)
+		return
+	}
+	if iss := query.Get("iss"); iss != "" && iss != rp.provider.Issuer {
+		http.Error(w, "response from unexpected issuer "+iss, http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := rp.token(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {query.Get("code")},
+		"redirect_uri":  {rp.redirectURI},
+		"code_verifier": {login.verifier},
+	})
+	if err != nil {
+		http.Error(w, "token exchange failed: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	idClaims, err := rp.verifyIDToken(tokens.IDToken, login.nonce)
+	if err != nil {
+		http.Error(w, "invalid ID token: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	var userInfo map[string]any
+	if err := getJSON(rp.provider.UserInfoEndpoint, tokens.AccessToken, &userInfo); err != nil {
+		http.Error(w, "userinfo failed: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(map[string]any{
+		"id_token_claims": idClaims,
+		"userinfo":        userInfo,
+		"scope":           tokens.Scope,
+		"expires_in":      tokens.ExpiresIn,
+	})
+}
+
+// token calls the token endpoint, authenticating with HTTP Basic when the
+// client has a secret
+func (rp *relyingParty) token(form url.Values) (*tokenResponse, error) {
+	if rp.clientSecret == "" {
+		form.Set("client_id", rp.clientID)
+	}
+	req, err := http.NewRequest(http.MethodPost, rp.provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if rp.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(rp.clientID), url.QueryEscape(rp.clientSecret))
+	}
+
+	var tokens tokenResponse
+	if err := doJSON(req, &tokens); err != nil {
+		return nil, err
+	}
+	return &tokens, nil
+}
+
+// verifyIDToken checks the signature of an ID token against the published
+// keys, and its issuer, audience and nonce
+func (rp *relyingParty) verifyIDToken(idToken, nonce string) (jwt.MapClaims, error) {
+	if idToken == "" {
+		return nil, errors.New("no ID token; request the openid scope")
+	}
+
+	var jwks struct {
+		Keys []struct {
+			KeyID string `json:"kid"`
+			N     string `json:"n"`
+			E     string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(rp.provider.JWKSURI, "", &jwks); err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range jwks.Keys {
+			if key.KeyID != kid {
+				continue
+			}
+			n, err := base64.RawURLEncoding.DecodeString(key.N)
+			if err != nil {
+				return nil, err
+			}
+			e, err := base64.RawURLEncoding.DecodeString(key.E)
+			if err != nil {
+				return nil, err
+			}
+			return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+		}
+		return nil, fmt.Errorf("unknown key %q", kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(rp.provider.Issuer),
+		jwt.WithAudience(rp.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims["nonce"] != nonce {
+		return nil, errors.New("nonce does not match")
+	}
+	return claims, nil
+}
+
+// getJSON fetches a JSON document, with a Bearer token if one is given
+func getJSON(uri, accessToken string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return doJSON(req, v)
+}
+
+// doJSON sends a request and decodes its JSON response, turning error
+// statuses into errors
+func doJSON(req *http.Request, v any) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
+
+func printJSON(v any) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
+// randomString returns 32 random bytes, base64url encoded, which is also a
+// valid PKCE verifier
+func randomString() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("Failed to read random bytes: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,8 +16,14 @@ import (
 	"app-microservice/services/user-service/internal/delivery/http/routes"
 	"app-microservice/services/user-service/internal/domain/services"
 	"app-microservice/services/user-service/internal/infrastructure/database"
+	"app-microservice/services/user-service/internal/infrastructure/events"
+	"app-microservice/services/user-service/internal/infrastructure/mail"
 	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/security"
+	"app-microservice/services/user-service/internal/infrastructure/webhook"
 	"app-microservice/services/user-service/pkg/logger"
+	"app-microservice/shared/svcauth"
+	"app-microservice/shared/tlsconfig"
 
 	_ "app-microservice/services/user-service/docs"
 )
@@ -37,6 +44,10 @@ import (
 // @BasePath /api/v1
 // @schemes http https
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
@@ -92,23 +103,203 @@ func main() {
 
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(entClient)
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(entClient)
+	sessionRepo := repositories.NewSessionRepository(entClient)
+	oauthClientRepo := repositories.NewOAuthClientRepository(entClient)
+	authorizationCodeRepo := repositories.NewAuthorizationCodeRepository(entClient)
+	roleRepo := repositories.NewRoleRepository(entClient)
+	verificationTokenRepo := repositories.NewVerificationTokenRepository(entClient)
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(entClient)
+	lockoutEventRepo := repositories.NewLockoutEventRepository(entClient)
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(entClient)
+	auditRepo := repositories.NewAuditRepository(entClient)
+	outboxRepo := repositories.NewOutboxRepository(entClient)
+	webhookRepo := repositories.NewWebhookSubscriptionRepository(entClient)
+	webhookDeliveryRepo := repositories.NewWebhookDeliveryRepository(entClient)
+	txManager := repositories.NewTxManager(entClient)
+	switch cfg.Login.Store {
+	case "database":
+	case "memory":
+		loginAttemptRepo = repositories.NewMemoryLoginAttemptRepository()
+	default:
+		logger.Fatalf("Unknown LOGIN_ATTEMPT_STORE %q", cfg.Login.Store)
+	}
 	logger.Info("Repositories initialized")
 
 	// Initialize domain services
 	userDomainService := services.NewUserDomainService(userRepo)
+	passwordHasher := security.NewArgon2Hasher(security.Argon2Params{
+		Memory:      uint32(cfg.Password.Argon2Memory),
+		Iterations:  uint32(cfg.Password.Argon2Iterations),
+		Parallelism: uint8(cfg.Password.Argon2Parallelism),
+	})
+	passwordPolicy := services.PasswordPolicy{
+		MinLength:     cfg.Password.MinLength,
+		RequireUpper:  cfg.Password.RequireUpper,
+		RequireLower:  cfg.Password.RequireLower,
+		RequireDigit:  cfg.Password.RequireDigit,
+		RequireSymbol: cfg.Password.RequireSymbol,
+	}
+	if cfg.JWT.SigningKeyFile == "" {
+		logger.Warn("JWT_SIGNING_KEY_FILE is not set, signing tokens with an ephemeral key")
+	}
+	keySet, err := security.LoadKeySet(cfg.JWT.SigningKeyFile, cfg.JWT.VerificationKeyFiles)
+	if err != nil {
+		logger.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	tokenIssuer := security.NewJWTIssuer(keySet, security.JWTConfig{
+		Issuer:   cfg.JWT.Issuer,
+		Audience: cfg.JWT.Audience,
+		TTL:      cfg.JWT.AccessTokenTTL,
+	})
+	oidcTokenIssuer := security.NewOIDCTokenIssuer(keySet, security.OIDCConfig{
+		Issuer:         cfg.OIDC.Issuer,
+		Audience:       cfg.JWT.Audience,
+		AccessTokenTTL: cfg.OIDC.TokenTTL,
+		IDTokenTTL:     cfg.OIDC.TokenTTL,
+	})
+	if cfg.MFA.EncryptionKey == "" {
+		logger.Warn("MFA_ENCRYPTION_KEY is not set, encrypting TOTP secrets with an ephemeral key")
+	}
+	secretBox, err := security.NewSecretBox(cfg.MFA.EncryptionKey)
+	if err != nil {
+		logger.Fatalf("Failed to initialize MFA encryption: %v", err)
+	}
+	clock := services.SystemClock{}
+	totp := security.NewTOTP(security.TOTPConfig{
+		Issuer: cfg.MFA.Issuer,
+		Clock:  clock,
+	})
+	mailer, err := newMailer(cfg.Mail)
+	if err != nil {
+		logger.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	eventBroker, err := newEventBroker(cfg.Events)
+	if err != nil {
+		logger.Fatalf("Failed to initialize event broker: %v", err)
+	}
+	if closer, ok := eventBroker.(io.Closer); ok {
+		defer closer.Close()
+	}
+	// Webhooks are fed in process whatever the broker, so events published
+	// elsewhere are handed to an in-memory broker as well
+	eventHub, ok := eventBroker.(*events.MemoryBroker)
+	if !ok {
+		eventHub = events.NewMemoryBroker()
+		eventHub.Subscribe(events.AllEvents, eventBroker.Publish)
+	}
+	webhookSender := webhook.NewHTTPSender(cfg.Webhook.Timeout, cfg.Webhook.AllowPrivate, clock)
 	logger.Info("Domain services initialized")
 
 	// Initialize use cases
-	userUseCase := usecases.NewUserUseCase(userRepo, userDomainService)
+	auditor := usecases.NewAuditor(auditRepo, clock)
+	outbox := usecases.NewOutbox(txManager, outboxRepo, clock)
+	emailVerifier := usecases.NewEmailVerifier(userRepo, verificationTokenRepo, mailer, auditor, outbox, clock, usecases.EmailVerificationConfig{
+		TokenTTL:       cfg.Email.TokenTTL,
+		ResendInterval: cfg.Email.ResendInterval,
+		VerifyURL:      cfg.Email.VerifyURL,
+	})
+	passwordResetter := usecases.NewPasswordResetter(userRepo, verificationTokenRepo, sessionRepo, mailer, passwordHasher, passwordPolicy, auditor, outbox, clock, usecases.PasswordResetConfig{
+		TokenTTL:        cfg.Reset.TokenTTL,
+		RequestInterval: cfg.Reset.RequestInterval,
+		ResetURL:        cfg.Reset.ResetURL,
+	})
+	mfaManager := usecases.NewMFAManager(userRepo, verificationTokenRepo, recoveryCodeRepo, txManager, totp, secretBox, clock, usecases.MFAConfig{
+		ChallengeTTL:  cfg.MFA.ChallengeTTL,
+		MaxAttempts:   cfg.MFA.MaxAttempts,
+		RecoveryCodes: cfg.MFA.RecoveryCodes,
+	})
+	loginGuard := usecases.NewLoginGuard(loginAttemptRepo, lockoutEventRepo, userRepo, clock, usecases.LoginProtectionConfig{
+		Window:            cfg.Login.Window,
+		DelayAfter:        cfg.Login.DelayAfter,
+		BaseDelay:         cfg.Login.BaseDelay,
+		MaxDelay:          cfg.Login.MaxDelay,
+		MaxFailures:       cfg.Login.MaxFailures,
+		LockoutDuration:   cfg.Login.LockoutDuration,
+		IPMaxFailures:     cfg.Login.IPMaxFailures,
+		IPLockoutDuration: cfg.Login.IPLockoutDuration,
+	})
+	sessionManager := usecases.NewSessionManager(sessionRepo, clock, cfg.JWT.RefreshTokenTTL)
+	outboxRelay := usecases.NewOutboxRelay(outboxRepo, eventHub, clock, usecases.OutboxRelayConfig{
+		BatchSize: cfg.Events.BatchSize,
+		Lease:     cfg.Events.Lease,
+		RetryBase: cfg.Events.RetryBase,
+		RetryMax:  cfg.Events.RetryMax,
+		Retention: cfg.Events.Retention,
+	})
+	userUseCase := usecases.NewUserUseCase(userRepo, userDomainService, emailVerifier, auditor, outbox, clock)
+	authUseCase := usecases.NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, userDomainService, passwordHasher, passwordPolicy, tokenIssuer, emailVerifier, passwordResetter, mfaManager, loginGuard, sessionManager, auditor, outbox, cfg.JWT.RefreshTokenTTL)
+	roleUseCase := usecases.NewRoleUseCase(roleRepo, userRepo, auditor, outbox)
+	auditUseCase := usecases.NewAuditUseCase(auditRepo)
+	oidcUseCase := usecases.NewOIDCUseCase(oauthClientRepo, authorizationCodeRepo, userRepo, sessionRepo, oidcTokenIssuer, clock, auditor, outbox, usecases.OIDCConfig{
+		Issuer:  cfg.OIDC.Issuer,
+		CodeTTL: cfg.OIDC.CodeTTL,
+	})
+	webhookUseCase := usecases.NewWebhookUseCase(webhookRepo, webhookDeliveryRepo, secretBox, auditor, outbox, clock, cfg.Webhook.AllowPrivate)
+	webhookDispatcher := usecases.NewWebhookDispatcher(webhookRepo, webhookDeliveryRepo, webhookSender, secretBox, clock, usecases.WebhookDeliveryConfig{
+		Workers:     cfg.Webhook.Workers,
+		BatchSize:   cfg.Webhook.BatchSize,
+		Lease:       cfg.Webhook.Lease,
+		MaxAttempts: cfg.Webhook.MaxAttempts,
+		RetryBase:   cfg.Webhook.RetryBase,
+		RetryMax:    cfg.Webhook.RetryMax,
+		Retention:   cfg.Webhook.Retention,
+	})
+	eventHub.Subscribe(events.AllEvents, webhookDispatcher.Enqueue)
+	eventUseCase := usecases.NewEventUseCase(outboxRepo)
 	logger.Info("Use cases initialized")
 
+	// Create the built-in roles and give the bootstrap admin full access
+	if err := roleUseCase.EnsureDefaultRoles(ctx); err != nil {
+		logger.Fatalf("Failed to create default roles: %v", err)
+	}
+	if cfg.RBAC.BootstrapAdminEmail != "" {
+		if err := roleUseCase.BootstrapAdmin(ctx, cfg.RBAC.BootstrapAdminEmail); err != nil {
+			logger.Warnf("Failed to bootstrap admin: %v", err)
+		} else {
+			logger.Infof("Admin role granted to %s", cfg.RBAC.BootstrapAdminEmail)
+		}
+	}
+
+	// Start the purge job for soft-deleted users and expired tokens, the job
+	// that lifts ended suspensions and the relays of events and webhooks
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go runPurgeJob(purgeCtx, userUseCase, authUseCase, oidcUseCase, outboxRelay, webhookDispatcher, cfg.SoftDelete.Retention, cfg.SoftDelete.PurgeInterval)
+	go runSuspensionJob(purgeCtx, userUseCase, cfg.Suspension.CheckInterval)
+	go runOutboxRelay(purgeCtx, outboxRelay, cfg.Events.RelayInterval)
+	go runWebhookDelivery(purgeCtx, webhookDispatcher, cfg.Webhook.DeliveryInterval)
+
 	// Initialize handlers
 	userHandler := handlers.NewUserHandler(userUseCase)
+	authHandler := handlers.NewAuthHandler(authUseCase)
+	roleHandler := handlers.NewRoleHandler(roleUseCase)
+	sessionHandler := handlers.NewSessionHandler(authUseCase)
+	oidcHandler := handlers.NewOIDCHandler(oidcUseCase)
+	auditHandler := handlers.NewAuditHandler(auditUseCase)
+	webhookHandler := handlers.NewWebhookHandler(webhookUseCase)
+	eventHandler := handlers.NewEventHandler(eventUseCase)
 	logger.Info("Handlers initialized")
 
+	// Only peers such as the gateway may call the service when service auth
+	// is configured
+	var serviceAuth *svcauth.Authenticator
+	if cfg.Service.KeyFile != "" {
+		serviceAuth, err = svcauth.Load(cfg.Service.Name, cfg.Service.KeyFile, cfg.Service.Peers)
+		if err != nil {
+			logger.Fatalf("Failed to load service auth keys: %v", err)
+		}
+		logger.Infof("Service auth enabled as %s", cfg.Service.Name)
+	} else {
+		logger.Warn("SERVICE_AUTH_KEY_FILE not set, calls from other services are not authenticated")
+	}
+
 	// Initialize router
-	router := routes.NewRouter(userHandler)
+	router := routes.NewRouter(userHandler, authHandler, roleHandler, sessionHandler, oidcHandler, auditHandler, webhookHandler, eventHandler, authUseCase, serviceAuth, cfg.Server.RequireIfMatch)
 	ginEngine := router.SetupRoutes()
+	if err := ginEngine.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	logger.Info("Routes configured")
 
 	// Setup HTTP server
@@ -119,14 +310,29 @@ func main() {
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 	}
 
+	scheme := "http"
+	if cfg.Server.TLSCertFile != "" {
+		server.TLSConfig, err = tlsconfig.Server(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile, cfg.Server.TLSMinVersion)
+		if err != nil {
+			logger.Fatalf("Failed to load TLS certificate: %v", err)
+		}
+		scheme = "https"
+	}
+
 	// Start server in a goroutine
 	go func() {
 		logger.Infof("User Service starting on port %s", cfg.Server.Port)
-		logger.Infof("Swagger UI available at: http://localhost:%s/swagger/index.html", cfg.Server.Port)
-		logger.Infof("Health check available at: http://localhost:%s/health", cfg.Server.Port)
-		logger.Infof("API endpoints available at: http://localhost:%s/api/v1/users", cfg.Server.Port)
+		logger.Infof("Swagger UI available at: %s://localhost:%s/swagger/index.html", scheme, cfg.Server.Port)
+		logger.Infof("Health check available at: %s://localhost:%s/health", scheme, cfg.Server.Port)
+		logger.Infof("API endpoints available at: %s://localhost:%s/api/v1/users", scheme, cfg.Server.Port)
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if server.TLSConfig != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Fatalf("Failed to start server: %v", err)
 		}
 	}()
@@ -146,6 +352,154 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Errorf("Server forced to shutdown: %v", err)
 	}
+	// Let reset emails requested before the shutdown go out
+	passwordResetter.Wait()
 
 	logger.Info("User Service stopped")
 }
+
+// runPurgeJob permanently removes users that have been soft-deleted for longer
+// than retention, refresh and verification tokens and authorization codes
+// that have expired, events published longer ago than the outbox retention
+// and finished webhook deliveries older than the webhook retention, checking
+// every interval until ctx is cancelled
+func runPurgeJob(ctx context.Context, userUseCase usecases.UserUseCase, authUseCase usecases.AuthUseCase, oidcUseCase usecases.OIDCUseCase, outboxRelay *usecases.OutboxRelay, webhookDispatcher *usecases.WebhookDispatcher, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := userUseCase.PurgeDeletedUsers(ctx, retention)
+			if err != nil {
+				logger.Errorf("Failed to purge deleted users: %v", err)
+			} else if purged > 0 {
+				logger.Infof("Purged %d users deleted more than %s ago", purged, retention)
+			}
+
+			expired, err := authUseCase.PurgeExpiredTokens(ctx)
+			if err != nil {
+				logger.Errorf("Failed to purge expired tokens: %v", err)
+			} else if expired > 0 {
+				logger.Infof("Purged %d expired tokens", expired)
+			}
+
+			codes, err := oidcUseCase.PurgeExpiredCodes(ctx)
+			if err != nil {
+				logger.Errorf("Failed to purge expired authorization codes: %v", err)
+			} else if codes > 0 {
+				logger.Infof("Purged %d expired authorization codes", codes)
+			}
+
+			events, err := outboxRelay.PurgePublished(ctx)
+			if err != nil {
+				logger.Errorf("Failed to purge published events: %v", err)
+			} else if events > 0 {
+				logger.Infof("Purged %d published events", events)
+			}
+
+			deliveries, err := webhookDispatcher.PurgeFinished(ctx)
+			if err != nil {
+				logger.Errorf("Failed to purge finished webhook deliveries: %v", err)
+			} else if deliveries > 0 {
+				logger.Infof("Purged %d finished webhook deliveries", deliveries)
+			}
+		}
+	}
+}
+
+// runSuspensionJob periodically makes users whose timed suspension ended
+// active again until ctx is cancelled
+func runSuspensionJob(ctx context.Context, userUseCase usecases.UserUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			lifted, err := userUseCase.LiftExpiredSuspensions(ctx)
+			if err != nil {
+				logger.Errorf("Failed to lift ended suspensions: %v", err)
+			} else if lifted > 0 {
+				logger.Infof("Lifted %d ended suspensions", lifted)
+			}
+		}
+	}
+}
+
+// runOutboxRelay publishes the events waiting in the outbox every interval
+// until ctx is cancelled
+func runOutboxRelay(ctx context.Context, outboxRelay *usecases.OutboxRelay, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := outboxRelay.RelayDue(ctx); err != nil {
+				logger.Errorf("Failed to relay outbox events: %v", err)
+			}
+		}
+	}
+}
+
+// runWebhookDelivery posts the webhook deliveries that are due every interval
+// until ctx is cancelled. A run waits for its deliveries, so a slow webhook
+// delays the next run rather than piling up workers.
+func runWebhookDelivery(ctx context.Context, webhookDispatcher *usecases.WebhookDispatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := webhookDispatcher.DeliverDue(ctx); err != nil {
+				logger.Errorf("Failed to deliver webhooks: %v", err)
+			}
+		}
+	}
+}
+
+// newMailer creates the mailer selected by MAIL_DRIVER
+func newMailer(cfg config.MailConfig) (services.Mailer, error) {
+	switch cfg.Driver {
+	case "log":
+		return mail.NewLogMailer(), nil
+	case "file":
+		return mail.NewFileMailer(cfg.FileDir, cfg.From)
+	case "smtp":
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", cfg.Driver)
+	}
+}
+
+// newEventBroker creates the event broker selected by EVENT_BROKER
+func newEventBroker(cfg config.EventsConfig) (services.EventBroker, error) {
+	switch cfg.Broker {
+	case "memory":
+		return events.NewMemoryBroker(), nil
+	case "nats":
+		return events.NewNATSBroker(events.NATSConfig{
+			URL:           cfg.NATSURL,
+			SubjectPrefix: cfg.SubjectPrefix,
+			Name:          "user-service",
+		})
+	default:
+		return nil, fmt.Errorf("unknown EVENT_BROKER %q", cfg.Broker)
+	}
+}
@@ -3,7 +3,22 @@
 package ent
 
 import (
+	"app-microservice/services/user-service/ent/auditentry"
+	"app-microservice/services/user-service/ent/authorizationcode"
+	"app-microservice/services/user-service/ent/lockoutevent"
+	"app-microservice/services/user-service/ent/loginattempt"
+	"app-microservice/services/user-service/ent/oauthclient"
+	"app-microservice/services/user-service/ent/outboxevent"
+	"app-microservice/services/user-service/ent/permission"
+	"app-microservice/services/user-service/ent/recoverycode"
+	"app-microservice/services/user-service/ent/refreshtoken"
+	"app-microservice/services/user-service/ent/role"
+	"app-microservice/services/user-service/ent/session"
 	"app-microservice/services/user-service/ent/user"
+	"app-microservice/services/user-service/ent/userstatushistory"
+	"app-microservice/services/user-service/ent/verificationtoken"
+	"app-microservice/services/user-service/ent/webhookdelivery"
+	"app-microservice/services/user-service/ent/webhooksubscription"
 	"context"
 	"errors"
 	"fmt"
@@ -73,7 +88,22 @@ var (
 func checkColumn(t, c string) error {
 	initCheck.Do(func() {
 		columnCheck = sql.NewColumnCheck(map[string]func(string) bool{
-			user.Table: user.ValidColumn,
+			auditentry.Table:          auditentry.ValidColumn,
+			authorizationcode.Table:   authorizationcode.ValidColumn,
+			lockoutevent.Table:        lockoutevent.ValidColumn,
+			loginattempt.Table:        loginattempt.ValidColumn,
+			oauthclient.Table:         oauthclient.ValidColumn,
+			outboxevent.Table:         outboxevent.ValidColumn,
+			permission.Table:          permission.ValidColumn,
+			recoverycode.Table:        recoverycode.ValidColumn,
+			refreshtoken.Table:        refreshtoken.ValidColumn,
+			role.Table:                role.ValidColumn,
+			session.Table:             session.ValidColumn,
+			user.Table:                user.ValidColumn,
+			userstatushistory.Table:   userstatushistory.ValidColumn,
+			verificationtoken.Table:   verificationtoken.ValidColumn,
+			webhookdelivery.Table:     webhookdelivery.ValidColumn,
+			webhooksubscription.Table: webhooksubscription.ValidColumn,
 		})
 	})
 	return columnCheck(t, c)
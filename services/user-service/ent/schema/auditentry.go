@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+
+	"app-microservice/services/user-service/ent/hook"
+)
+
+// AuditEntry holds the schema definition for the AuditEntry entity, a record
+// of an administration action. Entries form a hash chain: each stores the
+// hash of the one before it, so changing or removing an entry breaks the
+// chain from there on.
+type AuditEntry struct {
+	ent.Schema
+}
+
+// Fields of the AuditEntry.
+func (AuditEntry) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("actor_id").
+			Optional().
+			Nillable().
+			Immutable().
+			Comment("User that performed the action, empty for the system"),
+		field.String("actor_email").
+			Optional().
+			MaxLen(255).
+			Immutable().
+			Comment("Email of the actor at the time, kept after the actor is purged"),
+		field.String("action").
+			NotEmpty().
+			MaxLen(64).
+			Immutable().
+			Comment("What was done, such as user.update or role.assign"),
+		field.String("target_type").
+			NotEmpty().
+			MaxLen(32).
+			Immutable().
+			Comment("Kind of object acted on, such as user or oauth_client"),
+		field.String("target_id").
+			NotEmpty().
+			MaxLen(64).
+			Immutable().
+			Comment("ID of the object acted on"),
+		field.Text("changes").
+			Optional().
+			Immutable().
+			Comment("JSON object of the changed fields, each with its before and after value"),
+		field.String("request_id").
+			Optional().
+			MaxLen(64).
+			Immutable().
+			Comment("ID of the request that performed the action"),
+		field.String("ip").
+			Optional().
+			MaxLen(64).
+			Immutable().
+			Comment("Client IP of the request"),
+		field.Time("created_at").
+			Immutable().
+			Comment("Time of the action, part of the hash"),
+		field.String("prev_hash").
+			MaxLen(64).
+			Immutable().
+			Comment("Hash of the previous entry, empty for the first"),
+		field.String("hash").
+			NotEmpty().
+			MaxLen(64).
+			Immutable().
+			Comment("SHA-256 of the previous hash and the fields of this entry"),
+	}
+}
+
+// Indexes of the AuditEntry.
+func (AuditEntry) Indexes() []ent.Index {
+	return []ent.Index{
+		// One successor per entry keeps the chain linear when replicas
+		// append at the same time
+		index.Fields("prev_hash").Unique(),
+		index.Fields("actor_id", "created_at"),
+		index.Fields("target_type", "target_id", "created_at"),
+		index.Fields("action", "created_at"),
+		index.Fields("request_id"),
+	}
+}
+
+// Hooks of the AuditEntry.
+func (AuditEntry) Hooks() []ent.Hook {
+	return []ent.Hook{
+		// The log is append-only
+		hook.Reject(ent.OpUpdate | ent.OpUpdateOne | ent.OpDelete | ent.OpDeleteOne),
+	}
+}
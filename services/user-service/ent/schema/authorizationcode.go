@@ -0,0 +1,91 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// AuthorizationCode holds the schema definition for the AuthorizationCode
+// entity, a short-lived single-use code of the OAuth 2 authorization code
+// flow. Only a hash of the code is stored.
+type AuthorizationCode struct {
+	ent.Schema
+}
+
+// Fields of the AuthorizationCode.
+func (AuthorizationCode) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("code_hash").
+			Unique().
+			NotEmpty().
+			Sensitive().
+			Immutable().
+			Comment("SHA-256 hash of the code"),
+		field.Int("client_id").
+			Immutable().
+			Comment("Client the code was issued to"),
+		field.Int("user_id").
+			Immutable().
+			Comment("User that authorized the client"),
+		field.String("redirect_uri").
+			NotEmpty().
+			Immutable().
+			Comment("Redirect URI the code was sent to; the token request must repeat it"),
+		field.String("scope").
+			Default("").
+			Immutable().
+			Comment("Space-separated granted scopes"),
+		field.String("nonce").
+			Default("").
+			MaxLen(512).
+			Immutable().
+			Comment("Nonce of the authorization request, echoed in the ID token"),
+		field.String("code_challenge").
+			NotEmpty().
+			Immutable().
+			Comment("PKCE S256 code challenge"),
+		field.Time("auth_time").
+			Immutable().
+			Comment("Time the user signed in"),
+		field.Time("expires_at").
+			Immutable().
+			Comment("Code expiry"),
+		field.Time("used_at").
+			Optional().
+			Nillable().
+			Comment("Set when the code is exchanged"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("Code creation timestamp"),
+	}
+}
+
+// Edges of the AuthorizationCode.
+func (AuthorizationCode) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("client", OAuthClient.Type).
+			Ref("authorization_codes").
+			Field("client_id").
+			Unique().
+			Required().
+			Immutable(),
+		edge.From("user", User.Type).
+			Ref("authorization_codes").
+			Field("user_id").
+			Unique().
+			Required().
+			Immutable(),
+	}
+}
+
+// Indexes of the AuthorizationCode.
+func (AuthorizationCode) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("expires_at"),
+	}
+}
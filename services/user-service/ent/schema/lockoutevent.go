@@ -0,0 +1,83 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// LockoutEvent holds the schema definition for the LockoutEvent entity, a
+// record of an account or client IP being locked out or unlocked
+type LockoutEvent struct {
+	ent.Schema
+}
+
+// Fields of the LockoutEvent.
+func (LockoutEvent) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("user_id").
+			Optional().
+			Nillable().
+			Immutable().
+			Comment("Locked user, empty for client IPs and unknown accounts"),
+		field.String("subject").
+			NotEmpty().
+			MaxLen(320).
+			Immutable().
+			Comment("Counter that triggered the event, such as account:<email> or ip:<address>"),
+		field.Enum("action").
+			Values("locked", "unlocked").
+			Immutable().
+			Comment("Whether the subject was locked or unlocked"),
+		field.String("reason").
+			Optional().
+			MaxLen(255).
+			Immutable().
+			Comment("Why the event happened"),
+		field.String("ip").
+			Optional().
+			MaxLen(64).
+			Immutable().
+			Comment("Client IP of the attempt that triggered a lockout"),
+		field.Int("failures").
+			Default(0).
+			Immutable().
+			Comment("Failed attempts counted when the subject was locked"),
+		field.Time("locked_until").
+			Optional().
+			Nillable().
+			Immutable().
+			Comment("End of the lockout"),
+		field.Int("actor_id").
+			Optional().
+			Nillable().
+			Immutable().
+			Comment("Admin that unlocked the subject"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("Event timestamp"),
+	}
+}
+
+// Edges of the LockoutEvent.
+func (LockoutEvent) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).
+			Ref("lockout_events").
+			Field("user_id").
+			Unique().
+			Immutable(),
+	}
+}
+
+// Indexes of the LockoutEvent.
+func (LockoutEvent) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("user_id", "created_at"),
+		index.Fields("subject", "created_at"),
+	}
+}
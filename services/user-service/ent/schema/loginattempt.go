@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// LoginAttempt holds the schema definition for the LoginAttempt entity, the
+// failed login counter of an account or client IP. It is the shared store
+// the service replicas count failures in.
+type LoginAttempt struct {
+	ent.Schema
+}
+
+// Fields of the LoginAttempt.
+func (LoginAttempt) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("subject").
+			Unique().
+			NotEmpty().
+			MaxLen(320).
+			Comment("What is counted, such as account:<email> or ip:<address>"),
+		field.Int("failures").
+			Default(0).
+			NonNegative().
+			Comment("Failed attempts since the counter was last reset"),
+		field.Time("last_failure_at").
+			Default(time.Now).
+			Comment("Time of the latest failed attempt"),
+		field.Time("locked_until").
+			Optional().
+			Nillable().
+			Comment("Attempts are refused until this time"),
+	}
+}
+
+// Indexes of the LoginAttempt.
+func (LoginAttempt) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("last_failure_at"),
+	}
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// OAuthClient holds the schema definition for the OAuthClient entity, an
+// application registered to sign users in through OpenID Connect or to call
+// services with its own credentials
+type OAuthClient struct {
+	ent.Schema
+}
+
+// Fields of the OAuthClient.
+func (OAuthClient) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("client_id").
+			Unique().
+			NotEmpty().
+			MaxLen(64).
+			Immutable().
+			Comment("Public identifier of the client"),
+		field.String("name").
+			NotEmpty().
+			MaxLen(100).
+			Comment("Display name of the client"),
+		field.String("secret_hash").
+			Default("").
+			Sensitive().
+			Comment("SHA-256 hash of the client secret, empty for public clients"),
+		field.Strings("redirect_uris").
+			Optional().
+			Comment("Exact redirect URIs allowed in the authorization code flow"),
+		field.Strings("grant_types").
+			Comment("Grant types the client may use"),
+		field.Strings("scopes").
+			Optional().
+			Comment("Scopes the client may request"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("Registration timestamp"),
+	}
+}
+
+// Edges of the OAuthClient.
+func (OAuthClient) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("authorization_codes", AuthorizationCode.Type).
+			Annotations(entsql.OnDelete(entsql.Cascade)),
+	}
+}
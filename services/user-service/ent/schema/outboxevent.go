@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// OutboxEvent holds the schema definition for the OutboxEvent entity, a
+// domain event stored in the same transaction as the change that raised it
+// and published to the event broker afterwards
+type OutboxEvent struct {
+	ent.Schema
+}
+
+// Fields of the OutboxEvent.
+func (OutboxEvent) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("id").
+			NotEmpty().
+			MaxLen(36).
+			Immutable().
+			Comment("Event ID, which consumers use to skip events delivered twice"),
+		field.String("event_type").
+			NotEmpty().
+			MaxLen(64).
+			Immutable().
+			Comment("Event type, such as user.created"),
+		field.String("aggregate_type").
+			NotEmpty().
+			MaxLen(32).
+			Immutable().
+			Comment("Kind of object the event is about"),
+		field.String("aggregate_id").
+			NotEmpty().
+			MaxLen(64).
+			Immutable().
+			Comment("ID of the object the event is about"),
+		field.Text("data").
+			Immutable().
+			Comment("Event data as JSON"),
+		field.Time("occurred_at").
+			Default(time.Now).
+			Immutable().
+			Comment("When the change happened"),
+		field.Int("attempts").
+			Default(0).
+			NonNegative().
+			Comment("Failed attempts to publish the event"),
+		field.String("last_error").
+			Optional().
+			MaxLen(1024).
+			Comment("Error of the last failed attempt"),
+		field.Time("next_attempt_at").
+			Default(time.Now).
+			Comment("When the event is published next; a relay moves it ahead while publishing"),
+		field.Time("published_at").
+			Optional().
+			Nillable().
+			Comment("When the broker accepted the event"),
+	}
+}
+
+// Indexes of the OutboxEvent.
+func (OutboxEvent) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("published_at", "next_attempt_at"),
+		index.Fields("aggregate_type", "aggregate_id", "occurred_at"),
+	}
+}
@@ -0,0 +1,35 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// Permission holds the schema definition for the Permission entity.
+type Permission struct {
+	ent.Schema
+}
+
+// Fields of the Permission.
+func (Permission) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("name").
+			Unique().
+			NotEmpty().
+			MaxLen(100).
+			Comment("Permission name in resource:action form, e.g. users:delete"),
+		field.String("description").
+			Optional().
+			MaxLen(255).
+			Comment("What the permission allows"),
+	}
+}
+
+// Edges of the Permission.
+func (Permission) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("roles", Role.Type).
+			Ref("permissions"),
+	}
+}
@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// RecoveryCode holds the schema definition for the RecoveryCode entity, a
+// single-use code that replaces a TOTP code when the authenticator is lost.
+// Only a hash of the code is stored.
+type RecoveryCode struct {
+	ent.Schema
+}
+
+// Fields of the RecoveryCode.
+func (RecoveryCode) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("code_hash").
+			NotEmpty().
+			Sensitive().
+			Immutable().
+			Comment("SHA-256 hash of the recovery code"),
+		field.Int("user_id").
+			Immutable().
+			Comment("Owner of the code"),
+		field.Time("used_at").
+			Optional().
+			Nillable().
+			Comment("Set when the code is used"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("Recovery code creation timestamp"),
+	}
+}
+
+// Edges of the RecoveryCode.
+func (RecoveryCode) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).
+			Ref("recovery_codes").
+			Field("user_id").
+			Unique().
+			Required().
+			Immutable(),
+	}
+}
+
+// Indexes of the RecoveryCode.
+func (RecoveryCode) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("user_id", "code_hash").Unique(),
+	}
+}
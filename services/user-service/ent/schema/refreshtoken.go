@@ -0,0 +1,67 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// RefreshToken holds the schema definition for the RefreshToken entity.
+// Only a hash of the token is stored; the token itself is handed to the
+// client once.
+type RefreshToken struct {
+	ent.Schema
+}
+
+// Fields of the RefreshToken.
+func (RefreshToken) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("token_hash").
+			Unique().
+			NotEmpty().
+			Sensitive().
+			Comment("SHA-256 hash of the refresh token"),
+		field.String("family_id").
+			NotEmpty().
+			Immutable().
+			Comment("Identifies the chain of tokens issued from one login"),
+		field.Int("user_id").
+			Immutable().
+			Comment("Owner of the token"),
+		field.Time("expires_at").
+			Immutable().
+			Comment("Refresh token expiry"),
+		field.Time("revoked_at").
+			Optional().
+			Nillable().
+			Comment("Set when the token is rotated, logged out or revoked"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("Refresh token creation timestamp"),
+	}
+}
+
+// Edges of the RefreshToken.
+func (RefreshToken) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).
+			Ref("refresh_tokens").
+			Field("user_id").
+			Unique().
+			Required().
+			Immutable(),
+	}
+}
+
+// Indexes of the RefreshToken.
+func (RefreshToken) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("family_id"),
+		index.Fields("user_id"),
+		index.Fields("expires_at"),
+	}
+}
@@ -0,0 +1,42 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// Role holds the schema definition for the Role entity.
+type Role struct {
+	ent.Schema
+}
+
+// Fields of the Role.
+func (Role) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("name").
+			Unique().
+			NotEmpty().
+			MaxLen(50).
+			Comment("Role name, e.g. admin"),
+		field.String("description").
+			Optional().
+			MaxLen(255).
+			Comment("What the role is for"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("Role creation timestamp"),
+	}
+}
+
+// Edges of the Role.
+func (Role) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("permissions", Permission.Type),
+		edge.From("users", User.Type).
+			Ref("roles"),
+	}
+}
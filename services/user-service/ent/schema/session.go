@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Session holds the schema definition for the Session entity, one sign-in of
+// a user. Its ID is the family of the refresh tokens issued from that sign-in
+// and the sid claim of its access tokens.
+type Session struct {
+	ent.Schema
+}
+
+// Fields of the Session.
+func (Session) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("id").
+			NotEmpty().
+			Immutable().
+			Comment("Refresh token family of the session"),
+		field.Int("user_id").
+			Immutable().
+			Comment("Signed-in user"),
+		field.String("user_agent").
+			MaxLen(512).
+			Default("").
+			Immutable().
+			Comment("User agent of the sign-in request"),
+		field.String("ip").
+			MaxLen(64).
+			Default("").
+			Immutable().
+			Comment("Client IP of the sign-in request"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("Sign-in timestamp"),
+		field.Time("last_used_at").
+			Default(time.Now).
+			Comment("Last time the session refreshed or authenticated a request"),
+		field.Time("expires_at").
+			Comment("Expiry of the latest refresh token of the session"),
+		field.Time("revoked_at").
+			Optional().
+			Nillable().
+			Comment("Set when the session is logged out or revoked"),
+	}
+}
+
+// Edges of the Session.
+func (Session) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).
+			Ref("sessions").
+			Field("user_id").
+			Unique().
+			Required().
+			Immutable(),
+	}
+}
+
+// Indexes of the Session.
+func (Session) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("user_id", "last_used_at"),
+		index.Fields("expires_at"),
+	}
+}
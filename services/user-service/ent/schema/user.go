@@ -2,6 +2,8 @@ package schema
 
 import (
 	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
 	"entgo.io/ent/schema/index"
 	"time"
@@ -12,6 +14,13 @@ type User struct {
 	ent.Schema
 }
 
+// Mixin of the User.
+func (User) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		SoftDeleteMixin{},
+	}
+}
+
 // Fields of the User.
 func (User) Fields() []ent.Field {
 	return []ent.Field{
@@ -29,10 +38,41 @@ func (User) Fields() []ent.Field {
 			Optional().
 			MaxLen(20).
 			Comment("User's phone number"),
+		field.String("password_hash").
+			Optional().
+			Sensitive().
+			Comment("Encoded password hash, empty for users without a password"),
+		field.Time("password_changed_at").
+			Optional().
+			Nillable().
+			Comment("Set when the password is reset; access tokens issued earlier are rejected"),
+		field.String("mfa_secret").
+			Optional().
+			Sensitive().
+			Comment("Encrypted TOTP secret, set from enrolment until MFA is reset"),
+		field.Time("mfa_enabled_at").
+			Optional().
+			Nillable().
+			Comment("Set when TOTP enrolment is confirmed; login requires a second factor from then on"),
+		field.Int64("mfa_last_step").
+			Default(0).
+			Comment("Time step of the last accepted TOTP code, so a code cannot be replayed"),
 		field.Enum("status").
-			Values("active", "inactive", "suspended").
-			Default("active").
+			Values("pending_verification", "active", "inactive", "suspended", "locked").
+			Default("pending_verification").
 			Comment("User's account status"),
+		field.Time("locked_until").
+			Optional().
+			Nillable().
+			Comment("End of a temporary lockout after too many failed logins"),
+		field.Time("suspended_until").
+			Optional().
+			Nillable().
+			Comment("End of a timed suspension, after which the user is made active again"),
+		field.Int("version").
+			Default(1).
+			Positive().
+			Comment("Optimistic concurrency version, incremented on every update"),
 		field.Time("created_at").
 			Default(time.Now).
 			Immutable().
@@ -46,7 +86,23 @@ func (User) Fields() []ent.Field {
 
 // Edges of the User.
 func (User) Edges() []ent.Edge {
-	return nil
+	return []ent.Edge{
+		edge.To("refresh_tokens", RefreshToken.Type).
+			Annotations(entsql.OnDelete(entsql.Cascade)),
+		edge.To("verification_tokens", VerificationToken.Type).
+			Annotations(entsql.OnDelete(entsql.Cascade)),
+		edge.To("recovery_codes", RecoveryCode.Type).
+			Annotations(entsql.OnDelete(entsql.Cascade)),
+		edge.To("lockout_events", LockoutEvent.Type).
+			Annotations(entsql.OnDelete(entsql.Cascade)),
+		edge.To("status_history", UserStatusHistory.Type).
+			Annotations(entsql.OnDelete(entsql.Cascade)),
+		edge.To("sessions", Session.Type).
+			Annotations(entsql.OnDelete(entsql.Cascade)),
+		edge.To("authorization_codes", AuthorizationCode.Type).
+			Annotations(entsql.OnDelete(entsql.Cascade)),
+		edge.To("roles", Role.Type),
+	}
 }
 
 // Indexes of the User.
@@ -54,6 +110,7 @@ func (User) Indexes() []ent.Index {
 	return []ent.Index{
 		index.Fields("email").Unique(),
 		index.Fields("status"),
+		index.Fields("status", "suspended_until"),
 		index.Fields("created_at"),
 	}
 }
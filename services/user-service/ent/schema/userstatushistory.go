@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// UserStatusHistory holds the schema definition for the UserStatusHistory
+// entity, a record of one change of a user's status
+type UserStatusHistory struct {
+	ent.Schema
+}
+
+// Annotations of the UserStatusHistory.
+func (UserStatusHistory) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.Annotation{Table: "user_status_history"},
+	}
+}
+
+// Fields of the UserStatusHistory.
+func (UserStatusHistory) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("user_id").
+			Immutable().
+			Comment("User whose status changed"),
+		field.String("from_status").
+			NotEmpty().
+			MaxLen(32).
+			Immutable().
+			Comment("Status before the change"),
+		field.String("to_status").
+			NotEmpty().
+			MaxLen(32).
+			Immutable().
+			Comment("Status after the change"),
+		field.String("reason").
+			Optional().
+			MaxLen(255).
+			Immutable().
+			Comment("Why the status changed; required for suspensions"),
+		field.Time("suspended_until").
+			Optional().
+			Nillable().
+			Immutable().
+			Comment("End of a timed suspension"),
+		field.Int("actor_id").
+			Optional().
+			Nillable().
+			Immutable().
+			Comment("Admin that changed the status, empty for automatic changes"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("Change timestamp"),
+	}
+}
+
+// Edges of the UserStatusHistory.
+func (UserStatusHistory) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).
+			Ref("status_history").
+			Field("user_id").
+			Unique().
+			Required().
+			Immutable(),
+	}
+}
+
+// Indexes of the UserStatusHistory.
+func (UserStatusHistory) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("user_id", "created_at"),
+	}
+}
@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// VerificationToken holds the schema definition for the VerificationToken
+// entity, a single-use token sent to a user's email address. Only a hash of
+// the token is stored.
+type VerificationToken struct {
+	ent.Schema
+}
+
+// Fields of the VerificationToken.
+func (VerificationToken) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("token_hash").
+			Unique().
+			NotEmpty().
+			Sensitive().
+			Comment("SHA-256 hash of the token"),
+		field.Enum("purpose").
+			Values("email_verification", "password_reset", "mfa_challenge").
+			Immutable().
+			Comment("What the token may be used for"),
+		field.Int("user_id").
+			Immutable().
+			Comment("Owner of the token"),
+		field.Time("expires_at").
+			Immutable().
+			Comment("Token expiry"),
+		field.Int("failed_attempts").
+			Default(0).
+			NonNegative().
+			Comment("Wrong codes presented with an MFA challenge"),
+		field.Time("used_at").
+			Optional().
+			Nillable().
+			Comment("Set when the token is used, replaced by a newer one or retired after too many failed attempts"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("Token creation timestamp"),
+	}
+}
+
+// Edges of the VerificationToken.
+func (VerificationToken) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).
+			Ref("verification_tokens").
+			Field("user_id").
+			Unique().
+			Required().
+			Immutable(),
+	}
+}
+
+// Indexes of the VerificationToken.
+func (VerificationToken) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("user_id", "purpose"),
+		index.Fields("expires_at"),
+	}
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// WebhookDelivery holds the schema definition for the WebhookDelivery entity,
+// one event to be posted to one webhook, with the outcome of its last attempt
+type WebhookDelivery struct {
+	ent.Schema
+}
+
+// Fields of the WebhookDelivery.
+func (WebhookDelivery) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("subscription_id").
+			Immutable().
+			Comment("Webhook the event is posted to"),
+		field.String("event_id").
+			NotEmpty().
+			MaxLen(36).
+			Immutable().
+			Comment("ID of the event, sent so the receiver can skip duplicates"),
+		field.String("event_type").
+			NotEmpty().
+			MaxLen(64).
+			Immutable().
+			Comment("Type of the event"),
+		field.Text("payload").
+			Immutable().
+			Comment("Request body: the event as JSON"),
+		field.Enum("status").
+			Values("pending", "succeeded", "dead").
+			Default("pending").
+			Comment("pending until delivered, or dead once retries are exhausted"),
+		field.Int("attempts").
+			Default(0).
+			NonNegative().
+			Comment("Attempts since the delivery was queued or redelivered"),
+		field.Time("next_attempt_at").
+			Default(time.Now).
+			Comment("When the event is posted next; a worker moves it ahead while posting"),
+		field.Time("last_attempt_at").
+			Optional().
+			Nillable().
+			Comment("When the event was last posted"),
+		field.Int("last_status_code").
+			Optional().
+			Nillable().
+			Comment("HTTP status of the last attempt, empty if there was no response"),
+		field.String("last_error").
+			Optional().
+			MaxLen(1024).
+			Comment("Error of the last failed attempt"),
+		field.Time("delivered_at").
+			Optional().
+			Nillable().
+			Comment("When the webhook accepted the event"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("When the delivery was queued"),
+	}
+}
+
+// Edges of the WebhookDelivery.
+func (WebhookDelivery) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("subscription", WebhookSubscription.Type).
+			Ref("deliveries").
+			Field("subscription_id").
+			Unique().
+			Required().
+			Immutable(),
+	}
+}
+
+// Indexes of the WebhookDelivery.
+func (WebhookDelivery) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("subscription_id", "event_id").Unique(),
+		index.Fields("status", "next_attempt_at"),
+		index.Fields("subscription_id", "created_at"),
+	}
+}
@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+)
+
+// WebhookSubscription holds the schema definition for the WebhookSubscription
+// entity, a partner URL that is called with the events it subscribed to
+type WebhookSubscription struct {
+	ent.Schema
+}
+
+// Fields of the WebhookSubscription.
+func (WebhookSubscription) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("url").
+			NotEmpty().
+			MaxLen(2048).
+			Comment("URL the events are posted to"),
+		field.Strings("event_types").
+			Comment("Event types delivered, such as user.created, user.* or *"),
+		field.String("secret").
+			NotEmpty().
+			Sensitive().
+			Comment("Encrypted secret the payloads are signed with"),
+		field.String("description").
+			Optional().
+			MaxLen(255).
+			Comment("What the webhook is for"),
+		field.Bool("active").
+			Default(true).
+			Comment("Whether events are delivered; deliveries to an inactive webhook are dead-lettered"),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("Creation timestamp"),
+		field.Time("updated_at").
+			Default(time.Now).
+			UpdateDefault(time.Now).
+			Comment("Last update timestamp"),
+	}
+}
+
+// Edges of the WebhookSubscription.
+func (WebhookSubscription) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("deliveries", WebhookDelivery.Type).
+			Annotations(entsql.OnDelete(entsql.Cascade)),
+	}
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"time"
+)
+
+// AuditQuery represents the filters of an audit log search. Times are RFC
+// 3339; From is inclusive and To exclusive.
+type AuditQuery struct {
+	ActorID    *int       `form:"actor_id" binding:"omitempty,min=1"`
+	Action     string     `form:"action" binding:"omitempty,max=64"`
+	TargetType string     `form:"target_type" binding:"omitempty,max=32"`
+	TargetID   string     `form:"target_id" binding:"omitempty,max=64"`
+	RequestID  string     `form:"request_id" binding:"omitempty,max=64"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page       int        `form:"page" binding:"omitempty,min=1"`
+	PageSize   int        `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// AuditListResponse represents a page of audit entries, newest first
+type AuditListResponse struct {
+	Entries    []*entities.AuditEntry `json:"entries"`
+	Total      int64                  `json:"total"`
+	Page       int                    `json:"page"`
+	PageSize   int                    `json:"page_size"`
+	TotalPages int                    `json:"total_pages"`
+}
+
+// AuditVerifyResponse represents the result of checking the hash chain of
+// the audit log
+type AuditVerifyResponse struct {
+	Valid    bool   `json:"valid"`
+	Checked  int    `json:"checked"`             // entries checked, up to the first broken one
+	BrokenAt *int   `json:"broken_at,omitempty"` // ID of the first entry that does not match
+	Reason   string `json:"reason,omitempty"`
+	Head     string `json:"head,omitempty"` // hash of the last entry; record it elsewhere to detect truncation
+}
+
+// ToFilter converts the query to a repository filter
+func (q *AuditQuery) ToFilter() entities.AuditFilter {
+	return entities.AuditFilter{
+		ActorID:    q.ActorID,
+		Action:     q.Action,
+		TargetType: q.TargetType,
+		TargetID:   q.TargetID,
+		RequestID:  q.RequestID,
+		From:       q.From,
+		To:         q.To,
+	}
+}
@@ -0,0 +1,39 @@
+package dto
+
+// LoginResponse represents the response to a password login. Users without
+// MFA receive tokens right away; users with MFA receive a challenge token to
+// present with a code at the MFA verify endpoint instead.
+type LoginResponse struct {
+	*AuthResponse
+	MFARequired        bool   `json:"mfa_required,omitempty"`
+	ChallengeToken     string `json:"challenge_token,omitempty"`
+	ChallengeExpiresIn int    `json:"challenge_expires_in,omitempty"`
+}
+
+// MFAEnrollResponse carries a new TOTP secret. The provisioning URI is
+// usually shown as a QR code for an authenticator app to scan.
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// ConfirmMFARequest carries the first code generated by the authenticator app
+type ConfirmMFARequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFARecoveryCodesResponse carries the recovery codes issued when MFA is
+// enabled. They are shown only here; they are stored hashed.
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// VerifyMFARequest completes a login with the challenge token and either a
+// TOTP code or a recovery code
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code,omitempty" binding:"required_without=RecoveryCode"`
+	RecoveryCode   string `json:"recovery_code,omitempty" binding:"required_without=Code"`
+	ClientIP       string `json:"-"` // set by the handler for per-IP throttling and the session
+	UserAgent      string `json:"-"` // set by the handler for the session
+}
@@ -0,0 +1,110 @@
+package dto
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"time"
+)
+
+// OIDCDiscovery is the OpenID Provider Configuration document served at
+// /.well-known/openid-configuration
+type OIDCDiscovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// AuthorizeRequest is an OAuth 2 authorization request, read from the query
+// string
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+}
+
+// AuthorizeResponse tells the sign-in page where to send the browser: back to
+// the client with a code, or with an error
+type AuthorizeResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// TokenRequest is a request to the token endpoint, read from the form body.
+// The client may authenticate with HTTP Basic instead of ClientID and
+// ClientSecret.
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// TokenResponse is a successful response of the token endpoint (RFC 6749
+// section 5.1)
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	IDToken     string `json:"id_token,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// UserInfoResponse holds the claims about a user released for the scopes of
+// an access token
+type UserInfoResponse struct {
+	Subject       string `json:"sub"`
+	Name          string `json:"name,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+}
+
+// CreateOAuthClientRequest represents the request for registering a client
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" binding:"omitempty,dive,url"`
+	GrantTypes   []string `json:"grant_types" binding:"required,min=1,dive,oneof=authorization_code client_credentials"`
+	Scopes       []string `json:"scopes" binding:"omitempty,dive,required,excludesall= "`
+	Public       bool     `json:"public"` // no secret; the client relies on PKCE alone
+}
+
+// OAuthClientResponse represents the response format for a client. The
+// secret is only returned when the client is registered.
+type OAuthClientResponse struct {
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"`
+	Public       bool      `json:"public"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ToOAuthClientResponse converts an OAuthClient entity to OAuthClientResponse
+func ToOAuthClientResponse(client *entities.OAuthClient) OAuthClientResponse {
+	return OAuthClientResponse{
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIs,
+		GrantTypes:   client.GrantTypes,
+		Scopes:       client.Scopes,
+		Public:       client.IsPublic(),
+		CreatedAt:    client.CreatedAt,
+	}
+}
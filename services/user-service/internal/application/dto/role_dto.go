@@ -0,0 +1,28 @@
+package dto
+
+import "app-microservice/services/user-service/internal/domain/entities"
+
+// AssignRoleRequest represents the request for assigning a role to a user
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required,max=50"`
+}
+
+// RoleResponse represents the response format for role data
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// ToRoleResponses converts Role entities to RoleResponses
+func ToRoleResponses(roles []*entities.Role) []RoleResponse {
+	responses := make([]RoleResponse, len(roles))
+	for i, role := range roles {
+		responses[i] = RoleResponse{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: role.Permissions,
+		}
+	}
+	return responses
+}
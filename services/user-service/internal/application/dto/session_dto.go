@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"time"
+)
+
+// SessionResponse represents the response format for a session
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // the session of the request
+}
+
+// RevokeSessionsResponse reports how many sessions were ended
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// ToSessionResponses converts Session entities to SessionResponses.
+// currentID marks the session the request was made with.
+func ToSessionResponses(sessions []*entities.Session, currentID string) []SessionResponse {
+	responses := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    currentID != "" && session.ID == currentID,
+		}
+	}
+	return responses
+}
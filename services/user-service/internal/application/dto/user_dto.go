@@ -12,6 +12,59 @@ type CreateUserRequest struct {
 	Phone string `json:"phone,omitempty" binding:"omitempty"`
 }
 
+// RegisterRequest represents the request for registering with a password
+type RegisterRequest struct {
+	Name     string `json:"name" binding:"required,min=2,max=100"`
+	Email    string `json:"email" binding:"required,email"`
+	Phone    string `json:"phone,omitempty" binding:"omitempty"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginRequest represents the request for logging in with a password
+type LoginRequest struct {
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required"`
+	ClientIP  string `json:"-"` // set by the handler for per-IP throttling and the session
+	UserAgent string `json:"-"` // set by the handler for the session
+}
+
+// RefreshTokenRequest carries a refresh token to rotate or revoke
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// VerifyEmailRequest carries the token from a verification email
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendVerificationRequest represents the request for a new verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ForgotPasswordRequest represents the request for a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest carries the token from a password reset email and the
+// new password
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// AuthResponse represents the response to a successful register, login or
+// token refresh. The refresh token is shown only here; it is stored hashed.
+type AuthResponse struct {
+	User         UserResponse `json:"user"`
+	AccessToken  string       `json:"access_token"`
+	TokenType    string       `json:"token_type"`
+	ExpiresIn    int          `json:"expires_in"`
+	RefreshToken string       `json:"refresh_token"`
+}
+
 // UpdateUserRequest represents the request for updating a user
 type UpdateUserRequest struct {
 	Name  string `json:"name,omitempty" binding:"omitempty,min=2,max=100"`
@@ -19,15 +72,27 @@ type UpdateUserRequest struct {
 	Phone string `json:"phone,omitempty" binding:"omitempty"`
 }
 
+// UserPatchDocument is the JSON document a PATCH request is applied to. Phone
+// is nullable so that a merge patch of {"phone": null} clears it.
+type UserPatchDocument struct {
+	Name  string  `json:"name"`
+	Email string  `json:"email"`
+	Phone *string `json:"phone"`
+}
+
 // UserResponse represents the response format for user data
 type UserResponse struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone,omitempty"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             int        `json:"id"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email"`
+	Phone          string     `json:"phone,omitempty"`
+	Status         string     `json:"status"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	MFAEnabled     bool       `json:"mfa_enabled"`
+	Version        int        `json:"version"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
 }
 
 // UserListResponse represents the response format for user list
@@ -41,39 +106,58 @@ type UserListResponse struct {
 
 // SearchUsersRequest represents the request for searching users
 type SearchUsersRequest struct {
-	Query    string `form:"query" binding:"omitempty,min=1"`
-	Status   string `form:"status" binding:"omitempty,oneof=active inactive suspended"`
-	Page     int    `form:"page" binding:"omitempty,min=1"`
-	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Query          string `form:"query" binding:"omitempty,min=1"`
+	Status         string `form:"status" binding:"omitempty,oneof=pending_verification active inactive suspended locked"`
+	IncludeDeleted bool   `form:"include_deleted"`
+	Page           int    `form:"page" binding:"omitempty,min=1"`
+	PageSize       int    `form:"page_size" binding:"omitempty,min=1,max=100"`
 }
 
-// UpdateUserStatusRequest represents the request for updating user status
+// UpdateUserStatusRequest represents the request for updating user status.
+// Suspensions need a reason and end at SuspendedUntil, or never if it is
+// empty.
 type UpdateUserStatusRequest struct {
-	Status string `json:"status" binding:"required,oneof=active inactive suspended"`
+	Status         string     `json:"status" binding:"required,oneof=active inactive suspended"`
+	Reason         string     `json:"reason,omitempty" binding:"omitempty,max=255"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
 }
 
 // Conversion methods
 
-// ToEntity converts CreateUserRequest to User entity
+// ToEntity converts CreateUserRequest to User entity. The status is left for
+// ValidateUserCreation to default to pending verification.
 func (req *CreateUserRequest) ToEntity() *entities.User {
 	return &entities.User{
-		Name:   req.Name,
-		Email:  req.Email,
-		Phone:  req.Phone,
-		Status: string(entities.UserStatusActive),
+		Name:  req.Name,
+		Email: req.Email,
+		Phone: req.Phone,
+	}
+}
+
+// ToEntity converts RegisterRequest to User entity. The password is hashed
+// separately and never stored on the entity in plain text.
+func (req *RegisterRequest) ToEntity() *entities.User {
+	return &entities.User{
+		Name:  req.Name,
+		Email: req.Email,
+		Phone: req.Phone,
 	}
 }
 
 // ToUserResponse converts User entity to UserResponse
 func ToUserResponse(user *entities.User) UserResponse {
 	return UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Phone:     user.Phone,
-		Status:    user.Status,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:             user.ID,
+		Name:           user.Name,
+		Email:          user.Email,
+		Phone:          user.Phone,
+		Status:         user.Status,
+		SuspendedUntil: user.SuspendedUntil,
+		MFAEnabled:     user.MFAEnabled(),
+		Version:        user.Version,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+		DeletedAt:      user.DeletedAt,
 	}
 }
 
@@ -98,6 +182,30 @@ func ToUserListResponse(users []*entities.User, total int64, page, pageSize int)
 	}
 }
 
+// NewUserPatchDocument returns the patchable fields of a user
+func NewUserPatchDocument(user *entities.User) UserPatchDocument {
+	doc := UserPatchDocument{
+		Name:  user.Name,
+		Email: user.Email,
+	}
+	if user.Phone != "" {
+		doc.Phone = &user.Phone
+	}
+	return doc
+}
+
+// ApplyToEntity replaces the patchable fields of the user with the document,
+// clearing phone when it is null or empty
+func (doc *UserPatchDocument) ApplyToEntity(user *entities.User) {
+	user.Name = doc.Name
+	user.Email = doc.Email
+	user.Phone = ""
+	if doc.Phone != nil {
+		user.Phone = *doc.Phone
+	}
+	user.UpdatedAt = time.Now()
+}
+
 // ApplyToEntity applies UpdateUserRequest changes to User entity
 func (req *UpdateUserRequest) ApplyToEntity(user *entities.User) {
 	if req.Name != "" {
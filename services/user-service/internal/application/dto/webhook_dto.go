@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"encoding/json"
+	"time"
+)
+
+// CreateWebhookRequest represents the request for creating a webhook. A
+// secret is generated unless one is given.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2048"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1,dive,required,max=64"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16,max=128"`
+	Description string   `json:"description" binding:"max=255"`
+	Active      *bool    `json:"active"`
+}
+
+// UpdateWebhookRequest represents the request for updating a webhook. The
+// secret is kept unless a new one is given or RotateSecret asks for a
+// generated one.
+type UpdateWebhookRequest struct {
+	URL          string   `json:"url" binding:"required,url,max=2048"`
+	EventTypes   []string `json:"event_types" binding:"required,min=1,dive,required,max=64"`
+	Secret       string   `json:"secret" binding:"omitempty,min=16,max=128"`
+	RotateSecret bool     `json:"rotate_secret"`
+	Description  string   `json:"description" binding:"max=255"`
+	Active       *bool    `json:"active"`
+}
+
+// WebhookResponse represents the response format for a webhook. A generated
+// secret is only returned when the webhook is created or its secret rotated.
+type WebhookResponse struct {
+	ID          int       `json:"id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Secret      string    `json:"secret,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ToWebhookResponse converts a WebhookSubscription entity to WebhookResponse
+func ToWebhookResponse(webhook *entities.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:          webhook.ID,
+		URL:         webhook.URL,
+		EventTypes:  webhook.EventTypes,
+		Description: webhook.Description,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt,
+		UpdatedAt:   webhook.UpdatedAt,
+	}
+}
+
+// WebhookDeliveryQuery represents the filters of a delivery log search
+type WebhookDeliveryQuery struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending succeeded dead"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// WebhookDeliveryListResponse represents a page of deliveries, newest first
+type WebhookDeliveryListResponse struct {
+	Deliveries []*entities.WebhookDelivery `json:"deliveries"`
+	Total      int64                       `json:"total"`
+	Page       int                         `json:"page"`
+	PageSize   int                         `json:"page_size"`
+	TotalPages int                         `json:"total_pages"`
+}
+
+// ReceiveEventRequest represents a domain event sent by another service
+type ReceiveEventRequest struct {
+	ID            string          `json:"id" binding:"required,uuid"`
+	Type          string          `json:"type" binding:"required,max=64"`
+	AggregateType string          `json:"aggregate_type" binding:"required,max=32"`
+	AggregateID   string          `json:"aggregate_id" binding:"required,max=64"`
+	Data          json.RawMessage `json:"data" binding:"required"`
+	OccurredAt    time.Time       `json:"occurred_at" binding:"required"`
+}
+
+// ToEntity converts ReceiveEventRequest to a DomainEvent entity
+func (r *ReceiveEventRequest) ToEntity() *entities.DomainEvent {
+	return &entities.DomainEvent{
+		ID:            r.ID,
+		Type:          r.Type,
+		AggregateType: r.AggregateType,
+		AggregateID:   r.AggregateID,
+		Data:          r.Data,
+		OccurredAt:    r.OccurredAt,
+	}
+}
@@ -0,0 +1,213 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// auditVerifyBatch is the number of entries read at a time when verifying
+// the chain
+const auditVerifyBatch = 500
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context in which recorded actions are attributed
+// to actor
+func WithAuditActor(ctx context.Context, actor entities.AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// auditActorFrom returns the actor stored by WithAuditActor; actions without
+// one are attributed to the system
+func auditActorFrom(ctx context.Context) entities.AuditActor {
+	actor, _ := ctx.Value(auditActorKey{}).(entities.AuditActor)
+	return actor
+}
+
+// Auditor appends administration actions to the audit log, chaining each
+// entry to the one before it
+type Auditor struct {
+	repo  repositories.AuditRepository
+	clock services.Clock
+
+	// mu is held from an append until the transaction of the entry ends, so
+	// that no entry of this instance is chained to one that may still be
+	// rolled back. The repository rejects an entry whose predecessor already
+	// has a successor, which orders replicas.
+	mu sync.Mutex
+}
+
+// NewAuditor creates an auditor
+func NewAuditor(repo repositories.AuditRepository, clock services.Clock) *Auditor {
+	return &Auditor{repo: repo, clock: clock}
+}
+
+type auditTxKey struct{}
+
+// auditTx is the state of the audit log in a transaction of Outbox.Within
+type auditTx struct {
+	// locked is the auditor whose mu the transaction holds, if it appended
+	locked *Auditor
+}
+
+// withAuditTx returns a context for a new transaction, and the function to
+// call once it has ended. A context already in a transaction is returned
+// as is.
+func withAuditTx(ctx context.Context) (context.Context, func()) {
+	if _, ok := ctx.Value(auditTxKey{}).(*auditTx); ok {
+		return ctx, func() {}
+	}
+	tx := &auditTx{}
+	return context.WithValue(ctx, auditTxKey{}, tx), func() {
+		if tx.locked != nil {
+			tx.locked.mu.Unlock()
+		}
+	}
+}
+
+// Record appends an entry for an action on a target by the actor of ctx,
+// with the fields that differ between the before and after snapshots of the
+// target. It must be called within Outbox.Within, after the action's own
+// writes, so that the entry is stored if and only if the action is; a
+// failure to append fails the transaction.
+func (a *Auditor) Record(ctx context.Context, action, targetType, targetID string, before, after map[string]any) error {
+	tx, ok := ctx.Value(auditTxKey{}).(*auditTx)
+	if !ok {
+		return errors.New("audit entries must be recorded within a transaction")
+	}
+
+	changes, err := entities.DiffFields(before, after)
+	if err != nil {
+		return err
+	}
+	actor := auditActorFrom(ctx)
+	entry := &entities.AuditEntry{
+		ActorID:    actor.UserID,
+		ActorEmail: actor.Email,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Changes:    changes,
+		RequestID:  actor.RequestID,
+		IP:         actor.IP,
+		CreatedAt:  a.clock.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	if tx.locked == nil {
+		a.mu.Lock()
+		tx.locked = a
+	}
+
+	last, err := a.repo.Last(ctx)
+	if err != nil {
+		return err
+	}
+	prevHash := ""
+	if last != nil {
+		prevHash = last.Hash
+	}
+	if err := entry.Seal(prevHash); err != nil {
+		return err
+	}
+	return a.repo.Append(ctx, entry)
+}
+
+// AuditUseCase defines the interface for reading the audit log
+type AuditUseCase interface {
+	// ListEntries returns the entries matching the query, newest first
+	ListEntries(ctx context.Context, query *dto.AuditQuery) (*dto.AuditListResponse, error)
+
+	// Verify walks the hash chain from the first entry and reports the first
+	// entry that was changed, or whose predecessor was removed
+	Verify(ctx context.Context) (*dto.AuditVerifyResponse, error)
+}
+
+type auditUseCase struct {
+	auditRepo repositories.AuditRepository
+}
+
+// NewAuditUseCase creates a new audit use case
+func NewAuditUseCase(auditRepo repositories.AuditRepository) AuditUseCase {
+	return &auditUseCase{auditRepo: auditRepo}
+}
+
+// ListEntries returns the entries matching the query, newest first
+func (uc *auditUseCase) ListEntries(ctx context.Context, query *dto.AuditQuery) (*dto.AuditListResponse, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 20
+	}
+
+	filter := query.ToFilter()
+	entries, err := uc.auditRepo.List(ctx, filter, query.PageSize, (query.Page-1)*query.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	total, err := uc.auditRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total / int64(query.PageSize))
+	if total%int64(query.PageSize) > 0 {
+		totalPages++
+	}
+	return &dto.AuditListResponse{
+		Entries:    entries,
+		Total:      total,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// Verify walks the hash chain from the first entry
+func (uc *auditUseCase) Verify(ctx context.Context) (*dto.AuditVerifyResponse, error) {
+	response := &dto.AuditVerifyResponse{Valid: true}
+	prevHash, afterID := "", 0
+	for {
+		entries, err := uc.auditRepo.ListAfter(ctx, afterID, auditVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			reason := ""
+			if entry.PrevHash != prevHash {
+				reason = "previous hash does not match; an entry before it was removed or changed"
+			} else if hash, err := entry.ComputeHash(); err != nil || hash != entry.Hash {
+				reason = "hash does not match its fields; the entry was changed"
+			}
+			if reason != "" {
+				id := entry.ID
+				response.Valid, response.BrokenAt, response.Reason = false, &id, reason
+				return response, nil
+			}
+			response.Checked++
+			prevHash, afterID = entry.Hash, entry.ID
+		}
+		if len(entries) < auditVerifyBatch {
+			break
+		}
+	}
+	response.Head = prevHash
+	return response, nil
+}
+
+// auditUser is the snapshot of a user the audit log compares
+func auditUser(user *entities.User) map[string]any {
+	return map[string]any{
+		"name":            user.Name,
+		"email":           user.Email,
+		"phone":           user.Phone,
+		"status":          user.Status,
+		"suspended_until": user.SuspendedUntil,
+	}
+}
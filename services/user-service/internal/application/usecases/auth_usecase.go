@@ -0,0 +1,639 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/pkg/logger"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	// errInvalidCredentials is returned for an unknown email and for a wrong
+	// password alike so that callers cannot probe which accounts exist
+	errInvalidCredentials = errors.New("invalid email or password")
+
+	errInvalidRefreshToken = errors.New("invalid refresh token")
+	errInvalidAccessToken  = errors.New("invalid access token")
+	errCredentialsChanged  = errors.New("access token issued before password change")
+	errRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// maxLockoutEvents is the number of lockout events GetLockoutEvents returns
+const maxLockoutEvents = 50
+
+// AuthUseCase defines the interface for authentication use cases
+type AuthUseCase interface {
+	// Register creates a new user that logs in with a password once it has
+	// verified its email, and sends it a verification email
+	Register(ctx context.Context, req *dto.RegisterRequest) (*dto.UserResponse, error)
+
+	// VerifyEmail consumes a verification token and activates its user
+	VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) (*dto.UserResponse, error)
+
+	// ResendVerification sends a new verification email. It fails with a
+	// *ThrottledError if the previous one was sent too recently.
+	ResendVerification(ctx context.Context, req *dto.ResendVerificationRequest) error
+
+	// ForgotPassword emails a password reset link. It succeeds whether or not
+	// the email belongs to an account.
+	ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error
+
+	// ResetPassword sets a new password with a reset token and invalidates
+	// every token issued to the user before
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error
+
+	// Login verifies an email and password and issues tokens, or an MFA
+	// challenge if the user has MFA enabled. Repeated failures are slowed down
+	// with a *LoginThrottledError and end in an *AccountLockedError.
+	Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error)
+
+	// VerifyMFA completes a login challenged for MFA and issues tokens
+	VerifyMFA(ctx context.Context, req *dto.VerifyMFARequest) (*dto.AuthResponse, error)
+
+	// EnrollMFA starts MFA enrolment for a user with a new TOTP secret
+	EnrollMFA(ctx context.Context, userID int) (*dto.MFAEnrollResponse, error)
+
+	// ConfirmMFA enables MFA with the first code of the enrolled secret and
+	// returns the user's recovery codes
+	ConfirmMFA(ctx context.Context, userID int, req *dto.ConfirmMFARequest) (*dto.MFARecoveryCodesResponse, error)
+
+	// ResetMFA turns MFA off for a user so it can enrol again
+	ResetMFA(ctx context.Context, userID int) error
+
+	// UnlockUser lifts the lockout of a user after too many failed logins.
+	// actorID is the admin doing it.
+	UnlockUser(ctx context.Context, userID, actorID int) error
+
+	// GetLockoutEvents returns the most recent lockouts and unlocks of a user
+	GetLockoutEvents(ctx context.Context, userID int) ([]*entities.LockoutEvent, error)
+
+	// Refresh rotates a refresh token and issues a new access token. Presenting
+	// a token that was already rotated revokes its whole session.
+	Refresh(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.AuthResponse, error)
+
+	// Logout ends the session of the presented refresh token
+	Logout(ctx context.Context, req *dto.RefreshTokenRequest) error
+
+	// ListSessions returns the active sessions of a user. currentSessionID
+	// marks the session of the caller, if it is one of them.
+	ListSessions(ctx context.Context, userID int, currentSessionID string) ([]dto.SessionResponse, error)
+
+	// RevokeSession ends one active session of a user
+	RevokeSession(ctx context.Context, userID int, sessionID string) error
+
+	// RevokeAllSessions ends every session of a user, logging it out
+	// everywhere
+	RevokeAllSessions(ctx context.Context, userID int) (*dto.RevokeSessionsResponse, error)
+
+	// Authenticate verifies an access token and the session it was issued
+	// from, and resolves the caller with the permissions it currently holds
+	Authenticate(ctx context.Context, accessToken string) (*entities.Principal, error)
+
+	// JWKS returns the public keys access tokens are signed with
+	JWKS() services.JSONWebKeySet
+
+	// PurgeExpiredTokens removes refresh and verification tokens and sessions
+	// that have expired, and failed login counters that no longer matter
+	PurgeExpiredTokens(ctx context.Context) (int, error)
+}
+
+type authUseCase struct {
+	userRepo         repositories.UserRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	roleRepo         repositories.RoleRepository
+	userDomainSvc    services.UserDomainService
+	passwordHasher   services.PasswordHasher
+	passwordPolicy   services.PasswordPolicy
+	tokenIssuer      services.AccessTokenIssuer
+	emailVerifier    *EmailVerifier
+	passwordResetter *PasswordResetter
+	mfaManager       *MFAManager
+	loginGuard       *LoginGuard
+	sessionManager   *SessionManager
+	auditor          *Auditor
+	outbox           *Outbox
+	refreshTokenTTL  time.Duration
+
+	// dummyHash is verified against when the user does not exist, so a failed
+	// login takes the same time whether or not the email is registered
+	dummyHash string
+}
+
+// NewAuthUseCase creates a new auth use case. MFA resets, unlocks and
+// revoked sessions are recorded with auditor.
+func NewAuthUseCase(
+	userRepo repositories.UserRepository,
+	refreshTokenRepo repositories.RefreshTokenRepository,
+	roleRepo repositories.RoleRepository,
+	userDomainSvc services.UserDomainService,
+	passwordHasher services.PasswordHasher,
+	passwordPolicy services.PasswordPolicy,
+	tokenIssuer services.AccessTokenIssuer,
+	emailVerifier *EmailVerifier,
+	passwordResetter *PasswordResetter,
+	mfaManager *MFAManager,
+	loginGuard *LoginGuard,
+	sessionManager *SessionManager,
+	auditor *Auditor,
+	outbox *Outbox,
+	refreshTokenTTL time.Duration,
+) AuthUseCase {
+	dummyHash, err := passwordHasher.Hash("dummy password for unknown users")
+	if err != nil {
+		logger.Warnf("Failed to prepare dummy password hash: %v", err)
+	}
+
+	return &authUseCase{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		roleRepo:         roleRepo,
+		userDomainSvc:    userDomainSvc,
+		passwordHasher:   passwordHasher,
+		passwordPolicy:   passwordPolicy,
+		tokenIssuer:      tokenIssuer,
+		emailVerifier:    emailVerifier,
+		passwordResetter: passwordResetter,
+		mfaManager:       mfaManager,
+		loginGuard:       loginGuard,
+		sessionManager:   sessionManager,
+		auditor:          auditor,
+		outbox:           outbox,
+		refreshTokenTTL:  refreshTokenTTL,
+		dummyHash:        dummyHash,
+	}
+}
+
+// Register creates a new user that logs in with a password. No tokens are
+// issued until the user has verified its email.
+func (uc *authUseCase) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.UserResponse, error) {
+	if err := uc.passwordPolicy.Validate(req.Password); err != nil {
+		return nil, err
+	}
+
+	user := req.ToEntity()
+	if err := uc.userDomainSvc.ValidateUserCreation(ctx, user); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := uc.passwordHasher.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = passwordHash
+
+	err = uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		return uc.outbox.userEvents(entities.EventUserCreated, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.emailVerifier.SendOnCreate(ctx, user)
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// VerifyEmail consumes a verification token and activates its user
+func (uc *authUseCase) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) (*dto.UserResponse, error) {
+	user, err := uc.emailVerifier.Verify(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// ResendVerification sends a new verification email
+func (uc *authUseCase) ResendVerification(ctx context.Context, req *dto.ResendVerificationRequest) error {
+	return uc.emailVerifier.Resend(ctx, req.Email)
+}
+
+// ForgotPassword emails a password reset link
+func (uc *authUseCase) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	return uc.passwordResetter.Request(ctx, req.Email)
+}
+
+// ResetPassword sets a new password with a reset token
+func (uc *authUseCase) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	return uc.passwordResetter.Reset(ctx, req.Token, req.Password)
+}
+
+// Login verifies an email and password. Hashes made with outdated parameters
+// are replaced transparently after a successful login. Users with MFA get a
+// challenge instead of tokens.
+func (uc *authUseCase) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
+	if err := uc.loginGuard.Check(ctx, req.Email, req.ClientIP); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil && err.Error() != "user not found" {
+		return nil, err
+	}
+	if user == nil || !user.HasPassword() {
+		_, _, _ = uc.passwordHasher.Verify(req.Password, uc.dummyHash)
+		if err := uc.loginGuard.RecordFailure(ctx, req.Email, req.ClientIP, nil); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidCredentials
+	}
+	if err := uc.loginGuard.CheckUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	match, needsRehash, err := uc.passwordHasher.Verify(req.Password, user.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	if !match {
+		if err := uc.loginGuard.RecordFailure(ctx, req.Email, req.ClientIP, user); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidCredentials
+	}
+	if user.IsPendingVerification() {
+		return nil, errors.New("email is not verified")
+	}
+	if !user.IsActive() {
+		return nil, errors.New("user is not active")
+	}
+
+	if needsRehash {
+		if passwordHash, err := uc.passwordHasher.Hash(req.Password); err != nil {
+			logger.Warnf("Failed to rehash password of user %d: %v", user.ID, err)
+		} else if err := uc.userRepo.UpdatePasswordHash(ctx, user.ID, passwordHash); err != nil {
+			logger.Warnf("Failed to store rehashed password of user %d: %v", user.ID, err)
+		}
+	}
+
+	if user.MFAEnabled() {
+		challengeToken, expiresIn, err := uc.mfaManager.StartChallenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginResponse{
+			MFARequired:        true,
+			ChallengeToken:     challengeToken,
+			ChallengeExpiresIn: int(expiresIn.Seconds()),
+		}, nil
+	}
+
+	// With MFA the failures are forgotten only once the second factor passed
+	if err := uc.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+		return nil, err
+	}
+	response, err := uc.startSession(ctx, user, req.UserAgent, req.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{AuthResponse: response}, nil
+}
+
+// VerifyMFA checks the second factor of a challenged login and issues tokens
+func (uc *authUseCase) VerifyMFA(ctx context.Context, req *dto.VerifyMFARequest) (*dto.AuthResponse, error) {
+	if err := uc.loginGuard.Check(ctx, "", req.ClientIP); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.mfaManager.VerifyChallenge(ctx, req.ChallengeToken, req.Code, req.RecoveryCode)
+	if err != nil {
+		// Wrong codes count towards the account lockout too, or a stolen
+		// password would allow unlimited guesses over new challenges
+		if err == errInvalidMFACode {
+			if err := uc.loginGuard.RecordFailure(ctx, user.Email, req.ClientIP, user); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	if err := uc.loginGuard.CheckUser(ctx, user); err != nil {
+		return nil, err
+	}
+	// The user may have been suspended since the password step
+	if !user.IsActive() {
+		return nil, errors.New("user is not active")
+	}
+	if err := uc.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+		return nil, err
+	}
+	return uc.startSession(ctx, user, req.UserAgent, req.ClientIP)
+}
+
+// EnrollMFA starts MFA enrolment for a user
+func (uc *authUseCase) EnrollMFA(ctx context.Context, userID int) (*dto.MFAEnrollResponse, error) {
+	secret, provisioningURI, err := uc.mfaManager.Enroll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: provisioningURI,
+	}, nil
+}
+
+// ConfirmMFA enables MFA for a user
+func (uc *authUseCase) ConfirmMFA(ctx context.Context, userID int, req *dto.ConfirmMFARequest) (*dto.MFARecoveryCodesResponse, error) {
+	codes, err := uc.mfaManager.Confirm(ctx, userID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// ResetMFA turns MFA off for a user
+func (uc *authUseCase) ResetMFA(ctx context.Context, userID int) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.mfaManager.Reset(ctx, userID); err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, entities.AuditMFAReset, entities.AuditTargetUser, strconv.Itoa(userID),
+			map[string]any{"mfa_enabled": user.MFAEnabled()}, map[string]any{"mfa_enabled": false})
+	})
+}
+
+// Refresh rotates a refresh token and issues a new access token
+func (uc *authUseCase) Refresh(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
+	current, err := uc.refreshTokenRepo.GetByHash(ctx, services.HashOpaqueToken(req.RefreshToken))
+	if err != nil {
+		if err.Error() == "refresh token not found" {
+			return nil, errInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if current.IsRevoked() {
+		return nil, uc.revokeReusedFamily(ctx, current)
+	}
+	if current.IsExpired(time.Now()) {
+		return nil, errInvalidRefreshToken
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, current.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, errInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if !user.HasSessionAccess() {
+		if err := uc.sessionManager.Revoke(ctx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("user is not active")
+	}
+	// Tokens of a revoked session are revoked with it, but one may be
+	// rotated concurrently with the revocation
+	if err := uc.sessionManager.Extend(ctx, current.FamilyID, user.ID); err != nil {
+		if err == errSessionRevoked {
+			return nil, errInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	response, err := uc.issueTokens(ctx, user, current.FamilyID, current)
+	if err != nil && err.Error() == "refresh token already used" {
+		return nil, uc.revokeReusedFamily(ctx, current)
+	}
+	return response, err
+}
+
+// Logout ends the session of the presented refresh token. Unknown tokens are
+// ignored so that logging out twice is not an error.
+func (uc *authUseCase) Logout(ctx context.Context, req *dto.RefreshTokenRequest) error {
+	current, err := uc.refreshTokenRepo.GetByHash(ctx, services.HashOpaqueToken(req.RefreshToken))
+	if err != nil {
+		if err.Error() == "refresh token not found" {
+			return nil
+		}
+		return err
+	}
+	return uc.sessionManager.Revoke(ctx, current.FamilyID)
+}
+
+// ListSessions returns the active sessions of a user
+func (uc *authUseCase) ListSessions(ctx context.Context, userID int, currentSessionID string) ([]dto.SessionResponse, error) {
+	if err := uc.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+	sessions, err := uc.sessionManager.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToSessionResponses(sessions, currentSessionID), nil
+}
+
+// RevokeSession ends one active session of a user
+func (uc *authUseCase) RevokeSession(ctx context.Context, userID int, sessionID string) error {
+	if err := uc.ensureUserExists(ctx, userID); err != nil {
+		return err
+	}
+	return uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.sessionManager.RevokeForUser(ctx, userID, sessionID); err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, entities.AuditSessionRevoke, entities.AuditTargetUser, strconv.Itoa(userID),
+			map[string]any{"session_id": sessionID}, nil)
+	})
+}
+
+// RevokeAllSessions ends every session of a user
+func (uc *authUseCase) RevokeAllSessions(ctx context.Context, userID int) (*dto.RevokeSessionsResponse, error) {
+	if err := uc.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+	var revoked int
+	err := uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		var err error
+		if revoked, err = uc.sessionManager.RevokeAll(ctx, userID); err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, entities.AuditSessionsRevoke, entities.AuditTargetUser, strconv.Itoa(userID),
+			map[string]any{"active_sessions": revoked}, map[string]any{"active_sessions": 0})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RevokeSessionsResponse{Revoked: revoked}, nil
+}
+
+// Authenticate verifies an access token and loads the caller's permissions.
+// Permissions and the session are read on every request rather than trusted
+// from the token, so revoking a role or a session or suspending a user takes
+// effect immediately.
+func (uc *authUseCase) Authenticate(ctx context.Context, accessToken string) (*entities.Principal, error) {
+	claims, err := uc.tokenIssuer.Verify(accessToken)
+	if err != nil {
+		return nil, errInvalidAccessToken
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, errInvalidAccessToken
+		}
+		return nil, err
+	}
+	if !user.HasSessionAccess() {
+		return nil, errors.New("user is not active")
+	}
+	// The iat claim has a resolution of one second
+	if user.PasswordChangedAt != nil && claims.IssuedAt.Before(user.PasswordChangedAt.Truncate(time.Second)) {
+		return nil, errCredentialsChanged
+	}
+	if err := uc.sessionManager.Validate(ctx, claims.SessionID, user.ID); err != nil {
+		return nil, err
+	}
+
+	names, err := uc.roleRepo.GetUserPermissions(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	permissions := make(map[string]bool, len(names))
+	for _, name := range names {
+		permissions[name] = true
+	}
+
+	return &entities.Principal{
+		UserID:      user.ID,
+		Email:       user.Email,
+		SessionID:   claims.SessionID,
+		Permissions: permissions,
+	}, nil
+}
+
+// JWKS returns the public keys access tokens are signed with
+func (uc *authUseCase) JWKS() services.JSONWebKeySet {
+	return uc.tokenIssuer.JWKS()
+}
+
+// PurgeExpiredTokens removes refresh and verification tokens and sessions
+// that have expired, and failed login counters that no longer matter
+func (uc *authUseCase) PurgeExpiredTokens(ctx context.Context) (int, error) {
+	refreshTokens, err := uc.refreshTokenRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	verificationTokens, err := uc.emailVerifier.PurgeExpired(ctx)
+	if err != nil {
+		return refreshTokens, err
+	}
+	sessions, err := uc.sessionManager.PurgeExpired(ctx)
+	if err != nil {
+		return refreshTokens + verificationTokens, err
+	}
+	loginAttempts, err := uc.loginGuard.PurgeStale(ctx)
+	if err != nil {
+		return refreshTokens + verificationTokens + sessions, err
+	}
+	return refreshTokens + verificationTokens + sessions + loginAttempts, nil
+}
+
+// UnlockUser lifts the lockout of a user
+func (uc *authUseCase) UnlockUser(ctx context.Context, userID, actorID int) error {
+	before, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.loginGuard.Unlock(ctx, userID, actorID); err != nil {
+			return nil, err
+		}
+		after, err := uc.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, entities.AuditUserUnlock, entities.AuditTargetUser, strconv.Itoa(userID), auditUser(before), auditUser(after))
+	})
+}
+
+// GetLockoutEvents returns the most recent lockouts and unlocks of a user
+func (uc *authUseCase) GetLockoutEvents(ctx context.Context, userID int) ([]*entities.LockoutEvent, error) {
+	return uc.loginGuard.Events(ctx, userID, maxLockoutEvents)
+}
+
+// startSession records a new sign-in of user from the given client and issues
+// the first tokens of the session
+func (uc *authUseCase) startSession(ctx context.Context, user *entities.User, userAgent, ip string) (*dto.AuthResponse, error) {
+	session, err := uc.sessionManager.Start(ctx, user.ID, userAgent, ip)
+	if err != nil {
+		return nil, err
+	}
+	return uc.issueTokens(ctx, user, session.ID, nil)
+}
+
+// issueTokens stores a new refresh token in the family of a session, rotating
+// current if it is set, and signs an access token for user
+func (uc *authUseCase) issueTokens(ctx context.Context, user *entities.User, familyID string, current *entities.RefreshToken) (*dto.AuthResponse, error) {
+	refreshToken, refreshTokenHash, err := services.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	next := &entities.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: refreshTokenHash,
+		ExpiresAt: time.Now().Add(uc.refreshTokenTTL),
+	}
+	if current != nil {
+		err = uc.refreshTokenRepo.Rotate(ctx, current, next)
+	} else {
+		err = uc.refreshTokenRepo.Create(ctx, next)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := uc.tokenIssuer.Issue(services.AccessClaims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		SessionID: familyID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		User:         dto.ToUserResponse(user),
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(time.Until(expiresAt).Round(time.Second).Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// revokeReusedFamily handles a refresh token that is presented after it was
+// rotated. Either the client or an attacker holds a stolen copy, so the
+// session the token was issued from is ended.
+func (uc *authUseCase) revokeReusedFamily(ctx context.Context, token *entities.RefreshToken) error {
+	logger.Warnf("Refresh token reuse detected for user %d, revoking session %s", token.UserID, token.FamilyID)
+	if err := uc.sessionManager.Revoke(ctx, token.FamilyID); err != nil {
+		return err
+	}
+	return errRefreshTokenReused
+}
+
+// ensureUserExists fails with "user not found" for an unknown user ID
+func (uc *authUseCase) ensureUserExists(ctx context.Context, userID int) error {
+	exists, err := uc.userRepo.Exists(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("user not found")
+	}
+	return nil
+}
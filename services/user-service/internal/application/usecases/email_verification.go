@@ -0,0 +1,239 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var errInvalidVerificationToken = errors.New("invalid verification token")
+
+// ThrottledError is returned when an email was sent too recently to send
+// another one
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return "verification email recently sent"
+}
+
+// EmailVerificationConfig configures email verification
+type EmailVerificationConfig struct {
+	// TokenTTL is how long a verification link stays valid
+	TokenTTL time.Duration
+
+	// ResendInterval is the minimum time between two verification emails to
+	// the same user
+	ResendInterval time.Duration
+
+	// VerifyURL is the page the emailed link points to; the token is added as
+	// the token query parameter
+	VerifyURL string
+}
+
+// EmailVerifier sends verification emails to new users and activates them
+// when they present the token. It is shared by the user and auth use cases.
+type EmailVerifier struct {
+	userRepo  repositories.UserRepository
+	tokenRepo repositories.VerificationTokenRepository
+	mailer    services.Mailer
+	auditor   *Auditor
+	outbox    *Outbox
+	clock     services.Clock
+	config    EmailVerificationConfig
+}
+
+// NewEmailVerifier creates a new email verifier
+func NewEmailVerifier(
+	userRepo repositories.UserRepository,
+	tokenRepo repositories.VerificationTokenRepository,
+	mailer services.Mailer,
+	auditor *Auditor,
+	outbox *Outbox,
+	clock services.Clock,
+	config EmailVerificationConfig,
+) *EmailVerifier {
+	return &EmailVerifier{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mailer,
+		auditor:   auditor,
+		outbox:    outbox,
+		clock:     clock,
+		config:    config,
+	}
+}
+
+// Send issues a new verification token for user and emails it, invalidating
+// any link sent before
+func (v *EmailVerifier) Send(ctx context.Context, user *entities.User) error {
+	token, tokenHash, err := services.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := v.tokenRepo.Create(ctx, &entities.VerificationToken{
+		UserID:    user.ID,
+		Purpose:   entities.TokenPurposeEmailVerification,
+		TokenHash: tokenHash,
+		ExpiresAt: v.clock.Now().Add(v.config.TokenTTL),
+	}); err != nil {
+		return err
+	}
+
+	link, err := tokenLink(v.config.VerifyURL, token)
+	if err != nil {
+		return err
+	}
+
+	return v.mailer.Send(ctx, services.EmailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not create an account, you can ignore this email.\n",
+			user.Name, link, v.config.TokenTTL),
+	})
+}
+
+// SendOnCreate sends the verification email for a user that was just created.
+// A delivery failure is logged rather than returned, since the user exists
+// either way and can ask for the email again.
+func (v *EmailVerifier) SendOnCreate(ctx context.Context, user *entities.User) {
+	if !user.IsPendingVerification() {
+		return
+	}
+	if err := v.Send(ctx, user); err != nil {
+		logger.Errorf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+}
+
+// Verify consumes a verification token and activates its user. The token is
+// used up in the transaction of the activation, so it stays usable if the
+// activation fails.
+func (v *EmailVerifier) Verify(ctx context.Context, token string) (*entities.User, error) {
+	var user *entities.User
+	err := v.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		verification, err := consumeToken(ctx, v.tokenRepo, token, entities.TokenPurposeEmailVerification, v.clock.Now())
+		if err != nil {
+			if err == errTokenNotUsable {
+				return nil, errInvalidVerificationToken
+			}
+			return nil, err
+		}
+
+		user, err = v.userRepo.GetByID(ctx, verification.UserID)
+		if err != nil {
+			if err.Error() == "user not found" {
+				return nil, errInvalidVerificationToken
+			}
+			return nil, err
+		}
+		return activatePending(ctx, v.userRepo, v.auditor, v.outbox, user, "email verified", v.clock.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Resend emails a new verification link to the user with email if it is still
+// pending verification. Unknown and already verified emails are ignored, so
+// the caller cannot tell which accounts exist.
+func (v *EmailVerifier) Resend(ctx context.Context, email string) error {
+	user, err := v.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil
+		}
+		return err
+	}
+	if !user.IsPendingVerification() {
+		return nil
+	}
+
+	latest, err := v.tokenRepo.GetLatest(ctx, user.ID, entities.TokenPurposeEmailVerification)
+	if err != nil && err.Error() != "verification token not found" {
+		return err
+	}
+	if latest != nil {
+		if wait := v.config.ResendInterval - v.clock.Now().Sub(latest.CreatedAt); wait > 0 {
+			return &ThrottledError{RetryAfter: wait}
+		}
+	}
+
+	return v.Send(ctx, user)
+}
+
+// PurgeExpired removes verification tokens that have expired
+func (v *EmailVerifier) PurgeExpired(ctx context.Context) (int, error) {
+	return v.tokenRepo.DeleteExpired(ctx, v.clock.Now())
+}
+
+// errTokenNotUsable is returned by consumeToken for unknown, expired, used and
+// mismatched tokens alike
+var errTokenNotUsable = errors.New("token not usable")
+
+// consumeToken looks up an emailed token by its value and marks it used if it
+// has not expired at now. Of several concurrent uses of the same token only one
+// succeeds.
+func consumeToken(ctx context.Context, tokenRepo repositories.VerificationTokenRepository, token string, purpose entities.TokenPurpose, now time.Time) (*entities.VerificationToken, error) {
+	stored, err := tokenRepo.GetByHash(ctx, services.HashOpaqueToken(token))
+	if err != nil {
+		if err.Error() == "verification token not found" {
+			return nil, errTokenNotUsable
+		}
+		return nil, err
+	}
+	if stored.Purpose != purpose || stored.IsUsed() || stored.IsExpired(now) {
+		return nil, errTokenNotUsable
+	}
+
+	if err := tokenRepo.MarkUsed(ctx, stored.ID); err != nil {
+		if err.Error() == "verification token already used" {
+			return nil, errTokenNotUsable
+		}
+		return nil, err
+	}
+	return stored, nil
+}
+
+// activatePending makes user active if it is still pending verification,
+// recording the change in the audit log, and returns the user.activated event
+// to store. It must run within an Outbox transaction. A user suspended or
+// deactivated before verifying keeps that status.
+func activatePending(ctx context.Context, userRepo repositories.UserRepository, auditor *Auditor, outbox *Outbox, user *entities.User, reason string, now time.Time) ([]*entities.DomainEvent, error) {
+	if !user.IsPendingVerification() {
+		return nil, nil
+	}
+	before := auditUser(user)
+	if err := user.ChangeStatus(entities.UserStatusActive, reason, nil, now); err != nil {
+		return nil, err
+	}
+
+	if err := userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := auditor.Record(ctx, entities.AuditUserStatus, entities.AuditTargetUser, strconv.Itoa(user.ID), before, auditUser(user)); err != nil {
+		return nil, err
+	}
+	return outbox.userEvents(entities.EventUserActivated, user)
+}
+
+// tokenLink adds token as the token query parameter of base
+func tokenLink(base, token string) (string, error) {
+	link, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid link URL: %w", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
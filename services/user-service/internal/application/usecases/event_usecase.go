@@ -0,0 +1,51 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"context"
+	"errors"
+)
+
+// eventSources maps each service allowed to send events to the aggregate
+// type of the events it sends
+var eventSources = map[string]string{
+	"product-service": entities.EventAggregateProduct,
+}
+
+// EventUseCase defines the interface for receiving domain events raised by
+// other services
+type EventUseCase interface {
+	// Receive stores an event sent by the service source in the outbox, from
+	// where it is published like the events of this service. An event that
+	// was received before is accepted again without being stored twice, so
+	// the sender can retry until it gets an answer.
+	Receive(ctx context.Context, source string, req *dto.ReceiveEventRequest) error
+}
+
+type eventUseCase struct {
+	outboxRepo repositories.OutboxRepository
+}
+
+// NewEventUseCase creates a new event use case
+func NewEventUseCase(outboxRepo repositories.OutboxRepository) EventUseCase {
+	return &eventUseCase{outboxRepo: outboxRepo}
+}
+
+// Receive stores an event sent by another service
+func (uc *eventUseCase) Receive(ctx context.Context, source string, req *dto.ReceiveEventRequest) error {
+	aggregateType, ok := eventSources[source]
+	if !ok {
+		return errors.New("events must come from a known service")
+	}
+	if req.AggregateType != aggregateType || !entities.IsEventType(aggregateType, req.Type) {
+		return errors.New("unknown event type " + req.Type)
+	}
+
+	err := uc.outboxRepo.Add(ctx, req.ToEntity())
+	if err != nil && err.Error() == "event already exists" {
+		return nil
+	}
+	return err
+}
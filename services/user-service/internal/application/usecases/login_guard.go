@@ -0,0 +1,307 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/pkg/logger"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// LoginThrottledError is returned when a login comes too soon after the
+// previous failed attempts of the same account or client IP
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return "too many failed login attempts"
+}
+
+// AccountLockedError is returned when a login is refused because the account
+// is temporarily locked out
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return "account is temporarily locked"
+}
+
+// LoginProtectionConfig configures brute-force protection of logins
+type LoginProtectionConfig struct {
+	// Window is how long a failed attempt counts; failures older than this
+	// are forgotten
+	Window time.Duration
+
+	// DelayAfter is the number of failures allowed before each further
+	// attempt has to wait; the wait starts at BaseDelay and doubles with every
+	// failure up to MaxDelay
+	DelayAfter int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+
+	// MaxFailures is the number of failures after which an account is locked
+	// for LockoutDuration
+	MaxFailures     int
+	LockoutDuration time.Duration
+
+	// IPMaxFailures is the number of failures after which a client IP is
+	// refused for IPLockoutDuration. It is set higher than MaxFailures
+	// because one IP may be shared by many users.
+	IPMaxFailures     int
+	IPLockoutDuration time.Duration
+}
+
+// LoginGuard counts failed logins per account and per client IP, slows down
+// repeated failures and locks out accounts and IPs that keep failing
+type LoginGuard struct {
+	attemptRepo repositories.LoginAttemptRepository
+	eventRepo   repositories.LockoutEventRepository
+	userRepo    repositories.UserRepository
+	clock       services.Clock
+	config      LoginProtectionConfig
+}
+
+// NewLoginGuard creates a new login guard
+func NewLoginGuard(
+	attemptRepo repositories.LoginAttemptRepository,
+	eventRepo repositories.LockoutEventRepository,
+	userRepo repositories.UserRepository,
+	clock services.Clock,
+	config LoginProtectionConfig,
+) *LoginGuard {
+	return &LoginGuard{
+		attemptRepo: attemptRepo,
+		eventRepo:   eventRepo,
+		userRepo:    userRepo,
+		clock:       clock,
+		config:      config,
+	}
+}
+
+// Check refuses a login attempt for email from ip while either is locked out
+// or still has to wait after its last failure. Empty values are not checked.
+// Unknown emails are counted like known ones, so the answer does not reveal
+// which accounts exist.
+func (g *LoginGuard) Check(ctx context.Context, email, ip string) error {
+	if ip != "" {
+		if err := g.checkSubject(ctx, ipSubject(ip), false); err != nil {
+			return err
+		}
+	}
+	if email != "" {
+		if err := g.checkSubject(ctx, accountSubject(email), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckUser refuses a login for a locked user. A lockout that has run out is
+// lifted here, so the user does not stay locked until an admin steps in.
+func (g *LoginGuard) CheckUser(ctx context.Context, user *entities.User) error {
+	if user.Status != string(entities.UserStatusLocked) {
+		return nil
+	}
+
+	now := g.clock.Now()
+	if user.IsLocked(now) {
+		return &AccountLockedError{RetryAfter: user.LockedUntil.Sub(now)}
+	}
+
+	if err := g.userRepo.Unlock(ctx, user.ID, "lockout expired", nil); err != nil && err.Error() != "user is not locked" {
+		return err
+	}
+	user.Activate()
+	g.recordEvent(ctx, &entities.LockoutEvent{
+		UserID:  &user.ID,
+		Subject: accountSubject(user.Email),
+		Action:  entities.LockoutActionUnlocked,
+		Reason:  "lockout expired",
+	})
+	return nil
+}
+
+// RecordFailure counts a failed login for email from ip and locks out
+// whichever reached its limit. user is the account email belongs to, or nil
+// if there is none.
+func (g *LoginGuard) RecordFailure(ctx context.Context, email, ip string, user *entities.User) error {
+	now := g.clock.Now()
+
+	if ip != "" {
+		attempts, err := g.attemptRepo.RecordFailure(ctx, ipSubject(ip), now, g.config.Window)
+		if err != nil {
+			return err
+		}
+		if attempts.Failures >= g.config.IPMaxFailures {
+			until := now.Add(g.config.IPLockoutDuration)
+			if err := g.attemptRepo.Lock(ctx, attempts.Subject, until); err != nil {
+				return err
+			}
+			logger.Warnf("Locked out IP %s for %s after %d failed logins", ip, g.config.IPLockoutDuration, attempts.Failures)
+			g.recordEvent(ctx, &entities.LockoutEvent{
+				Subject:     attempts.Subject,
+				Action:      entities.LockoutActionLocked,
+				Reason:      "too many failed logins from this IP",
+				IP:          ip,
+				Failures:    attempts.Failures,
+				LockedUntil: &until,
+			})
+		}
+	}
+
+	if email == "" {
+		return nil
+	}
+	attempts, err := g.attemptRepo.RecordFailure(ctx, accountSubject(email), now, g.config.Window)
+	if err != nil {
+		return err
+	}
+	if attempts.Failures < g.config.MaxFailures {
+		return nil
+	}
+
+	until := now.Add(g.config.LockoutDuration)
+	if err := g.attemptRepo.Lock(ctx, attempts.Subject, until); err != nil {
+		return err
+	}
+	event := &entities.LockoutEvent{
+		Subject:     attempts.Subject,
+		Action:      entities.LockoutActionLocked,
+		Reason:      "too many failed logins",
+		IP:          ip,
+		Failures:    attempts.Failures,
+		LockedUntil: &until,
+	}
+	// Only active users change status; suspended and inactive users cannot
+	// log in anyway and keep the status an admin gave them
+	if user != nil {
+		event.UserID = &user.ID
+		if user.IsActive() {
+			if err := g.userRepo.Lock(ctx, user.ID, until, "too many failed logins"); err != nil {
+				// Changed by an admin since it was loaded
+				if err.Error() != "user is not active" {
+					return err
+				}
+			}
+			logger.Warnf("Locked out user %d for %s after %d failed logins", user.ID, g.config.LockoutDuration, attempts.Failures)
+		}
+	}
+	g.recordEvent(ctx, event)
+	return nil
+}
+
+// RecordSuccess forgets the failures of the account email. Failures of the
+// client IP are kept, so one working account does not clear the record of
+// guesses against others.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, email string) error {
+	return g.attemptRepo.Reset(ctx, accountSubject(email))
+}
+
+// Unlock lifts the lockout of a user before it runs out. actorID is the admin
+// doing it.
+func (g *LoginGuard) Unlock(ctx context.Context, userID, actorID int) error {
+	user, err := g.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	subject := accountSubject(user.Email)
+	attempts, err := g.attemptRepo.Get(ctx, subject)
+	if err != nil {
+		return err
+	}
+	// The account counter can be locked while the status is not, for
+	// instance for a user that was suspended when the lockout happened
+	if err := g.userRepo.Unlock(ctx, user.ID, "unlocked by admin", &actorID); err != nil {
+		if err.Error() != "user is not locked" || !attempts.IsLocked(g.clock.Now()) {
+			return err
+		}
+	}
+	if err := g.attemptRepo.Reset(ctx, subject); err != nil {
+		return err
+	}
+
+	g.recordEvent(ctx, &entities.LockoutEvent{
+		UserID:  &user.ID,
+		Subject: subject,
+		Action:  entities.LockoutActionUnlocked,
+		Reason:  "unlocked by admin",
+		ActorID: &actorID,
+	})
+	return nil
+}
+
+// Events returns the most recent lockout events of a user, newest first
+func (g *LoginGuard) Events(ctx context.Context, userID int, limit int) ([]*entities.LockoutEvent, error) {
+	exists, err := g.userRepo.Exists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("user not found")
+	}
+	return g.eventRepo.GetByUser(ctx, userID, limit)
+}
+
+// PurgeStale removes counters that no longer affect any login
+func (g *LoginGuard) PurgeStale(ctx context.Context) (int, error) {
+	return g.attemptRepo.DeleteStale(ctx, g.clock.Now().Add(-g.config.Window))
+}
+
+// checkSubject returns the error for a subject that is locked out or has to
+// wait. Locked accounts are reported as such; locked IPs are only throttled,
+// so a shared IP does not make every account on it look locked.
+func (g *LoginGuard) checkSubject(ctx context.Context, subject string, account bool) error {
+	attempts, err := g.attemptRepo.Get(ctx, subject)
+	if err != nil {
+		return err
+	}
+
+	now := g.clock.Now()
+	if attempts.IsLocked(now) {
+		if account {
+			return &AccountLockedError{RetryAfter: attempts.LockedUntil.Sub(now)}
+		}
+		return &LoginThrottledError{RetryAfter: attempts.LockedUntil.Sub(now)}
+	}
+	if wait := attempts.LastFailureAt.Add(g.delay(attempts.Failures)).Sub(now); wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// delay is how long to wait after the given number of failures
+func (g *LoginGuard) delay(failures int) time.Duration {
+	if failures < g.config.DelayAfter {
+		return 0
+	}
+	delay := g.config.BaseDelay
+	for i := g.config.DelayAfter; i < failures && delay < g.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > g.config.MaxDelay {
+		delay = g.config.MaxDelay
+	}
+	return delay
+}
+
+// recordEvent stores a lockout event. A failure to store it is logged rather
+// than returned, since the lockout itself already took effect.
+func (g *LoginGuard) recordEvent(ctx context.Context, event *entities.LockoutEvent) {
+	if err := g.eventRepo.Create(ctx, event); err != nil {
+		logger.Errorf("Failed to record lockout event for %s: %v", event.Subject, err)
+	}
+}
+
+func accountSubject(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,256 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/pkg/logger"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	errInvalidMFAChallenge = errors.New("invalid mfa challenge")
+	errInvalidMFACode      = errors.New("invalid mfa code")
+)
+
+// MFAConfig configures TOTP multi-factor authentication
+type MFAConfig struct {
+	// ChallengeTTL is how long a login may take between the password step and
+	// the code step
+	ChallengeTTL time.Duration
+
+	// MaxAttempts is the number of wrong codes after which a challenge is
+	// retired and the login has to start over
+	MaxAttempts int
+
+	// RecoveryCodes is the number of recovery codes issued when MFA is enabled
+	RecoveryCodes int
+}
+
+// MFAManager enrols users in TOTP multi-factor authentication and checks the
+// second factor during login
+type MFAManager struct {
+	userRepo         repositories.UserRepository
+	tokenRepo        repositories.VerificationTokenRepository
+	recoveryCodeRepo repositories.RecoveryCodeRepository
+	txManager        repositories.TxManager
+	totp             services.TOTP
+	secretBox        services.SecretBox
+	clock            services.Clock
+	config           MFAConfig
+}
+
+// NewMFAManager creates a new MFA manager. The clock should be the one the
+// TOTP validator uses.
+func NewMFAManager(
+	userRepo repositories.UserRepository,
+	tokenRepo repositories.VerificationTokenRepository,
+	recoveryCodeRepo repositories.RecoveryCodeRepository,
+	txManager repositories.TxManager,
+	totp services.TOTP,
+	secretBox services.SecretBox,
+	clock services.Clock,
+	config MFAConfig,
+) *MFAManager {
+	return &MFAManager{
+		userRepo:         userRepo,
+		tokenRepo:        tokenRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		txManager:        txManager,
+		totp:             totp,
+		secretBox:        secretBox,
+		clock:            clock,
+		config:           config,
+	}
+}
+
+// Enroll generates a new TOTP secret for a user and stores it unconfirmed.
+// Enrolling again before confirming replaces the secret.
+func (m *MFAManager) Enroll(ctx context.Context, userID int) (secret, provisioningURI string, err error) {
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.MFAEnabled() {
+		return "", "", errors.New("mfa already enabled")
+	}
+
+	secret, err = m.totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	sealed, err := m.secretBox.Seal(secret)
+	if err != nil {
+		return "", "", err
+	}
+	if err := m.userRepo.UpdateMFA(ctx, user.ID, sealed, nil); err != nil {
+		return "", "", err
+	}
+
+	return secret, m.totp.ProvisioningURI(secret, user.Email), nil
+}
+
+// Confirm enables MFA once the user proves its authenticator app generates
+// codes for the enrolled secret, and returns a fresh set of recovery codes
+func (m *MFAManager) Confirm(ctx context.Context, userID int, code string) ([]string, error) {
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled() {
+		return nil, errors.New("mfa already enabled")
+	}
+	if user.MFASecret == "" {
+		return nil, errors.New("mfa enrollment not started")
+	}
+
+	secret, err := m.secretBox.Open(user.MFASecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := m.totp.Validate(secret, code)
+	if !ok {
+		return nil, errInvalidMFACode
+	}
+
+	codes := make([]string, 0, m.config.RecoveryCodes)
+	hashes := make([]string, 0, m.config.RecoveryCodes)
+	for i := 0; i < m.config.RecoveryCodes; i++ {
+		code, hash, err := services.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hash)
+	}
+	// MFA is enabled together with its recovery codes or not at all
+	err = m.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := m.recoveryCodeRepo.Replace(ctx, user.ID, hashes); err != nil {
+			return err
+		}
+		enabledAt := m.clock.Now()
+		if err := m.userRepo.UpdateMFA(ctx, user.ID, user.MFASecret, &enabledAt); err != nil {
+			return err
+		}
+		// The confirmation code must not be usable again to log in
+		return m.userRepo.UseMFAStep(ctx, user.ID, step)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Reset turns MFA off for a user and deletes its secret and recovery codes,
+// for users that lost their authenticator app and their recovery codes
+func (m *MFAManager) Reset(ctx context.Context, userID int) error {
+	return m.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := m.userRepo.UpdateMFA(ctx, userID, "", nil); err != nil {
+			return err
+		}
+		return m.recoveryCodeRepo.DeleteForUser(ctx, userID)
+	})
+}
+
+// StartChallenge issues the challenge token a user with MFA presents together
+// with a code to finish logging in, and returns how long it is valid
+func (m *MFAManager) StartChallenge(ctx context.Context, user *entities.User) (string, time.Duration, error) {
+	token, tokenHash, err := services.GenerateOpaqueToken()
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := m.tokenRepo.Create(ctx, &entities.VerificationToken{
+		UserID:    user.ID,
+		Purpose:   entities.TokenPurposeMFAChallenge,
+		TokenHash: tokenHash,
+		ExpiresAt: m.clock.Now().Add(m.config.ChallengeTTL),
+	}); err != nil {
+		return "", 0, err
+	}
+	return token, m.config.ChallengeTTL, nil
+}
+
+// VerifyChallenge checks a TOTP code or recovery code against a challenge and
+// consumes the challenge on success. Each wrong code counts against the
+// challenge, which is retired after MaxAttempts. On a wrong code the user of
+// the challenge is returned along with the error, so the caller can count the
+// failure against the account.
+func (m *MFAManager) VerifyChallenge(ctx context.Context, challengeToken, code, recoveryCode string) (*entities.User, error) {
+	challenge, err := m.tokenRepo.GetByHash(ctx, services.HashOpaqueToken(challengeToken))
+	if err != nil {
+		if err.Error() == "verification token not found" {
+			return nil, errInvalidMFAChallenge
+		}
+		return nil, err
+	}
+	if challenge.Purpose != entities.TokenPurposeMFAChallenge || challenge.IsUsed() || challenge.IsExpired(m.clock.Now()) {
+		return nil, errInvalidMFAChallenge
+	}
+
+	user, err := m.userRepo.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, errInvalidMFAChallenge
+		}
+		return nil, err
+	}
+	// MFA may have been reset since the challenge was issued
+	if !user.MFAEnabled() {
+		return nil, errInvalidMFAChallenge
+	}
+
+	accepted, err := m.checkFactor(ctx, user, code, recoveryCode)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		if err := m.tokenRepo.RecordFailedAttempt(ctx, challenge.ID, m.config.MaxAttempts); err != nil {
+			return nil, err
+		}
+		return user, errInvalidMFACode
+	}
+
+	if err := m.tokenRepo.MarkUsed(ctx, challenge.ID); err != nil {
+		if err.Error() == "verification token already used" {
+			return nil, errInvalidMFAChallenge
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// checkFactor reports whether code is a current TOTP code that was not used
+// before or, when code is empty, whether recoveryCode is an unused recovery
+// code of user. A matching code is spent.
+func (m *MFAManager) checkFactor(ctx context.Context, user *entities.User, code, recoveryCode string) (bool, error) {
+	if code == "" {
+		err := m.recoveryCodeRepo.Use(ctx, user.ID, services.HashRecoveryCode(recoveryCode))
+		if err != nil {
+			if err.Error() == "recovery code not found" {
+				return false, nil
+			}
+			return false, err
+		}
+		logger.Infof("User %d logged in with a recovery code", user.ID)
+		return true, nil
+	}
+
+	secret, err := m.secretBox.Open(user.MFASecret)
+	if err != nil {
+		return false, err
+	}
+	step, ok := m.totp.Validate(secret, code)
+	if !ok {
+		return false, nil
+	}
+	if err := m.userRepo.UseMFAStep(ctx, user.ID, step); err != nil {
+		if err.Error() == "mfa code already used" {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
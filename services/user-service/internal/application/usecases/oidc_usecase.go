@@ -0,0 +1,582 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/pkg/logger"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthError is an error of the OAuth 2 protocol, identified by one of the
+// error codes of RFC 6749. When RedirectURI is set the error is reported by
+// redirecting the browser back to the client, otherwise to the caller
+// directly, since the redirect URI could not be trusted.
+type OAuthError struct {
+	Code        string
+	Description string
+	RedirectURI string
+	State       string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// RedirectTo returns the redirect URI with the error added to its query
+func (e *OAuthError) RedirectTo() string {
+	return addQuery(e.RedirectURI, map[string]string{
+		"error":             e.Code,
+		"error_description": e.Description,
+		"state":             e.State,
+	})
+}
+
+// OIDCConfig configures the OpenID Connect provider
+type OIDCConfig struct {
+	// Issuer is the URL of the provider that relying parties discover it by.
+	// The endpoints are served under it.
+	Issuer string
+
+	// CodeTTL is how long an authorization code can be exchanged for tokens
+	CodeTTL time.Duration
+}
+
+// OIDCUseCase defines the interface for the OpenID Connect provider
+type OIDCUseCase interface {
+	// Discovery returns the provider configuration document
+	Discovery() *dto.OIDCDiscovery
+
+	// ValidateAuthorize checks an authorization request before the user is
+	// asked to sign in and returns the client asking. It fails with an
+	// *OAuthError.
+	ValidateAuthorize(ctx context.Context, req *dto.AuthorizeRequest) (*dto.OAuthClientResponse, error)
+
+	// Authorize issues an authorization code to the client of the request on
+	// behalf of the signed-in principal
+	Authorize(ctx context.Context, req *dto.AuthorizeRequest, principal *entities.Principal) (*dto.AuthorizeResponse, error)
+
+	// Token exchanges an authorization code, or the credentials of a client,
+	// for tokens. It fails with an *OAuthError.
+	Token(ctx context.Context, req *dto.TokenRequest) (*dto.TokenResponse, error)
+
+	// UserInfo returns the claims about the user of an access token
+	UserInfo(ctx context.Context, accessToken string) (*dto.UserInfoResponse, error)
+
+	// RegisterClient registers a new client and returns its secret once
+	RegisterClient(ctx context.Context, req *dto.CreateOAuthClientRequest) (*dto.OAuthClientResponse, error)
+
+	// ListClients returns every registered client
+	ListClients(ctx context.Context) ([]dto.OAuthClientResponse, error)
+
+	// DeleteClient removes a client. Tokens already issued to it stay valid
+	// until they expire.
+	DeleteClient(ctx context.Context, clientID string) error
+
+	// PurgeExpiredCodes removes authorization codes that have expired
+	PurgeExpiredCodes(ctx context.Context) (int, error)
+}
+
+type oidcUseCase struct {
+	clientRepo  repositories.OAuthClientRepository
+	codeRepo    repositories.AuthorizationCodeRepository
+	userRepo    repositories.UserRepository
+	sessionRepo repositories.SessionRepository
+	tokenIssuer services.OIDCTokenIssuer
+	clock       services.Clock
+	auditor     *Auditor
+	outbox      *Outbox
+	config      OIDCConfig
+}
+
+// NewOIDCUseCase creates a new OpenID Connect use case. Registered and
+// deleted clients are recorded with auditor, in a transaction of outbox.
+func NewOIDCUseCase(
+	clientRepo repositories.OAuthClientRepository,
+	codeRepo repositories.AuthorizationCodeRepository,
+	userRepo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	tokenIssuer services.OIDCTokenIssuer,
+	clock services.Clock,
+	auditor *Auditor,
+	outbox *Outbox,
+	config OIDCConfig,
+) OIDCUseCase {
+	config.Issuer = strings.TrimRight(config.Issuer, "/")
+	return &oidcUseCase{
+		clientRepo:  clientRepo,
+		codeRepo:    codeRepo,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		tokenIssuer: tokenIssuer,
+		clock:       clock,
+		auditor:     auditor,
+		outbox:      outbox,
+		config:      config,
+	}
+}
+
+// Discovery returns the provider configuration document
+func (uc *oidcUseCase) Discovery() *dto.OIDCDiscovery {
+	return &dto.OIDCDiscovery{
+		Issuer:                            uc.config.Issuer,
+		AuthorizationEndpoint:             uc.config.Issuer + "/oauth2/authorize",
+		TokenEndpoint:                     uc.config.Issuer + "/oauth2/token",
+		UserInfoEndpoint:                  uc.config.Issuer + "/oauth2/userinfo",
+		JWKSURI:                           uc.config.Issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{entities.GrantTypeAuthorizationCode, entities.GrantTypeClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		ScopesSupported:                   []string{entities.ScopeOpenID, entities.ScopeProfile, entities.ScopeEmail},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "name", "email", "email_verified"},
+	}
+}
+
+// ValidateAuthorize checks an authorization request
+func (uc *oidcUseCase) ValidateAuthorize(ctx context.Context, req *dto.AuthorizeRequest) (*dto.OAuthClientResponse, error) {
+	client, err := uc.validateAuthorize(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	response := dto.ToOAuthClientResponse(client)
+	return &response, nil
+}
+
+// Authorize issues an authorization code for the request. The code is bound
+// to the client, redirect URI, scope and PKCE challenge of the request.
+func (uc *oidcUseCase) Authorize(ctx context.Context, req *dto.AuthorizeRequest, principal *entities.Principal) (*dto.AuthorizeResponse, error) {
+	client, err := uc.validateAuthorize(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The user signed in when its session started
+	session, err := uc.sessionRepo.GetByID(ctx, principal.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, codeHash, err := services.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.codeRepo.Create(ctx, &entities.AuthorizationCode{
+		ClientID:      client.ID,
+		UserID:        principal.UserID,
+		CodeHash:      codeHash,
+		RedirectURI:   req.RedirectURI,
+		Scope:         req.Scope,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      session.CreatedAt,
+		ExpiresAt:     uc.clock.Now().Add(uc.config.CodeTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthorizeResponse{
+		RedirectTo: addQuery(req.RedirectURI, map[string]string{
+			"code":  code,
+			"state": req.State,
+			"iss":   uc.config.Issuer, // RFC 9207
+		}),
+	}, nil
+}
+
+// Token exchanges an authorization code or client credentials for tokens
+func (uc *oidcUseCase) Token(ctx context.Context, req *dto.TokenRequest) (*dto.TokenResponse, error) {
+	client, err := uc.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case "":
+		return nil, &OAuthError{Code: "invalid_request", Description: "grant_type is required"}
+	case entities.GrantTypeAuthorizationCode, entities.GrantTypeClientCredentials:
+	default:
+		return nil, &OAuthError{Code: "unsupported_grant_type", Description: "grant type " + req.GrantType + " is not supported"}
+	}
+	if !client.AllowsGrantType(req.GrantType) {
+		return nil, &OAuthError{Code: "unauthorized_client", Description: "client may not use grant type " + req.GrantType}
+	}
+
+	if req.GrantType == entities.GrantTypeClientCredentials {
+		return uc.clientCredentials(client, req)
+	}
+	return uc.exchangeCode(ctx, client, req)
+}
+
+// UserInfo returns the claims about the user of an access token that its
+// scopes release
+func (uc *oidcUseCase) UserInfo(ctx context.Context, accessToken string) (*dto.UserInfoResponse, error) {
+	claims, err := uc.tokenIssuer.VerifyAccessToken(accessToken)
+	if err != nil {
+		return nil, errInvalidAccessToken
+	}
+	// Tokens from client credentials have the client as their subject
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, errInvalidAccessToken
+	}
+	scopes := strings.Fields(claims.Scope)
+	if !slices.Contains(scopes, entities.ScopeOpenID) {
+		return nil, errors.New("insufficient scope")
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, errInvalidAccessToken
+		}
+		return nil, err
+	}
+	if !user.HasSessionAccess() {
+		return nil, errInvalidAccessToken
+	}
+
+	response := &dto.UserInfoResponse{Subject: claims.Subject}
+	if slices.Contains(scopes, entities.ScopeProfile) {
+		response.Name = user.Name
+	}
+	if slices.Contains(scopes, entities.ScopeEmail) {
+		verified := !user.IsPendingVerification()
+		response.Email = user.Email
+		response.EmailVerified = &verified
+	}
+	return response, nil
+}
+
+// RegisterClient registers a new client. Confidential clients get a secret,
+// which is only stored as a hash and returned once.
+func (uc *oidcUseCase) RegisterClient(ctx context.Context, req *dto.CreateOAuthClientRequest) (*dto.OAuthClientResponse, error) {
+	client := &entities.OAuthClient{
+		ClientID:     uuid.NewString(),
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		GrantTypes:   slices.Compact(slices.Sorted(slices.Values(req.GrantTypes))),
+		Scopes:       req.Scopes,
+	}
+	if err := validateClientMetadata(client, req.Public); err != nil {
+		return nil, err
+	}
+
+	var secret string
+	if !req.Public {
+		var err error
+		if secret, client.SecretHash, err = services.GenerateOpaqueToken(); err != nil {
+			return nil, err
+		}
+	}
+	err := uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.clientRepo.Create(ctx, client); err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, entities.AuditClientCreate, entities.AuditTargetClient, client.ClientID, nil, auditClient(client))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToOAuthClientResponse(client)
+	response.ClientSecret = secret
+	return &response, nil
+}
+
+// ListClients returns every registered client
+func (uc *oidcUseCase) ListClients(ctx context.Context) ([]dto.OAuthClientResponse, error) {
+	clients, err := uc.clientRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.OAuthClientResponse, len(clients))
+	for i, client := range clients {
+		responses[i] = dto.ToOAuthClientResponse(client)
+	}
+	return responses, nil
+}
+
+// DeleteClient removes a client
+func (uc *oidcUseCase) DeleteClient(ctx context.Context, clientID string) error {
+	client, err := uc.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	return uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.clientRepo.Delete(ctx, clientID); err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, entities.AuditClientDelete, entities.AuditTargetClient, clientID, auditClient(client), nil)
+	})
+}
+
+// auditClient is the snapshot of a client the audit log compares
+func auditClient(client *entities.OAuthClient) map[string]any {
+	return map[string]any{
+		"name":          client.Name,
+		"redirect_uris": client.RedirectURIs,
+		"grant_types":   client.GrantTypes,
+		"scopes":        client.Scopes,
+		"public":        client.IsPublic(),
+	}
+}
+
+// PurgeExpiredCodes removes authorization codes that have expired
+func (uc *oidcUseCase) PurgeExpiredCodes(ctx context.Context) (int, error) {
+	return uc.codeRepo.DeleteExpired(ctx, uc.clock.Now())
+}
+
+// validateAuthorize checks an authorization request and returns its client.
+// Problems with the client or redirect URI are reported to the caller; any
+// other problem is reported to the client through its redirect URI.
+func (uc *oidcUseCase) validateAuthorize(ctx context.Context, req *dto.AuthorizeRequest) (*entities.OAuthClient, error) {
+	client, err := uc.clientRepo.GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		if err.Error() == "oauth client not found" {
+			return nil, &OAuthError{Code: "invalid_request", Description: "unknown client_id"}
+		}
+		return nil, err
+	}
+	if !client.AllowsRedirectURI(req.RedirectURI) {
+		return nil, &OAuthError{Code: "invalid_request", Description: "redirect_uri is not registered for the client"}
+	}
+
+	fail := func(code, description string) error {
+		return &OAuthError{Code: code, Description: description, RedirectURI: req.RedirectURI, State: req.State}
+	}
+	switch {
+	case req.ResponseType != "code":
+		return nil, fail("unsupported_response_type", "response_type must be code")
+	case !client.AllowsGrantType(entities.GrantTypeAuthorizationCode):
+		return nil, fail("unauthorized_client", "client may not use the authorization code flow")
+	case strings.TrimSpace(req.Scope) == "":
+		return nil, fail("invalid_scope", "scope is required")
+	case !client.AllowsScopes(req.Scope):
+		return nil, fail("invalid_scope", "client may not request scope "+req.Scope)
+	case req.CodeChallenge == "":
+		return nil, fail("invalid_request", "code_challenge is required")
+	case req.CodeChallengeMethod != "S256":
+		return nil, fail("invalid_request", "code_challenge_method must be S256")
+	}
+	return client, nil
+}
+
+// authenticateClient resolves the client of a token request. Public clients
+// only identify themselves; confidential clients must present their secret.
+func (uc *oidcUseCase) authenticateClient(ctx context.Context, clientID, secret string) (*entities.OAuthClient, error) {
+	if clientID == "" {
+		return nil, &OAuthError{Code: "invalid_client", Description: "client authentication is required"}
+	}
+	client, err := uc.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		if err.Error() == "oauth client not found" {
+			return nil, &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+		}
+		return nil, err
+	}
+
+	if client.IsPublic() {
+		if secret != "" {
+			return nil, &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(services.HashOpaqueToken(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, &OAuthError{Code: "invalid_client", Description: "client authentication failed"}
+	}
+	return client, nil
+}
+
+// exchangeCode redeems an authorization code for an access token and, for
+// the openid scope, an ID token
+func (uc *oidcUseCase) exchangeCode(ctx context.Context, client *entities.OAuthClient, req *dto.TokenRequest) (*dto.TokenResponse, error) {
+	invalidGrant := &OAuthError{Code: "invalid_grant", Description: "invalid authorization code"}
+	if req.Code == "" {
+		return nil, &OAuthError{Code: "invalid_request", Description: "code is required"}
+	}
+
+	code, err := uc.codeRepo.Consume(ctx, services.HashOpaqueToken(req.Code))
+	if err != nil {
+		switch err.Error() {
+		case "authorization code already used":
+			logger.Warnf("Authorization code reuse detected for client %s", client.ClientID)
+			return nil, invalidGrant
+		case "authorization code not found":
+			return nil, invalidGrant
+		}
+		return nil, err
+	}
+	if code.ClientID != client.ID || code.IsExpired(uc.clock.Now()) {
+		return nil, invalidGrant
+	}
+	if code.RedirectURI != req.RedirectURI {
+		return nil, &OAuthError{Code: "invalid_grant", Description: "redirect_uri does not match the authorization request"}
+	}
+	if !services.VerifyPKCE(req.CodeVerifier, code.CodeChallenge) {
+		return nil, &OAuthError{Code: "invalid_grant", Description: "invalid code_verifier"}
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, code.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, invalidGrant
+		}
+		return nil, err
+	}
+	if !user.HasSessionAccess() {
+		return nil, &OAuthError{Code: "invalid_grant", Description: "user is not active"}
+	}
+
+	subject := strconv.Itoa(user.ID)
+	accessToken, expiresAt, err := uc.tokenIssuer.IssueAccessToken(services.OAuthAccessClaims{
+		Subject:  subject,
+		ClientID: client.ClientID,
+		Scope:    code.Scope,
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := &dto.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(time.Until(expiresAt).Round(time.Second).Seconds()),
+		Scope:       code.Scope,
+	}
+
+	if code.HasScope(entities.ScopeOpenID) {
+		claims := services.IDTokenClaims{
+			Subject:  subject,
+			Audience: client.ClientID,
+			Nonce:    code.Nonce,
+			AuthTime: code.AuthTime,
+		}
+		if code.HasScope(entities.ScopeProfile) {
+			claims.Name = user.Name
+		}
+		if code.HasScope(entities.ScopeEmail) {
+			verified := !user.IsPendingVerification()
+			claims.Email = user.Email
+			claims.EmailVerified = &verified
+		}
+		if response.IDToken, err = uc.tokenIssuer.IssueIDToken(claims); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// clientCredentials issues an access token to a confidential client acting on
+// its own behalf. Without a scope the client gets every scope it may request,
+// except the OpenID Connect scopes, which need a user.
+func (uc *oidcUseCase) clientCredentials(client *entities.OAuthClient, req *dto.TokenRequest) (*dto.TokenResponse, error) {
+	if client.IsPublic() {
+		return nil, &OAuthError{Code: "unauthorized_client", Description: "public clients cannot use client credentials"}
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		for _, s := range client.Scopes {
+			if !entities.IsIdentityScope(s) {
+				scopes = append(scopes, s)
+			}
+		}
+	} else if !client.AllowsScopes(req.Scope) || slices.ContainsFunc(scopes, entities.IsIdentityScope) {
+		return nil, &OAuthError{Code: "invalid_scope", Description: "client may not request scope " + req.Scope}
+	}
+	scope := strings.Join(scopes, " ")
+
+	accessToken, expiresAt, err := uc.tokenIssuer.IssueAccessToken(services.OAuthAccessClaims{
+		Subject:  client.ClientID,
+		ClientID: client.ClientID,
+		Scope:    scope,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(time.Until(expiresAt).Round(time.Second).Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// validateClientMetadata checks a client before it is registered and fills in
+// the default scopes of the authorization code flow
+func validateClientMetadata(client *entities.OAuthClient, public bool) error {
+	invalid := func(description string) error {
+		return &OAuthError{Code: "invalid_client_metadata", Description: description}
+	}
+
+	if public && client.AllowsGrantType(entities.GrantTypeClientCredentials) {
+		return invalid("public clients cannot use client credentials")
+	}
+	if !client.AllowsGrantType(entities.GrantTypeAuthorizationCode) {
+		if len(client.RedirectURIs) > 0 {
+			return invalid("redirect_uris are only used by the authorization code flow")
+		}
+		return nil
+	}
+
+	if len(client.RedirectURIs) == 0 {
+		return invalid("the authorization code flow needs at least one redirect URI")
+	}
+	for _, uri := range client.RedirectURIs {
+		if !isValidRedirectURI(uri) {
+			return invalid("redirect URI " + uri + " must be an absolute https URI without fragment, or http on a loopback address")
+		}
+	}
+	if len(client.Scopes) == 0 {
+		client.Scopes = []string{entities.ScopeOpenID, entities.ScopeProfile, entities.ScopeEmail}
+	}
+	return nil
+}
+
+// isValidRedirectURI allows absolute https URIs, and plain http only for
+// loopback addresses used by native apps and local development
+func isValidRedirectURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Fragment != "" || u.Host == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		ip := net.ParseIP(host)
+		return host == "localhost" || (ip != nil && ip.IsLoopback())
+	}
+	return false
+}
+
+// addQuery adds the non-empty params to the query of uri
+func addQuery(uri string, params map[string]string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	query := u.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
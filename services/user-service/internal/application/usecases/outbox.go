@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/pkg/logger"
+	"context"
+	"strconv"
+	"time"
+)
+
+// Outbox stores the domain events raised by a change in the same transaction
+// as the change, for OutboxRelay to publish
+type Outbox struct {
+	txManager  repositories.TxManager
+	outboxRepo repositories.OutboxRepository
+	clock      services.Clock
+}
+
+// NewOutbox creates an outbox
+func NewOutbox(txManager repositories.TxManager, outboxRepo repositories.OutboxRepository, clock services.Clock) *Outbox {
+	return &Outbox{txManager: txManager, outboxRepo: outboxRepo, clock: clock}
+}
+
+// Within runs fn in a transaction and stores the events it returns in the
+// same transaction, so the events are stored if and only if the change is.
+// Audit entries fn records with an Auditor are part of the transaction too.
+func (o *Outbox) Within(ctx context.Context, fn func(ctx context.Context) ([]*entities.DomainEvent, error)) error {
+	ctx, done := withAuditTx(ctx)
+	defer done()
+
+	return o.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		events, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		return o.outboxRepo.Add(ctx, events...)
+	})
+}
+
+// userEvents returns the event of type eventType about user, or none if
+// eventType is empty
+func (o *Outbox) userEvents(eventType string, user *entities.User) ([]*entities.DomainEvent, error) {
+	if eventType == "" {
+		return nil, nil
+	}
+	event, err := entities.NewDomainEvent(eventType, entities.EventAggregateUser, strconv.Itoa(user.ID), dto.ToUserResponse(user), o.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	return []*entities.DomainEvent{event}, nil
+}
+
+// OutboxRelayConfig holds the settings of an outbox relay
+type OutboxRelayConfig struct {
+	BatchSize int           // events published per run
+	Lease     time.Duration // how long other relays leave an event alone while it is published
+	RetryBase time.Duration // delay before the first retry, doubled for each further one
+	RetryMax  time.Duration // longest delay between retries
+	Retention time.Duration // how long published events are kept
+}
+
+// OutboxRelay publishes the events in the outbox to the event broker. An
+// event is marked published only after the broker accepted it, so it is
+// delivered at least once; failed events are retried with exponential
+// backoff, after which events of one user may arrive out of order.
+type OutboxRelay struct {
+	outboxRepo repositories.OutboxRepository
+	broker     services.EventBroker
+	clock      services.Clock
+	config     OutboxRelayConfig
+}
+
+// NewOutboxRelay creates an outbox relay
+func NewOutboxRelay(outboxRepo repositories.OutboxRepository, broker services.EventBroker, clock services.Clock, config OutboxRelayConfig) *OutboxRelay {
+	return &OutboxRelay{outboxRepo: outboxRepo, broker: broker, clock: clock, config: config}
+}
+
+// RelayDue publishes the events that are due and returns how many the broker
+// accepted. Events that fail are scheduled for a retry rather than returned
+// as an error.
+func (r *OutboxRelay) RelayDue(ctx context.Context) (int, error) {
+	now := r.clock.Now()
+	events, err := r.outboxRepo.Due(ctx, now, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, event := range events {
+		claimed, err := r.outboxRepo.Claim(ctx, event.ID, now, now.Add(r.config.Lease))
+		if err != nil {
+			return published, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := r.broker.Publish(ctx, &event.DomainEvent); err != nil {
+			retryAt := r.clock.Now().Add(r.retryDelay(event.Attempts + 1))
+			logger.Warnf("Failed to publish event %s (%s), retrying at %s: %v", event.ID, event.Type, retryAt.Format(time.RFC3339), err)
+			if err := r.outboxRepo.MarkFailed(ctx, event.ID, err.Error(), retryAt); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err := r.outboxRepo.MarkPublished(ctx, event.ID, r.clock.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// PurgePublished removes events published longer ago than the retention
+func (r *OutboxRelay) PurgePublished(ctx context.Context) (int, error) {
+	return r.outboxRepo.DeletePublished(ctx, r.clock.Now().Add(-r.config.Retention))
+}
+
+// retryDelay returns the delay before the given attempt, counting failures
+// from one
+func (r *OutboxRelay) retryDelay(attempt int) time.Duration {
+	return backoff(r.config.RetryBase, r.config.RetryMax, attempt)
+}
+
+// backoff returns base doubled for each attempt after the first, up to max
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
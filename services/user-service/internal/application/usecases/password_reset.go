@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var errInvalidPasswordResetToken = errors.New("invalid password reset token")
+
+// PasswordResetConfig configures password reset
+type PasswordResetConfig struct {
+	// TokenTTL is how long a reset link stays valid
+	TokenTTL time.Duration
+
+	// RequestInterval is the minimum time between two reset emails to the same
+	// user; requests in between are silently dropped
+	RequestInterval time.Duration
+
+	// ResetURL is the page the emailed link points to; the token is added as
+	// the token query parameter
+	ResetURL string
+}
+
+// PasswordResetter emails single-use password reset links and sets the new
+// password when one is presented
+type PasswordResetter struct {
+	// requests tracks the reset requests being handled in the background
+	requests sync.WaitGroup
+
+	userRepo       repositories.UserRepository
+	tokenRepo      repositories.VerificationTokenRepository
+	sessionRepo    repositories.SessionRepository
+	mailer         services.Mailer
+	passwordHasher services.PasswordHasher
+	passwordPolicy services.PasswordPolicy
+	auditor        *Auditor
+	outbox         *Outbox
+	clock          services.Clock
+	config         PasswordResetConfig
+}
+
+// NewPasswordResetter creates a new password resetter
+func NewPasswordResetter(
+	userRepo repositories.UserRepository,
+	tokenRepo repositories.VerificationTokenRepository,
+	sessionRepo repositories.SessionRepository,
+	mailer services.Mailer,
+	passwordHasher services.PasswordHasher,
+	passwordPolicy services.PasswordPolicy,
+	auditor *Auditor,
+	outbox *Outbox,
+	clock services.Clock,
+	config PasswordResetConfig,
+) *PasswordResetter {
+	return &PasswordResetter{
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		sessionRepo:    sessionRepo,
+		mailer:         mailer,
+		passwordHasher: passwordHasher,
+		passwordPolicy: passwordPolicy,
+		auditor:        auditor,
+		outbox:         outbox,
+		clock:          clock,
+		config:         config,
+	}
+}
+
+// Request emails a reset link to the user with email. The account is looked
+// up and the email sent in the background, so it returns at once and nil
+// whether or not the account exists; how long it takes never reveals that
+// either. Failures are logged.
+func (r *PasswordResetter) Request(ctx context.Context, email string) error {
+	ctx = context.WithoutCancel(ctx)
+	r.requests.Add(1)
+	go func() {
+		defer r.requests.Done()
+		if err := r.request(ctx, email); err != nil {
+			logger.Errorf("Failed to handle password reset request: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Wait blocks until the reset requests handled in the background are done
+func (r *PasswordResetter) Wait() {
+	r.requests.Wait()
+}
+
+// request sends a reset link to the user with email unless there is no such
+// user or one was sent within the request interval
+func (r *PasswordResetter) request(ctx context.Context, email string) error {
+	user, err := r.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil
+		}
+		return err
+	}
+
+	latest, err := r.tokenRepo.GetLatest(ctx, user.ID, entities.TokenPurposePasswordReset)
+	if err != nil && err.Error() != "verification token not found" {
+		return err
+	}
+	if latest != nil && r.clock.Now().Sub(latest.CreatedAt) < r.config.RequestInterval {
+		return nil
+	}
+
+	if err := r.send(ctx, user); err != nil {
+		return fmt.Errorf("failed to send password reset email to user %d: %w", user.ID, err)
+	}
+	return nil
+}
+
+// Reset sets a new password for the owner of token. Every refresh token of the
+// user is revoked and access tokens issued before the reset stop being
+// accepted. Following the emailed link proves control of the address, so a
+// user still pending verification is activated. The token is used up in the
+// transaction of these changes, so it stays usable if they fail.
+func (r *PasswordResetter) Reset(ctx context.Context, token, password string) error {
+	if err := r.passwordPolicy.Validate(password); err != nil {
+		return err
+	}
+	passwordHash, err := r.passwordHasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return r.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		reset, err := consumeToken(ctx, r.tokenRepo, token, entities.TokenPurposePasswordReset, r.clock.Now())
+		if err != nil {
+			if err == errTokenNotUsable {
+				return nil, errInvalidPasswordResetToken
+			}
+			return nil, err
+		}
+
+		if err := r.userRepo.ChangePassword(ctx, reset.UserID, passwordHash); err != nil {
+			if err.Error() == "user not found" {
+				return nil, errInvalidPasswordResetToken
+			}
+			return nil, err
+		}
+		if _, err := r.sessionRepo.RevokeAllForUser(ctx, reset.UserID); err != nil {
+			return nil, err
+		}
+
+		user, err := r.userRepo.GetByID(ctx, reset.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return activatePending(ctx, r.userRepo, r.auditor, r.outbox, user, "email verified by password reset", r.clock.Now())
+	})
+}
+
+func (r *PasswordResetter) send(ctx context.Context, user *entities.User) error {
+	token, tokenHash, err := services.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := r.tokenRepo.Create(ctx, &entities.VerificationToken{
+		UserID:    user.ID,
+		Purpose:   entities.TokenPurposePasswordReset,
+		TokenHash: tokenHash,
+		ExpiresAt: r.clock.Now().Add(r.config.TokenTTL),
+	}); err != nil {
+		return err
+	}
+
+	link, err := tokenLink(r.config.ResetURL, token)
+	if err != nil {
+		return err
+	}
+
+	return r.mailer.Send(ctx, services.EmailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nSomeone asked to reset the password of your account. To choose a new password, open the link below:\n\n%s\n\nThe link expires in %s and can be used once. If you did not ask for this, you can ignore this email; your password has not changed.\n",
+			user.Name, link, r.config.TokenTTL),
+	})
+}
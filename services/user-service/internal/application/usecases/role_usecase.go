@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// RoleUseCase defines the interface for role use cases
+type RoleUseCase interface {
+	// EnsureDefaultRoles creates the built-in roles and permissions
+	EnsureDefaultRoles(ctx context.Context) error
+
+	// BootstrapAdmin gives the admin role to the user with the given email, so
+	// that a fresh deployment has someone who can assign roles
+	BootstrapAdmin(ctx context.Context, email string) error
+
+	// ListRoles lists every role with its permissions
+	ListRoles(ctx context.Context) ([]dto.RoleResponse, error)
+
+	// GetUserRoles lists the roles assigned to a user
+	GetUserRoles(ctx context.Context, userID int) ([]dto.RoleResponse, error)
+
+	// AssignRole gives a role to a user
+	AssignRole(ctx context.Context, userID int, req *dto.AssignRoleRequest) ([]dto.RoleResponse, error)
+
+	// RevokeRole takes a role away from a user
+	RevokeRole(ctx context.Context, userID int, roleName string) ([]dto.RoleResponse, error)
+}
+
+type roleUseCase struct {
+	roleRepo repositories.RoleRepository
+	userRepo repositories.UserRepository
+	auditor  *Auditor
+	outbox   *Outbox
+}
+
+// NewRoleUseCase creates a new role use case. Role assignments are recorded
+// with auditor, in a transaction of outbox.
+func NewRoleUseCase(roleRepo repositories.RoleRepository, userRepo repositories.UserRepository, auditor *Auditor, outbox *Outbox) RoleUseCase {
+	return &roleUseCase{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		auditor:  auditor,
+		outbox:   outbox,
+	}
+}
+
+// EnsureDefaultRoles creates the built-in roles and permissions
+func (uc *roleUseCase) EnsureDefaultRoles(ctx context.Context) error {
+	return uc.roleRepo.EnsureRoles(ctx, entities.DefaultRoles, entities.PermissionDescriptions)
+}
+
+// BootstrapAdmin gives the admin role to the user with the given email
+func (uc *roleUseCase) BootstrapAdmin(ctx context.Context, email string) error {
+	user, err := uc.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to find bootstrap admin %s: %w", email, err)
+	}
+	return uc.roleRepo.AssignRole(ctx, user.ID, entities.RoleAdmin)
+}
+
+// ListRoles lists every role with its permissions
+func (uc *roleUseCase) ListRoles(ctx context.Context) ([]dto.RoleResponse, error) {
+	roles, err := uc.roleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToRoleResponses(roles), nil
+}
+
+// GetUserRoles lists the roles assigned to a user
+func (uc *roleUseCase) GetUserRoles(ctx context.Context, userID int) ([]dto.RoleResponse, error) {
+	roles, err := uc.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToRoleResponses(roles), nil
+}
+
+// AssignRole gives a role to a user and returns the user's roles
+func (uc *roleUseCase) AssignRole(ctx context.Context, userID int, req *dto.AssignRoleRequest) ([]dto.RoleResponse, error) {
+	return uc.changeRoles(ctx, userID, entities.AuditRoleAssign, func(ctx context.Context) error {
+		return uc.roleRepo.AssignRole(ctx, userID, req.Role)
+	})
+}
+
+// RevokeRole takes a role away from a user and returns the user's roles
+func (uc *roleUseCase) RevokeRole(ctx context.Context, userID int, roleName string) ([]dto.RoleResponse, error) {
+	return uc.changeRoles(ctx, userID, entities.AuditRoleRevoke, func(ctx context.Context) error {
+		return uc.roleRepo.RevokeRole(ctx, userID, roleName)
+	})
+}
+
+// changeRoles applies a change to the roles of a user and records it as
+// action in one transaction, and returns the user's roles
+func (uc *roleUseCase) changeRoles(ctx context.Context, userID int, action string, change func(ctx context.Context) error) ([]dto.RoleResponse, error) {
+	var after []*entities.Role
+	err := uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		before, err := uc.roleRepo.GetUserRoles(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := change(ctx); err != nil {
+			return nil, err
+		}
+		if after, err = uc.roleRepo.GetUserRoles(ctx, userID); err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, action, entities.AuditTargetUser, strconv.Itoa(userID), auditRoles(before), auditRoles(after))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToRoleResponses(after), nil
+}
+
+// auditRoles is the snapshot of the roles of a user the audit log compares
+func auditRoles(roles []*entities.Role) map[string]any {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name
+	}
+	return map[string]any{"roles": names}
+}
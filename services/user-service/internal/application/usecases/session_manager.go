@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/pkg/logger"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errSessionRevoked  = errors.New("session revoked")
+	errSessionNotFound = errors.New("session not found")
+)
+
+const (
+	// sessionTouchInterval is how stale the last use of a session may get
+	// before an authenticated request records it, so not every request writes
+	sessionTouchInterval = time.Minute
+
+	// maxUserAgentLength is the longest user agent stored with a session
+	maxUserAgentLength = 512
+)
+
+// SessionManager records where users are signed in and lets them, or an
+// admin, end those sign-ins
+type SessionManager struct {
+	sessionRepo repositories.SessionRepository
+	clock       services.Clock
+	ttl         time.Duration
+}
+
+// NewSessionManager creates a new session manager. A session expires ttl
+// after it was last refreshed, which should match the refresh token TTL.
+func NewSessionManager(sessionRepo repositories.SessionRepository, clock services.Clock, ttl time.Duration) *SessionManager {
+	return &SessionManager{
+		sessionRepo: sessionRepo,
+		clock:       clock,
+		ttl:         ttl,
+	}
+}
+
+// Start records a new sign-in of a user from the given client
+func (m *SessionManager) Start(ctx context.Context, userID int, userAgent, ip string) (*entities.Session, error) {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+
+	now := m.clock.Now()
+	session := &entities.Session{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		UserAgent: userAgent,
+		IP:        ip,
+		CreatedAt: now,
+		ExpiresAt: now.Add(m.ttl),
+	}
+	if err := m.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Validate checks that the session an access token was issued from is still
+// active and belongs to userID, and records its use
+func (m *SessionManager) Validate(ctx context.Context, sessionID string, userID int) error {
+	if sessionID == "" {
+		return errSessionRevoked
+	}
+	session, err := m.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if err.Error() == "session not found" {
+			return errSessionRevoked
+		}
+		return err
+	}
+
+	now := m.clock.Now()
+	if session.UserID != userID || !session.IsActive(now) {
+		return errSessionRevoked
+	}
+	if now.Sub(session.LastUsedAt) >= sessionTouchInterval {
+		if err := m.sessionRepo.Touch(ctx, session.ID, now); err != nil {
+			logger.Warnf("Failed to record use of session %s: %v", session.ID, err)
+		}
+	}
+	return nil
+}
+
+// Extend keeps a session alive for another TTL when its refresh token is
+// rotated. It fails with errSessionRevoked if the session has ended.
+func (m *SessionManager) Extend(ctx context.Context, sessionID string, userID int) error {
+	session, err := m.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if err.Error() == "session not found" {
+			return errSessionRevoked
+		}
+		return err
+	}
+
+	now := m.clock.Now()
+	if session.UserID != userID || !session.IsActive(now) {
+		return errSessionRevoked
+	}
+	return m.sessionRepo.Extend(ctx, session.ID, now, now.Add(m.ttl))
+}
+
+// List returns the active sessions of a user, most recently used first
+func (m *SessionManager) List(ctx context.Context, userID int) ([]*entities.Session, error) {
+	return m.sessionRepo.GetActiveByUser(ctx, userID, m.clock.Now())
+}
+
+// Revoke ends a session and its refresh tokens
+func (m *SessionManager) Revoke(ctx context.Context, sessionID string) error {
+	return m.sessionRepo.Revoke(ctx, sessionID)
+}
+
+// RevokeForUser ends an active session of a user. Sessions of other users are
+// reported as not found.
+func (m *SessionManager) RevokeForUser(ctx context.Context, userID int, sessionID string) error {
+	session, err := m.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if err.Error() == "session not found" {
+			return errSessionNotFound
+		}
+		return err
+	}
+	if session.UserID != userID || !session.IsActive(m.clock.Now()) {
+		return errSessionNotFound
+	}
+	return m.sessionRepo.Revoke(ctx, session.ID)
+}
+
+// RevokeAll ends every session of a user and returns how many were active
+func (m *SessionManager) RevokeAll(ctx context.Context, userID int) (int, error) {
+	return m.sessionRepo.RevokeAllForUser(ctx, userID)
+}
+
+// PurgeExpired removes sessions that have expired
+func (m *SessionManager) PurgeExpired(ctx context.Context) (int, error) {
+	return m.sessionRepo.DeleteExpired(ctx, m.clock.Now())
+}
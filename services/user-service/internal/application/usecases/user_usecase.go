@@ -5,12 +5,31 @@ import (
 	"app-microservice/services/user-service/internal/domain/entities"
 	"app-microservice/services/user-service/internal/domain/repositories"
 	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/shared/etag"
+	"app-microservice/shared/patch"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxStatusHistory is the number of status changes GetStatusHistory
+	// returns
+	maxStatusHistory = 50
+
+	// suspensionBatch is the number of expired suspensions lifted at a time
+	suspensionBatch = 100
 )
 
 // UserUseCase defines the interface for user use cases
 type UserUseCase interface {
-	// CreateUser creates a new user
+	// CreateUser creates a new user pending email verification and sends it
+	// a verification email
 	CreateUser(ctx context.Context, req *dto.CreateUserRequest) (*dto.UserResponse, error)
 
 	// GetUserByID retrieves a user by ID
@@ -19,32 +38,83 @@ type UserUseCase interface {
 	// GetUsers retrieves users with pagination and optional filters
 	GetUsers(ctx context.Context, req *dto.SearchUsersRequest) (*dto.UserListResponse, error)
 
+	// The write operations below take the request's If-Match header and fail
+	// with "user version conflict" when it does not match the current version.
+
 	// UpdateUser updates an existing user
-	UpdateUser(ctx context.Context, id int, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
+	UpdateUser(ctx context.Context, id int, ifMatch string, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
+
+	// PatchUser applies a JSON Merge Patch or JSON Patch document to a user
+	PatchUser(ctx context.Context, id int, ifMatch, contentType string, body []byte) (*dto.UserResponse, error)
 
 	// DeleteUser deletes a user by ID
-	DeleteUser(ctx context.Context, id int) error
+	DeleteUser(ctx context.Context, id int, ifMatch string) error
+
+	// RestoreUser restores a soft-deleted user
+	RestoreUser(ctx context.Context, id int) (*dto.UserResponse, error)
 
-	// UpdateUserStatus updates user status
-	UpdateUserStatus(ctx context.Context, id int, req *dto.UpdateUserStatusRequest) (*dto.UserResponse, error)
+	// PurgeDeletedUsers permanently removes users soft-deleted longer ago than retention
+	PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int, error)
+
+	// UpdateUserStatus changes user status if the user status state machine
+	// allows it, and records the change in the user's status history
+	UpdateUserStatus(ctx context.Context, id int, ifMatch string, req *dto.UpdateUserStatusRequest) (*dto.UserResponse, error)
+
+	// GetStatusHistory returns the most recent status changes of a user
+	GetStatusHistory(ctx context.Context, id int) ([]*entities.UserStatusChange, error)
+
+	// LiftExpiredSuspensions makes users whose timed suspension ended active
+	// again
+	LiftExpiredSuspensions(ctx context.Context) (int, error)
 
 	// SearchUsers searches users by query
 	SearchUsers(ctx context.Context, req *dto.SearchUsersRequest) (*dto.UserListResponse, error)
 }
 
+type selfServiceKey struct{}
+
+// WithSelfService returns a context for a user editing their own profile
+// without the users:write permission. Such a user may not change their email,
+// since the new address would be trusted without ever being verified.
+func WithSelfService(ctx context.Context) context.Context {
+	return context.WithValue(ctx, selfServiceKey{}, true)
+}
+
+// checkEmailChange rejects a change of email by a self-service caller
+func checkEmailChange(ctx context.Context, before, after string) error {
+	if selfService, _ := ctx.Value(selfServiceKey{}).(bool); selfService && !strings.EqualFold(before, after) {
+		return errors.New("email can only be changed by an administrator")
+	}
+	return nil
+}
+
 type userUseCase struct {
 	userRepo      repositories.UserRepository
 	userDomainSvc services.UserDomainService
+	emailVerifier *EmailVerifier
+	auditor       *Auditor
+	outbox        *Outbox
+	clock         services.Clock
 }
 
-// NewUserUseCase creates a new user use case
+// NewUserUseCase creates a new user use case. Changes to users are recorded
+// with auditor and announced to other services through outbox; clock times
+// status changes and the end of suspensions.
 func NewUserUseCase(
 	userRepo repositories.UserRepository,
 	userDomainSvc services.UserDomainService,
+	emailVerifier *EmailVerifier,
+	auditor *Auditor,
+	outbox *Outbox,
+	clock services.Clock,
 ) UserUseCase {
 	return &userUseCase{
 		userRepo:      userRepo,
 		userDomainSvc: userDomainSvc,
+		emailVerifier: emailVerifier,
+		auditor:       auditor,
+		outbox:        outbox,
+		clock:         clock,
 	}
 }
 
@@ -58,10 +128,20 @@ func (uc *userUseCase) CreateUser(ctx context.Context, req *dto.CreateUserReques
 		return nil, err
 	}
 
-	// Create user
-	if err := uc.userRepo.Create(ctx, user); err != nil {
+	// Create user, its event and its audit entry together
+	err := uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		if err := uc.auditor.Record(ctx, entities.AuditUserCreate, entities.AuditTargetUser, strconv.Itoa(user.ID), nil, auditUser(user)); err != nil {
+			return nil, err
+		}
+		return uc.outbox.userEvents(entities.EventUserCreated, user)
+	})
+	if err != nil {
 		return nil, err
 	}
+	uc.emailVerifier.SendOnCreate(ctx, user)
 
 	// Convert to response DTO
 	response := dto.ToUserResponse(user)
@@ -90,6 +170,9 @@ func (uc *userUseCase) GetUsers(ctx context.Context, req *dto.SearchUsersRequest
 	}
 
 	offset := (req.Page - 1) * req.PageSize
+	if req.IncludeDeleted {
+		ctx = repositories.WithDeleted(ctx)
+	}
 
 	var users []*entities.User
 	var err error
@@ -116,15 +199,20 @@ func (uc *userUseCase) GetUsers(ctx context.Context, req *dto.SearchUsersRequest
 }
 
 // UpdateUser updates an existing user
-func (uc *userUseCase) UpdateUser(ctx context.Context, id int, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
+func (uc *userUseCase) UpdateUser(ctx context.Context, id int, ifMatch string, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
 	// Get existing user
-	user, err := uc.userRepo.GetByID(ctx, id)
+	user, err := uc.getForWrite(ctx, id, ifMatch)
 	if err != nil {
 		return nil, err
 	}
 
 	// Apply updates
+	before := auditUser(user)
+	email := user.Email
 	req.ApplyToEntity(user)
+	if err := checkEmailChange(ctx, email, user.Email); err != nil {
+		return nil, err
+	}
 
 	// Validate business rules
 	if err := uc.userDomainSvc.ValidateUserUpdate(ctx, user); err != nil {
@@ -132,7 +220,56 @@ func (uc *userUseCase) UpdateUser(ctx context.Context, id int, req *dto.UpdateUs
 	}
 
 	// Update user
-	if err := uc.userRepo.Update(ctx, user); err != nil {
+	if err := uc.update(ctx, user, entities.EventUserUpdated, entities.AuditUserUpdate, before); err != nil {
+		return nil, err
+	}
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// PatchUser applies a JSON Merge Patch or JSON Patch document to a user. The
+// patch is applied to the user's current state and the merged result is
+// validated before it is saved.
+func (uc *userUseCase) PatchUser(ctx context.Context, id int, ifMatch, contentType string, body []byte) (*dto.UserResponse, error) {
+	// Get existing user
+	user, err := uc.getForWrite(ctx, id, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := json.Marshal(dto.NewUserPatchDocument(user))
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := patch.Apply(contentType, doc, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result dto.UserPatchDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: %v", patch.ErrInvalidPatch, err)
+	}
+
+	// Apply updates
+	before := auditUser(user)
+	email := user.Email
+	result.ApplyToEntity(user)
+	if err := checkEmailChange(ctx, email, user.Email); err != nil {
+		return nil, err
+	}
+
+	// Validate business rules on the merged result
+	if err := uc.userDomainSvc.ValidateUserUpdate(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// Update user
+	if err := uc.update(ctx, user, entities.EventUserUpdated, entities.AuditUserUpdate, before); err != nil {
 		return nil, err
 	}
 
@@ -141,36 +278,74 @@ func (uc *userUseCase) UpdateUser(ctx context.Context, id int, req *dto.UpdateUs
 }
 
 // DeleteUser deletes a user by ID
-func (uc *userUseCase) DeleteUser(ctx context.Context, id int) error {
+func (uc *userUseCase) DeleteUser(ctx context.Context, id int, ifMatch string) error {
 	// Validate deletion
 	if err := uc.userDomainSvc.CanDeleteUser(ctx, id); err != nil {
 		return err
 	}
 
+	user, err := uc.getForWrite(ctx, id, ifMatch)
+	if err != nil {
+		return err
+	}
+
 	// Delete user
-	return uc.userRepo.Delete(ctx, id)
+	return uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.userRepo.Delete(ctx, id, user.Version); err != nil {
+			return nil, err
+		}
+		if err := uc.auditor.Record(ctx, entities.AuditUserDelete, entities.AuditTargetUser, strconv.Itoa(id), auditUser(user), nil); err != nil {
+			return nil, err
+		}
+		return uc.outbox.userEvents(entities.EventUserDeleted, user)
+	})
+}
+
+// RestoreUser restores a soft-deleted user
+func (uc *userUseCase) RestoreUser(ctx context.Context, id int) (*dto.UserResponse, error) {
+	var user *entities.User
+	err := uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.userRepo.Restore(ctx, id); err != nil {
+			return nil, err
+		}
+		var err error
+		if user, err = uc.userRepo.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		if err := uc.auditor.Record(ctx, entities.AuditUserRestore, entities.AuditTargetUser, strconv.Itoa(id), nil, auditUser(user)); err != nil {
+			return nil, err
+		}
+		return uc.outbox.userEvents(entities.EventUserRestored, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToUserResponse(user)
+	return &response, nil
+}
+
+// PurgeDeletedUsers permanently removes users soft-deleted longer ago than retention
+func (uc *userUseCase) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int, error) {
+	return uc.userRepo.PurgeDeleted(ctx, time.Now().Add(-retention))
 }
 
 // UpdateUserStatus updates user status
-func (uc *userUseCase) UpdateUserStatus(ctx context.Context, id int, req *dto.UpdateUserStatusRequest) (*dto.UserResponse, error) {
+func (uc *userUseCase) UpdateUserStatus(ctx context.Context, id int, ifMatch string, req *dto.UpdateUserStatusRequest) (*dto.UserResponse, error) {
 	// Get existing user
-	user, err := uc.userRepo.GetByID(ctx, id)
+	user, err := uc.getForWrite(ctx, id, ifMatch)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update status based on request
-	switch req.Status {
-	case string(entities.UserStatusActive):
-		user.Activate()
-	case string(entities.UserStatusInactive):
-		user.Deactivate()
-	case string(entities.UserStatusSuspended):
-		user.Suspend()
+	before := auditUser(user)
+	if err := user.ChangeStatus(entities.UserStatus(req.Status), req.Reason, req.SuspendedUntil, uc.clock.Now()); err != nil {
+		return nil, err
 	}
+	user.StatusChange.ActorID = auditActorFrom(ctx).UserID
 
 	// Update user
-	if err := uc.userRepo.Update(ctx, user); err != nil {
+	if err := uc.update(ctx, user, entities.UserStatusEvent(entities.UserStatus(req.Status)), entities.AuditUserStatus, before); err != nil {
 		return nil, err
 	}
 
@@ -178,6 +353,85 @@ func (uc *userUseCase) UpdateUserStatus(ctx context.Context, id int, req *dto.Up
 	return &response, nil
 }
 
+// GetStatusHistory returns the most recent status changes of a user
+func (uc *userUseCase) GetStatusHistory(ctx context.Context, id int) ([]*entities.UserStatusChange, error) {
+	exists, err := uc.userRepo.Exists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("user not found")
+	}
+	return uc.userRepo.GetStatusHistory(ctx, id, maxStatusHistory)
+}
+
+// LiftExpiredSuspensions makes users whose timed suspension ended active
+// again, a batch at a time
+func (uc *userUseCase) LiftExpiredSuspensions(ctx context.Context) (int, error) {
+	now := uc.clock.Now()
+	lifted := 0
+	for {
+		users, err := uc.userRepo.GetExpiredSuspensions(ctx, now, suspensionBatch)
+		if err != nil {
+			return lifted, err
+		}
+		for _, user := range users {
+			var active *entities.User
+			err := uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+				ok, err := uc.userRepo.LiftSuspension(ctx, user.ID, now, "suspension ended")
+				if err != nil || !ok {
+					return nil, err
+				}
+				if active, err = uc.userRepo.GetByID(ctx, user.ID); err != nil {
+					return nil, err
+				}
+				if err := uc.auditor.Record(ctx, entities.AuditUserStatus, entities.AuditTargetUser, strconv.Itoa(user.ID), auditUser(user), auditUser(active)); err != nil {
+					return nil, err
+				}
+				return uc.outbox.userEvents(entities.EventUserActivated, active)
+			})
+			if err != nil {
+				return lifted, err
+			}
+			if active == nil {
+				continue
+			}
+			lifted++
+		}
+		if len(users) < suspensionBatch {
+			return lifted, nil
+		}
+	}
+}
+
+// update saves user, records the change from before as action and raises an
+// event of type eventType about it, if any, in one transaction
+func (uc *userUseCase) update(ctx context.Context, user *entities.User, eventType, action string, before map[string]any) error {
+	return uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+		if err := uc.auditor.Record(ctx, action, entities.AuditTargetUser, strconv.Itoa(user.ID), before, auditUser(user)); err != nil {
+			return nil, err
+		}
+		return uc.outbox.userEvents(eventType, user)
+	})
+}
+
+// getForWrite loads a user and checks the If-Match precondition against its
+// version. The repository re-checks the version when writing, so a concurrent
+// update between this read and the write is still detected.
+func (uc *userUseCase) getForWrite(ctx context.Context, id int, ifMatch string) (*entities.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !etag.Match(ifMatch, user.Version) {
+		return nil, errors.New("user version conflict")
+	}
+	return user, nil
+}
+
 // SearchUsers searches users by query
 func (uc *userUseCase) SearchUsers(ctx context.Context, req *dto.SearchUsersRequest) (*dto.UserListResponse, error) {
 	// Set default values
@@ -189,6 +443,9 @@ func (uc *userUseCase) SearchUsers(ctx context.Context, req *dto.SearchUsersRequ
 	}
 
 	offset := (req.Page - 1) * req.PageSize
+	if req.IncludeDeleted {
+		ctx = repositories.WithDeleted(ctx)
+	}
 
 	// Search users
 	users, err := uc.userRepo.Search(ctx, req.Query, req.PageSize, offset)
@@ -0,0 +1,220 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/pkg/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// WebhookDeliveryConfig holds the settings of webhook delivery
+type WebhookDeliveryConfig struct {
+	Workers     int           // deliveries posted at the same time
+	BatchSize   int           // deliveries picked up per run
+	Lease       time.Duration // how long other workers leave a delivery alone while it is posted
+	MaxAttempts int           // attempts before a delivery is dead-lettered
+	RetryBase   time.Duration // delay before the first retry, doubled for each further one
+	RetryMax    time.Duration // longest delay between retries
+	Retention   time.Duration // how long succeeded and dead deliveries are kept
+}
+
+// WebhookDispatcher queues events for the webhooks subscribed to them and
+// posts the queued deliveries. Deliveries are stored, so those pending when
+// the service stops are posted after it starts again; a delivery whose worker
+// stopped mid-attempt is retried once its lease expires.
+type WebhookDispatcher struct {
+	webhookRepo  repositories.WebhookSubscriptionRepository
+	deliveryRepo repositories.WebhookDeliveryRepository
+	sender       services.WebhookSender
+	secretBox    services.SecretBox
+	clock        services.Clock
+	config       WebhookDeliveryConfig
+}
+
+// NewWebhookDispatcher creates a webhook dispatcher
+func NewWebhookDispatcher(
+	webhookRepo repositories.WebhookSubscriptionRepository,
+	deliveryRepo repositories.WebhookDeliveryRepository,
+	sender services.WebhookSender,
+	secretBox services.SecretBox,
+	clock services.Clock,
+	config WebhookDeliveryConfig,
+) *WebhookDispatcher {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	return &WebhookDispatcher{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		sender:       sender,
+		secretBox:    secretBox,
+		clock:        clock,
+		config:       config,
+	}
+}
+
+// Enqueue queues event for every active webhook subscribed to its type. It
+// handles events from the event broker, which may hand it an event more than
+// once; the event is still queued only once per webhook.
+func (d *WebhookDispatcher) Enqueue(ctx context.Context, event *entities.DomainEvent) error {
+	webhooks, err := d.webhookRepo.GetActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []*entities.WebhookDelivery
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, &entities.WebhookDelivery{
+			SubscriptionID: webhook.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        string(payload),
+			NextAttemptAt:  d.clock.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	_, err = d.deliveryRepo.Enqueue(ctx, deliveries...)
+	return err
+}
+
+// DeliverDue posts the deliveries that are due, at most Workers at a time,
+// and returns how many the webhooks accepted. Failed deliveries are scheduled
+// for a retry or dead-lettered rather than returned as an error.
+func (d *WebhookDispatcher) DeliverDue(ctx context.Context) (int, error) {
+	now := d.clock.Now()
+	deliveries, err := d.deliveryRepo.Due(ctx, now, d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+		firstErr  error
+	)
+	record := func(ok bool, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ok {
+			delivered++
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	workers := make(chan struct{}, d.config.Workers)
+	webhooks := make(map[int]*entities.WebhookSubscription)
+	for _, delivery := range deliveries {
+		// Claim only once a worker is free, so the lease covers the attempt
+		// rather than the wait for a worker
+		workers <- struct{}{}
+		claimed, err := d.deliveryRepo.Claim(ctx, delivery.ID, now, d.clock.Now().Add(d.config.Lease))
+		if err != nil || !claimed {
+			<-workers
+			if err != nil {
+				record(false, err)
+				break
+			}
+			continue
+		}
+
+		webhook, ok := webhooks[delivery.SubscriptionID]
+		if !ok {
+			webhook, err = d.webhookRepo.GetByID(ctx, delivery.SubscriptionID)
+			if err != nil && err.Error() != "webhook not found" {
+				<-workers
+				record(false, err)
+				break
+			}
+			webhooks[delivery.SubscriptionID] = webhook
+		}
+
+		wg.Add(1)
+		go func(delivery *entities.WebhookDelivery, webhook *entities.WebhookSubscription) {
+			defer wg.Done()
+			defer func() { <-workers }()
+			record(d.attempt(ctx, delivery, webhook))
+		}(delivery, webhook)
+	}
+	wg.Wait()
+	return delivered, firstErr
+}
+
+// PurgeFinished removes succeeded and dead deliveries older than the
+// retention
+func (d *WebhookDispatcher) PurgeFinished(ctx context.Context) (int, error) {
+	return d.deliveryRepo.DeleteFinished(ctx, d.clock.Now().Add(-d.config.Retention))
+}
+
+// attempt posts delivery to webhook once and stores the outcome. Deliveries
+// to a webhook deactivated meanwhile are dead-lettered without being posted;
+// they can be redelivered once it is active again.
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *entities.WebhookDelivery, webhook *entities.WebhookSubscription) (bool, error) {
+	if webhook == nil {
+		// Deleting the webhook removed the delivery as well
+		return false, nil
+	}
+
+	attemptedAt := d.clock.Now()
+	var statusCode int
+	err := errors.New("webhook is inactive")
+	if webhook.Active {
+		var secret string
+		if secret, err = d.secretBox.Open(webhook.Secret); err == nil {
+			statusCode, err = d.sender.Send(ctx, services.WebhookRequest{
+				URL:        webhook.URL,
+				Secret:     secret,
+				DeliveryID: delivery.ID,
+				EventID:    delivery.EventID,
+				EventType:  delivery.EventType,
+				Payload:    []byte(delivery.Payload),
+			})
+		}
+	}
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &attemptedAt
+	delivery.LastStatusCode = nil
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+	switch {
+	case err == nil:
+		deliveredAt := d.clock.Now()
+		delivery.Status = entities.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &deliveredAt
+		delivery.LastError = ""
+	case !webhook.Active || delivery.Attempts >= d.config.MaxAttempts:
+		delivery.Status = entities.WebhookDeliveryDead
+		delivery.LastError = err.Error()
+		logger.Warnf("Webhook delivery %d of event %s to webhook %d dead-lettered after %d attempts: %v",
+			delivery.ID, delivery.EventID, webhook.ID, delivery.Attempts, err)
+	default:
+		delivery.NextAttemptAt = d.clock.Now().Add(backoff(d.config.RetryBase, d.config.RetryMax, delivery.Attempts))
+		delivery.LastError = err.Error()
+	}
+
+	if saveErr := d.deliveryRepo.SaveAttempt(ctx, delivery); saveErr != nil {
+		return false, saveErr
+	}
+	return err == nil, nil
+}
@@ -0,0 +1,285 @@
+package usecases
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"context"
+	"errors"
+	"strconv"
+)
+
+// WebhookUseCase defines the interface for managing webhooks and their
+// delivery log
+type WebhookUseCase interface {
+	// CreateWebhook creates a webhook and returns its secret once
+	CreateWebhook(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, error)
+
+	// ListWebhooks returns every webhook
+	ListWebhooks(ctx context.Context) ([]dto.WebhookResponse, error)
+
+	// GetWebhook returns a webhook
+	GetWebhook(ctx context.Context, id int) (*dto.WebhookResponse, error)
+
+	// UpdateWebhook replaces the settings of a webhook and returns a rotated
+	// secret once
+	UpdateWebhook(ctx context.Context, id int, req *dto.UpdateWebhookRequest) (*dto.WebhookResponse, error)
+
+	// DeleteWebhook removes a webhook and its delivery log
+	DeleteWebhook(ctx context.Context, id int) error
+
+	// ListDeliveries returns the delivery log of a webhook, newest first
+	ListDeliveries(ctx context.Context, id int, query *dto.WebhookDeliveryQuery) (*dto.WebhookDeliveryListResponse, error)
+
+	// Redeliver queues a delivery of a webhook to be posted again now, with
+	// a fresh set of retries
+	Redeliver(ctx context.Context, id, deliveryID int) (*entities.WebhookDelivery, error)
+}
+
+type webhookUseCase struct {
+	webhookRepo  repositories.WebhookSubscriptionRepository
+	deliveryRepo repositories.WebhookDeliveryRepository
+	secretBox    services.SecretBox
+	auditor      *Auditor
+	outbox       *Outbox
+	clock        services.Clock
+	allowPrivate bool
+}
+
+// NewWebhookUseCase creates a new webhook use case. Secrets are stored sealed
+// by secretBox, since they must be read back to sign payloads. Webhooks may
+// point to loopback, private and link-local hosts only if allowPrivate is set.
+func NewWebhookUseCase(
+	webhookRepo repositories.WebhookSubscriptionRepository,
+	deliveryRepo repositories.WebhookDeliveryRepository,
+	secretBox services.SecretBox,
+	auditor *Auditor,
+	outbox *Outbox,
+	clock services.Clock,
+	allowPrivate bool,
+) WebhookUseCase {
+	return &webhookUseCase{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		secretBox:    secretBox,
+		auditor:      auditor,
+		outbox:       outbox,
+		clock:        clock,
+		allowPrivate: allowPrivate,
+	}
+}
+
+// CreateWebhook creates a webhook. A generated secret is returned once; a
+// secret given in the request is not returned.
+func (uc *webhookUseCase) CreateWebhook(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, error) {
+	webhook := &entities.WebhookSubscription{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Active:      req.Active == nil || *req.Active,
+	}
+	if err := uc.validate(webhook); err != nil {
+		return nil, err
+	}
+
+	generated, err := uc.setSecret(webhook, req.Secret, true)
+	if err != nil {
+		return nil, err
+	}
+	err = uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.webhookRepo.Create(ctx, webhook); err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, entities.AuditWebhookCreate, entities.AuditTargetWebhook, strconv.Itoa(webhook.ID), nil, auditWebhook(webhook))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWebhookResponse(webhook)
+	response.Secret = generated
+	return &response, nil
+}
+
+// ListWebhooks returns every webhook, without secrets
+func (uc *webhookUseCase) ListWebhooks(ctx context.Context) ([]dto.WebhookResponse, error) {
+	webhooks, err := uc.webhookRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responses[i] = dto.ToWebhookResponse(webhook)
+	}
+	return responses, nil
+}
+
+// GetWebhook returns a webhook, without its secret
+func (uc *webhookUseCase) GetWebhook(ctx context.Context, id int) (*dto.WebhookResponse, error) {
+	webhook, err := uc.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWebhookResponse(webhook)
+	return &response, nil
+}
+
+// UpdateWebhook replaces the settings of a webhook. Active is kept if the
+// request leaves it out.
+func (uc *webhookUseCase) UpdateWebhook(ctx context.Context, id int, req *dto.UpdateWebhookRequest) (*dto.WebhookResponse, error) {
+	webhook, err := uc.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := auditWebhook(webhook)
+
+	webhook.URL = req.URL
+	webhook.EventTypes = req.EventTypes
+	webhook.Description = req.Description
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	if err := uc.validate(webhook); err != nil {
+		return nil, err
+	}
+
+	generated, err := uc.setSecret(webhook, req.Secret, req.RotateSecret)
+	if err != nil {
+		return nil, err
+	}
+	after := auditWebhook(webhook)
+	if req.Secret != "" || req.RotateSecret {
+		after["secret_rotated"] = true
+	}
+	err = uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.webhookRepo.Update(ctx, webhook); err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, entities.AuditWebhookUpdate, entities.AuditTargetWebhook, strconv.Itoa(id), before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToWebhookResponse(webhook)
+	response.Secret = generated
+	return &response, nil
+}
+
+// DeleteWebhook removes a webhook. Deliveries still pending are dropped.
+func (uc *webhookUseCase) DeleteWebhook(ctx context.Context, id int) error {
+	webhook, err := uc.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.webhookRepo.Delete(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, entities.AuditWebhookDelete, entities.AuditTargetWebhook, strconv.Itoa(id), auditWebhook(webhook), nil)
+	})
+}
+
+// ListDeliveries returns a page of the delivery log of a webhook
+func (uc *webhookUseCase) ListDeliveries(ctx context.Context, id int, query *dto.WebhookDeliveryQuery) (*dto.WebhookDeliveryListResponse, error) {
+	if _, err := uc.webhookRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 20
+	}
+
+	deliveries, total, err := uc.deliveryRepo.List(ctx, id, query.Status, (query.Page-1)*query.PageSize, query.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total / int64(query.PageSize))
+	if total%int64(query.PageSize) > 0 {
+		totalPages++
+	}
+	return &dto.WebhookDeliveryListResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// Redeliver queues a delivery to be posted again, whatever its status
+func (uc *webhookUseCase) Redeliver(ctx context.Context, id, deliveryID int) (*entities.WebhookDelivery, error) {
+	delivery, err := uc.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.SubscriptionID != id {
+		return nil, errors.New("webhook delivery not found")
+	}
+	err = uc.outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := uc.deliveryRepo.Redeliver(ctx, deliveryID, uc.clock.Now()); err != nil {
+			return nil, err
+		}
+		return nil, uc.auditor.Record(ctx, entities.AuditWebhookRedeliver, entities.AuditTargetWebhook, strconv.Itoa(id),
+			map[string]any{"delivery_id": deliveryID, "event_id": delivery.EventID, "status": delivery.Status},
+			map[string]any{"delivery_id": deliveryID, "event_id": delivery.EventID, "status": entities.WebhookDeliveryPending})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.deliveryRepo.GetByID(ctx, deliveryID)
+}
+
+// validate checks the settings of webhook and, unless private hosts are
+// allowed, that its host is public
+func (uc *webhookUseCase) validate(webhook *entities.WebhookSubscription) error {
+	if err := webhook.Validate(); err != nil {
+		return err
+	}
+	if uc.allowPrivate {
+		return nil
+	}
+	return webhook.CheckPublicHost()
+}
+
+// setSecret seals secret into webhook, or a generated one if secret is empty
+// and generate is set, and returns the generated secret
+func (uc *webhookUseCase) setSecret(webhook *entities.WebhookSubscription, secret string, generate bool) (string, error) {
+	var generated string
+	if secret == "" {
+		if !generate {
+			return "", nil
+		}
+		var err error
+		if generated, _, err = services.GenerateOpaqueToken(); err != nil {
+			return "", err
+		}
+		generated = "whsec_" + generated
+		secret = generated
+	}
+
+	sealed, err := uc.secretBox.Seal(secret)
+	if err != nil {
+		return "", err
+	}
+	webhook.Secret = sealed
+	return generated, nil
+}
+
+// auditWebhook is the snapshot of a webhook the audit log compares. The
+// secret is left out.
+func auditWebhook(webhook *entities.WebhookSubscription) map[string]any {
+	return map[string]any{
+		"url":         webhook.URL,
+		"event_types": webhook.EventTypes,
+		"description": webhook.Description,
+		"active":      webhook.Active,
+	}
+}
@@ -4,23 +4,44 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Logger   LoggerConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Logger     LoggerConfig
+	SoftDelete SoftDeleteConfig
+	Suspension SuspensionConfig
+	Events     EventsConfig
+	Webhook    WebhookConfig
+	Password   PasswordConfig
+	JWT        JWTConfig
+	RBAC       RBACConfig
+	Mail       MailConfig
+	Email      EmailVerificationConfig
+	Reset      PasswordResetConfig
+	MFA        MFAConfig
+	Login      LoginProtectionConfig
+	OIDC       OIDCConfig
+	Service    ServiceAuthConfig
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port         string
-	Host         string
-	ReadTimeout  int
-	WriteTimeout int
+	Port           string
+	Host           string
+	ReadTimeout    int
+	WriteTimeout   int
+	RequireIfMatch bool     // reject PUT/PATCH/DELETE without If-Match
+	TrustedProxies []string // proxies whose X-Forwarded-For is believed
+	TLSCertFile    string   // serve HTTPS with this certificate chain when set
+	TLSKeyFile     string
+	TLSMinVersion  string // 1.2 or 1.3
 }
 
 // DatabaseConfig holds database configuration
@@ -43,6 +64,136 @@ type LoggerConfig struct {
 	Format string
 }
 
+// SoftDeleteConfig holds retention configuration for soft-deleted users
+type SoftDeleteConfig struct {
+	Retention     time.Duration // how long deleted users can still be restored
+	PurgeInterval time.Duration // how often the purge job runs
+}
+
+// SuspensionConfig holds configuration for lifting timed suspensions
+type SuspensionConfig struct {
+	CheckInterval time.Duration // how often ended suspensions are lifted
+}
+
+// EventsConfig holds configuration for publishing domain events
+type EventsConfig struct {
+	Broker        string        // memory or nats
+	NATSURL       string        // nats://[user:password@]host:port
+	SubjectPrefix string        // events are published to <prefix>.<event type>
+	RelayInterval time.Duration // how often the outbox is checked for events
+	BatchSize     int           // events published per check
+	Lease         time.Duration // how long an event being published is left alone by other instances
+	RetryBase     time.Duration // delay before the first retry, doubled for each further one
+	RetryMax      time.Duration // longest delay between retries
+	Retention     time.Duration // how long published events are kept
+}
+
+// WebhookConfig holds configuration for delivering events to webhooks
+type WebhookConfig struct {
+	Workers          int           // deliveries posted at the same time
+	BatchSize        int           // deliveries picked up per check
+	DeliveryInterval time.Duration // how often due deliveries are checked for
+	Timeout          time.Duration // how long a webhook has to respond
+	Lease            time.Duration // how long a delivery being posted is left alone by other instances
+	MaxAttempts      int           // attempts before a delivery is dead-lettered
+	RetryBase        time.Duration // delay before the first retry, doubled for each further one
+	RetryMax         time.Duration // longest delay between retries
+	Retention        time.Duration // how long succeeded and dead deliveries are kept
+	AllowPrivate     bool          // lets webhooks point to loopback, private and link-local addresses, for development
+}
+
+// PasswordConfig holds the password policy and argon2id hashing parameters.
+// Changing the hashing parameters rehashes passwords on the next login.
+type PasswordConfig struct {
+	MinLength         int
+	RequireUpper      bool
+	RequireLower      bool
+	RequireDigit      bool
+	RequireSymbol     bool
+	Argon2Memory      int // KiB
+	Argon2Iterations  int
+	Argon2Parallelism int
+}
+
+// JWTConfig holds token issuing configuration. Without a signing key file an
+// ephemeral key is generated at startup.
+type JWTConfig struct {
+	Issuer               string
+	Audience             string
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+	SigningKeyFile       string   // PEM encoded RSA private key
+	VerificationKeyFiles []string // previous public keys still published during a key rotation
+}
+
+// RBACConfig holds role-based access control configuration
+type RBACConfig struct {
+	BootstrapAdminEmail string // user given the admin role at startup, if it exists
+}
+
+// MailConfig holds email delivery configuration. Driver is log, file or smtp.
+type MailConfig struct {
+	Driver       string
+	From         string
+	FileDir      string // where the file driver writes .eml files
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// EmailVerificationConfig holds email verification configuration
+type EmailVerificationConfig struct {
+	TokenTTL       time.Duration
+	ResendInterval time.Duration
+	VerifyURL      string // page the emailed link points to
+}
+
+// PasswordResetConfig holds password reset configuration
+type PasswordResetConfig struct {
+	TokenTTL        time.Duration
+	RequestInterval time.Duration
+	ResetURL        string // page the emailed link points to
+}
+
+// MFAConfig holds TOTP multi-factor authentication configuration
+type MFAConfig struct {
+	Issuer        string // shown as the account issuer in authenticator apps
+	EncryptionKey string // base64 AES-256 key for stored TOTP secrets
+	ChallengeTTL  time.Duration
+	MaxAttempts   int
+	RecoveryCodes int
+}
+
+// LoginProtectionConfig holds brute-force protection configuration
+type LoginProtectionConfig struct {
+	Store             string // memory for a single instance, database for replicas
+	Window            time.Duration
+	DelayAfter        int
+	BaseDelay         time.Duration
+	MaxDelay          time.Duration
+	MaxFailures       int
+	LockoutDuration   time.Duration
+	IPMaxFailures     int
+	IPLockoutDuration time.Duration
+}
+
+// OIDCConfig holds OpenID Connect provider configuration. Its tokens are
+// signed with the JWT keys.
+type OIDCConfig struct {
+	Issuer   string // public URL of the service, which relying parties discover it by
+	CodeTTL  time.Duration
+	TokenTTL time.Duration // lifetime of access and ID tokens
+}
+
+// ServiceAuthConfig holds the keys calls between services are authenticated
+// with. Without a key file the service accepts calls from anyone.
+type ServiceAuthConfig struct {
+	Name    string // name the peers know the service by
+	KeyFile string // PEM Ed25519 private key
+	Peers   string // services allowed to call, as name=public-key-file,...
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	// Try to load .env file (ignore if not found)
@@ -52,10 +203,15 @@ func LoadConfig() *Config {
 
 	return &Config{
 		Server: ServerConfig{
-			Port:         getEnv("PORT", "8081"),
-			Host:         getEnv("HOST", "0.0.0.0"),
-			ReadTimeout:  getEnvAsInt("READ_TIMEOUT", 30),
-			WriteTimeout: getEnvAsInt("WRITE_TIMEOUT", 30),
+			Port:           getEnv("PORT", "8081"),
+			Host:           getEnv("HOST", "0.0.0.0"),
+			ReadTimeout:    getEnvAsInt("READ_TIMEOUT", 30),
+			WriteTimeout:   getEnvAsInt("WRITE_TIMEOUT", 30),
+			RequireIfMatch: getEnvAsBool("REQUIRE_IF_MATCH", false),
+			TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES"),
+			TLSCertFile:    getEnv("TLS_CERT_FILE", ""),
+			TLSKeyFile:     getEnv("TLS_KEY_FILE", ""),
+			TLSMinVersion:  getEnv("TLS_MIN_VERSION", "1.2"),
 		},
 		Database: DatabaseConfig{
 			Driver:                getEnv("DB_DRIVER", "sqlite"),
@@ -73,6 +229,104 @@ func LoadConfig() *Config {
 			Level:  getEnv("LOG_LEVEL", "info"),
 			Format: getEnv("LOG_FORMAT", "json"),
 		},
+		SoftDelete: SoftDeleteConfig{
+			Retention:     getEnvAsDuration("SOFT_DELETE_RETENTION", 30*24*time.Hour),
+			PurgeInterval: getEnvAsDuration("SOFT_DELETE_PURGE_INTERVAL", time.Hour),
+		},
+		Suspension: SuspensionConfig{
+			CheckInterval: getEnvAsDuration("SUSPENSION_CHECK_INTERVAL", time.Minute),
+		},
+		Events: EventsConfig{
+			Broker:        getEnv("EVENT_BROKER", "memory"),
+			NATSURL:       getEnv("NATS_URL", "nats://localhost:4222"),
+			SubjectPrefix: getEnv("EVENT_SUBJECT_PREFIX", "events"),
+			RelayInterval: getEnvAsDuration("OUTBOX_RELAY_INTERVAL", time.Second),
+			BatchSize:     getEnvAsInt("OUTBOX_BATCH_SIZE", 100),
+			Lease:         getEnvAsDuration("OUTBOX_LEASE", 30*time.Second),
+			RetryBase:     getEnvAsDuration("OUTBOX_RETRY_BASE", time.Second),
+			RetryMax:      getEnvAsDuration("OUTBOX_RETRY_MAX", 5*time.Minute),
+			Retention:     getEnvAsDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+		},
+		Webhook: WebhookConfig{
+			Workers:          getEnvAsInt("WEBHOOK_WORKERS", 4),
+			BatchSize:        getEnvAsInt("WEBHOOK_BATCH_SIZE", 100),
+			DeliveryInterval: getEnvAsDuration("WEBHOOK_DELIVERY_INTERVAL", time.Second),
+			Timeout:          getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			Lease:            getEnvAsDuration("WEBHOOK_LEASE", time.Minute),
+			MaxAttempts:      getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 10),
+			RetryBase:        getEnvAsDuration("WEBHOOK_RETRY_BASE", 30*time.Second),
+			RetryMax:         getEnvAsDuration("WEBHOOK_RETRY_MAX", time.Hour),
+			Retention:        getEnvAsDuration("WEBHOOK_RETENTION", 30*24*time.Hour),
+			AllowPrivate:     getEnvAsBool("WEBHOOK_ALLOW_PRIVATE", false),
+		},
+		Password: PasswordConfig{
+			MinLength:         getEnvAsInt("PASSWORD_MIN_LENGTH", 10),
+			RequireUpper:      getEnvAsBool("PASSWORD_REQUIRE_UPPER", true),
+			RequireLower:      getEnvAsBool("PASSWORD_REQUIRE_LOWER", true),
+			RequireDigit:      getEnvAsBool("PASSWORD_REQUIRE_DIGIT", true),
+			RequireSymbol:     getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+			Argon2Memory:      getEnvAsInt("ARGON2_MEMORY_KIB", 64*1024),
+			Argon2Iterations:  getEnvAsInt("ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getEnvAsInt("ARGON2_PARALLELISM", 2),
+		},
+		JWT: JWTConfig{
+			Issuer:               getEnv("JWT_ISSUER", "user-service"),
+			Audience:             getEnv("JWT_AUDIENCE", "app-microservice"),
+			AccessTokenTTL:       getEnvAsDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL:      getEnvAsDuration("JWT_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			SigningKeyFile:       getEnv("JWT_SIGNING_KEY_FILE", ""),
+			VerificationKeyFiles: getEnvAsSlice("JWT_VERIFICATION_KEY_FILES"),
+		},
+		RBAC: RBACConfig{
+			BootstrapAdminEmail: getEnv("RBAC_BOOTSTRAP_ADMIN_EMAIL", ""),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "User Service <no-reply@localhost>"),
+			FileDir:      getEnv("MAIL_FILE_DIR", "tmp/mail"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		},
+		Email: EmailVerificationConfig{
+			TokenTTL:       getEnvAsDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			ResendInterval: getEnvAsDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute),
+			VerifyURL:      getEnv("EMAIL_VERIFICATION_URL", "http://localhost:8080/verify-email"),
+		},
+		Reset: PasswordResetConfig{
+			TokenTTL:        getEnvAsDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+			RequestInterval: getEnvAsDuration("PASSWORD_RESET_REQUEST_INTERVAL", time.Minute),
+			ResetURL:        getEnv("PASSWORD_RESET_URL", "http://localhost:8080/reset-password"),
+		},
+		MFA: MFAConfig{
+			Issuer:        getEnv("MFA_ISSUER", "app-microservice"),
+			EncryptionKey: getEnv("MFA_ENCRYPTION_KEY", ""),
+			ChallengeTTL:  getEnvAsDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+			MaxAttempts:   getEnvAsInt("MFA_MAX_ATTEMPTS", 5),
+			RecoveryCodes: getEnvAsInt("MFA_RECOVERY_CODES", 10),
+		},
+		Login: LoginProtectionConfig{
+			Store:             getEnv("LOGIN_ATTEMPT_STORE", "database"),
+			Window:            getEnvAsDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			DelayAfter:        getEnvAsInt("LOGIN_DELAY_AFTER", 3),
+			BaseDelay:         getEnvAsDuration("LOGIN_BASE_DELAY", time.Second),
+			MaxDelay:          getEnvAsDuration("LOGIN_MAX_DELAY", 30*time.Second),
+			MaxFailures:       getEnvAsInt("LOGIN_MAX_FAILURES", 10),
+			LockoutDuration:   getEnvAsDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			IPMaxFailures:     getEnvAsInt("LOGIN_IP_MAX_FAILURES", 100),
+			IPLockoutDuration: getEnvAsDuration("LOGIN_IP_LOCKOUT_DURATION", 15*time.Minute),
+		},
+		OIDC: OIDCConfig{
+			Issuer:   getEnv("OIDC_ISSUER", "http://localhost:8081"),
+			CodeTTL:  getEnvAsDuration("OIDC_CODE_TTL", time.Minute),
+			TokenTTL: getEnvAsDuration("OIDC_TOKEN_TTL", 15*time.Minute),
+		},
+		Service: ServiceAuthConfig{
+			Name:    getEnv("SERVICE_NAME", "user-service"),
+			KeyFile: getEnv("SERVICE_AUTH_KEY_FILE", ""),
+			Peers:   getEnv("SERVICE_AUTH_PEERS", ""),
+		},
 	}
 }
 
@@ -93,6 +347,25 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsSlice(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
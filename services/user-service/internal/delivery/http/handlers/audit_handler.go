@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler handles the audit log endpoints
+type AuditHandler struct {
+	auditUseCase usecases.AuditUseCase
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditUseCase usecases.AuditUseCase) *AuditHandler {
+	return &AuditHandler{
+		auditUseCase: auditUseCase,
+	}
+}
+
+// ListEntries godoc
+// @Summary Search the audit log
+// @Description List the recorded administration actions, newest first, optionally filtered
+// @Tags audit
+// @Produce json
+// @Param actor_id query int false "User that performed the action"
+// @Param action query string false "Action, such as user.update or role.assign"
+// @Param target_type query string false "Kind of object acted on" Enums(user, oauth_client, webhook)
+// @Param target_id query string false "ID of the object acted on"
+// @Param request_id query string false "ID of the request that performed the action"
+// @Param from query string false "Earliest time, RFC 3339, inclusive"
+// @Param to query string false "Latest time, RFC 3339, exclusive"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.AuditListResponse
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) ListEntries(c *gin.Context) {
+	var query dto.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	entries, err := h.auditUseCase.ListEntries(c.Request.Context(), &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to get audit entries",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Audit entries retrieved successfully",
+		Data:    entries,
+	})
+}
+
+// Verify godoc
+// @Summary Verify the audit log
+// @Description Check the hash chain of the audit log and report the first entry that was changed or follows a removed one
+// @Tags audit
+// @Produce json
+// @Success 200 {object} dto.AuditVerifyResponse
+// @Security BearerAuth
+// @Router /api/v1/audit/verify [get]
+func (h *AuditHandler) Verify(c *gin.Context) {
+	result, err := h.auditUseCase.Verify(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to verify audit log",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	message := "Audit log is intact"
+	if !result.Valid {
+		message = "Audit log has been tampered with"
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: message,
+		Data:    result,
+	})
+}
@@ -0,0 +1,693 @@
+package handlers
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/delivery/http/middleware"
+	"app-microservice/services/user-service/internal/domain/services"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler handles HTTP requests for authentication
+type AuthHandler struct {
+	authUseCase usecases.AuthUseCase
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(authUseCase usecases.AuthUseCase) *AuthHandler {
+	return &AuthHandler{
+		authUseCase: authUseCase,
+	}
+}
+
+// Register godoc
+// @Summary Register a new user
+// @Description Create a user that logs in with an email and password. The user is pending until it follows the link in the verification email.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param user body dto.RegisterRequest true "Registration data"
+// @Success 201 {object} dto.UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req dto.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	response, err := h.authUseCase.Register(c.Request.Context(), &req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case err.Error() == "email already exists":
+			statusCode = http.StatusConflict
+		case errors.Is(err, services.ErrWeakPassword), isValidationError(err):
+			statusCode = http.StatusBadRequest
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to register user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Status:  "success",
+		Message: "User registered successfully, check your email to verify it",
+		Data:    response,
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Verify email
+// @Description Activate a user with the token from its verification email. Each token can be used once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body dto.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	user, err := h.authUseCase.VerifyEmail(c.Request.Context(), &req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "invalid verification token" {
+			statusCode = http.StatusBadRequest
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to verify email",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Email verified successfully",
+		Data:    user,
+	})
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Send a new verification email if the address belongs to a user pending verification. The response does not say whether it does.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param email body dto.ResendVerificationRequest true "Email address"
+// @Success 202 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req dto.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := h.authUseCase.ResendVerification(c.Request.Context(), &req); err != nil {
+		statusCode := http.StatusInternalServerError
+		var throttled *usecases.ThrottledError
+		if errors.As(err, &throttled) {
+			statusCode = http.StatusTooManyRequests
+			setRetryAfter(c, throttled.RetryAfter)
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to resend verification email",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Status:  "success",
+		Message: "If the email belongs to an unverified account, a verification email has been sent",
+		Data:    nil,
+	})
+}
+
+// ForgotPassword godoc
+// @Summary Forgot password
+// @Description Email a single-use password reset link. The response is the same whether or not the email belongs to an account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param email body dto.ForgotPasswordRequest true "Email address"
+// @Success 202 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := h.authUseCase.ForgotPassword(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to request password reset",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Status:  "success",
+		Message: "If the email belongs to an account, a password reset email has been sent",
+		Data:    nil,
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password with the token from a password reset email. Every session of the user is logged out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param reset body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := h.authUseCase.ResetPassword(c.Request.Context(), &req); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "invalid password reset token" || errors.Is(err, services.ErrWeakPassword) {
+			statusCode = http.StatusBadRequest
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to reset password",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Password reset successfully",
+		Data:    nil,
+	})
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Verify an email and password and issue an access token and a refresh token. If the user has MFA enabled, the response instead has mfa_required set and a challenge token to complete the login at /auth/mfa/verify.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body dto.LoginRequest true "Login credentials"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req dto.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.authUseCase.Login(c.Request.Context(), &req)
+	if err != nil {
+		statusCode := loginErrorStatus(c, err)
+		switch err.Error() {
+		case "invalid email or password":
+			statusCode = http.StatusUnauthorized
+		case "user is not active", "email is not verified":
+			statusCode = http.StatusForbidden
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Login failed",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	message := "Login successful"
+	if response.MFARequired {
+		message = "MFA code required"
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: message,
+		Data:    response,
+	})
+}
+
+// VerifyMFA godoc
+// @Summary Complete MFA login
+// @Description Present the challenge token from login with a TOTP code or a recovery code and issue tokens. A challenge is retired after too many wrong codes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param mfa body dto.VerifyMFARequest true "Challenge token and code"
+// @Success 200 {object} dto.AuthResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req dto.VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	response, err := h.authUseCase.VerifyMFA(c.Request.Context(), &req)
+	if err != nil {
+		statusCode := loginErrorStatus(c, err)
+		switch err.Error() {
+		case "invalid mfa challenge", "invalid mfa code":
+			statusCode = http.StatusUnauthorized
+		case "user is not active":
+			statusCode = http.StatusForbidden
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "MFA verification failed",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Login successful",
+		Data:    response,
+	})
+}
+
+// EnrollMFA godoc
+// @Summary Start MFA enrolment
+// @Description Generate a TOTP secret for the caller. Show the provisioning URI as a QR code and confirm with the first code; enrolling again before confirming replaces the secret.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.MFAEnrollResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/mfa/enroll [post]
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	response, err := h.authUseCase.EnrollMFA(c.Request.Context(), principal.UserID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "mfa already enabled" {
+			statusCode = http.StatusConflict
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to enrol in MFA",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Scan the provisioning URI and confirm with a code",
+		Data:    response,
+	})
+}
+
+// ConfirmMFA godoc
+// @Summary Confirm MFA enrolment
+// @Description Enable MFA with the first code of the enrolled secret. The recovery codes are shown only in this response.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body dto.ConfirmMFARequest true "TOTP code"
+// @Success 200 {object} dto.MFARecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /auth/mfa/confirm [post]
+func (h *AuthHandler) ConfirmMFA(c *gin.Context) {
+	var req dto.ConfirmMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	response, err := h.authUseCase.ConfirmMFA(c.Request.Context(), principal.UserID, &req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "invalid mfa code", "mfa enrollment not started":
+			statusCode = http.StatusBadRequest
+		case "mfa already enabled":
+			statusCode = http.StatusConflict
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to confirm MFA",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "MFA enabled, store the recovery codes safely",
+		Data:    response,
+	})
+}
+
+// ResetMFA godoc
+// @Summary Reset MFA
+// @Description Turn MFA off for a user that lost its authenticator and recovery codes, deleting its secret and recovery codes
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/mfa [delete]
+func (h *AuthHandler) ResetMFA(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid user ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := h.authUseCase.ResetMFA(c.Request.Context(), id); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "user not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to reset MFA",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "MFA reset successfully",
+		Data:    nil,
+	})
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can be used once; reusing one revokes every token issued from the same login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} dto.AuthResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	response, err := h.authUseCase.Refresh(c.Request.Context(), &req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "invalid refresh token", "refresh token reuse detected":
+			statusCode = http.StatusUnauthorized
+		case "user is not active":
+			statusCode = http.StatusForbidden
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to refresh token",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Token refreshed successfully",
+		Data:    response,
+	})
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description End the session of the refresh token, revoking every token issued from the same login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := h.authUseCase.Logout(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to log out",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Logged out successfully",
+		Data:    nil,
+	})
+}
+
+// UnlockUser godoc
+// @Summary Unlock a user
+// @Description Lift the lockout of a user after too many failed logins before it runs out, and forget its failed attempts
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/unlock [post]
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid user ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	if err := h.authUseCase.UnlockUser(c.Request.Context(), id, principal.UserID); err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "user not found":
+			statusCode = http.StatusNotFound
+		case "user is not locked":
+			statusCode = http.StatusConflict
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to unlock user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "User unlocked successfully",
+		Data:    nil,
+	})
+}
+
+// GetLockoutEvents godoc
+// @Summary Get lockout events
+// @Description List the most recent lockouts and unlocks of a user, newest first
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {array} entities.LockoutEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/lockouts [get]
+func (h *AuthHandler) GetLockoutEvents(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid user ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	events, err := h.authUseCase.GetLockoutEvents(c.Request.Context(), id)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "user not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to get lockout events",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Lockout events retrieved successfully",
+		Data:    events,
+	})
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys that access tokens are signed with
+// @Tags auth
+// @Produce json
+// @Success 200 {object} services.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authUseCase.JWKS())
+}
+
+// loginErrorStatus maps the throttling and lockout errors of a login attempt
+// to 429 and 423 with a Retry-After header, and anything else to 500
+func loginErrorStatus(c *gin.Context, err error) int {
+	var throttled *usecases.LoginThrottledError
+	var locked *usecases.AccountLockedError
+	switch {
+	case errors.As(err, &throttled):
+		setRetryAfter(c, throttled.RetryAfter)
+		return http.StatusTooManyRequests
+	case errors.As(err, &locked):
+		setRetryAfter(c, locked.RetryAfter)
+		return http.StatusLocked
+	}
+	return http.StatusInternalServerError
+}
+
+// setRetryAfter sets the Retry-After header in whole seconds, rounded up
+func setRetryAfter(c *gin.Context, d time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+}
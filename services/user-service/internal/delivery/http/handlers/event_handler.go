@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/shared/svcauth"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventHandler handles domain events sent by other services
+type EventHandler struct {
+	eventUseCase usecases.EventUseCase
+}
+
+// NewEventHandler creates a new event handler
+func NewEventHandler(eventUseCase usecases.EventUseCase) *EventHandler {
+	return &EventHandler{
+		eventUseCase: eventUseCase,
+	}
+}
+
+// ReceiveEvent godoc
+// @Summary Receive a domain event
+// @Description Accept an event raised by another service, such as product.updated, for delivery to webhooks and the event broker. Only services authenticated with a service token may call it; an event sent twice is stored once.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param event body dto.ReceiveEventRequest true "Event"
+// @Success 202 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /internal/events [post]
+func (h *EventHandler) ReceiveEvent(c *gin.Context) {
+	var req dto.ReceiveEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := h.eventUseCase.Receive(c.Request.Context(), svcauth.Caller(c), &req); err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case err.Error() == "events must come from a known service":
+			statusCode = http.StatusForbidden
+		case strings.HasPrefix(err.Error(), "unknown event type "):
+			statusCode = http.StatusBadRequest
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to receive event",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Status:  "success",
+		Message: "Event received",
+		Data:    nil,
+	})
+}
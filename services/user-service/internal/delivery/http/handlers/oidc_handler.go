@@ -0,0 +1,477 @@
+package handlers
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/delivery/http/middleware"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCHandler handles the OpenID Connect provider endpoints and the
+// registration of its clients
+type OIDCHandler struct {
+	oidcUseCase usecases.OIDCUseCase
+}
+
+// NewOIDCHandler creates a new OpenID Connect handler
+func NewOIDCHandler(oidcUseCase usecases.OIDCUseCase) *OIDCHandler {
+	return &OIDCHandler{
+		oidcUseCase: oidcUseCase,
+	}
+}
+
+// Discovery godoc
+// @Summary OpenID Connect discovery
+// @Description OpenID Provider Configuration document that relying parties configure themselves from
+// @Tags oidc
+// @Produce json
+// @Success 200 {object} dto.OIDCDiscovery
+// @Router /.well-known/openid-configuration [get]
+func (h *OIDCHandler) Discovery(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.oidcUseCase.Discovery())
+}
+
+// AuthorizePage godoc
+// @Summary Authorization endpoint
+// @Description Start the authorization code flow. Shows a sign-in page; once the user signs in, the browser is sent back to the redirect URI with a code. PKCE with S256 is required.
+// @Tags oidc
+// @Produce html
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param scope query string true "Space separated scopes, e.g. openid profile email"
+// @Param state query string false "Opaque value returned to the client"
+// @Param nonce query string false "Value copied into the ID token"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Success 200 {string} string "Sign-in page"
+// @Failure 302 {string} string "Error sent to the redirect URI"
+// @Failure 400 {string} string "Unknown client or redirect URI"
+// @Router /oauth2/authorize [get]
+func (h *OIDCHandler) AuthorizePage(c *gin.Context) {
+	var req dto.AuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		renderSignInPage(c, http.StatusBadRequest, signInPage{Error: err.Error()})
+		return
+	}
+
+	client, err := h.oidcUseCase.ValidateAuthorize(c.Request.Context(), &req)
+	if err != nil {
+		var oauthErr *usecases.OAuthError
+		switch {
+		case errors.As(err, &oauthErr) && oauthErr.RedirectURI != "":
+			c.Redirect(http.StatusFound, oauthErr.RedirectTo())
+		case errors.As(err, &oauthErr):
+			renderSignInPage(c, http.StatusBadRequest, signInPage{Error: oauthErr.Description})
+		default:
+			renderSignInPage(c, http.StatusInternalServerError, signInPage{Error: "Something went wrong, please try again later"})
+		}
+		return
+	}
+
+	renderSignInPage(c, http.StatusOK, signInPage{ClientName: client.Name})
+}
+
+// Authorize godoc
+// @Summary Issue an authorization code
+// @Description Called by the sign-in page with the query of the authorization request and the access token of the signed-in user. Returns where to send the browser: back to the client with a code, or with an error.
+// @Tags oidc
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.AuthorizeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /oauth2/authorize [post]
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	var req dto.AuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	response, err := h.oidcUseCase.Authorize(c.Request.Context(), &req, principal)
+	if err != nil {
+		var oauthErr *usecases.OAuthError
+		if errors.As(err, &oauthErr) && oauthErr.RedirectURI != "" {
+			response = &dto.AuthorizeResponse{RedirectTo: oauthErr.RedirectTo()}
+		} else {
+			statusCode := http.StatusInternalServerError
+			if oauthErr != nil {
+				statusCode = http.StatusBadRequest
+			}
+
+			c.JSON(statusCode, ErrorResponse{
+				Status:  "error",
+				Message: "Authorization failed",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Authorization complete",
+		Data:    response,
+	})
+}
+
+// Token godoc
+// @Summary Token endpoint
+// @Description Exchange an authorization code and its PKCE verifier, or the credentials of a confidential client, for tokens. Clients authenticate with HTTP Basic or client_id and client_secret in the form; public clients send only client_id. Errors follow RFC 6749.
+// @Tags oidc
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code or client_credentials"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI of the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param scope formData string false "Scopes for client credentials"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200 {object} dto.TokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /oauth2/token [post]
+func (h *OIDCHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req dto.TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		oauthError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	// Credentials in the Authorization header are form-encoded (RFC 6749
+	// section 2.3.1)
+	usedBasic := false
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		if req.ClientSecret != "" {
+			oauthError(c, http.StatusBadRequest, "invalid_request", "client authenticated more than once")
+			return
+		}
+		idValue, idErr := url.QueryUnescape(id)
+		secretValue, secretErr := url.QueryUnescape(secret)
+		if idErr != nil || secretErr != nil || (req.ClientID != "" && req.ClientID != idValue) {
+			oauthError(c, http.StatusBadRequest, "invalid_request", "malformed client credentials")
+			return
+		}
+		req.ClientID, req.ClientSecret, usedBasic = idValue, secretValue, true
+	}
+
+	response, err := h.oidcUseCase.Token(c.Request.Context(), &req)
+	if err != nil {
+		var oauthErr *usecases.OAuthError
+		if !errors.As(err, &oauthErr) {
+			oauthError(c, http.StatusInternalServerError, "server_error", "the token could not be issued")
+			return
+		}
+
+		statusCode := http.StatusBadRequest
+		if oauthErr.Code == "invalid_client" {
+			statusCode = http.StatusUnauthorized
+			if usedBasic {
+				c.Header("WWW-Authenticate", `Basic realm="user-service"`)
+			}
+		}
+		oauthError(c, statusCode, oauthErr.Code, oauthErr.Description)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UserInfo godoc
+// @Summary UserInfo endpoint
+// @Description Claims about the user of an OAuth access token with the openid scope. name needs the profile scope; email and email_verified need the email scope.
+// @Tags oidc
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.UserInfoResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /oauth2/userinfo [get]
+func (h *OIDCHandler) UserInfo(c *gin.Context) {
+	scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		c.Header("WWW-Authenticate", `Bearer`)
+		oauthError(c, http.StatusUnauthorized, "invalid_request", "Authorization header with a Bearer access token is required")
+		return
+	}
+
+	response, err := h.oidcUseCase.UserInfo(c.Request.Context(), strings.TrimSpace(token))
+	if err != nil {
+		switch err.Error() {
+		case "invalid access token":
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			oauthError(c, http.StatusUnauthorized, "invalid_token", err.Error())
+		case "insufficient scope":
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+			oauthError(c, http.StatusForbidden, "insufficient_scope", "the access token needs the openid scope")
+		default:
+			oauthError(c, http.StatusInternalServerError, "server_error", "the claims could not be read")
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateClient godoc
+// @Summary Register an OAuth client
+// @Description Register an application that signs users in with OpenID Connect, or calls services with client credentials. The client secret is only returned here; public clients get none and must use PKCE.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param client body dto.CreateOAuthClientRequest true "Client data"
+// @Success 201 {object} dto.OAuthClientResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /oauth/clients [post]
+func (h *OIDCHandler) CreateClient(c *gin.Context) {
+	var req dto.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	client, err := h.oidcUseCase.RegisterClient(c.Request.Context(), &req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		var oauthErr *usecases.OAuthError
+		if errors.As(err, &oauthErr) {
+			statusCode = http.StatusBadRequest
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to register client",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Status:  "success",
+		Message: "Client registered successfully",
+		Data:    client,
+	})
+}
+
+// ListClients godoc
+// @Summary List OAuth clients
+// @Description List the registered clients, without their secrets
+// @Tags oauth-clients
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.OAuthClientResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /oauth/clients [get]
+func (h *OIDCHandler) ListClients(c *gin.Context) {
+	clients, err := h.oidcUseCase.ListClients(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to get clients",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Clients retrieved successfully",
+		Data:    clients,
+	})
+}
+
+// DeleteClient godoc
+// @Summary Delete an OAuth client
+// @Description Remove a client. It can no longer sign users in or get tokens; tokens already issued stay valid until they expire.
+// @Tags oauth-clients
+// @Produce json
+// @Security BearerAuth
+// @Param client_id path string true "Client ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /oauth/clients/{client_id} [delete]
+func (h *OIDCHandler) DeleteClient(c *gin.Context) {
+	if err := h.oidcUseCase.DeleteClient(c.Request.Context(), c.Param("client_id")); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "oauth client not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to delete client",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Client deleted successfully",
+		Data:    nil,
+	})
+}
+
+// oauthError responds with an error in the format of RFC 6749 section 5.2,
+// which OAuth client libraries expect
+func oauthError(c *gin.Context, statusCode int, code, description string) {
+	c.JSON(statusCode, gin.H{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// signInPage is the data of the sign-in page template
+type signInPage struct {
+	ClientName string
+	Error      string
+	Nonce      string
+}
+
+// renderSignInPage renders the sign-in page. Its script may only run with a
+// per-response nonce.
+func renderSignInPage(c *gin.Context, statusCode int, page signInPage) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		c.String(http.StatusInternalServerError, "Something went wrong, please try again later")
+		return
+	}
+	page.Nonce = base64.StdEncoding.EncodeToString(nonce)
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Security-Policy", "default-src 'none'; connect-src 'self'; style-src 'unsafe-inline'; script-src 'nonce-"+page.Nonce+"'; form-action 'self'; frame-ancestors 'none'")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(statusCode)
+	if err := signInTemplate.Execute(c.Writer, page); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// signInTemplate signs the user in with the login API and, for MFA users,
+// the MFA API, then asks for a code with the access token. The token is kept
+// for the browser tab, so further clients sign in without asking again.
+var signInTemplate = template.Must(template.New("sign-in").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Sign in</title>
+<style>
+body { font-family: sans-serif; background: #f4f5f7; display: flex; justify-content: center; padding-top: 10vh; }
+main { background: #fff; padding: 2rem; border-radius: 8px; width: 20rem; box-shadow: 0 1px 4px rgba(0,0,0,.1); }
+label { display: block; margin-top: 1rem; }
+input { width: 100%; box-sizing: border-box; padding: .5rem; }
+button { margin-top: 1.5rem; width: 100%; padding: .6rem; }
+.error { color: #b00020; }
+[hidden] { display: none; }
+</style>
+</head>
+<body>
+<main>
+{{if .Error}}
+<h1>Sign-in request rejected</h1>
+<p class="error">{{.Error}}</p>
+{{else}}
+<h1>Sign in</h1>
+<p>to continue to <strong>{{.ClientName}}</strong></p>
+<form id="login" hidden>
+<label>Email <input name="email" type="email" autocomplete="username" required></label>
+<label>Password <input name="password" type="password" autocomplete="current-password" required></label>
+<button type="submit">Sign in</button>
+</form>
+<form id="mfa" hidden>
+<label>Authentication code <input name="code" autocomplete="one-time-code" required></label>
+<button type="submit">Verify</button>
+</form>
+<p id="message" class="error"></p>
+<script nonce="{{.Nonce}}">
+(function () {
+  var tokenKey = "oidc_access_token";
+  var login = document.getElementById("login");
+  var mfa = document.getElementById("mfa");
+  var message = document.getElementById("message");
+  var challenge = "";
+
+  function post(url, body, token) {
+    var headers = { "Content-Type": "application/json" };
+    if (token) { headers.Authorization = "Bearer " + token; }
+    return fetch(url, { method: "POST", headers: headers, body: JSON.stringify(body || {}) })
+      .then(function (res) { return res.json().then(function (data) { return { ok: res.ok, status: res.status, data: data }; }); });
+  }
+
+  function showLogin() { mfa.hidden = true; login.hidden = false; }
+
+  function authorize(token) {
+    return post(location.pathname + location.search, null, token).then(function (res) {
+      if (res.status === 401) {
+        sessionStorage.removeItem(tokenKey);
+        showLogin();
+        return;
+      }
+      if (!res.ok) { message.textContent = res.data.error || "Authorization failed"; return; }
+      location.assign(res.data.data.redirect_to);
+    });
+  }
+
+  function signedIn(res) {
+    if (!res.ok) { message.textContent = res.data.error || "Sign-in failed"; return; }
+    if (res.data.data.mfa_required) {
+      challenge = res.data.data.challenge_token;
+      login.hidden = true;
+      mfa.hidden = false;
+      return;
+    }
+    sessionStorage.setItem(tokenKey, res.data.data.access_token);
+    return authorize(res.data.data.access_token);
+  }
+
+  login.addEventListener("submit", function (e) {
+    e.preventDefault();
+    message.textContent = "";
+    post("/api/v1/auth/login", { email: login.email.value, password: login.password.value }).then(signedIn);
+  });
+  mfa.addEventListener("submit", function (e) {
+    e.preventDefault();
+    message.textContent = "";
+    post("/api/v1/auth/mfa/verify", { challenge_token: challenge, code: mfa.code.value }).then(signedIn);
+  });
+
+  var token = sessionStorage.getItem(tokenKey);
+  if (token) { authorize(token); } else { showLogin(); }
+})();
+</script>
+{{end}}
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler handles HTTP requests for roles
+type RoleHandler struct {
+	roleUseCase usecases.RoleUseCase
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleUseCase usecases.RoleUseCase) *RoleHandler {
+	return &RoleHandler{
+		roleUseCase: roleUseCase,
+	}
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List every role with the permissions it grants
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.RoleResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleUseCase.ListRoles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to get roles",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Roles retrieved successfully",
+		Data:    roles,
+	})
+}
+
+// GetUserRoles godoc
+// @Summary Get user roles
+// @Description List the roles assigned to a user
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {array} dto.RoleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/roles [get]
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid user ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	roles, err := h.roleUseCase.GetUserRoles(c.Request.Context(), id)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "user not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to get user roles",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "User roles retrieved successfully",
+		Data:    roles,
+	})
+}
+
+// AssignRole godoc
+// @Summary Assign a role
+// @Description Give a role to a user. Assigning a role the user already has is not an error.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param role body dto.AssignRoleRequest true "Role to assign"
+// @Success 200 {array} dto.RoleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/roles [post]
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid user ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	roles, err := h.roleUseCase.AssignRole(c.Request.Context(), id, &req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "user not found", "role not found":
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to assign role",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Role assigned successfully",
+		Data:    roles,
+	})
+}
+
+// RevokeRole godoc
+// @Summary Revoke a role
+// @Description Take a role away from a user
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param role path string true "Role name"
+// @Success 200 {array} dto.RoleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/roles/{role} [delete]
+func (h *RoleHandler) RevokeRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid user ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	roles, err := h.roleUseCase.RevokeRole(c.Request.Context(), id, c.Param("role"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "user not found", "role not found":
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to revoke role",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Role revoked successfully",
+		Data:    roles,
+	})
+}
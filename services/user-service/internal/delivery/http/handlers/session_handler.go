@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/delivery/http/middleware"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler handles HTTP requests for the sessions of users
+type SessionHandler struct {
+	authUseCase usecases.AuthUseCase
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(authUseCase usecases.AuthUseCase) *SessionHandler {
+	return &SessionHandler{
+		authUseCase: authUseCase,
+	}
+}
+
+// ListMySessions godoc
+// @Summary List my sessions
+// @Description List where the caller is signed in, most recently used first. The session of the request is marked current.
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.SessionResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /me/sessions [get]
+func (h *SessionHandler) ListMySessions(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+	h.listSessions(c, principal.UserID, principal.SessionID)
+}
+
+// RevokeMySession godoc
+// @Summary Revoke one of my sessions
+// @Description Sign the caller out of one session. Its tokens stop working immediately.
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+	h.revokeSession(c, principal.UserID, c.Param("id"))
+}
+
+// RevokeMySessions godoc
+// @Summary Log out everywhere
+// @Description Sign the caller out of every session, including the one of the request
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.RevokeSessionsResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /me/sessions [delete]
+func (h *SessionHandler) RevokeMySessions(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+	h.revokeAllSessions(c, principal.UserID)
+}
+
+// ListUserSessions godoc
+// @Summary List user sessions
+// @Description List where a user is signed in, most recently used first
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {array} dto.SessionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/sessions [get]
+func (h *SessionHandler) ListUserSessions(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	principal, _ := middleware.GetPrincipal(c)
+	h.listSessions(c, id, principal.SessionID)
+}
+
+// RevokeUserSession godoc
+// @Summary Revoke a user session
+// @Description Sign a user out of one session
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param session_id path string true "Session ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/sessions/{session_id} [delete]
+func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	h.revokeSession(c, id, c.Param("session_id"))
+}
+
+// RevokeUserSessions godoc
+// @Summary Log a user out everywhere
+// @Description Sign a user out of every session
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.RevokeSessionsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/sessions [delete]
+func (h *SessionHandler) RevokeUserSessions(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	h.revokeAllSessions(c, id)
+}
+
+func (h *SessionHandler) listSessions(c *gin.Context, userID int, currentSessionID string) {
+	sessions, err := h.authUseCase.ListSessions(c.Request.Context(), userID, currentSessionID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "user not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to get sessions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Sessions retrieved successfully",
+		Data:    sessions,
+	})
+}
+
+func (h *SessionHandler) revokeSession(c *gin.Context, userID int, sessionID string) {
+	if err := h.authUseCase.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "user not found", "session not found":
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to revoke session",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Session revoked successfully",
+		Data:    nil,
+	})
+}
+
+func (h *SessionHandler) revokeAllSessions(c *gin.Context, userID int) {
+	response, err := h.authUseCase.RevokeAllSessions(c.Request.Context(), userID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "user not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to revoke sessions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Sessions revoked successfully",
+		Data:    response,
+	})
+}
+
+// userIDParam parses the :id path parameter, responding with 400 if it is
+// not a number
+func userIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid user ID",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -3,6 +3,13 @@ package handlers
 import (
 	"app-microservice/services/user-service/internal/application/dto"
 	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/delivery/http/middleware"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/shared/etag"
+	"app-microservice/shared/patch"
+	"context"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -30,6 +37,7 @@ func NewUserHandler(userUseCase usecases.UserUseCase) *UserHandler {
 // @Param user body dto.CreateUserRequest true "User data"
 // @Success 201 {object} dto.UserResponse
 // @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req dto.CreateUserRequest
@@ -66,8 +74,10 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} dto.UserResponse
+// @Header 200 {string} ETag "Current user version"
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /users/{id} [get]
 func (h *UserHandler) GetUserByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -95,6 +105,7 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag.Format(user.Version))
 	c.JSON(http.StatusOK, SuccessResponse{
 		Status:  "success",
 		Message: "User retrieved successfully",
@@ -108,10 +119,12 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 // @Tags users
 // @Produce json
 // @Param query query string false "Search query"
-// @Param status query string false "User status filter" Enums(active, inactive, suspended)
+// @Param status query string false "User status filter" Enums(pending_verification, active, inactive, suspended, locked)
+// @Param include_deleted query bool false "Include soft-deleted users (admin)"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(10)
 // @Success 200 {object} dto.UserListResponse
+// @Security BearerAuth
 // @Router /users [get]
 func (h *UserHandler) GetUsers(c *gin.Context) {
 	var req dto.SearchUsersRequest
@@ -151,15 +164,19 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 
 // UpdateUser godoc
 // @Summary Update a user
-// @Description Update user data by ID
+// @Description Update user data by ID. Users without the users:write permission may update their own profile but not their email.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Param user body dto.UpdateUserRequest true "Updated user data"
 // @Success 200 {object} dto.UserResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -182,13 +199,17 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userUseCase.UpdateUser(c.Request.Context(), id, &req)
+	user, err := h.userUseCase.UpdateUser(profileContext(c), id, c.GetHeader("If-Match"), &req)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "user not found" {
 			statusCode = http.StatusNotFound
+		} else if err.Error() == "user version conflict" {
+			statusCode = http.StatusPreconditionFailed
 		} else if err.Error() == "email already exists for another user" {
 			statusCode = http.StatusBadRequest
+		} else if err.Error() == "email can only be changed by an administrator" {
+			statusCode = http.StatusForbidden
 		}
 
 		c.JSON(statusCode, ErrorResponse{
@@ -199,6 +220,81 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag.Format(user.Version))
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "User updated successfully",
+		Data:    user,
+	})
+}
+
+// PatchUser godoc
+// @Summary Partially update a user
+// @Description Apply a JSON Merge Patch (application/merge-patch+json, RFC 7396) or a JSON Patch (application/json-patch+json, RFC 6902) to a user.
+// @Description Only name, email and phone can be patched; setting phone to null clears it. Validation runs on the patched user. Users without the users:write permission may patch their own profile but not their email.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param patch body dto.UserPatchDocument true "Merge patch or JSON Patch document"
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /users/{id} [patch]
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid user ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userUseCase.PatchUser(profileContext(c), id, c.GetHeader("If-Match"), c.GetHeader("Content-Type"), body)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case err.Error() == "user not found":
+			statusCode = http.StatusNotFound
+		case err.Error() == "user version conflict":
+			statusCode = http.StatusPreconditionFailed
+		case err.Error() == "email can only be changed by an administrator":
+			statusCode = http.StatusForbidden
+		case errors.Is(err, patch.ErrUnsupportedMediaType):
+			statusCode = http.StatusUnsupportedMediaType
+		case errors.Is(err, patch.ErrTestFailed):
+			statusCode = http.StatusConflict
+		case errors.Is(err, patch.ErrInvalidPatch), isValidationError(err):
+			statusCode = http.StatusBadRequest
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to patch user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", etag.Format(user.Version))
 	c.JSON(http.StatusOK, SuccessResponse{
 		Status:  "success",
 		Message: "User updated successfully",
@@ -208,13 +304,16 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 // DeleteUser godoc
 // @Summary Delete a user
-// @Description Delete user by ID
+// @Description Soft-delete user by ID. The user can be restored until the purge job removes it.
 // @Tags users
 // @Produce json
 // @Param id path int true "User ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -227,11 +326,13 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = h.userUseCase.DeleteUser(c.Request.Context(), id)
+	err = h.userUseCase.DeleteUser(c.Request.Context(), id, c.GetHeader("If-Match"))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "user not found" {
 			statusCode = http.StatusNotFound
+		} else if err.Error() == "user version conflict" {
+			statusCode = http.StatusPreconditionFailed
 		}
 
 		c.JSON(statusCode, ErrorResponse{
@@ -249,17 +350,69 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	})
 }
 
+// RestoreUser godoc
+// @Summary Restore a deleted user
+// @Description Restore a soft-deleted user
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid user ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userUseCase.RestoreUser(c.Request.Context(), id)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "user not found" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "user is not deleted" {
+			statusCode = http.StatusConflict
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to restore user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", etag.Format(user.Version))
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "User restored successfully",
+		Data:    user,
+	})
+}
+
 // UpdateUserStatus godoc
 // @Summary Update user status
-// @Description Update user status by ID
+// @Description Change user status by ID. Suspensions need a reason and may end at suspended_until; locked and pending_verification are set by the service only.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Param status body dto.UpdateUserStatusRequest true "User status"
 // @Success 200 {object} dto.UserResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /users/{id}/status [patch]
 func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -282,11 +435,18 @@ func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userUseCase.UpdateUserStatus(c.Request.Context(), id, &req)
+	user, err := h.userUseCase.UpdateUserStatus(c.Request.Context(), id, c.GetHeader("If-Match"), &req)
 	if err != nil {
+		var transitionErr *entities.StatusTransitionError
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "user not found" {
 			statusCode = http.StatusNotFound
+		} else if err.Error() == "user version conflict" {
+			statusCode = http.StatusPreconditionFailed
+		} else if errors.As(err, &transitionErr) {
+			statusCode = http.StatusConflict
+		} else if isValidationError(err) {
+			statusCode = http.StatusBadRequest
 		}
 
 		c.JSON(statusCode, ErrorResponse{
@@ -297,6 +457,7 @@ func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag.Format(user.Version))
 	c.JSON(http.StatusOK, SuccessResponse{
 		Status:  "success",
 		Message: "User status updated successfully",
@@ -304,6 +465,73 @@ func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
 	})
 }
 
+// GetStatusHistory godoc
+// @Summary Get user status history
+// @Description List the most recent status changes of a user, newest first
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {array} entities.UserStatusChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/status-history [get]
+func (h *UserHandler) GetStatusHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid user ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	history, err := h.userUseCase.GetStatusHistory(c.Request.Context(), id)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "user not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to get status history",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Status history retrieved successfully",
+		Data:    history,
+	})
+}
+
+// isValidationError reports whether err comes from entity validation or a
+// business rule rather than from the infrastructure
+func isValidationError(err error) bool {
+	switch err.Error() {
+	case "user name is required",
+		"user name must be at least 2 characters",
+		"user name must not exceed 100 characters",
+		"user email is required",
+		"invalid email format",
+		"invalid phone format",
+		"email already exists",
+		"email already exists for another user",
+		"invalid user status",
+		"suspension reason is required",
+		"suspension end must be in the future",
+		"only suspensions can have an end":
+		return true
+	}
+	return false
+}
+
 // Response structures
 type SuccessResponse struct {
 	Status  string      `json:"status"`
@@ -316,3 +544,13 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 	Error   string `json:"error"`
 }
+
+// profileContext returns the request context, marked as self-service when the
+// caller may only edit their own profile
+func profileContext(c *gin.Context) context.Context {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok || !principal.Can(entities.PermissionUsersWrite) {
+		return usecases.WithSelfService(c.Request.Context())
+	}
+	return c.Request.Context()
+}
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles the webhook endpoints
+type WebhookHandler struct {
+	webhookUseCase usecases.WebhookUseCase
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookUseCase usecases.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// CreateWebhook godoc
+// @Summary Create a webhook
+// @Description Subscribe a URL to events, such as user.created, user.* or *. Payloads are signed with HMAC-SHA256 of "<timestamp>.<body>" in X-Webhook-Signature, with the timestamp in X-Webhook-Timestamp. A generated secret is only returned here.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhook body dto.CreateWebhookRequest true "Webhook data"
+// @Success 201 {object} dto.WebhookResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	webhook, err := h.webhookUseCase.CreateWebhook(c.Request.Context(), &req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if isWebhookValidationError(err) {
+			statusCode = http.StatusBadRequest
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to create webhook",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Status:  "success",
+		Message: "Webhook created successfully",
+		Data:    webhook,
+	})
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description List every webhook, without secrets
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.WebhookResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookUseCase.ListWebhooks(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Status:  "error",
+			Message: "Failed to get webhooks",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Webhooks retrieved successfully",
+		Data:    webhooks,
+	})
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook
+// @Description Get a webhook, without its secret
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} dto.WebhookResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookUseCase.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), ErrorResponse{
+			Status:  "error",
+			Message: "Failed to get webhook",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Webhook retrieved successfully",
+		Data:    webhook,
+	})
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook
+// @Description Replace the URL, event types and description of a webhook, and optionally its active flag and secret. Set rotate_secret to get a new generated secret, which is only returned here.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param webhook body dto.UpdateWebhookRequest true "Webhook data"
+// @Success 200 {object} dto.WebhookResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid request data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	webhook, err := h.webhookUseCase.UpdateWebhook(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), ErrorResponse{
+			Status:  "error",
+			Message: "Failed to update webhook",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Webhook updated successfully",
+		Data:    webhook,
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Remove a webhook together with its delivery log. Pending deliveries are dropped.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookUseCase.DeleteWebhook(c.Request.Context(), id); err != nil {
+		c.JSON(webhookErrorStatus(err), ErrorResponse{
+			Status:  "error",
+			Message: "Failed to delete webhook",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Webhook deleted successfully",
+		Data:    nil,
+	})
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description List the events queued for a webhook, newest first, with the outcome of their last attempt
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param status query string false "Delivery status" Enums(pending, succeeded, dead)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.WebhookDeliveryListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	var query dto.WebhookDeliveryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	deliveries, err := h.webhookUseCase.ListDeliveries(c.Request.Context(), id, &query)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), ErrorResponse{
+			Status:  "error",
+			Message: "Failed to get webhook deliveries",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Message: "Webhook deliveries retrieved successfully",
+		Data:    deliveries,
+	})
+}
+
+// Redeliver godoc
+// @Summary Redeliver a webhook event
+// @Description Queue a delivery to be posted again now, with a fresh set of retries, whatever its status
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 202 {object} entities.WebhookDelivery
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid delivery ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	delivery, err := h.webhookUseCase.Redeliver(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), ErrorResponse{
+			Status:  "error",
+			Message: "Failed to redeliver webhook event",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Status:  "success",
+		Message: "Webhook event queued for redelivery",
+		Data:    delivery,
+	})
+}
+
+// webhookID parses the webhook ID path parameter, answering 400 if it is
+// not a number
+func webhookID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Status:  "error",
+			Message: "Invalid webhook ID",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// webhookErrorStatus maps an error of the webhook use case to an HTTP status
+func webhookErrorStatus(err error) int {
+	switch {
+	case err.Error() == "webhook not found", err.Error() == "webhook delivery not found":
+		return http.StatusNotFound
+	case isWebhookValidationError(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// isWebhookValidationError reports whether err comes from webhook validation
+func isWebhookValidationError(err error) bool {
+	switch err.Error() {
+	case "webhook URL must be an absolute http or https URL",
+		"webhook URL must not contain credentials",
+		"webhook URL must not point to a private address",
+		"webhook must subscribe to at least one event type":
+		return true
+	}
+	return strings.HasPrefix(err.Error(), "unknown event type ")
+}
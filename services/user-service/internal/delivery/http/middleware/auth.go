@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// principalKey is the gin context key the authenticated caller is stored under
+const principalKey = "Principal"
+
+// AuthMiddleware requires a valid Bearer access token and stores the caller
+// in the request context, which also attributes audited actions to it
+func AuthMiddleware(authUseCase usecases.AuthUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			c.Header("WWW-Authenticate", `Bearer`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"status":  "error",
+				"message": "Authentication required",
+				"error":   "Authorization header with a Bearer access token is required",
+			})
+			return
+		}
+
+		principal, err := authUseCase.Authenticate(c.Request.Context(), strings.TrimSpace(token))
+		if err != nil {
+			statusCode := http.StatusInternalServerError
+			switch err.Error() {
+			case "invalid access token", "access token issued before password change", "session revoked":
+				statusCode = http.StatusUnauthorized
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			case "user is not active":
+				statusCode = http.StatusForbidden
+			}
+			c.AbortWithStatusJSON(statusCode, gin.H{
+				"status":  "error",
+				"message": "Authentication failed",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Request = c.Request.WithContext(usecases.WithAuditActor(c.Request.Context(), entities.AuditActor{
+			UserID:    &principal.UserID,
+			Email:     principal.Email,
+			RequestID: c.GetString("RequestID"),
+			IP:        c.ClientIP(),
+		}))
+		c.Next()
+	}
+}
+
+// GetPrincipal returns the caller stored by AuthMiddleware
+func GetPrincipal(c *gin.Context) (*entities.Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*entities.Principal)
+	return principal, ok
+}
+
+// RequirePermission allows the request only if the caller holds permission.
+// It must run after AuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok || !principal.Can(permission) {
+			abortForbidden(c, permission)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermissionOrSelf allows the request if the caller holds permission
+// or is the user named by the :id path parameter. It must run after
+// AuthMiddleware.
+func RequirePermissionOrSelf(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			abortForbidden(c, permission)
+			return
+		}
+		if id, err := strconv.Atoi(c.Param("id")); err == nil && principal.IsUser(id) {
+			c.Next()
+			return
+		}
+		if !principal.Can(permission) {
+			abortForbidden(c, permission)
+			return
+		}
+		c.Next()
+	}
+}
+
+func abortForbidden(c *gin.Context, permission string) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"status":  "error",
+		"message": "Permission denied",
+		"error":   "missing permission " + permission,
+	})
+}
@@ -12,8 +12,8 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, X-Requested-With")
-		c.Header("Access-Control-Expose-Headers", "Content-Length")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, X-Requested-With, If-Match")
+		c.Header("Access-Control-Expose-Headers", "Content-Length, ETag")
 		c.Header("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == "OPTIONS" {
@@ -25,6 +25,25 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
+// RequireIfMatchMiddleware rejects PUT, PATCH and DELETE requests that do not
+// carry an If-Match header, so clients cannot overwrite changes they have not seen
+func RequireIfMatchMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case "PUT", "PATCH", "DELETE":
+			if c.GetHeader("If-Match") == "" {
+				c.AbortWithStatusJSON(428, gin.H{
+					"status":  "error",
+					"message": "Precondition required",
+					"error":   "If-Match header with the current ETag is required",
+				})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
 // RequestIDMiddleware adds a unique request ID to each request
 func RequestIDMiddleware() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
@@ -61,7 +80,11 @@ func SecurityMiddleware() gin.HandlerFunc {
 		c.Header("X-Content-Type-Options", "nosniff")
 		c.Header("X-Frame-Options", "DENY")
 		c.Header("X-XSS-Protection", "1; mode=block")
-		c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		// Browsers ignore HSTS over plain HTTP, and it would be wrong for a
+		// service that is not served over HTTPS
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		}
 		c.Next()
 	}
 }
@@ -75,15 +98,6 @@ func RateLimitMiddleware() gin.HandlerFunc {
 	}
 }
 
-// AuthMiddleware provides authentication (placeholder)
-func AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Add your authentication logic here
-		// For now, this is a placeholder
-		c.Next()
-	}
-}
-
 // ValidationErrorMiddleware handles validation errors
 func ValidationErrorMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -1,8 +1,11 @@
 package routes
 
 import (
+	"app-microservice/services/user-service/internal/application/usecases"
 	"app-microservice/services/user-service/internal/delivery/http/handlers"
 	"app-microservice/services/user-service/internal/delivery/http/middleware"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/shared/svcauth"
 	"net/http"
 	"time"
 
@@ -13,13 +16,36 @@ import (
 
 // Router holds the router configuration
 type Router struct {
-	userHandler *handlers.UserHandler
+	userHandler    *handlers.UserHandler
+	authHandler    *handlers.AuthHandler
+	roleHandler    *handlers.RoleHandler
+	sessionHandler *handlers.SessionHandler
+	oidcHandler    *handlers.OIDCHandler
+	auditHandler   *handlers.AuditHandler
+	webhookHandler *handlers.WebhookHandler
+	eventHandler   *handlers.EventHandler
+	authUseCase    usecases.AuthUseCase
+	serviceAuth    *svcauth.Authenticator
+	requireIfMatch bool
 }
 
-// NewRouter creates a new router instance
-func NewRouter(userHandler *handlers.UserHandler) *Router {
+// NewRouter creates a new router instance. authUseCase resolves the caller of
+// protected routes. When serviceAuth is set, every route but the health check
+// requires a token of a peer service, and peers may send their domain events.
+// When requireIfMatch is set, user updates and deletes must send If-Match.
+func NewRouter(userHandler *handlers.UserHandler, authHandler *handlers.AuthHandler, roleHandler *handlers.RoleHandler, sessionHandler *handlers.SessionHandler, oidcHandler *handlers.OIDCHandler, auditHandler *handlers.AuditHandler, webhookHandler *handlers.WebhookHandler, eventHandler *handlers.EventHandler, authUseCase usecases.AuthUseCase, serviceAuth *svcauth.Authenticator, requireIfMatch bool) *Router {
 	return &Router{
-		userHandler: userHandler,
+		userHandler:    userHandler,
+		authHandler:    authHandler,
+		roleHandler:    roleHandler,
+		sessionHandler: sessionHandler,
+		oidcHandler:    oidcHandler,
+		auditHandler:   auditHandler,
+		webhookHandler: webhookHandler,
+		eventHandler:   eventHandler,
+		authUseCase:    authUseCase,
+		serviceAuth:    serviceAuth,
+		requireIfMatch: requireIfMatch,
 	}
 }
 
@@ -37,21 +63,68 @@ func (r *Router) SetupRoutes() *gin.Engine {
 	router.Use(middleware.SecurityMiddleware())
 	router.Use(gin.Recovery())
 	router.Use(middleware.ValidationErrorMiddleware())
+	if r.serviceAuth != nil {
+		router.Use(r.serviceAuth.Middleware("/health"))
+	}
 
 	// Health check endpoint
 	router.GET("/health", r.healthCheck)
 
+	// Public keys for verifying access tokens
+	router.GET("/.well-known/jwks.json", r.authHandler.JWKS)
+
+	// Every user and role route requires an access token. Permission checks
+	// are declared per route; a user may always read and update their own
+	// profile and see their own roles. Resetting MFA and ending sessions of
+	// others is for admins only; users change their own MFA under /auth/mfa
+	// and their own sessions under /me/sessions.
+	authenticated := middleware.AuthMiddleware(r.authUseCase)
+	can := middleware.RequirePermission
+	canOrSelf := middleware.RequirePermissionOrSelf
+	userRoutes := []route{
+		{http.MethodPost, "", can(entities.PermissionUsersWrite), r.userHandler.CreateUser},
+		{http.MethodGet, "", can(entities.PermissionUsersRead), r.userHandler.GetUsers},
+		{http.MethodGet, "/:id", canOrSelf(entities.PermissionUsersRead), r.userHandler.GetUserByID},
+		{http.MethodPut, "/:id", canOrSelf(entities.PermissionUsersWrite), r.userHandler.UpdateUser},
+		{http.MethodPatch, "/:id", canOrSelf(entities.PermissionUsersWrite), r.userHandler.PatchUser},
+		{http.MethodDelete, "/:id", can(entities.PermissionUsersDelete), r.userHandler.DeleteUser},
+		{http.MethodPost, "/:id/restore", can(entities.PermissionUsersDelete), r.userHandler.RestoreUser},
+		{http.MethodPatch, "/:id/status", can(entities.PermissionUsersStatus), r.userHandler.UpdateUserStatus},
+	}
+	userActionRoutes := []route{
+		{http.MethodGet, "/:id/roles", canOrSelf(entities.PermissionRolesManage), r.roleHandler.GetUserRoles},
+		{http.MethodPost, "/:id/roles", can(entities.PermissionRolesManage), r.roleHandler.AssignRole},
+		{http.MethodDelete, "/:id/roles/:role", can(entities.PermissionRolesManage), r.roleHandler.RevokeRole},
+		{http.MethodDelete, "/:id/mfa", can(entities.PermissionUsersMFA), r.authHandler.ResetMFA},
+		{http.MethodPost, "/:id/unlock", can(entities.PermissionUsersStatus), r.authHandler.UnlockUser},
+		{http.MethodGet, "/:id/lockouts", can(entities.PermissionUsersStatus), r.authHandler.GetLockoutEvents},
+		{http.MethodGet, "/:id/status-history", can(entities.PermissionUsersStatus), r.userHandler.GetStatusHistory},
+		{http.MethodGet, "/:id/sessions", can(entities.PermissionUsersSessions), r.sessionHandler.ListUserSessions},
+		{http.MethodDelete, "/:id/sessions", can(entities.PermissionUsersSessions), r.sessionHandler.RevokeUserSessions},
+		{http.MethodDelete, "/:id/sessions/:session_id", can(entities.PermissionUsersSessions), r.sessionHandler.RevokeUserSession},
+	}
+
 	// API version 1 routes
 	v1 := router.Group("/api/v1")
 	{
-		r.setupUserRoutes(v1)
+		r.setupAuthRoutes(v1, authenticated)
+		r.setupUserRoutes(v1.Group("/users", authenticated), userRoutes, userActionRoutes)
+		r.setupMeRoutes(v1.Group("/me", authenticated))
+		v1.GET("/roles", authenticated, can(entities.PermissionRolesManage), r.roleHandler.ListRoles)
+		r.setupOAuthClientRoutes(v1.Group("/oauth/clients", authenticated, can(entities.PermissionClientsManage)))
+		r.setupAuditRoutes(v1.Group("/audit", authenticated, can(entities.PermissionAuditRead)))
+		r.setupWebhookRoutes(v1.Group("/webhooks", authenticated, can(entities.PermissionWebhooksManage)))
 	}
 
+	// Domain events of peer services, which the gateway does not proxy. The
+	// handler rejects callers that are not a known peer service.
+	router.POST("/internal/events", r.eventHandler.ReceiveEvent)
+
+	// OpenID Connect provider
+	r.setupOIDCRoutes(router, authenticated)
+
 	// User routes (for backward compatibility)
-	userGroup := router.Group("/users")
-	{
-		r.setupUserRoutesCompat(userGroup)
-	}
+	r.setupUserRoutes(router.Group("/users", authenticated), userRoutes, userActionRoutes)
 
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -68,27 +141,95 @@ func (r *Router) SetupRoutes() *gin.Engine {
 	return router
 }
 
-// setupUserRoutes sets up user-related routes for v1 API
-func (r *Router) setupUserRoutes(rg *gin.RouterGroup) {
-	users := rg.Group("/users")
+// setupAuthRoutes sets up authentication routes for v1 API
+func (r *Router) setupAuthRoutes(rg *gin.RouterGroup, authenticated gin.HandlerFunc) {
+	auth := rg.Group("/auth")
+	{
+		auth.POST("/register", r.authHandler.Register)
+		auth.POST("/verify-email", r.authHandler.VerifyEmail)
+		auth.POST("/verify-email/resend", r.authHandler.ResendVerification)
+		auth.POST("/password/forgot", r.authHandler.ForgotPassword)
+		auth.POST("/password/reset", r.authHandler.ResetPassword)
+		auth.POST("/login", r.authHandler.Login)
+		auth.POST("/mfa/verify", r.authHandler.VerifyMFA)
+		auth.POST("/mfa/enroll", authenticated, r.authHandler.EnrollMFA)
+		auth.POST("/mfa/confirm", authenticated, r.authHandler.ConfirmMFA)
+		auth.POST("/refresh", r.authHandler.Refresh)
+		auth.POST("/logout", r.authHandler.Logout)
+	}
+}
+
+// setupMeRoutes sets up the routes of the caller's own account for v1 API
+func (r *Router) setupMeRoutes(rg *gin.RouterGroup) {
+	sessions := rg.Group("/sessions")
+	{
+		sessions.GET("", r.sessionHandler.ListMySessions)
+		sessions.DELETE("", r.sessionHandler.RevokeMySessions)
+		sessions.DELETE("/:id", r.sessionHandler.RevokeMySession)
+	}
+}
+
+// setupOIDCRoutes sets up the OpenID Connect provider endpoints. They are
+// not versioned, since relying parties find them through discovery.
+func (r *Router) setupOIDCRoutes(router *gin.Engine, authenticated gin.HandlerFunc) {
+	router.GET("/.well-known/openid-configuration", r.oidcHandler.Discovery)
+
+	oauth2 := router.Group("/oauth2")
 	{
-		users.POST("", r.userHandler.CreateUser)
-		users.GET("", r.userHandler.GetUsers)
-		users.GET("/:id", r.userHandler.GetUserByID)
-		users.PUT("/:id", r.userHandler.UpdateUser)
-		users.DELETE("/:id", r.userHandler.DeleteUser)
-		users.PATCH("/:id/status", r.userHandler.UpdateUserStatus)
+		oauth2.GET("/authorize", r.oidcHandler.AuthorizePage)
+		oauth2.POST("/authorize", authenticated, r.oidcHandler.Authorize)
+		oauth2.POST("/token", r.oidcHandler.Token)
+		oauth2.GET("/userinfo", r.oidcHandler.UserInfo)
+		oauth2.POST("/userinfo", r.oidcHandler.UserInfo)
 	}
 }
 
-// setupUserRoutesCompat sets up user routes for backward compatibility
-func (r *Router) setupUserRoutesCompat(rg *gin.RouterGroup) {
-	rg.POST("", r.userHandler.CreateUser)
-	rg.GET("", r.userHandler.GetUsers)
-	rg.GET("/:id", r.userHandler.GetUserByID)
-	rg.PUT("/:id", r.userHandler.UpdateUser)
-	rg.DELETE("/:id", r.userHandler.DeleteUser)
-	rg.PATCH("/:id/status", r.userHandler.UpdateUserStatus)
+// setupOAuthClientRoutes sets up the registration of OAuth clients for v1 API
+func (r *Router) setupOAuthClientRoutes(rg *gin.RouterGroup) {
+	rg.POST("", r.oidcHandler.CreateClient)
+	rg.GET("", r.oidcHandler.ListClients)
+	rg.DELETE("/:client_id", r.oidcHandler.DeleteClient)
+}
+
+// setupAuditRoutes sets up the audit log for v1 API
+func (r *Router) setupAuditRoutes(rg *gin.RouterGroup) {
+	rg.GET("", r.auditHandler.ListEntries)
+	rg.GET("/verify", r.auditHandler.Verify)
+}
+
+// setupWebhookRoutes sets up webhooks and their delivery log for v1 API
+func (r *Router) setupWebhookRoutes(rg *gin.RouterGroup) {
+	rg.POST("", r.webhookHandler.CreateWebhook)
+	rg.GET("", r.webhookHandler.ListWebhooks)
+	rg.GET("/:id", r.webhookHandler.GetWebhook)
+	rg.PUT("/:id", r.webhookHandler.UpdateWebhook)
+	rg.DELETE("/:id", r.webhookHandler.DeleteWebhook)
+	rg.GET("/:id/deliveries", r.webhookHandler.ListDeliveries)
+	rg.POST("/:id/deliveries/:delivery_id/redeliver", r.webhookHandler.Redeliver)
+}
+
+// route is a user route guarded by a permission check
+type route struct {
+	method     string
+	path       string
+	permission gin.HandlerFunc
+	handler    gin.HandlerFunc
+}
+
+// setupUserRoutes registers user routes, which honour requireIfMatch, and
+// routes acting on a user's roles, MFA, lockout and sessions, which have no
+// ETag, on rg
+func (r *Router) setupUserRoutes(rg *gin.RouterGroup, userRoutes, userActionRoutes []route) {
+	users := rg.Group("")
+	if r.requireIfMatch {
+		users.Use(middleware.RequireIfMatchMiddleware())
+	}
+	for _, rt := range userRoutes {
+		users.Handle(rt.method, rt.path, rt.permission, rt.handler)
+	}
+	for _, rt := range userActionRoutes {
+		rg.Handle(rt.method, rt.path, rt.permission, rt.handler)
+	}
 }
 
 // healthCheck provides a health check endpoint
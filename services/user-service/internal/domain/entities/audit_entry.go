@@ -0,0 +1,182 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditUserCreate       = "user.create"
+	AuditUserUpdate       = "user.update"
+	AuditUserDelete       = "user.delete"
+	AuditUserRestore      = "user.restore"
+	AuditUserStatus       = "user.status"
+	AuditUserUnlock       = "user.unlock"
+	AuditMFAReset         = "user.mfa_reset"
+	AuditRoleAssign       = "role.assign"
+	AuditRoleRevoke       = "role.revoke"
+	AuditSessionRevoke    = "session.revoke"
+	AuditSessionsRevoke   = "session.revoke_all"
+	AuditClientCreate     = "oauth_client.create"
+	AuditClientDelete     = "oauth_client.delete"
+	AuditWebhookCreate    = "webhook.create"
+	AuditWebhookUpdate    = "webhook.update"
+	AuditWebhookDelete    = "webhook.delete"
+	AuditWebhookRedeliver = "webhook.redeliver"
+)
+
+// Audit target types
+const (
+	AuditTargetUser    = "user"
+	AuditTargetClient  = "oauth_client"
+	AuditTargetWebhook = "webhook"
+)
+
+// AuditActor identifies who made a request, for the audit log
+type AuditActor struct {
+	UserID    *int
+	Email     string
+	RequestID string
+	IP        string
+}
+
+// AuditChange is the value of a field before and after an action. Before is
+// nil for created fields and After for removed ones.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditEntry records an administration action. Entries are chained by hash:
+// Hash covers PrevHash, the hash of the entry before, and every other field
+// but ID, so an entry that was changed or removed is detected by Verify.
+type AuditEntry struct {
+	ID         int                    `json:"id"`
+	ActorID    *int                   `json:"actor_id,omitempty"`
+	ActorEmail string                 `json:"actor_email,omitempty"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type"`
+	TargetID   string                 `json:"target_id"`
+	Changes    map[string]AuditChange `json:"changes,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	IP         string                 `json:"ip,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	PrevHash   string                 `json:"prev_hash"`
+	Hash       string                 `json:"hash"`
+}
+
+// AuditFilter selects audit entries. Empty fields match every entry.
+type AuditFilter struct {
+	ActorID    *int
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+}
+
+// ComputeHash returns the hash of the entry over PrevHash and its fields.
+// CreatedAt is hashed in UTC at microsecond precision, which every database
+// keeps.
+func (e *AuditEntry) ComputeHash() (string, error) {
+	actorID := ""
+	if e.ActorID != nil {
+		actorID = strconv.Itoa(*e.ActorID)
+	}
+	changes, err := e.ChangesJSON()
+	if err != nil {
+		return "", err
+	}
+	fields, err := json.Marshal([]string{
+		e.PrevHash,
+		actorID,
+		e.ActorEmail,
+		e.Action,
+		e.TargetType,
+		e.TargetID,
+		changes,
+		e.RequestID,
+		e.IP,
+		e.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Seal sets PrevHash and Hash, linking the entry after the one with prevHash
+func (e *AuditEntry) Seal(prevHash string) error {
+	e.PrevHash = prevHash
+	hash, err := e.ComputeHash()
+	if err != nil {
+		return err
+	}
+	e.Hash = hash
+	return nil
+}
+
+// ChangesJSON returns Changes encoded as stored, with keys sorted, or "" if
+// there are none
+func (e *AuditEntry) ChangesJSON() (string, error) {
+	if len(e.Changes) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(e.Changes)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DiffFields compares two snapshots of an object, such as before and after
+// an update, and returns the fields whose values differ. Either snapshot may
+// be nil, for objects that were created or removed. Values are compared as
+// they encode to JSON, so they hash the same after they were stored.
+func DiffFields(before, after map[string]any) (map[string]AuditChange, error) {
+	before, err := normalize(before)
+	if err != nil {
+		return nil, err
+	}
+	after, err = normalize(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]AuditChange)
+	for field, value := range before {
+		if !reflect.DeepEqual(value, after[field]) {
+			changes[field] = AuditChange{Before: value, After: after[field]}
+		}
+	}
+	for field, value := range after {
+		if _, ok := before[field]; !ok && value != nil {
+			changes[field] = AuditChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+// normalize round-trips a snapshot through JSON, turning its values into the
+// types they decode back to
+func normalize(snapshot map[string]any) (map[string]any, error) {
+	if snapshot == nil {
+		return map[string]any{}, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
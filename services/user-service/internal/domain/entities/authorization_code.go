@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// AuthorizationCode is a single-use code of the OAuth 2 authorization code
+// flow, bound to the client, redirect URI and PKCE challenge of the request
+// it was issued for
+type AuthorizationCode struct {
+	ID            int
+	ClientID      int
+	UserID        int
+	CodeHash      string
+	RedirectURI   string
+	Scope         string
+	Nonce         string
+	CodeChallenge string
+	AuthTime      time.Time
+	ExpiresAt     time.Time
+	UsedAt        *time.Time
+	CreatedAt     time.Time
+}
+
+// IsExpired checks if the code has expired at the given time
+func (c *AuthorizationCode) IsExpired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
+
+// HasScope checks if scope was granted with the code
+func (c *AuthorizationCode) HasScope(scope string) bool {
+	return slices.Contains(strings.Fields(c.Scope), scope)
+}
@@ -0,0 +1,102 @@
+package entities
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Domain event types. Consumers subscribe to these names, so they must not
+// change once published.
+const (
+	EventUserCreated     = "user.created"
+	EventUserUpdated     = "user.updated"
+	EventUserDeleted     = "user.deleted"
+	EventUserRestored    = "user.restored"
+	EventUserActivated   = "user.activated"
+	EventUserDeactivated = "user.deactivated"
+	EventUserSuspended   = "user.suspended"
+)
+
+// Product event types, raised by the product service and received through
+// the internal events endpoint
+const (
+	EventProductCreated  = "product.created"
+	EventProductUpdated  = "product.updated"
+	EventProductDeleted  = "product.deleted"
+	EventProductRestored = "product.restored"
+)
+
+// Aggregate types of domain events
+const (
+	EventAggregateUser    = "user"
+	EventAggregateProduct = "product"
+)
+
+// EventTypes lists every event type by aggregate type
+var EventTypes = map[string][]string{
+	EventAggregateUser: {
+		EventUserCreated, EventUserUpdated, EventUserDeleted, EventUserRestored,
+		EventUserActivated, EventUserDeactivated, EventUserSuspended,
+	},
+	EventAggregateProduct: {
+		EventProductCreated, EventProductUpdated, EventProductDeleted, EventProductRestored,
+	},
+}
+
+// IsEventType checks if eventType is a known event type of aggregateType
+func IsEventType(aggregateType, eventType string) bool {
+	return slices.Contains(EventTypes[aggregateType], eventType)
+}
+
+// userStatusEvents maps the statuses an admin can give to the event raised
+// for the change
+var userStatusEvents = map[UserStatus]string{
+	UserStatusActive:    EventUserActivated,
+	UserStatusInactive:  EventUserDeactivated,
+	UserStatusSuspended: EventUserSuspended,
+}
+
+// UserStatusEvent returns the event type raised when a user is given status,
+// or "" if there is none
+func UserStatusEvent(status UserStatus) string {
+	return userStatusEvents[status]
+}
+
+// DomainEvent is a change other services can learn about. It is delivered at
+// least once; consumers skip IDs they have already handled.
+type DomainEvent struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Data          json.RawMessage `json:"data"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// NewDomainEvent creates an event with a new ID and data encoded as JSON
+func NewDomainEvent(eventType, aggregateType, aggregateID string, data any, occurredAt time.Time) (*DomainEvent, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &DomainEvent{
+		ID:            uuid.NewString(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Data:          encoded,
+		OccurredAt:    occurredAt,
+	}, nil
+}
+
+// OutboxEvent is a domain event waiting in the outbox to be published
+type OutboxEvent struct {
+	DomainEvent
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	PublishedAt   *time.Time
+}
@@ -0,0 +1,40 @@
+package entities
+
+import "time"
+
+// LoginAttempts counts the failed logins of one subject, an account or a
+// client IP
+type LoginAttempts struct {
+	Subject       string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// IsLocked checks if attempts for the subject are refused at the given time
+func (a *LoginAttempts) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+// LockoutAction tells whether a lockout event locked or unlocked its subject
+type LockoutAction string
+
+const (
+	LockoutActionLocked   LockoutAction = "locked"
+	LockoutActionUnlocked LockoutAction = "unlocked"
+)
+
+// LockoutEvent records an account or client IP being locked out after too
+// many failed logins, or being unlocked again
+type LockoutEvent struct {
+	ID          int           `json:"id"`
+	UserID      *int          `json:"user_id,omitempty"`
+	Subject     string        `json:"subject"`
+	Action      LockoutAction `json:"action"`
+	Reason      string        `json:"reason,omitempty"`
+	IP          string        `json:"ip,omitempty"`
+	Failures    int           `json:"failures,omitempty"`
+	LockedUntil *time.Time    `json:"locked_until,omitempty"`
+	ActorID     *int          `json:"actor_id,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
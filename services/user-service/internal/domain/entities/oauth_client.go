@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Grant types an OAuth client can be allowed to use
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// OpenID Connect scopes of the authorization code flow. Clients using client
+// credentials are registered with scopes of their own.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// OAuthClient represents an application that signs users in through OpenID
+// Connect or calls services with its own credentials. Public clients, such as
+// single-page and native apps, have no secret and rely on PKCE alone.
+type OAuthClient struct {
+	ID           int       `json:"-"`
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	SecretHash   string    `json:"-"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// IsIdentityScope checks if scope is one of the OpenID Connect scopes, which
+// release claims about a user
+func IsIdentityScope(scope string) bool {
+	return scope == ScopeOpenID || scope == ScopeProfile || scope == ScopeEmail
+}
+
+// IsPublic checks if the client has no secret
+func (c *OAuthClient) IsPublic() bool {
+	return c.SecretHash == ""
+}
+
+// AllowsRedirectURI checks if uri is one of the registered redirect URIs.
+// URIs are compared exactly.
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
+
+// AllowsGrantType checks if the client may use a grant type
+func (c *OAuthClient) AllowsGrantType(grantType string) bool {
+	return slices.Contains(c.GrantTypes, grantType)
+}
+
+// AllowsScopes checks if the client may request every scope of a
+// space-separated scope string
+func (c *OAuthClient) AllowsScopes(scope string) bool {
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(c.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,19 @@
+package entities
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID      int
+	Email       string
+	SessionID   string
+	Permissions map[string]bool
+}
+
+// Can checks if the caller holds a permission
+func (p *Principal) Can(permission string) bool {
+	return p.Permissions[permission]
+}
+
+// IsUser checks if the caller is the user with the given ID
+func (p *Principal) IsUser(userID int) bool {
+	return p.UserID == userID
+}
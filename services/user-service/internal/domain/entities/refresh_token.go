@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// RefreshToken represents an issued refresh token. Tokens issued from the same
+// login share a family so that reuse of a rotated token can revoke them all.
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// IsExpired checks if the token has expired at the given time
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// IsRevoked checks if the token has been rotated, logged out or revoked
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
@@ -0,0 +1,69 @@
+package entities
+
+// Permissions checked by the HTTP routes. A user can always read and update
+// their own profile; these grant the same on other users.
+const (
+	PermissionUsersRead      = "users:read"
+	PermissionUsersWrite     = "users:write"
+	PermissionUsersDelete    = "users:delete"
+	PermissionUsersStatus    = "users:status"
+	PermissionUsersMFA       = "users:mfa"
+	PermissionUsersSessions  = "users:sessions"
+	PermissionRolesManage    = "roles:manage"
+	PermissionClientsManage  = "clients:manage"
+	PermissionAuditRead      = "audit:read"
+	PermissionWebhooksManage = "webhooks:manage"
+)
+
+// RoleAdmin is the built-in role holding every permission
+const RoleAdmin = "admin"
+
+// Role represents a named set of permissions
+type Role struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionDescriptions lists every permission the service knows about
+var PermissionDescriptions = map[string]string{
+	PermissionUsersRead:      "Read any user",
+	PermissionUsersWrite:     "Create and update any user",
+	PermissionUsersDelete:    "Delete and restore users",
+	PermissionUsersStatus:    "Change the status of users",
+	PermissionUsersMFA:       "Reset the MFA of users",
+	PermissionUsersSessions:  "List and revoke the sessions of users",
+	PermissionRolesManage:    "Assign and revoke roles",
+	PermissionClientsManage:  "Register and delete OAuth clients",
+	PermissionAuditRead:      "Read and verify the audit log",
+	PermissionWebhooksManage: "Manage webhooks and redeliver their events",
+}
+
+// DefaultRoles are created at startup if they do not exist yet
+var DefaultRoles = []Role{
+	{
+		Name:        RoleAdmin,
+		Description: "Full access to every user and role",
+		Permissions: []string{
+			PermissionUsersRead,
+			PermissionUsersWrite,
+			PermissionUsersDelete,
+			PermissionUsersStatus,
+			PermissionUsersMFA,
+			PermissionUsersSessions,
+			PermissionRolesManage,
+			PermissionClientsManage,
+			PermissionAuditRead,
+			PermissionWebhooksManage,
+		},
+	},
+	{
+		Name:        "support",
+		Description: "Read users and change their status",
+		Permissions: []string{
+			PermissionUsersRead,
+			PermissionUsersStatus,
+		},
+	},
+}
@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// Session is one sign-in of a user. The refresh tokens issued from the
+// sign-in form a family whose ID is the session ID, and its access tokens
+// carry the session ID as their sid claim.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     int        `json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsActive checks if the session is neither revoked nor expired at the given
+// time
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -2,30 +2,98 @@ package entities
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"time"
 )
 
 // User represents the user domain entity
 type User struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name" validate:"required,min=2,max=100"`
-	Email     string    `json:"email" validate:"required,email"`
-	Phone     string    `json:"phone,omitempty" validate:"omitempty,phone"`
-	Status    string    `json:"status" validate:"omitempty,oneof=active inactive suspended"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                int        `json:"id"`
+	Name              string     `json:"name" validate:"required,min=2,max=100"`
+	Email             string     `json:"email" validate:"required,email"`
+	Phone             string     `json:"phone,omitempty" validate:"omitempty,phone"`
+	PasswordHash      string     `json:"-"` // never serialized
+	PasswordChangedAt *time.Time `json:"-"` // access tokens issued earlier are rejected
+	MFASecret         string     `json:"-"` // encrypted TOTP secret
+	MFAEnabledAt      *time.Time `json:"-"`
+	MFALastStep       int64      `json:"-"` // time step of the last accepted TOTP code
+	Status            string     `json:"status" validate:"omitempty,oneof=pending_verification active inactive suspended locked"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
+	SuspendedUntil    *time.Time `json:"suspended_until,omitempty"`
+	Version           int        `json:"version"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
+
+	// StatusChange is the change made by ChangeStatus, which the repository
+	// stores in the status history when it saves the user
+	StatusChange *UserStatusChange `json:"-"`
 }
 
 // UserStatus represents possible user statuses
 type UserStatus string
 
 const (
-	UserStatusActive    UserStatus = "active"
-	UserStatusInactive  UserStatus = "inactive"
-	UserStatusSuspended UserStatus = "suspended"
+	UserStatusPendingVerification UserStatus = "pending_verification"
+	UserStatusActive              UserStatus = "active"
+	UserStatusInactive            UserStatus = "inactive"
+	UserStatusSuspended           UserStatus = "suspended"
+
+	// UserStatusLocked is set automatically after too many failed logins and
+	// lifts itself at LockedUntil, unlike suspended, which an admin sets and
+	// lifts
+	UserStatusLocked UserStatus = "locked"
 )
 
+// userStatusTransitions lists the statuses each status may change to.
+// Nothing returns to pending_verification, and only login protection locks a
+// user.
+var userStatusTransitions = map[UserStatus][]UserStatus{
+	UserStatusPendingVerification: {UserStatusActive, UserStatusInactive, UserStatusSuspended},
+	UserStatusActive:              {UserStatusInactive, UserStatusSuspended, UserStatusLocked},
+	UserStatusInactive:            {UserStatusActive, UserStatusSuspended},
+	UserStatusSuspended:           {UserStatusActive, UserStatusInactive, UserStatusSuspended},
+	UserStatusLocked:              {UserStatusActive, UserStatusInactive, UserStatusSuspended},
+}
+
+// CanTransitionTo checks if a user with status s may be given status to.
+// Suspending a suspended user again replaces its reason and end.
+func (s UserStatus) CanTransitionTo(to UserStatus) bool {
+	for _, allowed := range userStatusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusTransitionError is returned for a status change the state machine
+// does not allow
+type StatusTransitionError struct {
+	From UserStatus
+	To   UserStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	if e.From == e.To {
+		return fmt.Sprintf("user is already %s", e.To)
+	}
+	return fmt.Sprintf("cannot change user status from %s to %s", e.From, e.To)
+}
+
+// UserStatusChange records one change of a user's status
+type UserStatusChange struct {
+	ID             int        `json:"id"`
+	UserID         int        `json:"user_id"`
+	FromStatus     UserStatus `json:"from_status"`
+	ToStatus       UserStatus `json:"to_status"`
+	Reason         string     `json:"reason,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	ActorID        *int       `json:"actor_id,omitempty"` // admin that made the change, nil if automatic
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
 // Business rules and validation methods
 
 // Validate performs domain-level validation
@@ -66,21 +134,88 @@ func (u *User) IsActive() bool {
 	return u.Status == string(UserStatusActive)
 }
 
-// Activate sets user status to active
-func (u *User) Activate() {
-	u.Status = string(UserStatusActive)
-	u.UpdatedAt = time.Now()
+// IsPendingVerification checks if user has not verified its email yet
+func (u *User) IsPendingVerification() bool {
+	return u.Status == string(UserStatusPendingVerification)
 }
 
-// Deactivate sets user status to inactive
-func (u *User) Deactivate() {
-	u.Status = string(UserStatusInactive)
-	u.UpdatedAt = time.Now()
+// IsLocked checks if user is locked out at the given time. A lockout that
+// has run out no longer counts even before the status is reset.
+func (u *User) IsLocked(now time.Time) bool {
+	return u.Status == string(UserStatusLocked) && u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// HasSessionAccess checks if user may keep using tokens issued at login. A
+// lockout only stops new logins, so guessing a user's password cannot log
+// the user out.
+func (u *User) HasSessionAccess() bool {
+	return u.IsActive() || u.Status == string(UserStatusLocked)
+}
+
+// MFAEnabled checks if login requires a TOTP code or recovery code
+func (u *User) MFAEnabled() bool {
+	return u.MFAEnabledAt != nil
+}
+
+// IsSuspensionOver checks if user is suspended until a time that has passed
+func (u *User) IsSuspensionOver(now time.Time) bool {
+	return u.Status == string(UserStatusSuspended) && u.SuspendedUntil != nil && !now.Before(*u.SuspendedUntil)
+}
+
+// IsDeleted checks if user has been soft-deleted
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// HasPassword checks if user can log in with a password
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// ChangeStatus moves user to status to if the state machine allows it and
+// records the change in StatusChange. Suspensions need a reason and may end
+// at until, after which the user is made active again; until must be nil for
+// other statuses.
+func (u *User) ChangeStatus(to UserStatus, reason string, until *time.Time, now time.Time) error {
+	if !isValidStatus(string(to)) {
+		return errors.New("invalid user status")
+	}
+	from := UserStatus(u.Status)
+	if !from.CanTransitionTo(to) {
+		return &StatusTransitionError{From: from, To: to}
+	}
+	if to == UserStatusSuspended {
+		if reason == "" {
+			return errors.New("suspension reason is required")
+		}
+		if until != nil && !until.After(now) {
+			return errors.New("suspension end must be in the future")
+		}
+	} else if until != nil {
+		return errors.New("only suspensions can have an end")
+	}
+
+	u.Status = string(to)
+	u.LockedUntil = nil
+	u.SuspendedUntil = until
+	u.UpdatedAt = now
+	u.StatusChange = &UserStatusChange{
+		UserID:         u.ID,
+		FromStatus:     from,
+		ToStatus:       to,
+		Reason:         reason,
+		SuspendedUntil: until,
+		CreatedAt:      now,
+	}
+	return nil
 }
 
-// Suspend sets user status to suspended
-func (u *User) Suspend() {
-	u.Status = string(UserStatusSuspended)
+// Activate sets user status to active without going through the state
+// machine, to match a change the repository already made, such as an unlock
+func (u *User) Activate() {
+	u.Status = string(UserStatusActive)
+	u.LockedUntil = nil
+	u.SuspendedUntil = nil
 	u.UpdatedAt = time.Now()
 }
 
@@ -114,9 +249,11 @@ func isValidPhone(phone string) bool {
 
 func isValidStatus(status string) bool {
 	validStatuses := []string{
+		string(UserStatusPendingVerification),
 		string(UserStatusActive),
 		string(UserStatusInactive),
 		string(UserStatusSuspended),
+		string(UserStatusLocked),
 	}
 
 	for _, validStatus := range validStatuses {
@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+// TokenPurpose says what a verification token may be used for
+type TokenPurpose string
+
+const (
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeMFAChallenge      TokenPurpose = "mfa_challenge"
+)
+
+// VerificationToken represents a single-use token sent to a user by email or,
+// for an MFA challenge, handed out after the password step of a login
+type VerificationToken struct {
+	ID             int
+	UserID         int
+	Purpose        TokenPurpose
+	TokenHash      string
+	ExpiresAt      time.Time
+	UsedAt         *time.Time
+	FailedAttempts int
+	CreatedAt      time.Time
+}
+
+// IsExpired checks if the token has expired at the given time
+func (t *VerificationToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// IsUsed checks if the token has been used or replaced by a newer one
+func (t *VerificationToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
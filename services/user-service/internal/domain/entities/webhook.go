@@ -0,0 +1,130 @@
+package entities
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WebhookAllEvents subscribes a webhook to every event type. A type prefix
+// followed by ".*", such as user.*, subscribes it to the events of one
+// aggregate type.
+const WebhookAllEvents = "*"
+
+// Webhook delivery statuses. A delivery is pending until the webhook accepts
+// it, including between retries, and dead once its retries are exhausted.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription represents a partner URL that is posted the events it
+// subscribed to, signed with its secret
+type WebhookSubscription struct {
+	ID          int       `json:"id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Secret      string    `json:"-"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Validate checks the URL and event types of the webhook
+func (w *WebhookSubscription) Validate() error {
+	target, err := url.Parse(w.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("webhook URL must be an absolute http or https URL")
+	}
+	if target.User != nil {
+		return errors.New("webhook URL must not contain credentials")
+	}
+	if len(w.EventTypes) == 0 {
+		return errors.New("webhook must subscribe to at least one event type")
+	}
+	for _, eventType := range w.EventTypes {
+		if !isWebhookEventType(eventType) {
+			return errors.New("unknown event type " + eventType)
+		}
+	}
+	return nil
+}
+
+// CheckPublicHost rejects a webhook URL whose host is a loopback, private,
+// link-local or unspecified IP address, or a localhost name. Other names are
+// checked against the addresses they resolve to when a delivery is sent.
+func (w *WebhookSubscription) CheckPublicHost() error {
+	target, err := url.Parse(w.URL)
+	if err != nil {
+		return errors.New("webhook URL must be an absolute http or https URL")
+	}
+	host := strings.TrimSuffix(strings.ToLower(target.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("webhook URL must not point to a private address")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddress(addr) {
+		return errors.New("webhook URL must not point to a private address")
+	}
+	return nil
+}
+
+// IsPublicAddress checks if webhooks may be sent to addr. Loopback, private,
+// link-local and unspecified addresses reach this deployment or the metadata
+// service of its host rather than a partner.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsUnspecified()
+}
+
+// Subscribes checks if the webhook is sent events of eventType
+func (w *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, pattern := range w.EventTypes {
+		if pattern == WebhookAllEvents || pattern == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, ".*"); ok && strings.HasPrefix(eventType, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// isWebhookEventType checks if eventType is a known event type or pattern
+func isWebhookEventType(eventType string) bool {
+	if eventType == WebhookAllEvents {
+		return true
+	}
+	if aggregateType, ok := strings.CutSuffix(eventType, ".*"); ok {
+		_, known := EventTypes[aggregateType]
+		return known
+	}
+	aggregateType, _, _ := strings.Cut(eventType, ".")
+	return IsEventType(aggregateType, eventType)
+}
+
+// WebhookDelivery represents one event posted to one webhook, with the
+// outcome of its last attempt
+type WebhookDelivery struct {
+	ID             int        `json:"id"`
+	SubscriptionID int        `json:"subscription_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"-"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"context"
+)
+
+// AuditRepository defines the interface for the append-only audit log. It has
+// no way to change or remove entries.
+type AuditRepository interface {
+	// Last retrieves the most recently appended entry, or nil if the log is
+	// empty
+	Last(ctx context.Context) (*entities.AuditEntry, error)
+
+	// Append stores a sealed entry. It fails with "audit chain conflict" if
+	// another entry was already appended after the entry's PrevHash.
+	Append(ctx context.Context, entry *entities.AuditEntry) error
+
+	// List retrieves the entries matching filter, newest first
+	List(ctx context.Context, filter entities.AuditFilter, limit, offset int) ([]*entities.AuditEntry, error)
+
+	// Count counts the entries matching filter
+	Count(ctx context.Context, filter entities.AuditFilter) (int64, error)
+
+	// ListAfter retrieves up to limit entries appended after the entry with
+	// afterID, oldest first, to walk the chain
+	ListAfter(ctx context.Context, afterID int, limit int) ([]*entities.AuditEntry, error)
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"context"
+	"time"
+)
+
+// LoginAttemptRepository defines the interface for failed login counters.
+// A single instance can keep them in memory; replicas must share a store so
+// that an attacker cannot spread guesses across them.
+type LoginAttemptRepository interface {
+	// Get retrieves the counter of a subject. A subject without failures has a
+	// zero counter rather than an error.
+	Get(ctx context.Context, subject string) (*entities.LoginAttempts, error)
+
+	// RecordFailure counts a failed attempt at now and returns the updated
+	// counter. If the previous failure is older than window, counting starts
+	// over.
+	RecordFailure(ctx context.Context, subject string, now time.Time, window time.Duration) (*entities.LoginAttempts, error)
+
+	// Lock refuses attempts for a subject until the given time and resets its
+	// failure count
+	Lock(ctx context.Context, subject string, until time.Time) error
+
+	// Reset forgets the failures and lockout of a subject
+	Reset(ctx context.Context, subject string) error
+
+	// DeleteStale removes counters whose last failure and lockout both ended
+	// before the given time
+	DeleteStale(ctx context.Context, before time.Time) (int, error)
+}
+
+// LockoutEventRepository defines the interface for the lockout event record
+type LockoutEventRepository interface {
+	// Create stores a new event
+	Create(ctx context.Context, event *entities.LockoutEvent) error
+
+	// GetByUser retrieves the most recent events of a user, newest first
+	GetByUser(ctx context.Context, userID int, limit int) ([]*entities.LockoutEvent, error)
+}
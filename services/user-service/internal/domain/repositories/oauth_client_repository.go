@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"context"
+	"time"
+)
+
+// OAuthClientRepository defines the interface for OAuth client storage
+type OAuthClientRepository interface {
+	// Create stores a new client
+	Create(ctx context.Context, client *entities.OAuthClient) error
+
+	// GetByClientID retrieves a client by its public client ID
+	GetByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error)
+
+	// GetAll returns every client, in order of registration
+	GetAll(ctx context.Context) ([]*entities.OAuthClient, error)
+
+	// Delete removes a client together with its authorization codes
+	Delete(ctx context.Context, clientID string) error
+}
+
+// AuthorizationCodeRepository defines the interface for authorization code
+// storage
+type AuthorizationCodeRepository interface {
+	// Create stores a new authorization code
+	Create(ctx context.Context, code *entities.AuthorizationCode) error
+
+	// Consume marks the code with the given hash as used and returns it. It
+	// fails with "authorization code already used" if it was used before, so
+	// a code can be exchanged only once even under concurrent requests.
+	Consume(ctx context.Context, codeHash string) (*entities.AuthorizationCode, error)
+
+	// DeleteExpired removes codes that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
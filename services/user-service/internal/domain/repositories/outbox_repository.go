@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"context"
+	"time"
+)
+
+// OutboxRepository defines the interface for the transactional outbox of
+// domain events
+type OutboxRepository interface {
+	// Add stores events to be published, in the transaction of ctx if there
+	// is one. It fails with "event already exists" if an event ID is taken.
+	Add(ctx context.Context, events ...*entities.DomainEvent) error
+
+	// Due retrieves up to limit unpublished events whose next attempt is due
+	// by now, oldest first
+	Due(ctx context.Context, now time.Time, limit int) ([]*entities.OutboxEvent, error)
+
+	// Claim moves the next attempt of a due event to until, so other relays
+	// leave it alone while it is published. It returns false if the event was
+	// published or claimed in the meantime.
+	Claim(ctx context.Context, id string, now, until time.Time) (bool, error)
+
+	// MarkPublished records that the broker accepted an event
+	MarkPublished(ctx context.Context, id string, at time.Time) error
+
+	// MarkFailed records a failed attempt to publish an event and when to try
+	// again
+	MarkFailed(ctx context.Context, id string, reason string, nextAttempt time.Time) error
+
+	// DeletePublished removes events published before the given time
+	DeletePublished(ctx context.Context, before time.Time) (int, error)
+}
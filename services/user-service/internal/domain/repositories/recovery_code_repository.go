@@ -0,0 +1,18 @@
+package repositories
+
+import "context"
+
+// RecoveryCodeRepository defines the interface for MFA recovery code storage.
+// Codes are stored and looked up by hash only.
+type RecoveryCodeRepository interface {
+	// Replace deletes every recovery code of a user and stores the given hashes
+	// in one transaction
+	Replace(ctx context.Context, userID int, codeHashes []string) error
+
+	// Use marks an unused recovery code of a user as used. It fails with
+	// "recovery code not found" if the user has no such unused code.
+	Use(ctx context.Context, userID int, codeHash string) error
+
+	// DeleteForUser removes every recovery code of a user
+	DeleteForUser(ctx context.Context, userID int) error
+}
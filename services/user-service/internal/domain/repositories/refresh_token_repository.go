@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"context"
+	"time"
+)
+
+// RefreshTokenRepository defines the interface for refresh token storage.
+// Tokens are revoked together with their session through SessionRepository.
+type RefreshTokenRepository interface {
+	// Create stores a new refresh token
+	Create(ctx context.Context, token *entities.RefreshToken) error
+
+	// GetByHash retrieves a refresh token by the hash of its value
+	GetByHash(ctx context.Context, tokenHash string) (*entities.RefreshToken, error)
+
+	// Rotate revokes current and stores next in one transaction. It fails with
+	// "refresh token already used" if current was revoked in the meantime.
+	Rotate(ctx context.Context, current, next *entities.RefreshToken) error
+
+	// DeleteExpired removes tokens that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
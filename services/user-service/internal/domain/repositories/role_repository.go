@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"context"
+)
+
+// RoleRepository defines the interface for role and permission data operations
+type RoleRepository interface {
+	// EnsureRoles creates the given roles and their permissions if they do not
+	// exist and adds missing permissions to existing roles
+	EnsureRoles(ctx context.Context, roles []entities.Role, permissionDescriptions map[string]string) error
+
+	// GetAll retrieves every role with its permissions
+	GetAll(ctx context.Context) ([]*entities.Role, error)
+
+	// GetUserRoles retrieves the roles assigned to a user
+	GetUserRoles(ctx context.Context, userID int) ([]*entities.Role, error)
+
+	// GetUserPermissions retrieves the names of every permission a user holds
+	// through its roles
+	GetUserPermissions(ctx context.Context, userID int) ([]string, error)
+
+	// AssignRole gives a role to a user; assigning a role twice is not an error
+	AssignRole(ctx context.Context, userID int, roleName string) error
+
+	// RevokeRole takes a role away from a user
+	RevokeRole(ctx context.Context, userID int, roleName string) error
+}
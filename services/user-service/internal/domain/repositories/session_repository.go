@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"context"
+	"time"
+)
+
+// SessionRepository defines the interface for session storage. Revoking a
+// session also revokes the refresh tokens issued from it.
+type SessionRepository interface {
+	// Create stores a new session
+	Create(ctx context.Context, session *entities.Session) error
+
+	// GetByID retrieves a session, revoked or not
+	GetByID(ctx context.Context, id string) (*entities.Session, error)
+
+	// GetActiveByUser returns the sessions of a user that are active at now,
+	// most recently used first
+	GetActiveByUser(ctx context.Context, userID int, now time.Time) ([]*entities.Session, error)
+
+	// Touch records that a session was used at the given time
+	Touch(ctx context.Context, id string, usedAt time.Time) error
+
+	// Extend records that a session was used and moves its expiry, when its
+	// refresh token is rotated
+	Extend(ctx context.Context, id string, usedAt, expiresAt time.Time) error
+
+	// Revoke revokes a session and its refresh tokens. Revoking a session
+	// that is already revoked is not an error.
+	Revoke(ctx context.Context, id string) error
+
+	// RevokeAllForUser revokes every session and refresh token of a user and
+	// returns the number of sessions revoked
+	RevokeAllForUser(ctx context.Context, userID int) (int, error)
+
+	// DeleteExpired removes sessions that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import "context"
+
+// TxManager runs changes made through several repositories in one
+// transaction
+type TxManager interface {
+	// WithinTx runs fn in a transaction that repository calls made with the
+	// context passed to fn take part in. The transaction is committed if fn
+	// returns nil and rolled back otherwise. A WithinTx inside another one
+	// joins the outer transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
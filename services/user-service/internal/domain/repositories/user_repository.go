@@ -3,8 +3,23 @@ package repositories
 import (
 	"app-microservice/services/user-service/internal/domain/entities"
 	"context"
+	"time"
 )
 
+type includeDeletedKey struct{}
+
+// WithDeleted returns a context in which repository reads also return
+// soft-deleted users
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// IncludesDeleted reports whether ctx was created by WithDeleted
+func IncludesDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
+}
+
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
 	// Create creates a new user
@@ -19,11 +34,60 @@ type UserRepository interface {
 	// GetAll retrieves all users with pagination
 	GetAll(ctx context.Context, limit, offset int) ([]*entities.User, error)
 
-	// Update updates an existing user
+	// Update updates an existing user if its version still matches the stored
+	// one and increments the version. A pending StatusChange of the user is
+	// stored in its status history along with the update.
 	Update(ctx context.Context, user *entities.User) error
 
-	// Delete deletes a user by ID
-	Delete(ctx context.Context, id int) error
+	// UpdatePasswordHash replaces the stored password hash. The version is left
+	// alone because the hash is not part of the user's representation.
+	UpdatePasswordHash(ctx context.Context, id int, passwordHash string) error
+
+	// ChangePassword replaces the stored password hash and records the time of
+	// the change, which invalidates access tokens issued before it
+	ChangePassword(ctx context.Context, id int, passwordHash string) error
+
+	// UpdateMFA stores the encrypted TOTP secret of a user and when MFA was
+	// enabled, nil while enrolment is unconfirmed. An empty secret turns MFA
+	// off. The last accepted time step is reset either way.
+	UpdateMFA(ctx context.Context, id int, secret string, enabledAt *time.Time) error
+
+	// UseMFAStep records the time step of an accepted TOTP code. It fails with
+	// "mfa code already used" unless step is later than the last recorded one,
+	// so each code is accepted only once.
+	UseMFAStep(ctx context.Context, id int, step int64) error
+
+	// Lock sets the status of an active user to locked until the given time,
+	// increments the version and records the change with reason. It fails
+	// with "user is not active" if the user is not active.
+	Lock(ctx context.Context, id int, until time.Time, reason string) error
+
+	// Unlock makes a locked user active again, increments the version and
+	// records the change with reason and the admin doing it, nil if automatic.
+	// It fails with "user is not locked" if the user is not locked.
+	Unlock(ctx context.Context, id int, reason string, actorID *int) error
+
+	// LiftSuspension makes a user whose timed suspension ended by now active
+	// again, increments the version and records the change with reason. It
+	// returns false if the user is no longer suspended until then.
+	LiftSuspension(ctx context.Context, id int, now time.Time, reason string) (bool, error)
+
+	// GetExpiredSuspensions retrieves up to limit users whose timed suspension
+	// ended by now
+	GetExpiredSuspensions(ctx context.Context, now time.Time, limit int) ([]*entities.User, error)
+
+	// GetStatusHistory retrieves up to limit of the most recent status changes
+	// of a user, newest first
+	GetStatusHistory(ctx context.Context, id int, limit int) ([]*entities.UserStatusChange, error)
+
+	// Delete soft-deletes a user by ID if it is still at the given version
+	Delete(ctx context.Context, id int, version int) error
+
+	// Restore undoes the soft delete of a user
+	Restore(ctx context.Context, id int) error
+
+	// PurgeDeleted permanently removes users soft-deleted before the given time
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
 
 	// Count returns the total number of users
 	Count(ctx context.Context) (int64, error)
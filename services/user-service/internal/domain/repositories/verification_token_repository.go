@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"context"
+	"time"
+)
+
+// VerificationTokenRepository defines the interface for verification token storage
+type VerificationTokenRepository interface {
+	// Create stores a new token and marks every unused token of the same user
+	// and purpose as used, so only the latest token sent is valid
+	Create(ctx context.Context, token *entities.VerificationToken) error
+
+	// GetByHash retrieves a token by the hash of its value
+	GetByHash(ctx context.Context, tokenHash string) (*entities.VerificationToken, error)
+
+	// GetLatest retrieves the most recently created token of a user for a purpose
+	GetLatest(ctx context.Context, userID int, purpose entities.TokenPurpose) (*entities.VerificationToken, error)
+
+	// MarkUsed marks a token as used. It fails with "verification token already
+	// used" if the token was used in the meantime.
+	MarkUsed(ctx context.Context, id int) error
+
+	// RecordFailedAttempt counts a wrong code presented with a token and marks
+	// the token used once maxAttempts is reached
+	RecordFailedAttempt(ctx context.Context, id int, maxAttempts int) error
+
+	// DeleteExpired removes tokens that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"context"
+	"time"
+)
+
+// WebhookSubscriptionRepository defines the interface for webhook storage
+type WebhookSubscriptionRepository interface {
+	// Create stores a new webhook
+	Create(ctx context.Context, webhook *entities.WebhookSubscription) error
+
+	// GetByID retrieves a webhook by ID
+	GetByID(ctx context.Context, id int) (*entities.WebhookSubscription, error)
+
+	// GetAll returns every webhook, in order of creation
+	GetAll(ctx context.Context) ([]*entities.WebhookSubscription, error)
+
+	// GetActive returns the webhooks events are delivered to
+	GetActive(ctx context.Context) ([]*entities.WebhookSubscription, error)
+
+	// Update saves the URL, event types, secret, description and active flag
+	// of a webhook
+	Update(ctx context.Context, webhook *entities.WebhookSubscription) error
+
+	// Delete removes a webhook together with its deliveries
+	Delete(ctx context.Context, id int) error
+}
+
+// WebhookDeliveryRepository defines the interface for webhook delivery storage
+type WebhookDeliveryRepository interface {
+	// Enqueue stores deliveries and returns how many were new. A delivery of
+	// an event already queued for the same webhook is skipped, so an event
+	// received twice is posted once.
+	Enqueue(ctx context.Context, deliveries ...*entities.WebhookDelivery) (int, error)
+
+	// GetByID retrieves a delivery by ID
+	GetByID(ctx context.Context, id int) (*entities.WebhookDelivery, error)
+
+	// List returns the deliveries of a webhook, newest first, optionally only
+	// those with status, and their total count
+	List(ctx context.Context, subscriptionID int, status string, offset, limit int) ([]*entities.WebhookDelivery, int64, error)
+
+	// Due retrieves pending deliveries whose next attempt is due, oldest first
+	Due(ctx context.Context, now time.Time, limit int) ([]*entities.WebhookDelivery, error)
+
+	// Claim moves the next attempt of a due delivery to until so no other
+	// worker posts it meanwhile. It reports false if another worker claimed
+	// it first.
+	Claim(ctx context.Context, id int, now, until time.Time) (bool, error)
+
+	// SaveAttempt stores the outcome of an attempt: the status, attempts, next
+	// attempt and last attempt fields of delivery
+	SaveAttempt(ctx context.Context, delivery *entities.WebhookDelivery) error
+
+	// Redeliver makes a delivery pending again with no attempts, due at now
+	Redeliver(ctx context.Context, id int, now time.Time) error
+
+	// DeleteFinished removes succeeded and dead deliveries queued before the
+	// given time
+	DeleteFinished(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,17 @@
+package services
+
+import "time"
+
+// Clock tells the current time. Code that depends on the time of day, such as
+// TOTP validation, takes a Clock so tests can pin it.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by the system time
+type SystemClock struct{}
+
+// Now returns the current system time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"app-microservice/services/user-service/internal/domain/entities"
+	"context"
+)
+
+// EventBroker publishes domain events to other services
+type EventBroker interface {
+	// Publish delivers event or returns an error if the broker did not accept
+	// it. A failed event is published again later, and an event can also be
+	// published again after the broker accepted it, so consumers must skip
+	// event IDs they have already handled.
+	Publish(ctx context.Context, event *entities.DomainEvent) error
+}
+
+// WebhookRequest is one signed POST of an event to a webhook
+type WebhookRequest struct {
+	URL        string
+	Secret     string
+	DeliveryID int
+	EventID    string
+	EventType  string
+	Payload    []byte
+}
+
+// WebhookSender posts events to webhooks
+type WebhookSender interface {
+	// Send posts request and returns the HTTP status of the response, or 0 if
+	// there was none. It returns an error unless the webhook answered with a
+	// 2xx status.
+	Send(ctx context.Context, request WebhookRequest) (int, error)
+}
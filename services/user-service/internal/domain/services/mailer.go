@@ -0,0 +1,16 @@
+package services
+
+import "context"
+
+// EmailMessage is a plain text email to a single recipient
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email
+type Mailer interface {
+	// Send delivers message or returns an error if it could not be handed off
+	Send(ctx context.Context, message EmailMessage) error
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TOTP generates and validates RFC 6238 time-based one-time passwords
+type TOTP interface {
+	// GenerateSecret returns a new random base32 encoded shared secret
+	GenerateSecret() (string, error)
+
+	// ProvisioningURI returns the otpauth:// URI an authenticator app imports,
+	// usually by scanning it as a QR code
+	ProvisioningURI(secret, accountName string) string
+
+	// GenerateCode returns the code for secret at the given time
+	GenerateCode(secret string, at time.Time) (string, error)
+
+	// Validate checks code against secret around the current time, allowing
+	// one step of clock drift either way, and returns the time step it matched
+	Validate(secret, code string) (step int64, ok bool)
+}
+
+// SecretBox encrypts secrets that must be stored but can be read back, such
+// as TOTP secrets
+type SecretBox interface {
+	// Seal encrypts plaintext and returns it in a printable encoding
+	Seal(plaintext string) (string, error)
+
+	// Open decrypts a value returned by Seal
+	Open(sealed string) (string, error)
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCode returns a random recovery code of the form
+// xxxxx-xxxxx together with the hash that is stored in its place
+func GenerateRecoveryCode() (string, string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+	code := encoded[:5] + "-" + encoded[5:]
+	return code, HashRecoveryCode(code), nil
+}
+
+// HashRecoveryCode hashes a recovery code for storage and lookup. Case,
+// spaces and dashes are ignored so codes can be typed as they are read.
+func HashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, code)
+	return HashOpaqueToken(normalized)
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"time"
+)
+
+// OAuthAccessClaims are the claims of an access token issued to an OAuth
+// client. Subject is the user ID for the authorization code flow and the
+// client ID for client credentials.
+type OAuthAccessClaims struct {
+	Subject   string
+	ClientID  string
+	Scope     string // space-separated
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// IDTokenClaims are the claims of an OpenID Connect ID token. Name, Email and
+// EmailVerified are included only when the matching scope was granted.
+type IDTokenClaims struct {
+	Subject       string
+	Audience      string // client ID
+	Nonce         string
+	AuthTime      time.Time
+	Name          string
+	Email         string
+	EmailVerified *bool
+}
+
+// OIDCTokenIssuer mints the tokens of the OpenID Connect provider
+type OIDCTokenIssuer interface {
+	// IssueAccessToken returns a signed access token for an OAuth client and
+	// its expiry
+	IssueAccessToken(claims OAuthAccessClaims) (string, time.Time, error)
+
+	// VerifyAccessToken checks an access token issued by IssueAccessToken and
+	// returns its claims. Access tokens of the user API are rejected.
+	VerifyAccessToken(token string) (*OAuthAccessClaims, error)
+
+	// IssueIDToken returns a signed ID token
+	IssueIDToken(claims IDTokenClaims) (string, error)
+}
+
+// VerifyPKCE checks a PKCE code verifier against an S256 code challenge
+// (RFC 7636)
+func VerifyPKCE(verifier, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrWeakPassword is returned when a password does not satisfy the password policy
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// maxPasswordLength caps the input to the hash function so that very long
+// passwords cannot be used to tie up the CPU
+const maxPasswordLength = 128
+
+// PasswordHasher hashes and verifies user passwords
+type PasswordHasher interface {
+	// Hash returns an encoded hash of password that embeds its salt and parameters
+	Hash(password string) (string, error)
+
+	// Verify reports whether password matches the encoded hash and whether the
+	// hash was made with parameters other than the current ones and should be
+	// replaced. The comparison runs in constant time.
+	Verify(password, encoded string) (match bool, needsRehash bool, err error)
+}
+
+// PasswordPolicy describes the rules a new password must follow
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// Validate checks password against the policy
+func (p PasswordPolicy) Validate(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%w: password must be at least %d characters", ErrWeakPassword, p.MinLength)
+	}
+	if length > maxPasswordLength {
+		return fmt.Errorf("%w: password must not exceed %d characters", ErrWeakPassword, maxPasswordLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	var missing []string
+	if p.RequireUpper && !hasUpper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: password must contain %s", ErrWeakPassword, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
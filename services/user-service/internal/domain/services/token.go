@@ -0,0 +1,68 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrInvalidToken is returned for access tokens that are malformed, expired or
+// not signed by one of the service's keys
+var ErrInvalidToken = errors.New("invalid token")
+
+// AccessClaims are the claims carried by an access token
+type AccessClaims struct {
+	UserID    int
+	Email     string
+	SessionID string // refresh token family the access token was issued from
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// AccessTokenIssuer mints and verifies short-lived access tokens
+type AccessTokenIssuer interface {
+	// Issue returns a signed access token for the claims and its expiry
+	Issue(claims AccessClaims) (string, time.Time, error)
+
+	// Verify checks the signature and expiry of token and returns its claims
+	Verify(token string) (*AccessClaims, error)
+
+	// JWKS returns the public keys access tokens can be verified with
+	JWKS() JSONWebKeySet
+}
+
+// JSONWebKey is the public part of a signing key as published in a JWKS (RFC 7517)
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// GenerateOpaqueToken returns a random URL-safe token together with the hash
+// that is stored in its place
+func GenerateOpaqueToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, HashOpaqueToken(token), nil
+}
+
+// HashOpaqueToken hashes a token from GenerateOpaqueToken for storage and
+// lookup. A fast hash is enough because the token carries 256 bits of entropy.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
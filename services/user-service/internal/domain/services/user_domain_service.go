@@ -46,9 +46,9 @@ func (s *userDomainService) ValidateUserCreation(ctx context.Context, user *enti
 		return errors.New("email already exists")
 	}
 
-	// Set default status if not provided
+	// New users stay pending until they verify their email
 	if user.Status == "" {
-		user.Status = string(entities.UserStatusActive)
+		user.Status = string(entities.UserStatusPendingVerification)
 	}
 
 	return nil
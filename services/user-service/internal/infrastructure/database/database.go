@@ -3,11 +3,14 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"app-microservice/services/user-service/ent"
+	_ "app-microservice/services/user-service/ent/runtime" // registers schema hooks and interceptors
 	"app-microservice/services/user-service/ent/user"
 	"app-microservice/services/user-service/internal/domain/entities"
 
@@ -107,14 +110,14 @@ func NewConnection(config *Config) (*ent.Client, error) {
 // AutoMigrate runs database migrations
 func AutoMigrate(ctx context.Context, client *ent.Client) error {
 	log.Println("Starting database migration...")
-	
+
 	// Log the schema that will be created
 	log.Println("Creating/updating schema for entities: User")
-	
+
 	// Run the actual migration
 	migrationStart := time.Now()
 	err := client.Schema.Create(ctx)
-	
+
 	if err != nil {
 		log.Printf("Failed to create database schema: %v", err)
 		return fmt.Errorf("failed to create database schema: %w", err)
@@ -187,13 +190,22 @@ func SeedData(ctx context.Context, client *ent.Client) error {
 // EntUserToDomainUser converts Ent User to Domain User
 func EntUserToDomainUser(entUser *ent.User) *entities.User {
 	return &entities.User{
-		ID:        entUser.ID,
-		Name:      entUser.Name,
-		Email:     entUser.Email,
-		Phone:     entUser.Phone,
-		Status:    string(entUser.Status),
-		CreatedAt: entUser.CreatedAt,
-		UpdatedAt: entUser.UpdatedAt,
+		ID:                entUser.ID,
+		Name:              entUser.Name,
+		Email:             entUser.Email,
+		Phone:             entUser.Phone,
+		Status:            string(entUser.Status),
+		Version:           entUser.Version,
+		PasswordHash:      entUser.PasswordHash,
+		PasswordChangedAt: entUser.PasswordChangedAt,
+		MFASecret:         entUser.MfaSecret,
+		MFAEnabledAt:      entUser.MfaEnabledAt,
+		MFALastStep:       entUser.MfaLastStep,
+		LockedUntil:       entUser.LockedUntil,
+		SuspendedUntil:    entUser.SuspendedUntil,
+		CreatedAt:         entUser.CreatedAt,
+		UpdatedAt:         entUser.UpdatedAt,
+		DeletedAt:         entUser.DeletedAt,
 	}
 }
 
@@ -207,6 +219,221 @@ func DomainUserToEntCreate(client *ent.Client, domainUser *entities.User) *ent.U
 	if domainUser.Phone != "" {
 		create = create.SetPhone(domainUser.Phone)
 	}
+	if domainUser.PasswordHash != "" {
+		create = create.SetPasswordHash(domainUser.PasswordHash)
+	}
 
 	return create
 }
+
+// EntRefreshTokenToDomain converts Ent RefreshToken to domain RefreshToken
+func EntRefreshTokenToDomain(entToken *ent.RefreshToken) *entities.RefreshToken {
+	return &entities.RefreshToken{
+		ID:        entToken.ID,
+		UserID:    entToken.UserID,
+		FamilyID:  entToken.FamilyID,
+		TokenHash: entToken.TokenHash,
+		ExpiresAt: entToken.ExpiresAt,
+		RevokedAt: entToken.RevokedAt,
+		CreatedAt: entToken.CreatedAt,
+	}
+}
+
+// EntSessionToDomain converts Ent Session to domain Session
+func EntSessionToDomain(entSession *ent.Session) *entities.Session {
+	return &entities.Session{
+		ID:         entSession.ID,
+		UserID:     entSession.UserID,
+		UserAgent:  entSession.UserAgent,
+		IP:         entSession.IP,
+		CreatedAt:  entSession.CreatedAt,
+		LastUsedAt: entSession.LastUsedAt,
+		ExpiresAt:  entSession.ExpiresAt,
+		RevokedAt:  entSession.RevokedAt,
+	}
+}
+
+// EntOAuthClientToDomain converts Ent OAuthClient to domain OAuthClient
+func EntOAuthClientToDomain(entClient *ent.OAuthClient) *entities.OAuthClient {
+	return &entities.OAuthClient{
+		ID:           entClient.ID,
+		ClientID:     entClient.ClientID,
+		Name:         entClient.Name,
+		SecretHash:   entClient.SecretHash,
+		RedirectURIs: entClient.RedirectUris,
+		GrantTypes:   entClient.GrantTypes,
+		Scopes:       entClient.Scopes,
+		CreatedAt:    entClient.CreatedAt,
+	}
+}
+
+// EntAuthorizationCodeToDomain converts Ent AuthorizationCode to domain
+// AuthorizationCode
+func EntAuthorizationCodeToDomain(entCode *ent.AuthorizationCode) *entities.AuthorizationCode {
+	return &entities.AuthorizationCode{
+		ID:            entCode.ID,
+		ClientID:      entCode.ClientID,
+		UserID:        entCode.UserID,
+		CodeHash:      entCode.CodeHash,
+		RedirectURI:   entCode.RedirectURI,
+		Scope:         entCode.Scope,
+		Nonce:         entCode.Nonce,
+		CodeChallenge: entCode.CodeChallenge,
+		AuthTime:      entCode.AuthTime,
+		ExpiresAt:     entCode.ExpiresAt,
+		UsedAt:        entCode.UsedAt,
+		CreatedAt:     entCode.CreatedAt,
+	}
+}
+
+// EntRolesToDomain converts Ent Roles, loaded with their permissions, to
+// domain Roles
+func EntRolesToDomain(entRoles []*ent.Role) []*entities.Role {
+	roles := make([]*entities.Role, len(entRoles))
+	for i, entRole := range entRoles {
+		permissions := make([]string, len(entRole.Edges.Permissions))
+		for j, entPermission := range entRole.Edges.Permissions {
+			permissions[j] = entPermission.Name
+		}
+		sort.Strings(permissions)
+
+		roles[i] = &entities.Role{
+			ID:          entRole.ID,
+			Name:        entRole.Name,
+			Description: entRole.Description,
+			Permissions: permissions,
+		}
+	}
+	return roles
+}
+
+// EntVerificationTokenToDomain converts Ent VerificationToken to domain VerificationToken
+func EntVerificationTokenToDomain(entToken *ent.VerificationToken) *entities.VerificationToken {
+	return &entities.VerificationToken{
+		ID:             entToken.ID,
+		UserID:         entToken.UserID,
+		Purpose:        entities.TokenPurpose(entToken.Purpose),
+		TokenHash:      entToken.TokenHash,
+		ExpiresAt:      entToken.ExpiresAt,
+		UsedAt:         entToken.UsedAt,
+		FailedAttempts: entToken.FailedAttempts,
+		CreatedAt:      entToken.CreatedAt,
+	}
+}
+
+// EntLoginAttemptToDomain converts Ent LoginAttempt to domain LoginAttempts
+func EntLoginAttemptToDomain(entAttempt *ent.LoginAttempt) *entities.LoginAttempts {
+	return &entities.LoginAttempts{
+		Subject:       entAttempt.Subject,
+		Failures:      entAttempt.Failures,
+		LastFailureAt: entAttempt.LastFailureAt,
+		LockedUntil:   entAttempt.LockedUntil,
+	}
+}
+
+// EntLockoutEventToDomain converts Ent LockoutEvent to domain LockoutEvent
+func EntLockoutEventToDomain(entEvent *ent.LockoutEvent) *entities.LockoutEvent {
+	return &entities.LockoutEvent{
+		ID:          entEvent.ID,
+		UserID:      entEvent.UserID,
+		Subject:     entEvent.Subject,
+		Action:      entities.LockoutAction(entEvent.Action),
+		Reason:      entEvent.Reason,
+		IP:          entEvent.IP,
+		Failures:    entEvent.Failures,
+		LockedUntil: entEvent.LockedUntil,
+		ActorID:     entEvent.ActorID,
+		CreatedAt:   entEvent.CreatedAt,
+	}
+}
+
+// EntUserStatusHistoryToDomain converts Ent UserStatusHistory to a domain
+// UserStatusChange
+func EntUserStatusHistoryToDomain(entChange *ent.UserStatusHistory) *entities.UserStatusChange {
+	return &entities.UserStatusChange{
+		ID:             entChange.ID,
+		UserID:         entChange.UserID,
+		FromStatus:     entities.UserStatus(entChange.FromStatus),
+		ToStatus:       entities.UserStatus(entChange.ToStatus),
+		Reason:         entChange.Reason,
+		SuspendedUntil: entChange.SuspendedUntil,
+		ActorID:        entChange.ActorID,
+		CreatedAt:      entChange.CreatedAt,
+	}
+}
+
+// EntOutboxEventToDomain converts Ent OutboxEvent to domain OutboxEvent
+func EntOutboxEventToDomain(entEvent *ent.OutboxEvent) *entities.OutboxEvent {
+	return &entities.OutboxEvent{
+		DomainEvent: entities.DomainEvent{
+			ID:            entEvent.ID,
+			Type:          entEvent.EventType,
+			AggregateType: entEvent.AggregateType,
+			AggregateID:   entEvent.AggregateID,
+			Data:          json.RawMessage(entEvent.Data),
+			OccurredAt:    entEvent.OccurredAt,
+		},
+		Attempts:      entEvent.Attempts,
+		LastError:     entEvent.LastError,
+		NextAttemptAt: entEvent.NextAttemptAt,
+		PublishedAt:   entEvent.PublishedAt,
+	}
+}
+
+// EntAuditEntryToDomain converts Ent AuditEntry to domain AuditEntry. Changes
+// that do not decode are left out; the entry then fails verification, since
+// they are part of its hash.
+func EntAuditEntryToDomain(entEntry *ent.AuditEntry) *entities.AuditEntry {
+	entry := &entities.AuditEntry{
+		ID:         entEntry.ID,
+		ActorID:    entEntry.ActorID,
+		ActorEmail: entEntry.ActorEmail,
+		Action:     entEntry.Action,
+		TargetType: entEntry.TargetType,
+		TargetID:   entEntry.TargetID,
+		RequestID:  entEntry.RequestID,
+		IP:         entEntry.IP,
+		CreatedAt:  entEntry.CreatedAt,
+		PrevHash:   entEntry.PrevHash,
+		Hash:       entEntry.Hash,
+	}
+	if entEntry.Changes != "" {
+		_ = json.Unmarshal([]byte(entEntry.Changes), &entry.Changes)
+	}
+	return entry
+}
+
+// EntWebhookSubscriptionToDomain converts Ent WebhookSubscription to domain
+// WebhookSubscription. The secret stays sealed.
+func EntWebhookSubscriptionToDomain(entWebhook *ent.WebhookSubscription) *entities.WebhookSubscription {
+	return &entities.WebhookSubscription{
+		ID:          entWebhook.ID,
+		URL:         entWebhook.URL,
+		EventTypes:  entWebhook.EventTypes,
+		Secret:      entWebhook.Secret,
+		Description: entWebhook.Description,
+		Active:      entWebhook.Active,
+		CreatedAt:   entWebhook.CreatedAt,
+		UpdatedAt:   entWebhook.UpdatedAt,
+	}
+}
+
+// EntWebhookDeliveryToDomain converts Ent WebhookDelivery to domain
+// WebhookDelivery
+func EntWebhookDeliveryToDomain(entDelivery *ent.WebhookDelivery) *entities.WebhookDelivery {
+	return &entities.WebhookDelivery{
+		ID:             entDelivery.ID,
+		SubscriptionID: entDelivery.SubscriptionID,
+		EventID:        entDelivery.EventID,
+		EventType:      entDelivery.EventType,
+		Payload:        entDelivery.Payload,
+		Status:         string(entDelivery.Status),
+		Attempts:       entDelivery.Attempts,
+		NextAttemptAt:  entDelivery.NextAttemptAt,
+		LastAttemptAt:  entDelivery.LastAttemptAt,
+		LastStatusCode: entDelivery.LastStatusCode,
+		LastError:      entDelivery.LastError,
+		DeliveredAt:    entDelivery.DeliveredAt,
+		CreatedAt:      entDelivery.CreatedAt,
+	}
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"app-microservice/services/user-service/internal/domain/entities"
+)
+
+// AllEvents subscribes a handler to every event type
+const AllEvents = "*"
+
+// Handler consumes a domain event. It can see the same event more than once
+// and should skip IDs it has already handled.
+type Handler func(ctx context.Context, event *entities.DomainEvent) error
+
+// MemoryBroker implements services.EventBroker by handing events to handlers
+// in the same process. Publish fails if any handler fails, so the outbox
+// retries the event for all of its handlers.
+type MemoryBroker struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewMemoryBroker creates a broker without subscribers
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe calls handler for each published event of eventType, or for every
+// event if eventType is AllEvents
+func (b *MemoryBroker) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish calls the handlers subscribed to event in turn
+func (b *MemoryBroker) Publish(ctx context.Context, event *entities.DomainEvent) error {
+	b.mu.RLock()
+	handlers := append(append([]Handler(nil), b.handlers[event.Type]...), b.handlers[AllEvents]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("event handlers failed: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"app-microservice/services/user-service/internal/domain/entities"
+)
+
+// NATSConfig configures the NATS broker
+type NATSConfig struct {
+	URL           string // nats://[user:password@]host:port
+	SubjectPrefix string // events are published to <prefix>.<event type>
+	Name          string // client name shown by the server
+	Timeout       time.Duration
+}
+
+// NATSBroker implements services.EventBroker by publishing to a NATS server.
+// Each event is sent as JSON with its ID in the Nats-Msg-Id header, which
+// JetStream streams use to drop duplicates, and followed by a PING so Publish
+// only returns once the server has processed the message. The connection is
+// opened on first use and again after any error.
+type NATSBroker struct {
+	config  NATSConfig
+	address string
+	user    *url.Userinfo
+	mu      sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+}
+
+// natsInfo is the part of the server's INFO message the broker uses
+type natsInfo struct {
+	Headers bool `json:"headers"`
+}
+
+// natsConnect is the CONNECT message sent to the server
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Headers  bool   `json:"headers"`
+	Name     string `json:"name,omitempty"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	Protocol int    `json:"protocol"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+}
+
+// NewNATSBroker creates a broker for the server at config.URL
+func NewNATSBroker(config NATSConfig) (*NATSBroker, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil || u.Scheme != "nats" || u.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q", config.URL)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	if config.SubjectPrefix == "" {
+		config.SubjectPrefix = "events"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &NATSBroker{config: config, address: address, user: u.User}, nil
+}
+
+// Publish sends event to the subject <prefix>.<event type>
+func (b *NATSBroker) Publish(ctx context.Context, event *entities.DomainEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	headers := "NATS/1.0\r\nNats-Msg-Id: " + event.ID + "\r\nEvent-Type: " + event.Type + "\r\n\r\n"
+	subject := b.config.SubjectPrefix + "." + event.Type
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "HPUB %s %d %d\r\n%s", subject, len(headers), len(headers)+len(payload), headers)
+	msg.Write(payload)
+	msg.WriteString("\r\nPING\r\n")
+
+	ctx, cancel := context.WithTimeout(ctx, b.config.Timeout)
+	defer cancel()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn == nil {
+		if err := b.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = b.conn.SetDeadline(deadline)
+	}
+	if _, err := b.conn.Write(msg.Bytes()); err != nil {
+		b.closeConn()
+		return fmt.Errorf("failed to send event to NATS: %w", err)
+	}
+	if err := b.awaitPong(); err != nil {
+		b.closeConn()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection to the server, if any
+func (b *NATSBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closeConn()
+	return nil
+}
+
+// connect dials the server and completes the handshake
+func (b *NATSBroker) connect(ctx context.Context) error {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", b.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	b.conn = conn
+	b.reader = bufio.NewReader(conn)
+
+	line, err := b.readLine()
+	if err != nil {
+		b.closeConn()
+		return err
+	}
+	var info natsInfo
+	if !strings.HasPrefix(line, "INFO ") || json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info) != nil {
+		b.closeConn()
+		return fmt.Errorf("unexpected NATS greeting %q", line)
+	}
+	if !info.Headers {
+		b.closeConn()
+		return errors.New("NATS server does not support headers")
+	}
+
+	connect := natsConnect{Headers: true, Name: b.config.Name, Lang: "go", Version: "1.0.0", Protocol: 1}
+	if b.user != nil {
+		connect.User = b.user.Username()
+		connect.Pass, _ = b.user.Password()
+	}
+	data, _ := json.Marshal(connect)
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", data); err != nil {
+		b.closeConn()
+		return fmt.Errorf("failed to send NATS CONNECT: %w", err)
+	}
+	if err := b.awaitPong(); err != nil {
+		b.closeConn()
+		return err
+	}
+	return nil
+}
+
+// awaitPong reads until the server answers a PING, failing on -ERR
+func (b *NATSBroker) awaitPong() error {
+	for {
+		line, err := b.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := b.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("failed to answer NATS PING: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		case line == "+OK" || strings.HasPrefix(line, "INFO "):
+			// Acknowledgements and cluster updates need no answer
+		default:
+			return fmt.Errorf("unexpected NATS message %q", line)
+		}
+	}
+}
+
+// readLine reads one protocol line without its CRLF
+func (b *NATSBroker) readLine() (string, error) {
+	line, err := b.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read from NATS server: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// closeConn drops the connection so the next Publish opens a new one
+func (b *NATSBroker) closeConn() {
+	if b.conn != nil {
+		b.conn.Close()
+		b.conn = nil
+		b.reader = nil
+	}
+}
@@ -0,0 +1,66 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/pkg/logger"
+)
+
+// fileMailer implements services.Mailer for local development by writing each
+// email to a .eml file instead of delivering it
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates a mailer that writes emails into dir
+func NewFileMailer(dir, from string) (services.Mailer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &fileMailer{dir: dir, from: from}, nil
+}
+
+// Send writes message to a new file named after the time and recipient
+func (m *fileMailer) Send(ctx context.Context, message services.EmailMessage) error {
+	data, err := formatMessage(m.from, message)
+	if err != nil {
+		return err
+	}
+
+	recipient := strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, message.To)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), recipient)
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	logger.Infof("Email to %s written to %s", message.To, path)
+	return nil
+}
+
+// logMailer implements services.Mailer for local development by logging each
+// email, body included, instead of delivering it
+type logMailer struct{}
+
+// NewLogMailer creates a mailer that logs emails
+func NewLogMailer() services.Mailer {
+	return logMailer{}
+}
+
+// Send logs message
+func (logMailer) Send(ctx context.Context, message services.EmailMessage) error {
+	logger.Infof("Email to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
@@ -0,0 +1,51 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"app-microservice/services/user-service/internal/domain/services"
+)
+
+var errHeaderInjection = errors.New("email header contains a line break")
+
+// formatMessage renders message as an RFC 5322 plain text email from from
+func formatMessage(from string, message services.EmailMessage) ([]byte, error) {
+	if strings.ContainsAny(message.To+message.Subject+from, "\r\n") {
+		return nil, errHeaderInjection
+	}
+	if _, err := mail.ParseAddress(message.To); err != nil {
+		return nil, fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender: %w", err)
+	}
+	domain := sender.Address[strings.LastIndex(sender.Address, "@")+1:]
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return buf.Bytes(), nil
+}
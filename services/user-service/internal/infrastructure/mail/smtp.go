@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"app-microservice/services/user-service/internal/domain/services"
+)
+
+// SMTPConfig configures the SMTP mailer
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// smtpMailer implements services.Mailer by relaying through an SMTP server.
+// STARTTLS is used whenever the server offers it and is required before
+// authenticating.
+type smtpMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer creates a mailer that relays through the configured server
+func NewSMTPMailer(config SMTPConfig) services.Mailer {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &smtpMailer{config: config}
+}
+
+// Send delivers message through the SMTP server
+func (m *smtpMailer) Send(ctx context.Context, message services.EmailMessage) error {
+	data, err := formatMessage(m.config.From, message)
+	if err != nil {
+		return err
+	}
+	sender, _ := mail.ParseAddress(m.config.From)
+	recipient, _ := mail.ParseAddress(message.To)
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.config.Host, m.config.Port))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if m.config.Username != "" {
+		// smtp.PlainAuth refuses to send credentials over an unencrypted
+		// connection to anything but localhost
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate with SMTP server: %w", err)
+		}
+	}
+
+	if err := client.Mail(sender.Address); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		return fmt.Errorf("SMTP RCPT TO failed: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return client.Quit()
+}
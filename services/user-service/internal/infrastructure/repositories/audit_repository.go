@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/auditentry"
+	"app-microservice/services/user-service/ent/predicate"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// auditRepository implements the AuditRepository interface using Ent
+type auditRepository struct {
+	client *ent.Client
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(client *ent.Client) repositories.AuditRepository {
+	return &auditRepository{
+		client: client,
+	}
+}
+
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *auditRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
+// Last retrieves the most recently appended entry
+func (r *auditRepository) Last(ctx context.Context) (*entities.AuditEntry, error) {
+	entEntry, err := r.db(ctx).AuditEntry.Query().
+		Order(ent.Desc(auditentry.FieldID)).
+		First(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get last audit entry: %w", err)
+	}
+	return database.EntAuditEntryToDomain(entEntry), nil
+}
+
+// Append stores a sealed entry
+func (r *auditRepository) Append(ctx context.Context, entry *entities.AuditEntry) error {
+	changes, err := entry.ChangesJSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	entEntry, err := r.db(ctx).AuditEntry.Create().
+		SetNillableActorID(entry.ActorID).
+		SetActorEmail(entry.ActorEmail).
+		SetAction(entry.Action).
+		SetTargetType(entry.TargetType).
+		SetTargetID(entry.TargetID).
+		SetChanges(changes).
+		SetRequestID(entry.RequestID).
+		SetIP(entry.IP).
+		SetCreatedAt(entry.CreatedAt).
+		SetPrevHash(entry.PrevHash).
+		SetHash(entry.Hash).
+		Save(ctx)
+	if err != nil {
+		if ent.IsConstraintError(err) {
+			return errors.New("audit chain conflict")
+		}
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+
+	entry.ID = entEntry.ID
+	return nil
+}
+
+// List retrieves the entries matching filter, newest first
+func (r *auditRepository) List(ctx context.Context, filter entities.AuditFilter, limit, offset int) ([]*entities.AuditEntry, error) {
+	entEntries, err := r.db(ctx).AuditEntry.Query().
+		Where(auditPredicates(filter)...).
+		Order(ent.Desc(auditentry.FieldID)).
+		Limit(limit).
+		Offset(offset).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return toDomainAuditEntries(entEntries), nil
+}
+
+// Count counts the entries matching filter
+func (r *auditRepository) Count(ctx context.Context, filter entities.AuditFilter) (int64, error) {
+	count, err := r.db(ctx).AuditEntry.Query().
+		Where(auditPredicates(filter)...).
+		Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+	return int64(count), nil
+}
+
+// ListAfter retrieves entries appended after afterID, oldest first
+func (r *auditRepository) ListAfter(ctx context.Context, afterID int, limit int) ([]*entities.AuditEntry, error) {
+	entEntries, err := r.db(ctx).AuditEntry.Query().
+		Where(auditentry.IDGT(afterID)).
+		Order(ent.Asc(auditentry.FieldID)).
+		Limit(limit).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return toDomainAuditEntries(entEntries), nil
+}
+
+func auditPredicates(filter entities.AuditFilter) []predicate.AuditEntry {
+	var predicates []predicate.AuditEntry
+	if filter.ActorID != nil {
+		predicates = append(predicates, auditentry.ActorID(*filter.ActorID))
+	}
+	if filter.Action != "" {
+		predicates = append(predicates, auditentry.Action(filter.Action))
+	}
+	if filter.TargetType != "" {
+		predicates = append(predicates, auditentry.TargetType(filter.TargetType))
+	}
+	if filter.TargetID != "" {
+		predicates = append(predicates, auditentry.TargetID(filter.TargetID))
+	}
+	if filter.RequestID != "" {
+		predicates = append(predicates, auditentry.RequestID(filter.RequestID))
+	}
+	if filter.From != nil {
+		predicates = append(predicates, auditentry.CreatedAtGTE(*filter.From))
+	}
+	if filter.To != nil {
+		predicates = append(predicates, auditentry.CreatedAtLT(*filter.To))
+	}
+	return predicates
+}
+
+func toDomainAuditEntries(entEntries []*ent.AuditEntry) []*entities.AuditEntry {
+	entries := make([]*entities.AuditEntry, len(entEntries))
+	for i, entEntry := range entEntries {
+		entries[i] = database.EntAuditEntryToDomain(entEntry)
+	}
+	return entries
+}
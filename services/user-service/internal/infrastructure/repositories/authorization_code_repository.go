@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/authorizationcode"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// authorizationCodeRepository implements the AuthorizationCodeRepository
+// interface using Ent
+type authorizationCodeRepository struct {
+	client *ent.Client
+}
+
+// NewAuthorizationCodeRepository creates a new authorization code repository
+func NewAuthorizationCodeRepository(client *ent.Client) repositories.AuthorizationCodeRepository {
+	return &authorizationCodeRepository{
+		client: client,
+	}
+}
+
+// Create stores a new authorization code
+func (r *authorizationCodeRepository) Create(ctx context.Context, code *entities.AuthorizationCode) error {
+	entCode, err := r.client.AuthorizationCode.Create().
+		SetCodeHash(code.CodeHash).
+		SetClientID(code.ClientID).
+		SetUserID(code.UserID).
+		SetRedirectURI(code.RedirectURI).
+		SetScope(code.Scope).
+		SetNonce(code.Nonce).
+		SetCodeChallenge(code.CodeChallenge).
+		SetAuthTime(code.AuthTime).
+		SetExpiresAt(code.ExpiresAt).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create authorization code: %w", err)
+	}
+
+	code.ID = entCode.ID
+	code.CreatedAt = entCode.CreatedAt
+	return nil
+}
+
+// Consume marks the code with the given hash as used and returns it
+func (r *authorizationCodeRepository) Consume(ctx context.Context, codeHash string) (*entities.AuthorizationCode, error) {
+	entCode, err := r.client.AuthorizationCode.Query().
+		Where(authorizationcode.CodeHash(codeHash)).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errors.New("authorization code not found")
+		}
+		return nil, fmt.Errorf("failed to get authorization code: %w", err)
+	}
+
+	// Only one of several concurrent exchanges can flip used_at from NULL
+	usedAt := time.Now()
+	n, err := r.client.AuthorizationCode.Update().
+		Where(authorizationcode.ID(entCode.ID), authorizationcode.UsedAtIsNil()).
+		SetUsedAt(usedAt).
+		Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use authorization code: %w", err)
+	}
+	if n == 0 {
+		return nil, errors.New("authorization code already used")
+	}
+
+	code := database.EntAuthorizationCodeToDomain(entCode)
+	code.UsedAt = &usedAt
+	return code, nil
+}
+
+// DeleteExpired removes codes that expired before the given time
+func (r *authorizationCodeRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	n, err := r.client.AuthorizationCode.Delete().
+		Where(authorizationcode.ExpiresAtLT(before)).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired authorization codes: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/lockoutevent"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// lockoutEventRepository implements the LockoutEventRepository interface using Ent
+type lockoutEventRepository struct {
+	client *ent.Client
+}
+
+// NewLockoutEventRepository creates a new lockout event repository
+func NewLockoutEventRepository(client *ent.Client) repositories.LockoutEventRepository {
+	return &lockoutEventRepository{
+		client: client,
+	}
+}
+
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *lockoutEventRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
+// Create stores a new event
+func (r *lockoutEventRepository) Create(ctx context.Context, event *entities.LockoutEvent) error {
+	entEvent, err := r.db(ctx).LockoutEvent.Create().
+		SetNillableUserID(event.UserID).
+		SetSubject(event.Subject).
+		SetAction(lockoutevent.Action(event.Action)).
+		SetReason(event.Reason).
+		SetIP(event.IP).
+		SetFailures(event.Failures).
+		SetNillableLockedUntil(event.LockedUntil).
+		SetNillableActorID(event.ActorID).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create lockout event: %w", err)
+	}
+
+	event.ID = entEvent.ID
+	event.CreatedAt = entEvent.CreatedAt
+	return nil
+}
+
+// GetByUser retrieves the most recent events of a user
+func (r *lockoutEventRepository) GetByUser(ctx context.Context, userID int, limit int) ([]*entities.LockoutEvent, error) {
+	entEvents, err := r.db(ctx).LockoutEvent.Query().
+		Where(lockoutevent.UserID(userID)).
+		Order(ent.Desc(lockoutevent.FieldCreatedAt), ent.Desc(lockoutevent.FieldID)).
+		Limit(limit).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lockout events: %w", err)
+	}
+
+	events := make([]*entities.LockoutEvent, len(entEvents))
+	for i, entEvent := range entEvents {
+		events[i] = database.EntLockoutEventToDomain(entEvent)
+	}
+	return events, nil
+}
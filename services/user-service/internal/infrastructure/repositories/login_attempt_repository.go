@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/loginattempt"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// loginAttemptRepository implements the LoginAttemptRepository interface
+// using Ent, so every replica sharing the database shares the counters
+type loginAttemptRepository struct {
+	client *ent.Client
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository
+func NewLoginAttemptRepository(client *ent.Client) repositories.LoginAttemptRepository {
+	return &loginAttemptRepository{
+		client: client,
+	}
+}
+
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *loginAttemptRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
+// Get retrieves the counter of a subject
+func (r *loginAttemptRepository) Get(ctx context.Context, subject string) (*entities.LoginAttempts, error) {
+	entAttempt, err := r.db(ctx).LoginAttempt.Query().
+		Where(loginattempt.Subject(subject)).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return &entities.LoginAttempts{Subject: subject}, nil
+		}
+		return nil, fmt.Errorf("failed to get login attempts: %w", err)
+	}
+	return database.EntLoginAttemptToDomain(entAttempt), nil
+}
+
+// RecordFailure counts a failed attempt. The increment is a single UPDATE so
+// concurrent failures on different replicas are all counted.
+func (r *loginAttemptRepository) RecordFailure(ctx context.Context, subject string, now time.Time, window time.Duration) (*entities.LoginAttempts, error) {
+	// Start over if the previous failure fell out of the window
+	_, err := r.db(ctx).LoginAttempt.Update().
+		Where(loginattempt.Subject(subject), loginattempt.LastFailureAtLT(now.Add(-window))).
+		SetFailures(0).
+		Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	n, err := r.db(ctx).LoginAttempt.Update().
+		Where(loginattempt.Subject(subject)).
+		AddFailures(1).
+		SetLastFailureAt(now).
+		Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	if n == 0 {
+		err := r.db(ctx).LoginAttempt.Create().
+			SetSubject(subject).
+			SetFailures(1).
+			SetLastFailureAt(now).
+			Exec(ctx)
+		if ent.IsConstraintError(err) {
+			// Another replica created the counter first
+			return r.RecordFailure(ctx, subject, now, window)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to record login failure: %w", err)
+		}
+	}
+
+	return r.Get(ctx, subject)
+}
+
+// Lock refuses attempts for a subject until the given time
+func (r *loginAttemptRepository) Lock(ctx context.Context, subject string, until time.Time) error {
+	n, err := r.db(ctx).LoginAttempt.Update().
+		Where(loginattempt.Subject(subject)).
+		SetFailures(0).
+		SetLockedUntil(until).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to lock login attempts: %w", err)
+	}
+	if n == 0 {
+		err := r.db(ctx).LoginAttempt.Create().
+			SetSubject(subject).
+			SetLockedUntil(until).
+			Exec(ctx)
+		if err != nil && !ent.IsConstraintError(err) {
+			return fmt.Errorf("failed to lock login attempts: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reset forgets the failures and lockout of a subject
+func (r *loginAttemptRepository) Reset(ctx context.Context, subject string) error {
+	if _, err := r.db(ctx).LoginAttempt.Delete().Where(loginattempt.Subject(subject)).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+	return nil
+}
+
+// DeleteStale removes counters that no longer affect any login
+func (r *loginAttemptRepository) DeleteStale(ctx context.Context, before time.Time) (int, error) {
+	n, err := r.db(ctx).LoginAttempt.Delete().
+		Where(
+			loginattempt.LastFailureAtLT(before),
+			loginattempt.Or(loginattempt.LockedUntilIsNil(), loginattempt.LockedUntilLT(before)),
+		).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale login attempts: %w", err)
+	}
+	return n, nil
+}
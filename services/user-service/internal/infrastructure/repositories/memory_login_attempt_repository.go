@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+)
+
+// memoryLoginAttemptRepository implements the LoginAttemptRepository
+// interface in process memory. Counters are lost on restart and are not seen
+// by other replicas, so it suits a single instance only.
+type memoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]entities.LoginAttempts
+}
+
+// NewMemoryLoginAttemptRepository creates a new in-memory login attempt repository
+func NewMemoryLoginAttemptRepository() repositories.LoginAttemptRepository {
+	return &memoryLoginAttemptRepository{
+		attempts: make(map[string]entities.LoginAttempts),
+	}
+}
+
+// Get retrieves the counter of a subject
+func (r *memoryLoginAttemptRepository) Get(ctx context.Context, subject string) (*entities.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[subject]
+	if !ok {
+		attempts.Subject = subject
+	}
+	return &attempts, nil
+}
+
+// RecordFailure counts a failed attempt
+func (r *memoryLoginAttemptRepository) RecordFailure(ctx context.Context, subject string, now time.Time, window time.Duration) (*entities.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts := r.attempts[subject]
+	attempts.Subject = subject
+	if attempts.LastFailureAt.Before(now.Add(-window)) {
+		attempts.Failures = 0
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = now
+	r.attempts[subject] = attempts
+
+	return &attempts, nil
+}
+
+// Lock refuses attempts for a subject until the given time
+func (r *memoryLoginAttemptRepository) Lock(ctx context.Context, subject string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts := r.attempts[subject]
+	attempts.Subject = subject
+	attempts.Failures = 0
+	attempts.LockedUntil = &until
+	r.attempts[subject] = attempts
+	return nil
+}
+
+// Reset forgets the failures and lockout of a subject
+func (r *memoryLoginAttemptRepository) Reset(ctx context.Context, subject string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, subject)
+	return nil
+}
+
+// DeleteStale removes counters that no longer affect any login
+func (r *memoryLoginAttemptRepository) DeleteStale(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for subject, attempts := range r.attempts {
+		if attempts.LastFailureAt.Before(before) && (attempts.LockedUntil == nil || attempts.LockedUntil.Before(before)) {
+			delete(r.attempts, subject)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/oauthclient"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// oauthClientRepository implements the OAuthClientRepository interface using Ent
+type oauthClientRepository struct {
+	client *ent.Client
+}
+
+// NewOAuthClientRepository creates a new OAuth client repository
+func NewOAuthClientRepository(client *ent.Client) repositories.OAuthClientRepository {
+	return &oauthClientRepository{
+		client: client,
+	}
+}
+
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *oauthClientRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
+// Create stores a new client
+func (r *oauthClientRepository) Create(ctx context.Context, c *entities.OAuthClient) error {
+	entClient, err := r.db(ctx).OAuthClient.Create().
+		SetClientID(c.ClientID).
+		SetName(c.Name).
+		SetSecretHash(c.SecretHash).
+		SetRedirectUris(c.RedirectURIs).
+		SetGrantTypes(c.GrantTypes).
+		SetScopes(c.Scopes).
+		Save(ctx)
+	if err != nil {
+		if ent.IsConstraintError(err) {
+			return errors.New("oauth client already exists")
+		}
+		return fmt.Errorf("failed to create oauth client: %w", err)
+	}
+
+	c.ID = entClient.ID
+	c.CreatedAt = entClient.CreatedAt
+	return nil
+}
+
+// GetByClientID retrieves a client by its public client ID
+func (r *oauthClientRepository) GetByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error) {
+	entClient, err := r.db(ctx).OAuthClient.Query().
+		Where(oauthclient.ClientID(clientID)).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errors.New("oauth client not found")
+		}
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+
+	return database.EntOAuthClientToDomain(entClient), nil
+}
+
+// GetAll returns every client, in order of registration
+func (r *oauthClientRepository) GetAll(ctx context.Context) ([]*entities.OAuthClient, error) {
+	entClients, err := r.db(ctx).OAuthClient.Query().
+		Order(ent.Asc(oauthclient.FieldID)).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oauth clients: %w", err)
+	}
+
+	clients := make([]*entities.OAuthClient, len(entClients))
+	for i, entClient := range entClients {
+		clients[i] = database.EntOAuthClientToDomain(entClient)
+	}
+	return clients, nil
+}
+
+// Delete removes a client together with its authorization codes
+func (r *oauthClientRepository) Delete(ctx context.Context, clientID string) error {
+	n, err := r.db(ctx).OAuthClient.Delete().
+		Where(oauthclient.ClientID(clientID)).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete oauth client: %w", err)
+	}
+	if n == 0 {
+		return errors.New("oauth client not found")
+	}
+	return nil
+}
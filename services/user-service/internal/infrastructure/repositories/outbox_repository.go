@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/outboxevent"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// maxOutboxErrorLength is the length of the last error kept for an event
+const maxOutboxErrorLength = 1024
+
+// outboxRepository implements the OutboxRepository interface using Ent
+type outboxRepository struct {
+	client *ent.Client
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(client *ent.Client) repositories.OutboxRepository {
+	return &outboxRepository{
+		client: client,
+	}
+}
+
+// Add stores events to be published
+func (r *outboxRepository) Add(ctx context.Context, events ...*entities.DomainEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	client := clientFor(ctx, r.client)
+	builders := make([]*ent.OutboxEventCreate, len(events))
+	for i, event := range events {
+		builders[i] = client.OutboxEvent.Create().
+			SetID(event.ID).
+			SetEventType(event.Type).
+			SetAggregateType(event.AggregateType).
+			SetAggregateID(event.AggregateID).
+			SetData(string(event.Data)).
+			SetOccurredAt(event.OccurredAt).
+			SetNextAttemptAt(event.OccurredAt)
+	}
+	if err := client.OutboxEvent.CreateBulk(builders...).Exec(ctx); err != nil {
+		if ent.IsConstraintError(err) {
+			return errors.New("event already exists")
+		}
+		return fmt.Errorf("failed to add events to outbox: %w", err)
+	}
+	return nil
+}
+
+// Due retrieves unpublished events whose next attempt is due, oldest first
+func (r *outboxRepository) Due(ctx context.Context, now time.Time, limit int) ([]*entities.OutboxEvent, error) {
+	entEvents, err := r.client.OutboxEvent.Query().
+		Where(outboxevent.PublishedAtIsNil(), outboxevent.NextAttemptAtLTE(now)).
+		Order(ent.Asc(outboxevent.FieldOccurredAt)).
+		Limit(limit).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due events: %w", err)
+	}
+
+	events := make([]*entities.OutboxEvent, len(entEvents))
+	for i, entEvent := range entEvents {
+		events[i] = database.EntOutboxEventToDomain(entEvent)
+	}
+	return events, nil
+}
+
+// Claim moves the next attempt of a due event ahead. The conditions make
+// concurrent claims of the same attempt fail but one.
+func (r *outboxRepository) Claim(ctx context.Context, id string, now, until time.Time) (bool, error) {
+	n, err := r.client.OutboxEvent.Update().
+		Where(outboxevent.ID(id), outboxevent.PublishedAtIsNil(), outboxevent.NextAttemptAtLTE(now)).
+		SetNextAttemptAt(until).
+		Save(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim event: %w", err)
+	}
+	return n > 0, nil
+}
+
+// MarkPublished records that the broker accepted an event
+func (r *outboxRepository) MarkPublished(ctx context.Context, id string, at time.Time) error {
+	err := r.client.OutboxEvent.UpdateOneID(id).
+		SetPublishedAt(at).
+		ClearLastError().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to mark event published: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt to publish an event
+func (r *outboxRepository) MarkFailed(ctx context.Context, id string, reason string, nextAttempt time.Time) error {
+	if len(reason) > maxOutboxErrorLength {
+		reason = strings.ToValidUTF8(reason[:maxOutboxErrorLength], "")
+	}
+	err := r.client.OutboxEvent.UpdateOneID(id).
+		AddAttempts(1).
+		SetLastError(reason).
+		SetNextAttemptAt(nextAttempt).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record failed event: %w", err)
+	}
+	return nil
+}
+
+// DeletePublished removes events published before the given time
+func (r *outboxRepository) DeletePublished(ctx context.Context, before time.Time) (int, error) {
+	n, err := r.client.OutboxEvent.Delete().
+		Where(outboxevent.PublishedAtLT(before)).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete published events: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/recoverycode"
+	"app-microservice/services/user-service/internal/domain/repositories"
+)
+
+// recoveryCodeRepository implements the RecoveryCodeRepository interface using Ent
+type recoveryCodeRepository struct {
+	client *ent.Client
+}
+
+// NewRecoveryCodeRepository creates a new recovery code repository
+func NewRecoveryCodeRepository(client *ent.Client) repositories.RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		client: client,
+	}
+}
+
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *recoveryCodeRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
+// Replace swaps the recovery codes of a user for new ones
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID int, codeHashes []string) error {
+	return inTx(ctx, r.client, func(client *ent.Client) error {
+		if _, err := client.RecoveryCode.Delete().Where(recoverycode.UserID(userID)).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+
+		builders := make([]*ent.RecoveryCodeCreate, 0, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			builders = append(builders, client.RecoveryCode.Create().
+				SetUserID(userID).
+				SetCodeHash(codeHash))
+		}
+		if err := client.RecoveryCode.CreateBulk(builders...).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to create recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+// Use marks a recovery code as used. The condition on used_at makes
+// concurrent uses of the same code fail but one.
+func (r *recoveryCodeRepository) Use(ctx context.Context, userID int, codeHash string) error {
+	n, err := r.db(ctx).RecoveryCode.Update().
+		Where(
+			recoverycode.UserID(userID),
+			recoverycode.CodeHash(codeHash),
+			recoverycode.UsedAtIsNil(),
+		).
+		SetUsedAt(time.Now()).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if n == 0 {
+		return errors.New("recovery code not found")
+	}
+	return nil
+}
+
+// DeleteForUser removes every recovery code of a user
+func (r *recoveryCodeRepository) DeleteForUser(ctx context.Context, userID int) error {
+	if _, err := r.db(ctx).RecoveryCode.Delete().Where(recoverycode.UserID(userID)).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	return nil
+}
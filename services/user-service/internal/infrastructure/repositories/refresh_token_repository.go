@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/refreshtoken"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// refreshTokenRepository implements the RefreshTokenRepository interface using Ent
+type refreshTokenRepository struct {
+	client *ent.Client
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(client *ent.Client) repositories.RefreshTokenRepository {
+	return &refreshTokenRepository{
+		client: client,
+	}
+}
+
+// Create stores a new refresh token
+func (r *refreshTokenRepository) Create(ctx context.Context, token *entities.RefreshToken) error {
+	return createRefreshToken(ctx, r.client, token)
+}
+
+// GetByHash retrieves a refresh token by the hash of its value
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*entities.RefreshToken, error) {
+	entToken, err := r.client.RefreshToken.Query().
+		Where(refreshtoken.TokenHash(tokenHash)).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errors.New("refresh token not found")
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return database.EntRefreshTokenToDomain(entToken), nil
+}
+
+// Rotate revokes current and stores next in one transaction
+func (r *refreshTokenRepository) Rotate(ctx context.Context, current, next *entities.RefreshToken) error {
+	tx, err := r.client.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	// Only one of several concurrent rotations of the same token can flip
+	// revoked_at from NULL; the others see zero affected rows
+	n, err := tx.RefreshToken.Update().
+		Where(refreshtoken.ID(current.ID), refreshtoken.RevokedAtIsNil()).
+		SetRevokedAt(time.Now()).
+		Save(ctx)
+	if err != nil {
+		return rollback(tx, fmt.Errorf("failed to revoke refresh token: %w", err))
+	}
+	if n == 0 {
+		return rollback(tx, errors.New("refresh token already used"))
+	}
+
+	if err := createRefreshToken(ctx, tx.Client(), next); err != nil {
+		return rollback(tx, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit refresh token rotation: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes tokens that expired before the given time
+func (r *refreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	n, err := r.client.RefreshToken.Delete().
+		Where(refreshtoken.ExpiresAtLT(before)).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+	return n, nil
+}
+
+func createRefreshToken(ctx context.Context, client *ent.Client, token *entities.RefreshToken) error {
+	entToken, err := client.RefreshToken.Create().
+		SetTokenHash(token.TokenHash).
+		SetFamilyID(token.FamilyID).
+		SetUserID(token.UserID).
+		SetExpiresAt(token.ExpiresAt).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	token.ID = entToken.ID
+	token.CreatedAt = entToken.CreatedAt
+	return nil
+}
+
+// rollback aborts tx and returns err, wrapping a failed rollback
+func rollback(tx *ent.Tx, err error) error {
+	if rerr := tx.Rollback(); rerr != nil {
+		return fmt.Errorf("%w: rollback failed: %v", err, rerr)
+	}
+	return err
+}
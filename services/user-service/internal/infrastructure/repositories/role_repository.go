@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/permission"
+	"app-microservice/services/user-service/ent/role"
+	"app-microservice/services/user-service/ent/user"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// roleRepository implements the RoleRepository interface using Ent
+type roleRepository struct {
+	client *ent.Client
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(client *ent.Client) repositories.RoleRepository {
+	return &roleRepository{
+		client: client,
+	}
+}
+
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *roleRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
+// EnsureRoles creates missing permissions and roles and links every role to
+// its listed permissions. Permissions removed from a role definition are left
+// in place so that grants made by hand are not undone on restart.
+func (r *roleRepository) EnsureRoles(ctx context.Context, roles []entities.Role, permissionDescriptions map[string]string) error {
+	tx, err := r.client.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	names := make([]string, 0, len(permissionDescriptions))
+	for name := range permissionDescriptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	permissionIDs := make(map[string]int, len(names))
+	for _, name := range names {
+		id, err := tx.Permission.Query().Where(permission.Name(name)).OnlyID(ctx)
+		if ent.IsNotFound(err) {
+			var entPermission *ent.Permission
+			entPermission, err = tx.Permission.Create().
+				SetName(name).
+				SetDescription(permissionDescriptions[name]).
+				Save(ctx)
+			if err == nil {
+				id = entPermission.ID
+			}
+		}
+		if err != nil {
+			return rollback(tx, fmt.Errorf("failed to ensure permission %s: %w", name, err))
+		}
+		permissionIDs[name] = id
+	}
+
+	for _, definition := range roles {
+		entRole, err := tx.Role.Query().Where(role.Name(definition.Name)).Only(ctx)
+		if ent.IsNotFound(err) {
+			entRole, err = tx.Role.Create().
+				SetName(definition.Name).
+				SetDescription(definition.Description).
+				Save(ctx)
+		}
+		if err != nil {
+			return rollback(tx, fmt.Errorf("failed to ensure role %s: %w", definition.Name, err))
+		}
+
+		linked, err := entRole.QueryPermissions().IDs(ctx)
+		if err != nil {
+			return rollback(tx, fmt.Errorf("failed to get permissions of role %s: %w", definition.Name, err))
+		}
+		has := make(map[int]bool, len(linked))
+		for _, id := range linked {
+			has[id] = true
+		}
+
+		var missing []int
+		for _, name := range definition.Permissions {
+			id, ok := permissionIDs[name]
+			if !ok {
+				return rollback(tx, fmt.Errorf("role %s lists unknown permission %s", definition.Name, name))
+			}
+			if !has[id] {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			if err := tx.Role.UpdateOne(entRole).AddPermissionIDs(missing...).Exec(ctx); err != nil {
+				return rollback(tx, fmt.Errorf("failed to grant permissions to role %s: %w", definition.Name, err))
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit roles: %w", err)
+	}
+	return nil
+}
+
+// GetAll retrieves every role with its permissions
+func (r *roleRepository) GetAll(ctx context.Context) ([]*entities.Role, error) {
+	entRoles, err := r.db(ctx).Role.Query().
+		WithPermissions().
+		Order(ent.Asc(role.FieldName)).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	return database.EntRolesToDomain(entRoles), nil
+}
+
+// GetUserRoles retrieves the roles assigned to a user
+func (r *roleRepository) GetUserRoles(ctx context.Context, userID int) ([]*entities.Role, error) {
+	if err := r.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	entRoles, err := r.db(ctx).Role.Query().
+		Where(role.HasUsersWith(user.ID(userID))).
+		WithPermissions().
+		Order(ent.Asc(role.FieldName)).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	return database.EntRolesToDomain(entRoles), nil
+}
+
+// GetUserPermissions retrieves the names of every permission a user holds
+func (r *roleRepository) GetUserPermissions(ctx context.Context, userID int) ([]string, error) {
+	names, err := r.db(ctx).Permission.Query().
+		Where(permission.HasRolesWith(role.HasUsersWith(user.ID(userID)))).
+		Order(ent.Asc(permission.FieldName)).
+		Select(permission.FieldName).
+		Strings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user permissions: %w", err)
+	}
+	return names, nil
+}
+
+// AssignRole gives a role to a user
+func (r *roleRepository) AssignRole(ctx context.Context, userID int, roleName string) error {
+	roleID, err := r.roleID(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if err := r.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	assigned, err := r.db(ctx).Role.Query().
+		Where(role.ID(roleID), role.HasUsersWith(user.ID(userID))).
+		Exist(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check role assignment: %w", err)
+	}
+	if assigned {
+		return nil
+	}
+
+	if err := r.db(ctx).User.UpdateOneID(userID).AddRoleIDs(roleID).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+	return nil
+}
+
+// RevokeRole takes a role away from a user
+func (r *roleRepository) RevokeRole(ctx context.Context, userID int, roleName string) error {
+	roleID, err := r.roleID(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if err := r.ensureUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := r.db(ctx).User.UpdateOneID(userID).RemoveRoleIDs(roleID).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+	return nil
+}
+
+func (r *roleRepository) roleID(ctx context.Context, name string) (int, error) {
+	id, err := r.db(ctx).Role.Query().Where(role.Name(name)).OnlyID(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return 0, errors.New("role not found")
+		}
+		return 0, fmt.Errorf("failed to get role: %w", err)
+	}
+	return id, nil
+}
+
+func (r *roleRepository) ensureUser(ctx context.Context, userID int) error {
+	exists, err := r.db(ctx).User.Query().Where(user.ID(userID)).Exist(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !exists {
+		return errors.New("user not found")
+	}
+	return nil
+}
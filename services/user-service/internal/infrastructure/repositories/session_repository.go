@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/refreshtoken"
+	"app-microservice/services/user-service/ent/session"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// sessionRepository implements the SessionRepository interface using Ent
+type sessionRepository struct {
+	client *ent.Client
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(client *ent.Client) repositories.SessionRepository {
+	return &sessionRepository{
+		client: client,
+	}
+}
+
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *sessionRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
+// Create stores a new session
+func (r *sessionRepository) Create(ctx context.Context, s *entities.Session) error {
+	create := r.db(ctx).Session.Create().
+		SetID(s.ID).
+		SetUserID(s.UserID).
+		SetUserAgent(s.UserAgent).
+		SetIP(s.IP).
+		SetExpiresAt(s.ExpiresAt)
+	if !s.CreatedAt.IsZero() {
+		create = create.SetCreatedAt(s.CreatedAt).SetLastUsedAt(s.CreatedAt)
+	}
+
+	entSession, err := create.Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	s.CreatedAt = entSession.CreatedAt
+	s.LastUsedAt = entSession.LastUsedAt
+	return nil
+}
+
+// GetByID retrieves a session, revoked or not
+func (r *sessionRepository) GetByID(ctx context.Context, id string) (*entities.Session, error) {
+	entSession, err := r.db(ctx).Session.Get(ctx, id)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errors.New("session not found")
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return database.EntSessionToDomain(entSession), nil
+}
+
+// GetActiveByUser returns the active sessions of a user, most recently used
+// first
+func (r *sessionRepository) GetActiveByUser(ctx context.Context, userID int, now time.Time) ([]*entities.Session, error) {
+	entSessions, err := r.db(ctx).Session.Query().
+		Where(
+			session.UserID(userID),
+			session.RevokedAtIsNil(),
+			session.ExpiresAtGT(now),
+		).
+		Order(ent.Desc(session.FieldLastUsedAt)).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	sessions := make([]*entities.Session, len(entSessions))
+	for i, entSession := range entSessions {
+		sessions[i] = database.EntSessionToDomain(entSession)
+	}
+	return sessions, nil
+}
+
+// Touch records that a session was used at the given time
+func (r *sessionRepository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db(ctx).Session.Update().
+		Where(session.ID(id)).
+		SetLastUsedAt(usedAt).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+// Extend records that a session was used and moves its expiry
+func (r *sessionRepository) Extend(ctx context.Context, id string, usedAt, expiresAt time.Time) error {
+	_, err := r.db(ctx).Session.Update().
+		Where(session.ID(id)).
+		SetLastUsedAt(usedAt).
+		SetExpiresAt(expiresAt).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to extend session: %w", err)
+	}
+	return nil
+}
+
+// Revoke revokes a session and its refresh tokens in one transaction
+func (r *sessionRepository) Revoke(ctx context.Context, id string) error {
+	now := time.Now()
+	return inTx(ctx, r.client, func(client *ent.Client) error {
+		if _, err := client.Session.Update().
+			Where(session.ID(id), session.RevokedAtIsNil()).
+			SetRevokedAt(now).
+			Save(ctx); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+		if _, err := client.RefreshToken.Update().
+			Where(refreshtoken.FamilyID(id), refreshtoken.RevokedAtIsNil()).
+			SetRevokedAt(now).
+			Save(ctx); err != nil {
+			return fmt.Errorf("failed to revoke refresh token family: %w", err)
+		}
+		return nil
+	})
+}
+
+// RevokeAllForUser revokes every session and refresh token of a user in one
+// transaction
+func (r *sessionRepository) RevokeAllForUser(ctx context.Context, userID int) (int, error) {
+	now := time.Now()
+	var n int
+	err := inTx(ctx, r.client, func(client *ent.Client) error {
+		var err error
+		n, err = client.Session.Update().
+			Where(session.UserID(userID), session.RevokedAtIsNil(), session.ExpiresAtGT(now)).
+			SetRevokedAt(now).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		if _, err := client.RefreshToken.Update().
+			Where(refreshtoken.UserID(userID), refreshtoken.RevokedAtIsNil()).
+			SetRevokedAt(now).
+			Save(ctx); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// DeleteExpired removes sessions that expired before the given time
+func (r *sessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	n, err := r.db(ctx).Session.Delete().
+		Where(session.ExpiresAtLT(before)).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+	return n, nil
+}
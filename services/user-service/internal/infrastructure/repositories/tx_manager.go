@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/internal/domain/repositories"
+)
+
+type txKey struct{}
+
+// txManager implements the TxManager interface using Ent transactions, which
+// it passes to the repositories of this package through the context
+type txManager struct {
+	client *ent.Client
+}
+
+// NewTxManager creates a new transaction manager
+func NewTxManager(client *ent.Client) repositories.TxManager {
+	return &txManager{
+		client: client,
+	}
+}
+
+// WithinTx runs fn in a transaction, or in the one ctx already carries
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*ent.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.client.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return rollback(tx, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// clientFor returns the client of the transaction ctx carries, or client
+// outside of one
+func clientFor(ctx context.Context, client *ent.Client) *ent.Client {
+	if tx, ok := ctx.Value(txKey{}).(*ent.Tx); ok {
+		return tx.Client()
+	}
+	return client
+}
+
+// inTx runs fn in the transaction ctx carries, or in a new one that is
+// committed if fn succeeds. Only a new transaction is rolled back when fn
+// fails; the carried one is rolled back by its WithinTx.
+func inTx(ctx context.Context, client *ent.Client, fn func(client *ent.Client) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*ent.Tx); ok {
+		return fn(tx.Client())
+	}
+
+	tx, err := client.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	if err := fn(tx.Client()); err != nil {
+		return rollback(tx, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/schema"
 	"app-microservice/services/user-service/ent/user"
+	"app-microservice/services/user-service/ent/userstatushistory"
 	"app-microservice/services/user-service/internal/domain/entities"
 	"app-microservice/services/user-service/internal/domain/repositories"
 	"app-microservice/services/user-service/internal/infrastructure/database"
@@ -25,9 +28,15 @@ func NewUserRepository(client *ent.Client) repositories.UserRepository {
 	}
 }
 
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *userRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
 // Create creates a new user
 func (r *userRepository) Create(ctx context.Context, domainUser *entities.User) error {
-	entUser, err := database.DomainUserToEntCreate(r.client, domainUser).Save(ctx)
+	entUser, err := database.DomainUserToEntCreate(r.db(ctx), domainUser).Save(ctx)
 	if err != nil {
 		if ent.IsConstraintError(err) {
 			return errors.New("email already exists")
@@ -37,6 +46,7 @@ func (r *userRepository) Create(ctx context.Context, domainUser *entities.User)
 
 	// Update domain user with generated ID and timestamps
 	domainUser.ID = entUser.ID
+	domainUser.Version = entUser.Version
 	domainUser.CreatedAt = entUser.CreatedAt
 	domainUser.UpdatedAt = entUser.UpdatedAt
 
@@ -45,7 +55,8 @@ func (r *userRepository) Create(ctx context.Context, domainUser *entities.User)
 
 // GetByID retrieves a user by ID
 func (r *userRepository) GetByID(ctx context.Context, id int) (*entities.User, error) {
-	entUser, err := r.client.User.Get(ctx, id)
+	ctx = readContext(ctx)
+	entUser, err := r.db(ctx).User.Get(ctx, id)
 	if err != nil {
 		if ent.IsNotFound(err) {
 			return nil, errors.New("user not found")
@@ -58,7 +69,7 @@ func (r *userRepository) GetByID(ctx context.Context, id int) (*entities.User, e
 
 // GetByEmail retrieves a user by email
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
-	entUser, err := r.client.User.Query().
+	entUser, err := r.db(ctx).User.Query().
 		Where(user.EmailEQ(email)).
 		Only(ctx)
 	if err != nil {
@@ -73,7 +84,8 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entitie
 
 // GetAll retrieves all users with pagination
 func (r *userRepository) GetAll(ctx context.Context, limit, offset int) ([]*entities.User, error) {
-	entUsers, err := r.client.User.Query().
+	ctx = readContext(ctx)
+	entUsers, err := r.db(ctx).User.Query().
 		Limit(limit).
 		Offset(offset).
 		Order(ent.Desc(user.FieldCreatedAt)).
@@ -90,49 +102,378 @@ func (r *userRepository) GetAll(ctx context.Context, limit, offset int) ([]*enti
 	return domainUsers, nil
 }
 
-// Update updates an existing user
+// Update updates an existing user, and stores its pending status change in
+// the status history in the same transaction
 func (r *userRepository) Update(ctx context.Context, domainUser *entities.User) error {
-	updateBuilder := r.client.User.UpdateOneID(domainUser.ID).
+	var entUser *ent.User
+	err := inTx(ctx, r.client, func(client *ent.Client) error {
+		var err error
+		entUser, err = r.update(ctx, client, domainUser)
+		return err
+	})
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return r.missingOrConflict(ctx, domainUser.ID)
+		}
+		if ent.IsConstraintError(err) {
+			return errors.New("email already exists")
+		}
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	// Update domain user with new version and timestamps
+	domainUser.Version = entUser.Version
+	domainUser.UpdatedAt = entUser.UpdatedAt
+	domainUser.StatusChange = nil
+
+	return nil
+}
+
+// update saves domainUser and its pending status change with client
+func (r *userRepository) update(ctx context.Context, client *ent.Client, domainUser *entities.User) (*ent.User, error) {
+	// The version predicate makes this an atomic compare-and-swap:
+	// UPDATE users SET ..., version = version + 1 WHERE id = ? AND version = ?
+	updateBuilder := client.User.UpdateOneID(domainUser.ID).
+		Where(user.Version(domainUser.Version), user.DeletedAtIsNil()).
+		AddVersion(1).
 		SetName(domainUser.Name).
 		SetEmail(domainUser.Email).
 		SetStatus(user.Status(domainUser.Status))
 
 	if domainUser.Phone != "" {
 		updateBuilder = updateBuilder.SetPhone(domainUser.Phone)
+	} else {
+		updateBuilder = updateBuilder.ClearPhone()
+	}
+	// Leaving the locked status by any route ends the lockout
+	if domainUser.Status != string(entities.UserStatusLocked) {
+		updateBuilder = updateBuilder.ClearLockedUntil()
+	}
+	if domainUser.Status == string(entities.UserStatusSuspended) && domainUser.SuspendedUntil != nil {
+		updateBuilder = updateBuilder.SetSuspendedUntil(*domainUser.SuspendedUntil)
+	} else {
+		updateBuilder = updateBuilder.ClearSuspendedUntil()
 	}
 
 	entUser, err := updateBuilder.Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if change := domainUser.StatusChange; change != nil {
+		if err := createStatusChange(ctx, client, change); err != nil {
+			return nil, err
+		}
+	}
+	return entUser, nil
+}
+
+// UpdatePasswordHash replaces the stored password hash of a user
+func (r *userRepository) UpdatePasswordHash(ctx context.Context, id int, passwordHash string) error {
+	err := r.db(ctx).User.UpdateOneID(id).
+		Where(user.DeletedAtIsNil()).
+		SetPasswordHash(passwordHash).
+		Exec(ctx)
 	if err != nil {
 		if ent.IsNotFound(err) {
 			return errors.New("user not found")
 		}
-		if ent.IsConstraintError(err) {
-			return errors.New("email already exists")
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return nil
+}
+
+// ChangePassword replaces the stored password hash and records when it changed
+func (r *userRepository) ChangePassword(ctx context.Context, id int, passwordHash string) error {
+	err := r.db(ctx).User.UpdateOneID(id).
+		Where(user.DeletedAtIsNil()).
+		SetPasswordHash(passwordHash).
+		SetPasswordChangedAt(time.Now()).
+		Exec(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return errors.New("user not found")
 		}
-		return fmt.Errorf("failed to update user: %w", err)
+		return fmt.Errorf("failed to change password: %w", err)
 	}
+	return nil
+}
 
-	// Update domain user with new timestamps
-	domainUser.UpdatedAt = entUser.UpdatedAt
+// UpdateMFA stores or clears the TOTP secret and MFA enablement of a user
+func (r *userRepository) UpdateMFA(ctx context.Context, id int, secret string, enabledAt *time.Time) error {
+	update := r.db(ctx).User.UpdateOneID(id).
+		Where(user.DeletedAtIsNil()).
+		SetNillableMfaEnabledAt(enabledAt).
+		SetMfaLastStep(0)
+	if secret == "" {
+		update.ClearMfaSecret()
+	} else {
+		update.SetMfaSecret(secret)
+	}
+	if enabledAt == nil {
+		update.ClearMfaEnabledAt()
+	}
+
+	if err := update.Exec(ctx); err != nil {
+		if ent.IsNotFound(err) {
+			return errors.New("user not found")
+		}
+		return fmt.Errorf("failed to update MFA: %w", err)
+	}
+	return nil
+}
+
+// UseMFAStep advances the last accepted TOTP time step. The condition on the
+// stored step makes concurrent uses of the same code fail but one.
+func (r *userRepository) UseMFAStep(ctx context.Context, id int, step int64) error {
+	n, err := r.db(ctx).User.Update().
+		Where(user.ID(id), user.DeletedAtIsNil(), user.MfaLastStepLT(step)).
+		SetMfaLastStep(step).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record MFA code: %w", err)
+	}
+	if n == 0 {
+		return errors.New("mfa code already used")
+	}
+	return nil
+}
+
+// Lock locks an active user out until the given time. The status predicate
+// keeps a concurrent suspension from being overwritten.
+func (r *userRepository) Lock(ctx context.Context, id int, until time.Time, reason string) error {
+	change := &entities.UserStatusChange{
+		UserID:     id,
+		FromStatus: entities.UserStatusActive,
+		ToStatus:   entities.UserStatusLocked,
+		Reason:     reason,
+	}
+	n, err := r.changeStatus(ctx, change, func(update *ent.UserUpdate) *ent.UserUpdate {
+		return update.
+			Where(user.StatusEQ(user.StatusActive)).
+			SetLockedUntil(until)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+	if n == 0 {
+		exists, err := r.Exists(ctx, id)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.New("user is not active")
+		}
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// Unlock makes a locked user active again. The status predicate keeps a
+// concurrent suspension from being overwritten.
+func (r *userRepository) Unlock(ctx context.Context, id int, reason string, actorID *int) error {
+	change := &entities.UserStatusChange{
+		UserID:     id,
+		FromStatus: entities.UserStatusLocked,
+		ToStatus:   entities.UserStatusActive,
+		Reason:     reason,
+		ActorID:    actorID,
+	}
+	n, err := r.changeStatus(ctx, change, func(update *ent.UserUpdate) *ent.UserUpdate {
+		return update.
+			Where(user.StatusEQ(user.StatusLocked)).
+			ClearLockedUntil()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unlock user: %w", err)
+	}
+	if n == 0 {
+		exists, err := r.Exists(ctx, id)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.New("user is not locked")
+		}
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// LiftSuspension makes a user whose timed suspension ended by now active
+// again. It returns false if the user is no longer suspended or was suspended
+// again with a later end in the meantime.
+func (r *userRepository) LiftSuspension(ctx context.Context, id int, now time.Time, reason string) (bool, error) {
+	change := &entities.UserStatusChange{
+		UserID:     id,
+		FromStatus: entities.UserStatusSuspended,
+		ToStatus:   entities.UserStatusActive,
+		Reason:     reason,
+		CreatedAt:  now,
+	}
+	n, err := r.changeStatus(ctx, change, func(update *ent.UserUpdate) *ent.UserUpdate {
+		return update.
+			Where(user.StatusEQ(user.StatusSuspended), user.SuspendedUntilLTE(now)).
+			ClearSuspendedUntil()
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to lift suspension: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetExpiredSuspensions retrieves users whose timed suspension ended by now
+func (r *userRepository) GetExpiredSuspensions(ctx context.Context, now time.Time, limit int) ([]*entities.User, error) {
+	entUsers, err := r.db(ctx).User.Query().
+		Where(user.StatusEQ(user.StatusSuspended), user.SuspendedUntilLTE(now)).
+		Order(ent.Asc(user.FieldSuspendedUntil)).
+		Limit(limit).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired suspensions: %w", err)
+	}
+
+	domainUsers := make([]*entities.User, len(entUsers))
+	for i, entUser := range entUsers {
+		domainUsers[i] = database.EntUserToDomainUser(entUser)
+	}
+	return domainUsers, nil
+}
+
+// GetStatusHistory retrieves the most recent status changes of a user, newest
+// first
+func (r *userRepository) GetStatusHistory(ctx context.Context, id int, limit int) ([]*entities.UserStatusChange, error) {
+	entChanges, err := r.db(ctx).UserStatusHistory.Query().
+		Where(userstatushistory.UserID(id)).
+		Order(ent.Desc(userstatushistory.FieldID)).
+		Limit(limit).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status history: %w", err)
+	}
+
+	changes := make([]*entities.UserStatusChange, len(entChanges))
+	for i, entChange := range entChanges {
+		changes[i] = database.EntUserStatusHistoryToDomain(entChange)
+	}
+	return changes, nil
+}
+
+// changeStatus sets the status of a user to change.ToStatus with the
+// conditions and fields added by apply, and records change if the user was
+// updated, in one transaction. It returns the number of users updated.
+func (r *userRepository) changeStatus(ctx context.Context, change *entities.UserStatusChange, apply func(*ent.UserUpdate) *ent.UserUpdate) (int, error) {
+	var n int
+	err := inTx(ctx, r.client, func(client *ent.Client) error {
+		update := client.User.Update().
+			Where(user.ID(change.UserID), user.DeletedAtIsNil()).
+			SetStatus(user.Status(change.ToStatus)).
+			AddVersion(1)
+		var err error
+		if n, err = apply(update).Save(ctx); err != nil || n == 0 {
+			return err
+		}
+		return createStatusChange(ctx, client, change)
+	})
+	return n, err
+}
+
+// createStatusChange stores a status change in the status history
+func createStatusChange(ctx context.Context, client *ent.Client, change *entities.UserStatusChange) error {
+	create := client.UserStatusHistory.Create().
+		SetUserID(change.UserID).
+		SetFromStatus(string(change.FromStatus)).
+		SetToStatus(string(change.ToStatus)).
+		SetReason(change.Reason).
+		SetNillableSuspendedUntil(change.SuspendedUntil).
+		SetNillableActorID(change.ActorID)
+	if !change.CreatedAt.IsZero() {
+		create = create.SetCreatedAt(change.CreatedAt)
+	}
 
+	entChange, err := create.Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record status change: %w", err)
+	}
+	change.ID = entChange.ID
+	change.CreatedAt = entChange.CreatedAt
 	return nil
 }
 
-// Delete deletes a user by ID
-func (r *userRepository) Delete(ctx context.Context, id int) error {
-	err := r.client.User.DeleteOneID(id).Exec(ctx)
+// Delete soft-deletes a user by ID if it is still at the given version. The
+// soft delete mixin turns the delete into an update of deleted_at.
+func (r *userRepository) Delete(ctx context.Context, id int, version int) error {
+	err := r.db(ctx).User.DeleteOneID(id).
+		Where(user.Version(version)).
+		Exec(ctx)
 	if err != nil {
 		if ent.IsNotFound(err) {
-			return errors.New("user not found")
+			return r.missingOrConflict(ctx, id)
 		}
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 	return nil
 }
 
+// Restore undoes the soft delete of a user and bumps its version
+func (r *userRepository) Restore(ctx context.Context, id int) error {
+	_, err := r.db(ctx).User.UpdateOneID(id).
+		Where(user.DeletedAtNotNil()).
+		ClearDeletedAt().
+		AddVersion(1).
+		Save(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			exists, err := r.Exists(ctx, id)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return errors.New("user is not deleted")
+			}
+			return errors.New("user not found")
+		}
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeleted permanently removes users soft-deleted before the given time
+func (r *userRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	n, err := r.db(ctx).User.Delete().
+		Where(user.DeletedAtLT(before)).
+		Exec(schema.SkipSoftDelete(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted users: %w", err)
+	}
+	return n, nil
+}
+
+// readContext lifts the soft delete filter for reads made with
+// repositories.WithDeleted
+func readContext(ctx context.Context) context.Context {
+	if repositories.IncludesDeleted(ctx) {
+		return schema.SkipSoftDelete(ctx)
+	}
+	return ctx
+}
+
+// missingOrConflict tells apart a versioned write that matched no row because
+// the user is gone from one that lost a race with a concurrent update
+func (r *userRepository) missingOrConflict(ctx context.Context, id int) error {
+	exists, err := r.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("user not found")
+	}
+	return errors.New("user version conflict")
+}
+
 // Count returns the total number of users
 func (r *userRepository) Count(ctx context.Context) (int64, error) {
-	count, err := r.client.User.Query().Count(ctx)
+	ctx = readContext(ctx)
+	count, err := r.db(ctx).User.Query().Count(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count users: %w", err)
 	}
@@ -141,7 +482,8 @@ func (r *userRepository) Count(ctx context.Context) (int64, error) {
 
 // GetByStatus retrieves users by status
 func (r *userRepository) GetByStatus(ctx context.Context, status string, limit, offset int) ([]*entities.User, error) {
-	entUsers, err := r.client.User.Query().
+	ctx = readContext(ctx)
+	entUsers, err := r.db(ctx).User.Query().
 		Where(user.StatusEQ(user.Status(status))).
 		Limit(limit).
 		Offset(offset).
@@ -161,7 +503,8 @@ func (r *userRepository) GetByStatus(ctx context.Context, status string, limit,
 
 // Search searches users by name or email
 func (r *userRepository) Search(ctx context.Context, query string, limit, offset int) ([]*entities.User, error) {
-	entUsers, err := r.client.User.Query().
+	ctx = readContext(ctx)
+	entUsers, err := r.db(ctx).User.Query().
 		Where(
 			user.Or(
 				user.NameContains(strings.ToLower(query)),
@@ -188,7 +531,8 @@ func (r *userRepository) Search(ctx context.Context, query string, limit, offset
 
 // Exists checks if a user exists by ID
 func (r *userRepository) Exists(ctx context.Context, id int) (bool, error) {
-	exists, err := r.client.User.Query().
+	ctx = readContext(ctx)
+	exists, err := r.db(ctx).User.Query().
 		Where(user.IDEQ(id)).
 		Exist(ctx)
 	if err != nil {
@@ -199,9 +543,10 @@ func (r *userRepository) Exists(ctx context.Context, id int) (bool, error) {
 
 // EmailExists checks if an email already exists
 func (r *userRepository) EmailExists(ctx context.Context, email string) (bool, error) {
-	exists, err := r.client.User.Query().
+	// Soft-deleted users keep their email reserved, the unique index covers them too
+	exists, err := r.db(ctx).User.Query().
 		Where(user.EmailEQ(email)).
-		Exist(ctx)
+		Exist(schema.SkipSoftDelete(ctx))
 	if err != nil {
 		return false, fmt.Errorf("failed to check if email exists: %w", err)
 	}
@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/verificationtoken"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// verificationTokenRepository implements the VerificationTokenRepository interface using Ent
+type verificationTokenRepository struct {
+	client *ent.Client
+}
+
+// NewVerificationTokenRepository creates a new verification token repository
+func NewVerificationTokenRepository(client *ent.Client) repositories.VerificationTokenRepository {
+	return &verificationTokenRepository{
+		client: client,
+	}
+}
+
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *verificationTokenRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
+// Create stores a new token and retires the earlier unused tokens of the same
+// user and purpose in one transaction
+func (r *verificationTokenRepository) Create(ctx context.Context, token *entities.VerificationToken) error {
+	var entToken *ent.VerificationToken
+	err := inTx(ctx, r.client, func(client *ent.Client) error {
+		purpose := verificationtoken.Purpose(token.Purpose)
+		_, err := client.VerificationToken.Update().
+			Where(
+				verificationtoken.UserID(token.UserID),
+				verificationtoken.PurposeEQ(purpose),
+				verificationtoken.UsedAtIsNil(),
+			).
+			SetUsedAt(time.Now()).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to retire verification tokens: %w", err)
+		}
+
+		entToken, err = client.VerificationToken.Create().
+			SetTokenHash(token.TokenHash).
+			SetPurpose(purpose).
+			SetUserID(token.UserID).
+			SetExpiresAt(token.ExpiresAt).
+			Save(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to create verification token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	token.ID = entToken.ID
+	token.CreatedAt = entToken.CreatedAt
+	return nil
+}
+
+// GetByHash retrieves a token by the hash of its value
+func (r *verificationTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*entities.VerificationToken, error) {
+	entToken, err := r.db(ctx).VerificationToken.Query().
+		Where(verificationtoken.TokenHash(tokenHash)).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errors.New("verification token not found")
+		}
+		return nil, fmt.Errorf("failed to get verification token: %w", err)
+	}
+
+	return database.EntVerificationTokenToDomain(entToken), nil
+}
+
+// GetLatest retrieves the most recently created token of a user for a purpose
+func (r *verificationTokenRepository) GetLatest(ctx context.Context, userID int, purpose entities.TokenPurpose) (*entities.VerificationToken, error) {
+	entToken, err := r.db(ctx).VerificationToken.Query().
+		Where(
+			verificationtoken.UserID(userID),
+			verificationtoken.PurposeEQ(verificationtoken.Purpose(purpose)),
+		).
+		Order(ent.Desc(verificationtoken.FieldCreatedAt), ent.Desc(verificationtoken.FieldID)).
+		First(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errors.New("verification token not found")
+		}
+		return nil, fmt.Errorf("failed to get verification token: %w", err)
+	}
+
+	return database.EntVerificationTokenToDomain(entToken), nil
+}
+
+// MarkUsed marks a token as used unless it already is
+func (r *verificationTokenRepository) MarkUsed(ctx context.Context, id int) error {
+	n, err := r.db(ctx).VerificationToken.Update().
+		Where(verificationtoken.ID(id), verificationtoken.UsedAtIsNil()).
+		SetUsedAt(time.Now()).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to use verification token: %w", err)
+	}
+	if n == 0 {
+		return errors.New("verification token already used")
+	}
+	return nil
+}
+
+// RecordFailedAttempt counts a wrong code and retires the token after maxAttempts
+func (r *verificationTokenRepository) RecordFailedAttempt(ctx context.Context, id int, maxAttempts int) error {
+	_, err := r.db(ctx).VerificationToken.Update().
+		Where(verificationtoken.ID(id), verificationtoken.UsedAtIsNil()).
+		AddFailedAttempts(1).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record failed attempt: %w", err)
+	}
+
+	_, err = r.db(ctx).VerificationToken.Update().
+		Where(
+			verificationtoken.ID(id),
+			verificationtoken.UsedAtIsNil(),
+			verificationtoken.FailedAttemptsGTE(maxAttempts),
+		).
+		SetUsedAt(time.Now()).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retire verification token: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes tokens that expired before the given time
+func (r *verificationTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	n, err := r.db(ctx).VerificationToken.Delete().
+		Where(verificationtoken.ExpiresAtLT(before)).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired verification tokens: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,299 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/webhookdelivery"
+	"app-microservice/services/user-service/ent/webhooksubscription"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/database"
+)
+
+// maxWebhookErrorLength is the length of the last error kept for a delivery
+const maxWebhookErrorLength = 1024
+
+// webhookSubscriptionRepository implements the WebhookSubscriptionRepository
+// interface using Ent
+type webhookSubscriptionRepository struct {
+	client *ent.Client
+}
+
+// NewWebhookSubscriptionRepository creates a new webhook repository
+func NewWebhookSubscriptionRepository(client *ent.Client) repositories.WebhookSubscriptionRepository {
+	return &webhookSubscriptionRepository{
+		client: client,
+	}
+}
+
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *webhookSubscriptionRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
+// Create stores a new webhook
+func (r *webhookSubscriptionRepository) Create(ctx context.Context, webhook *entities.WebhookSubscription) error {
+	entWebhook, err := r.db(ctx).WebhookSubscription.Create().
+		SetURL(webhook.URL).
+		SetEventTypes(webhook.EventTypes).
+		SetSecret(webhook.Secret).
+		SetDescription(webhook.Description).
+		SetActive(webhook.Active).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	webhook.ID = entWebhook.ID
+	webhook.CreatedAt = entWebhook.CreatedAt
+	webhook.UpdatedAt = entWebhook.UpdatedAt
+	return nil
+}
+
+// GetByID retrieves a webhook by ID
+func (r *webhookSubscriptionRepository) GetByID(ctx context.Context, id int) (*entities.WebhookSubscription, error) {
+	entWebhook, err := r.db(ctx).WebhookSubscription.Get(ctx, id)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errors.New("webhook not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return database.EntWebhookSubscriptionToDomain(entWebhook), nil
+}
+
+// GetAll returns every webhook, in order of creation
+func (r *webhookSubscriptionRepository) GetAll(ctx context.Context) ([]*entities.WebhookSubscription, error) {
+	return r.query(ctx, r.db(ctx).WebhookSubscription.Query())
+}
+
+// GetActive returns the webhooks events are delivered to
+func (r *webhookSubscriptionRepository) GetActive(ctx context.Context) ([]*entities.WebhookSubscription, error) {
+	return r.query(ctx, r.db(ctx).WebhookSubscription.Query().Where(webhooksubscription.Active(true)))
+}
+
+// Update saves the mutable fields of a webhook
+func (r *webhookSubscriptionRepository) Update(ctx context.Context, webhook *entities.WebhookSubscription) error {
+	entWebhook, err := r.db(ctx).WebhookSubscription.UpdateOneID(webhook.ID).
+		SetURL(webhook.URL).
+		SetEventTypes(webhook.EventTypes).
+		SetSecret(webhook.Secret).
+		SetDescription(webhook.Description).
+		SetActive(webhook.Active).
+		Save(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return errors.New("webhook not found")
+		}
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	webhook.UpdatedAt = entWebhook.UpdatedAt
+	return nil
+}
+
+// Delete removes a webhook; its deliveries are removed by the cascade
+func (r *webhookSubscriptionRepository) Delete(ctx context.Context, id int) error {
+	n, err := r.db(ctx).WebhookSubscription.Delete().
+		Where(webhooksubscription.ID(id)).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if n == 0 {
+		return errors.New("webhook not found")
+	}
+	return nil
+}
+
+func (r *webhookSubscriptionRepository) query(ctx context.Context, query *ent.WebhookSubscriptionQuery) ([]*entities.WebhookSubscription, error) {
+	entWebhooks, err := query.Order(ent.Asc(webhooksubscription.FieldID)).All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	webhooks := make([]*entities.WebhookSubscription, len(entWebhooks))
+	for i, entWebhook := range entWebhooks {
+		webhooks[i] = database.EntWebhookSubscriptionToDomain(entWebhook)
+	}
+	return webhooks, nil
+}
+
+// webhookDeliveryRepository implements the WebhookDeliveryRepository
+// interface using Ent
+type webhookDeliveryRepository struct {
+	client *ent.Client
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(client *ent.Client) repositories.WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		client: client,
+	}
+}
+
+// db returns the client of the transaction ctx carries, or the repository's
+// client outside of one
+func (r *webhookDeliveryRepository) db(ctx context.Context) *ent.Client {
+	return clientFor(ctx, r.client)
+}
+
+// Enqueue stores deliveries one by one, so a delivery already queued only
+// skips itself
+func (r *webhookDeliveryRepository) Enqueue(ctx context.Context, deliveries ...*entities.WebhookDelivery) (int, error) {
+	queued := 0
+	for _, delivery := range deliveries {
+		entDelivery, err := r.db(ctx).WebhookDelivery.Create().
+			SetSubscriptionID(delivery.SubscriptionID).
+			SetEventID(delivery.EventID).
+			SetEventType(delivery.EventType).
+			SetPayload(delivery.Payload).
+			SetNextAttemptAt(delivery.NextAttemptAt).
+			Save(ctx)
+		if err != nil {
+			if ent.IsConstraintError(err) {
+				continue
+			}
+			return queued, fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+		delivery.ID = entDelivery.ID
+		delivery.Status = string(entDelivery.Status)
+		delivery.CreatedAt = entDelivery.CreatedAt
+		queued++
+	}
+	return queued, nil
+}
+
+// GetByID retrieves a delivery by ID
+func (r *webhookDeliveryRepository) GetByID(ctx context.Context, id int) (*entities.WebhookDelivery, error) {
+	entDelivery, err := r.db(ctx).WebhookDelivery.Get(ctx, id)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errors.New("webhook delivery not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return database.EntWebhookDeliveryToDomain(entDelivery), nil
+}
+
+// List returns a page of the deliveries of a webhook, newest first
+func (r *webhookDeliveryRepository) List(ctx context.Context, subscriptionID int, status string, offset, limit int) ([]*entities.WebhookDelivery, int64, error) {
+	query := r.db(ctx).WebhookDelivery.Query().
+		Where(webhookdelivery.SubscriptionID(subscriptionID))
+	if status != "" {
+		query = query.Where(webhookdelivery.StatusEQ(webhookdelivery.Status(status)))
+	}
+
+	total, err := query.Clone().Count(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+	entDeliveries, err := query.
+		Order(ent.Desc(webhookdelivery.FieldID)).
+		Offset(offset).
+		Limit(limit).
+		All(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]*entities.WebhookDelivery, len(entDeliveries))
+	for i, entDelivery := range entDeliveries {
+		deliveries[i] = database.EntWebhookDeliveryToDomain(entDelivery)
+	}
+	return deliveries, int64(total), nil
+}
+
+// Due retrieves pending deliveries whose next attempt is due, oldest first
+func (r *webhookDeliveryRepository) Due(ctx context.Context, now time.Time, limit int) ([]*entities.WebhookDelivery, error) {
+	entDeliveries, err := r.db(ctx).WebhookDelivery.Query().
+		Where(webhookdelivery.StatusEQ(webhookdelivery.StatusPending), webhookdelivery.NextAttemptAtLTE(now)).
+		Order(ent.Asc(webhookdelivery.FieldNextAttemptAt), ent.Asc(webhookdelivery.FieldID)).
+		Limit(limit).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]*entities.WebhookDelivery, len(entDeliveries))
+	for i, entDelivery := range entDeliveries {
+		deliveries[i] = database.EntWebhookDeliveryToDomain(entDelivery)
+	}
+	return deliveries, nil
+}
+
+// Claim moves the next attempt of a due delivery ahead. The conditions make
+// concurrent claims of the same attempt fail but one.
+func (r *webhookDeliveryRepository) Claim(ctx context.Context, id int, now, until time.Time) (bool, error) {
+	n, err := r.db(ctx).WebhookDelivery.Update().
+		Where(
+			webhookdelivery.ID(id),
+			webhookdelivery.StatusEQ(webhookdelivery.StatusPending),
+			webhookdelivery.NextAttemptAtLTE(now),
+		).
+		SetNextAttemptAt(until).
+		Save(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SaveAttempt stores the outcome of an attempt
+func (r *webhookDeliveryRepository) SaveAttempt(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	lastError := delivery.LastError
+	if len(lastError) > maxWebhookErrorLength {
+		lastError = strings.ToValidUTF8(lastError[:maxWebhookErrorLength], "")
+	}
+	err := r.db(ctx).WebhookDelivery.UpdateOneID(delivery.ID).
+		SetStatus(webhookdelivery.Status(delivery.Status)).
+		SetAttempts(delivery.Attempts).
+		SetNextAttemptAt(delivery.NextAttemptAt).
+		SetNillableLastAttemptAt(delivery.LastAttemptAt).
+		SetNillableLastStatusCode(delivery.LastStatusCode).
+		SetLastError(lastError).
+		SetNillableDeliveredAt(delivery.DeliveredAt).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// Redeliver makes a delivery pending again
+func (r *webhookDeliveryRepository) Redeliver(ctx context.Context, id int, now time.Time) error {
+	err := r.db(ctx).WebhookDelivery.UpdateOneID(id).
+		SetStatus(webhookdelivery.StatusPending).
+		SetAttempts(0).
+		SetNextAttemptAt(now).
+		ClearDeliveredAt().
+		Exec(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return errors.New("webhook delivery not found")
+		}
+		return fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// DeleteFinished removes succeeded and dead deliveries queued before the
+// given time
+func (r *webhookDeliveryRepository) DeleteFinished(ctx context.Context, before time.Time) (int, error) {
+	n, err := r.db(ctx).WebhookDelivery.Delete().
+		Where(
+			webhookdelivery.StatusIn(webhookdelivery.StatusSucceeded, webhookdelivery.StatusDead),
+			webhookdelivery.CreatedAtLT(before),
+		).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished webhook deliveries: %w", err)
+	}
+	return n, nil
+}
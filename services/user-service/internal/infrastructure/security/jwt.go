@@ -0,0 +1,226 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"app-microservice/services/user-service/internal/domain/services"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig configures the access token issuer
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+}
+
+// KeySet holds the key new tokens are signed with and every public key that
+// tokens are still accepted from. During a key rotation the previous key stays
+// in VerificationKeys until the last token signed with it has expired.
+type KeySet struct {
+	SigningKey       *rsa.PrivateKey
+	SigningKeyID     string
+	VerificationKeys map[string]*rsa.PublicKey
+}
+
+// LoadKeySet reads a PEM encoded RSA private key from signingKeyFile and any
+// additional PEM public keys from verificationKeyFiles. When signingKeyFile is
+// empty an ephemeral key is generated, so tokens do not survive a restart.
+func LoadKeySet(signingKeyFile string, verificationKeyFiles []string) (*KeySet, error) {
+	var signingKey *rsa.PrivateKey
+	if signingKeyFile == "" {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		signingKey = key
+	} else {
+		data, err := os.ReadFile(signingKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		if signingKey, err = jwt.ParseRSAPrivateKeyFromPEM(data); err != nil {
+			return nil, fmt.Errorf("failed to parse signing key: %w", err)
+		}
+	}
+
+	keys := &KeySet{
+		SigningKey:       signingKey,
+		SigningKeyID:     keyID(&signingKey.PublicKey),
+		VerificationKeys: map[string]*rsa.PublicKey{},
+	}
+	keys.VerificationKeys[keys.SigningKeyID] = &signingKey.PublicKey
+
+	for _, file := range verificationKeyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read verification key: %w", err)
+		}
+		publicKey, err := parseRSAPublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse verification key %s: %w", file, err)
+		}
+		keys.VerificationKeys[keyID(publicKey)] = publicKey
+	}
+
+	return keys, nil
+}
+
+// jwtIssuer implements services.AccessTokenIssuer with RS256 signed JWTs
+type jwtIssuer struct {
+	keys   *KeySet
+	config JWTConfig
+	parser *jwt.Parser
+}
+
+// accessTokenClaims is the JWT payload of an access token
+type accessTokenClaims struct {
+	Email     string `json:"email,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// NewJWTIssuer creates an access token issuer that signs with keys
+func NewJWTIssuer(keys *KeySet, config JWTConfig) services.AccessTokenIssuer {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &jwtIssuer{
+		keys:   keys,
+		config: config,
+		parser: jwt.NewParser(options...),
+	}
+}
+
+// Issue signs an access token for claims that expires after the configured TTL
+func (i *jwtIssuer) Issue(claims services.AccessClaims) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.config.TTL)
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", time.Time{}, err
+	}
+
+	payload := accessTokenClaims{
+		Email:     claims.Email,
+		SessionID: claims.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.config.Issuer,
+			Subject:   strconv.Itoa(claims.UserID),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        base64.RawURLEncoding.EncodeToString(jti),
+		},
+	}
+	if i.config.Audience != "" {
+		payload.Audience = jwt.ClaimStrings{i.config.Audience}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, payload)
+	token.Header["kid"] = i.keys.SigningKeyID
+
+	signed, err := token.SignedString(i.keys.SigningKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// Verify checks the signature, issuer, audience and expiry of an access token
+func (i *jwtIssuer) Verify(tokenString string) (*services.AccessClaims, error) {
+	var payload accessTokenClaims
+	_, err := i.parser.ParseWithClaims(tokenString, &payload, func(token *jwt.Token) (interface{}, error) {
+		// OAuth access tokens are signed with the same keys but are not
+		// valid for the user API
+		if typ, _ := token.Header["typ"].(string); typ == accessTokenType {
+			return nil, errors.New("oauth access token")
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := i.keys.VerificationKeys[kid]
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", services.ErrInvalidToken, err)
+	}
+
+	userID, err := strconv.Atoi(payload.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subject", services.ErrInvalidToken)
+	}
+
+	return &services.AccessClaims{
+		UserID:    userID,
+		Email:     payload.Email,
+		SessionID: payload.SessionID,
+		IssuedAt:  payload.IssuedAt.Time,
+		ExpiresAt: payload.ExpiresAt.Time,
+	}, nil
+}
+
+// JWKS returns every verification key as a JSON Web Key
+func (i *jwtIssuer) JWKS() services.JSONWebKeySet {
+	kids := make([]string, 0, len(i.keys.VerificationKeys))
+	for kid := range i.keys.VerificationKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := services.JSONWebKeySet{Keys: []services.JSONWebKey{}}
+	for _, kid := range kids {
+		key := i.keys.VerificationKeys[kid]
+		set.Keys = append(set.Keys, services.JSONWebKey{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: jwt.SigningMethodRS256.Alg(),
+			KeyID:     kid,
+			N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	return set
+}
+
+// keyID returns the RFC 7638 JWK thumbprint of an RSA public key
+func keyID(key *rsa.PublicKey) string {
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	sum := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// parseRSAPublicKey accepts a PEM public key, certificate or private key
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, errors.New("not an RSA public key")
+	}
+	return &key.PublicKey, nil
+}
@@ -0,0 +1,174 @@
+package security
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"app-microservice/services/user-service/internal/domain/services"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// accessTokenType is the typ header of OAuth access tokens (RFC 9068). It
+// keeps them apart from ID tokens and from access tokens of the user API,
+// which are signed with the same keys.
+const accessTokenType = "at+jwt"
+
+// OIDCConfig configures the tokens of the OpenID Connect provider
+type OIDCConfig struct {
+	Issuer         string // URL of the provider
+	Audience       string // audience of access tokens
+	AccessTokenTTL time.Duration
+	IDTokenTTL     time.Duration
+}
+
+// oidcIssuer implements services.OIDCTokenIssuer with RS256 signed JWTs
+type oidcIssuer struct {
+	keys   *KeySet
+	config OIDCConfig
+	parser *jwt.Parser
+}
+
+// oauthAccessTokenClaims is the JWT payload of an OAuth access token
+type oauthAccessTokenClaims struct {
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// idTokenClaims is the JWT payload of an ID token
+type idTokenClaims struct {
+	Nonce         string `json:"nonce,omitempty"`
+	AuthTime      int64  `json:"auth_time"`
+	Name          string `json:"name,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// NewOIDCTokenIssuer creates an OpenID Connect token issuer that signs with
+// keys, which should be the keys published at the JWKS endpoint
+func NewOIDCTokenIssuer(keys *KeySet, config OIDCConfig) services.OIDCTokenIssuer {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithIssuer(config.Issuer),
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &oidcIssuer{
+		keys:   keys,
+		config: config,
+		parser: jwt.NewParser(options...),
+	}
+}
+
+// IssueAccessToken signs an access token for an OAuth client
+func (i *oidcIssuer) IssueAccessToken(claims services.OAuthAccessClaims) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.config.AccessTokenTTL)
+
+	jti, err := randomID()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	payload := oauthAccessTokenClaims{
+		ClientID: claims.ClientID,
+		Scope:    claims.Scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.config.Issuer,
+			Subject:   claims.Subject,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        jti,
+		},
+	}
+	if i.config.Audience != "" {
+		payload.Audience = jwt.ClaimStrings{i.config.Audience}
+	}
+
+	signed, err := i.sign(payload, accessTokenType)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// VerifyAccessToken checks the signature, type, issuer, audience and expiry
+// of an OAuth access token
+func (i *oidcIssuer) VerifyAccessToken(tokenString string) (*services.OAuthAccessClaims, error) {
+	var payload oauthAccessTokenClaims
+	_, err := i.parser.ParseWithClaims(tokenString, &payload, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != accessTokenType {
+			return nil, errors.New("not an access token")
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := i.keys.VerificationKeys[kid]
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", services.ErrInvalidToken, err)
+	}
+	if payload.Subject == "" || payload.ClientID == "" {
+		return nil, fmt.Errorf("%w: missing subject or client", services.ErrInvalidToken)
+	}
+
+	return &services.OAuthAccessClaims{
+		Subject:   payload.Subject,
+		ClientID:  payload.ClientID,
+		Scope:     payload.Scope,
+		IssuedAt:  payload.IssuedAt.Time,
+		ExpiresAt: payload.ExpiresAt.Time,
+	}, nil
+}
+
+// IssueIDToken signs an ID token
+func (i *oidcIssuer) IssueIDToken(claims services.IDTokenClaims) (string, error) {
+	now := time.Now()
+	payload := idTokenClaims{
+		Nonce:         claims.Nonce,
+		AuthTime:      claims.AuthTime.Unix(),
+		Name:          claims.Name,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.config.Issuer,
+			Subject:   claims.Subject,
+			Audience:  jwt.ClaimStrings{claims.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(i.config.IDTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	return i.sign(payload, "JWT")
+}
+
+func (i *oidcIssuer) sign(claims jwt.Claims, typ string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = i.keys.SigningKeyID
+	token.Header["typ"] = typ
+
+	signed, err := token.SignedString(i.keys.SigningKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// randomID returns a random token ID
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
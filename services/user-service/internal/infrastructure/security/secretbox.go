@@ -0,0 +1,68 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// aesSecretBox implements services.SecretBox with AES-256-GCM. The random
+// nonce is stored in front of the ciphertext.
+type aesSecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox creates a SecretBox from a base64 encoded 32 byte key. When
+// encodedKey is empty an ephemeral key is generated, so sealed values cannot
+// be opened after a restart.
+func NewSecretBox(encodedKey string) (*aesSecretBox, error) {
+	key := make([]byte, 32)
+	if encodedKey == "" {
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+		}
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil || len(decoded) != 32 {
+			return nil, errors.New("encryption key must be 32 bytes, base64 encoded")
+		}
+		key = decoded
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &aesSecretBox{aead: aead}, nil
+}
+
+// Seal encrypts plaintext and returns the nonce and ciphertext in base64
+func (b *aesSecretBox) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value returned by Seal
+func (b *aesSecretBox) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", errors.New("invalid sealed secret")
+	}
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt secret")
+	}
+	return string(plaintext), nil
+}
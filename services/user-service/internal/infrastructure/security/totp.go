@@ -0,0 +1,128 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"app-microservice/services/user-service/internal/domain/services"
+)
+
+// TOTPConfig configures TOTP codes. The zero values of Digits and Period
+// default to the values every authenticator app supports.
+type TOTPConfig struct {
+	Issuer string
+	Digits int
+	Period time.Duration
+	Clock  services.Clock
+}
+
+// totp implements services.TOTP with HMAC-SHA1 as in RFC 6238
+type totp struct {
+	config TOTPConfig
+}
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTP creates a TOTP generator and validator
+func NewTOTP(config TOTPConfig) services.TOTP {
+	if config.Digits == 0 {
+		config.Digits = 6
+	}
+	if config.Period == 0 {
+		config.Period = 30 * time.Second
+	}
+	if config.Clock == nil {
+		config.Clock = services.SystemClock{}
+	}
+	return &totp{config: config}
+}
+
+// GenerateSecret returns a 160-bit random secret, the length RFC 4226
+// recommends for HMAC-SHA1
+func (t *totp) GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	return base32NoPadding.EncodeToString(secret), nil
+}
+
+// ProvisioningURI returns the otpauth URI in the Key Uri Format understood by
+// authenticator apps
+func (t *totp) ProvisioningURI(secret, accountName string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", t.config.Issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(t.config.Digits))
+	query.Set("period", fmt.Sprint(int(t.config.Period.Seconds())))
+
+	label := url.PathEscape(t.config.Issuer + ":" + accountName)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// GenerateCode returns the code for secret at the given time
+func (t *totp) GenerateCode(secret string, at time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return t.code(key, t.step(at)), nil
+}
+
+// Validate checks code against the previous, current and next time step
+func (t *totp) Validate(secret, code string) (int64, bool) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil || len(code) != t.config.Digits {
+		return 0, false
+	}
+
+	current := t.step(t.config.Clock.Now())
+	matched := int64(0)
+	for _, step := range []int64{current - 1, current, current + 1} {
+		// Check every step so the time taken does not reveal which one matched
+		if subtle.ConstantTimeCompare([]byte(t.code(key, step)), []byte(code)) == 1 {
+			matched = step
+		}
+	}
+	return matched, matched != 0
+}
+
+func (t *totp) step(at time.Time) int64 {
+	return at.Unix() / int64(t.config.Period.Seconds())
+}
+
+// code computes the RFC 4226 HOTP value for counter
+func (t *totp) code(key []byte, counter int64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulus := uint32(1)
+	for i := 0; i < t.config.Digits; i++ {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", t.config.Digits, value%modulus)
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	return key, nil
+}
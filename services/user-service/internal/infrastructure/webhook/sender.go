@@ -0,0 +1,126 @@
+// Package webhook posts signed events to partner URLs.
+//
+// Each request carries the Unix time it was signed at in the
+// X-Webhook-Timestamp header and an HMAC-SHA256 over "<timestamp>.<body>",
+// keyed with the webhook secret, in the X-Webhook-Signature header as
+// "sha256=<hex>". Receivers recompute the signature, compare it in constant
+// time and reject timestamps that are too old, which stops replays. The
+// X-Webhook-ID header holds the event ID, the same for every attempt, so
+// receivers can skip events they already handled.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/services"
+)
+
+// Headers of a webhook request
+const (
+	HeaderID        = "X-Webhook-ID"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// userAgent identifies webhook requests
+const userAgent = "app-microservice-webhooks/1.0"
+
+// Sign returns the signature of body sent at timestamp, in the format of the
+// X-Webhook-Signature header
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// httpSender implements services.WebhookSender with an HTTP client
+type httpSender struct {
+	client *http.Client
+	clock  services.Clock
+}
+
+// NewHTTPSender creates a sender whose requests time out after timeout.
+// Redirects are not followed; they count as failed attempts. Unless
+// allowPrivate is set, connections to addresses that are not public are
+// refused when dialing, after the name of the webhook has been resolved, so a
+// name that resolves to an internal address is caught however it was set up.
+func NewHTTPSender(timeout time.Duration, allowPrivate bool, clock services.Clock) services.WebhookSender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{Timeout: timeout, Control: dialPublicOnly}
+		transport.DialContext = dialer.DialContext
+		// A proxy would be dialed instead of the webhook, bypassing the check
+		transport.Proxy = nil
+	}
+
+	return &httpSender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		clock: clock,
+	}
+}
+
+// dialPublicOnly is a net.Dialer Control hook that refuses to connect to
+// addresses that are not public
+func dialPublicOnly(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !entities.IsPublicAddress(addr) {
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+	return nil
+}
+
+// Send posts a signed request to the webhook
+func (s *httpSender) Send(ctx context.Context, request services.WebhookRequest) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	timestamp := s.clock.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderID, request.EventID)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(request.DeliveryID))
+	req.Header.Set(HeaderEvent, request.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(request.Secret, timestamp, request.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,182 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/auditentry"
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/domain/entities"
+	domainrepos "app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestAuditor_RecordsChangesAndVerifies(t *testing.T) {
+	const dsn = "file:audit?mode=memory&cache=shared&_fk=1"
+	client := enttest.Open(t, "sqlite3", dsn)
+	defer client.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	auditRepo := repositories.NewAuditRepository(client)
+	userRepo := repositories.NewUserRepository(client)
+	auditor := usecases.NewAuditor(auditRepo, clock)
+	outbox := newTestOutbox(client, clock)
+	emailVerifier := usecases.NewEmailVerifier(userRepo, repositories.NewVerificationTokenRepository(client), &recordingMailer{}, auditor, outbox, clock, usecases.EmailVerificationConfig{
+		TokenTTL:  time.Hour,
+		VerifyURL: "http://localhost/verify-email",
+	})
+	userUseCase := usecases.NewUserUseCase(userRepo, services.NewUserDomainService(userRepo), emailVerifier, auditor, outbox, clock)
+	auditUseCase := usecases.NewAuditUseCase(auditRepo)
+
+	adminID := 42
+	ctx := usecases.WithAuditActor(context.Background(), entities.AuditActor{
+		UserID:    &adminID,
+		Email:     "admin@example.com",
+		RequestID: "req-1",
+		IP:        "203.0.113.7",
+	})
+	user, err := userUseCase.CreateUser(ctx, &dto.CreateUserRequest{Name: "John Doe", Email: "john@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	clock.Advance(time.Second)
+	if _, err := userUseCase.UpdateUserStatus(ctx, user.ID, "", &dto.UpdateUserStatusRequest{Status: "suspended", Reason: "chargeback"}); err != nil {
+		t.Fatalf("UpdateUserStatus() error = %v", err)
+	}
+	clock.Advance(time.Second)
+	if _, err := userUseCase.UpdateUser(context.Background(), user.ID, "", &dto.UpdateUserRequest{Name: "Johnny"}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+
+	list, err := auditUseCase.ListEntries(context.Background(), &dto.AuditQuery{Action: entities.AuditUserStatus})
+	if err != nil {
+		t.Fatalf("ListEntries() error = %v", err)
+	}
+	if list.Total != 1 || len(list.Entries) != 1 {
+		t.Fatalf("ListEntries(user.status) = %d entries, want 1", list.Total)
+	}
+	entry := list.Entries[0]
+	if entry.ActorID == nil || *entry.ActorID != adminID || entry.ActorEmail != "admin@example.com" ||
+		entry.RequestID != "req-1" || entry.IP != "203.0.113.7" {
+		t.Errorf("entry actor = %v %q %q %q, want admin with request req-1 from 203.0.113.7", entry.ActorID, entry.ActorEmail, entry.RequestID, entry.IP)
+	}
+	if entry.TargetType != entities.AuditTargetUser || entry.TargetID != strconv.Itoa(user.ID) {
+		t.Errorf("entry target = %s %s, want user %d", entry.TargetType, entry.TargetID, user.ID)
+	}
+	status, ok := entry.Changes["status"]
+	if len(entry.Changes) != 1 || !ok || status.Before != "pending_verification" || status.After != "suspended" {
+		t.Errorf("entry changes = %v, want only status pending_verification -> suspended", entry.Changes)
+	}
+
+	list, err = auditUseCase.ListEntries(context.Background(), &dto.AuditQuery{TargetType: entities.AuditTargetUser, TargetID: strconv.Itoa(user.ID)})
+	if err != nil {
+		t.Fatalf("ListEntries() error = %v", err)
+	}
+	if list.Total != 3 || list.Entries[0].Action != entities.AuditUserUpdate || list.Entries[2].Action != entities.AuditUserCreate {
+		t.Fatalf("ListEntries(target) = %d entries, want create, status and update newest first", list.Total)
+	}
+	if list.Entries[0].ActorID != nil || list.Entries[0].RequestID != "" {
+		t.Errorf("entry without actor = %v %q, want a system entry", list.Entries[0].ActorID, list.Entries[0].RequestID)
+	}
+	from := clock.Now().Add(-500 * time.Millisecond)
+	list, _ = auditUseCase.ListEntries(context.Background(), &dto.AuditQuery{From: &from})
+	if list.Total != 1 || list.Entries[0].Action != entities.AuditUserUpdate {
+		t.Errorf("ListEntries(from) = %d entries, want only the update", list.Total)
+	}
+
+	result, err := auditUseCase.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !result.Valid || result.Checked != 3 || result.Head != list.Entries[0].Hash {
+		t.Errorf("Verify() = %+v, want a valid chain of 3 ending at the update", result)
+	}
+
+	// The log refuses changes through Ent
+	if _, err := client.AuditEntry.Update().Where(auditentry.Action(entities.AuditUserStatus)).Save(context.Background()); err == nil {
+		t.Error("updating audit entries succeeded, want an error")
+	}
+	if _, err := client.AuditEntry.Delete().Where(auditentry.Action(entities.AuditUserStatus)).Exec(context.Background()); err == nil {
+		t.Error("deleting audit entries succeeded, want an error")
+	}
+
+	// Changes made directly in the database break the chain
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`UPDATE audit_entries SET changes = '{"status":{"before":"active","after":"active"}}' WHERE action = ?`, entities.AuditUserStatus); err != nil {
+		t.Fatalf("tampering error = %v", err)
+	}
+	result, err = auditUseCase.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if result.Valid || result.BrokenAt == nil || *result.BrokenAt != entry.ID || result.Checked != 1 {
+		t.Errorf("Verify() after tampering = %+v, want broken at entry %d", result, entry.ID)
+	}
+}
+
+// failingAuditRepository refuses every append
+type failingAuditRepository struct {
+	domainrepos.AuditRepository
+}
+
+func (failingAuditRepository) Append(ctx context.Context, entry *entities.AuditEntry) error {
+	return errors.New("audit log unavailable")
+}
+
+func TestAuditor_FailedAppendRollsBackTheChange(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:auditrollback?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	userRepo := repositories.NewUserRepository(client)
+	user := &entities.User{Name: "John Doe", Email: "john@example.com", Status: string(entities.UserStatusActive)}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	auditor := usecases.NewAuditor(failingAuditRepository{repositories.NewAuditRepository(client)}, clock)
+	if err := auditor.Record(ctx, entities.AuditUserUpdate, entities.AuditTargetUser, strconv.Itoa(user.ID), nil, nil); err == nil {
+		t.Error("Record() outside a transaction succeeded, want an error")
+	}
+
+	userUseCase := usecases.NewUserUseCase(userRepo, services.NewUserDomainService(userRepo), nil, auditor, newTestOutbox(client, clock), clock)
+	if _, err := userUseCase.UpdateUser(ctx, user.ID, "", &dto.UpdateUserRequest{Name: "Johnny"}); err == nil || err.Error() != "audit log unavailable" {
+		t.Fatalf("UpdateUser() error = %v, want the append error", err)
+	}
+
+	stored, err := userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.Name != "John Doe" || stored.Version != user.Version {
+		t.Errorf("user = %q version %d, want the update rolled back", stored.Name, stored.Version)
+	}
+	if n := client.OutboxEvent.Query().CountX(ctx); n != 0 {
+		t.Errorf("outbox holds %d events, want the event rolled back", n)
+	}
+
+	// The auditor is free again once the transaction has ended
+	auditor = usecases.NewAuditor(repositories.NewAuditRepository(client), clock)
+	userUseCase = usecases.NewUserUseCase(userRepo, services.NewUserDomainService(userRepo), nil, auditor, newTestOutbox(client, clock), clock)
+	for _, name := range []string{"Johnny", "Jack"} {
+		if _, err := userUseCase.UpdateUser(ctx, user.ID, "", &dto.UpdateUserRequest{Name: name}); err != nil {
+			t.Fatalf("UpdateUser(%s) error = %v", name, err)
+		}
+	}
+	if n := client.AuditEntry.Query().CountX(ctx); n != 2 {
+		t.Errorf("audit log holds %d entries, want 2", n)
+	}
+}
@@ -0,0 +1,102 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/internal/infrastructure/security"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestJWTIssuer_IssueAndVerify(t *testing.T) {
+	keys, err := security.LoadKeySet("", nil)
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+	issuer := security.NewJWTIssuer(keys, security.JWTConfig{Issuer: "user-service", Audience: "app", TTL: time.Minute})
+
+	token, expiresAt, err := issuer.Issue(services.AccessClaims{UserID: 7, Email: "a@example.com", SessionID: "family"})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if time.Until(expiresAt) > time.Minute {
+		t.Errorf("expiresAt = %v, want within the TTL", expiresAt)
+	}
+
+	claims, err := issuer.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if claims.UserID != 7 || claims.Email != "a@example.com" || claims.SessionID != "family" {
+		t.Errorf("claims = %+v, want user 7 in session family", claims)
+	}
+
+	jwks := issuer.JWKS()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != keys.SigningKeyID || jwks.Keys[0].Algorithm != "RS256" {
+		t.Errorf("JWKS() = %+v, want the signing key", jwks)
+	}
+
+	otherKeys, _ := security.LoadKeySet("", nil)
+	other := security.NewJWTIssuer(otherKeys, security.JWTConfig{Issuer: "user-service", Audience: "app", TTL: time.Minute})
+	if _, err := other.Verify(token); !errors.Is(err, services.ErrInvalidToken) {
+		t.Errorf("Verify() with unknown key error = %v, want ErrInvalidToken", err)
+	}
+
+	wrongAudience := security.NewJWTIssuer(keys, security.JWTConfig{Issuer: "user-service", Audience: "other", TTL: time.Minute})
+	if _, err := wrongAudience.Verify(token); !errors.Is(err, services.ErrInvalidToken) {
+		t.Errorf("Verify() with wrong audience error = %v, want ErrInvalidToken", err)
+	}
+
+	expired := security.NewJWTIssuer(keys, security.JWTConfig{Issuer: "user-service", Audience: "app", TTL: -time.Minute})
+	expiredToken, _, _ := expired.Issue(services.AccessClaims{UserID: 7})
+	if _, err := issuer.Verify(expiredToken); !errors.Is(err, services.ErrInvalidToken) {
+		t.Errorf("Verify() of expired token error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestAuthUseCase_RefreshRotationAndReuse(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:refresh?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	authUseCase, mailer := newTestAuthUseCase(client, time.Minute)
+
+	registered := registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "long enough"})
+	if registered.AccessToken == "" || registered.RefreshToken == "" || registered.TokenType != "Bearer" {
+		t.Fatalf("Register() = %+v, want tokens", registered)
+	}
+
+	rotated, err := authUseCase.Refresh(ctx, &dto.RefreshTokenRequest{RefreshToken: registered.RefreshToken})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if rotated.RefreshToken == registered.RefreshToken {
+		t.Error("Refresh() returned the same refresh token, want a rotated one")
+	}
+
+	if _, err := authUseCase.Refresh(ctx, &dto.RefreshTokenRequest{RefreshToken: registered.RefreshToken}); err == nil || err.Error() != "refresh token reuse detected" {
+		t.Errorf("Refresh() with a rotated token error = %v, want refresh token reuse detected", err)
+	}
+	if _, err := authUseCase.Refresh(ctx, &dto.RefreshTokenRequest{RefreshToken: rotated.RefreshToken}); err == nil {
+		t.Error("Refresh() after reuse succeeded, want the whole family revoked")
+	}
+
+	loggedIn, err := authUseCase.Login(ctx, &dto.LoginRequest{Email: "john@example.com", Password: "long enough"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if err := authUseCase.Logout(ctx, &dto.RefreshTokenRequest{RefreshToken: loggedIn.RefreshToken}); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := authUseCase.Refresh(ctx, &dto.RefreshTokenRequest{RefreshToken: loggedIn.RefreshToken}); err == nil {
+		t.Error("Refresh() after logout succeeded, want error")
+	}
+	if _, err := authUseCase.Refresh(ctx, &dto.RefreshTokenRequest{RefreshToken: "unknown"}); err == nil || err.Error() != "invalid refresh token" {
+		t.Errorf("Refresh() with unknown token error = %v, want invalid refresh token", err)
+	}
+}
@@ -0,0 +1,296 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/auditentry"
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/ent/outboxevent"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/domain/entities"
+	domainrepos "app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/security"
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// recordingMailer keeps every email sent so tests can follow links in them
+type recordingMailer struct {
+	sent []services.EmailMessage
+}
+
+func (m *recordingMailer) Send(ctx context.Context, message services.EmailMessage) error {
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+var linkPattern = regexp.MustCompile(`https?://\S+`)
+
+// testKeys signs the tokens of every test use case, so tokens of one can be
+// checked against the keys published by another
+var testKeys, _ = security.LoadKeySet("", nil)
+
+// lastToken returns the token query parameter of the link in the last email
+func (m *recordingMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(m.sent) == 0 {
+		t.Fatal("no email sent")
+	}
+	link, err := url.Parse(linkPattern.FindString(m.sent[len(m.sent)-1].Body))
+	if err != nil {
+		t.Fatalf("email has no valid link: %v", err)
+	}
+	token := link.Query().Get("token")
+	if token == "" {
+		t.Fatal("email link has no token")
+	}
+	return token
+}
+
+// newTestAuthUseCase wires an auth use case whose emails are recorded.
+// resendInterval throttles both verification and password reset emails.
+func newTestAuthUseCase(client *ent.Client, resendInterval time.Duration) (usecases.AuthUseCase, *recordingMailer) {
+	return newTestAuthUseCaseWithClock(client, resendInterval, services.SystemClock{})
+}
+
+// newTestAuthUseCaseWithClock is newTestAuthUseCase with TOTP codes, MFA
+// challenges, login throttling and session use timed by clock
+func newTestAuthUseCaseWithClock(client *ent.Client, resendInterval time.Duration, clock services.Clock) (usecases.AuthUseCase, *recordingMailer) {
+	userRepo := repositories.NewUserRepository(client)
+	tokenRepo := repositories.NewVerificationTokenRepository(client)
+	sessionRepo := repositories.NewSessionRepository(client)
+	passwordHasher := security.NewArgon2Hasher(testArgon2Params)
+	passwordPolicy := services.PasswordPolicy{MinLength: 8}
+	mailer := &recordingMailer{}
+	auditor := usecases.NewAuditor(repositories.NewAuditRepository(client), clock)
+	outbox := newTestOutbox(client, clock)
+	emailVerifier := usecases.NewEmailVerifier(userRepo, tokenRepo, mailer, auditor, outbox, clock, usecases.EmailVerificationConfig{
+		TokenTTL:       time.Hour,
+		ResendInterval: resendInterval,
+		VerifyURL:      "http://localhost/verify-email",
+	})
+	passwordResetter := usecases.NewPasswordResetter(userRepo, tokenRepo, sessionRepo, mailer, passwordHasher, passwordPolicy, auditor, outbox, clock, usecases.PasswordResetConfig{
+		TokenTTL:        time.Hour,
+		RequestInterval: resendInterval,
+		ResetURL:        "http://localhost/reset-password",
+	})
+	secretBox, _ := security.NewSecretBox("")
+	mfaManager := usecases.NewMFAManager(userRepo, tokenRepo, repositories.NewRecoveryCodeRepository(client), repositories.NewTxManager(client),
+		security.NewTOTP(security.TOTPConfig{Issuer: "test", Clock: clock}), secretBox, clock, usecases.MFAConfig{
+			ChallengeTTL:  5 * time.Minute,
+			MaxAttempts:   3,
+			RecoveryCodes: 4,
+		})
+	loginGuard := usecases.NewLoginGuard(repositories.NewLoginAttemptRepository(client), repositories.NewLockoutEventRepository(client), userRepo, clock, testLoginProtection)
+	authUseCase := usecases.NewAuthUseCase(
+		userRepo,
+		repositories.NewRefreshTokenRepository(client),
+		repositories.NewRoleRepository(client),
+		services.NewUserDomainService(userRepo),
+		passwordHasher,
+		passwordPolicy,
+		security.NewJWTIssuer(testKeys, security.JWTConfig{TTL: time.Minute}),
+		emailVerifier,
+		passwordResetter,
+		mfaManager,
+		loginGuard,
+		usecases.NewSessionManager(sessionRepo, clock, time.Hour),
+		auditor,
+		outbox,
+		time.Hour,
+	)
+	return syncResetAuthUseCase{authUseCase, passwordResetter}, mailer
+}
+
+// syncResetAuthUseCase waits for the reset email requested by ForgotPassword
+// to be sent, so tests can read it right away
+type syncResetAuthUseCase struct {
+	usecases.AuthUseCase
+	passwordResetter *usecases.PasswordResetter
+}
+
+func (uc syncResetAuthUseCase) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	err := uc.AuthUseCase.ForgotPassword(ctx, req)
+	uc.passwordResetter.Wait()
+	return err
+}
+
+// registerVerified registers a user, verifies its email and logs it in
+func registerVerified(t *testing.T, authUseCase usecases.AuthUseCase, mailer *recordingMailer, req *dto.RegisterRequest) *dto.AuthResponse {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := authUseCase.Register(ctx, req); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if _, err := authUseCase.VerifyEmail(ctx, &dto.VerifyEmailRequest{Token: mailer.lastToken(t)}); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	response, err := authUseCase.Login(ctx, &dto.LoginRequest{Email: req.Email, Password: req.Password})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	return response.AuthResponse
+}
+
+func TestAuthUseCase_EmailVerification(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:verification?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	authUseCase, mailer := newTestAuthUseCase(client, time.Hour)
+
+	registered, err := authUseCase.Register(ctx, &dto.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "long enough"})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if registered.Status != string(entities.UserStatusPendingVerification) {
+		t.Errorf("Register() status = %s, want pending_verification", registered.Status)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].To != "john@example.com" {
+		t.Fatalf("sent = %+v, want one email to john@example.com", mailer.sent)
+	}
+	firstToken := mailer.lastToken(t)
+
+	if _, err := authUseCase.Login(ctx, &dto.LoginRequest{Email: "john@example.com", Password: "long enough"}); err == nil || err.Error() != "email is not verified" {
+		t.Errorf("Login() before verification error = %v, want email is not verified", err)
+	}
+
+	var throttled *usecases.ThrottledError
+	if err := authUseCase.ResendVerification(ctx, &dto.ResendVerificationRequest{Email: "john@example.com"}); !errors.As(err, &throttled) {
+		t.Errorf("ResendVerification() right after register error = %v, want ThrottledError", err)
+	}
+	if err := authUseCase.ResendVerification(ctx, &dto.ResendVerificationRequest{Email: "nobody@example.com"}); err != nil {
+		t.Errorf("ResendVerification() for unknown email error = %v, want nil", err)
+	}
+	if len(mailer.sent) != 1 {
+		t.Errorf("sent %d emails, want no more after throttled and unknown resends", len(mailer.sent))
+	}
+
+	if _, err := authUseCase.VerifyEmail(ctx, &dto.VerifyEmailRequest{Token: "unknown"}); err == nil || err.Error() != "invalid verification token" {
+		t.Errorf("VerifyEmail() with unknown token error = %v, want invalid verification token", err)
+	}
+
+	verified, err := authUseCase.VerifyEmail(ctx, &dto.VerifyEmailRequest{Token: firstToken})
+	if err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if verified.Status != string(entities.UserStatusActive) {
+		t.Errorf("VerifyEmail() status = %s, want active", verified.Status)
+	}
+	if _, err := authUseCase.VerifyEmail(ctx, &dto.VerifyEmailRequest{Token: firstToken}); err == nil {
+		t.Error("VerifyEmail() with a used token succeeded, want error")
+	}
+	if _, err := authUseCase.Login(ctx, &dto.LoginRequest{Email: "john@example.com", Password: "long enough"}); err != nil {
+		t.Errorf("Login() after verification error = %v", err)
+	}
+}
+
+func TestAuthUseCase_ActivationIsRecorded(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:activation?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	authUseCase, mailer := newTestAuthUseCase(client, time.Hour)
+
+	// One user follows the verification link, the other a password reset link
+	verified, err := authUseCase.Register(ctx, &dto.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "long enough"})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if _, err := authUseCase.VerifyEmail(ctx, &dto.VerifyEmailRequest{Token: mailer.lastToken(t)}); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	reset, err := authUseCase.Register(ctx, &dto.RegisterRequest{Name: "Jane Doe", Email: "jane@example.com", Password: "long enough"})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := authUseCase.ForgotPassword(ctx, &dto.ForgotPasswordRequest{Email: "jane@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	if err := authUseCase.ResetPassword(ctx, &dto.ResetPasswordRequest{Token: mailer.lastToken(t), Password: "new password"}); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	for _, user := range []*dto.UserResponse{verified, reset} {
+		id := strconv.Itoa(user.ID)
+		if n := client.OutboxEvent.Query().Where(outboxevent.EventType(entities.EventUserActivated), outboxevent.AggregateID(id)).CountX(ctx); n != 1 {
+			t.Errorf("user %s has %d user.activated events, want 1", user.Email, n)
+		}
+		if n := client.AuditEntry.Query().Where(auditentry.Action(entities.AuditUserStatus), auditentry.TargetID(id)).CountX(ctx); n != 1 {
+			t.Errorf("user %s has %d status audit entries, want 1", user.Email, n)
+		}
+	}
+}
+
+func TestEmailVerifier_FailedActivationKeepsTheToken(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:verificationrollback?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	userRepo := repositories.NewUserRepository(client)
+	tokenRepo := repositories.NewVerificationTokenRepository(client)
+	mailer := &recordingMailer{}
+	config := usecases.EmailVerificationConfig{TokenTTL: time.Hour, VerifyURL: "http://localhost/verify-email"}
+	verifier := func(auditRepo domainrepos.AuditRepository) *usecases.EmailVerifier {
+		return usecases.NewEmailVerifier(userRepo, tokenRepo, mailer, usecases.NewAuditor(auditRepo, clock), newTestOutbox(client, clock), clock, config)
+	}
+
+	user := &entities.User{Name: "John Doe", Email: "john@example.com", Status: string(entities.UserStatusPendingVerification)}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := verifier(repositories.NewAuditRepository(client)).Send(ctx, user); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	token := mailer.lastToken(t)
+
+	if _, err := verifier(failingAuditRepository{repositories.NewAuditRepository(client)}).Verify(ctx, token); err == nil || err.Error() != "audit log unavailable" {
+		t.Fatalf("Verify() with a failing audit log error = %v, want the append error", err)
+	}
+	if stored, _ := userRepo.GetByID(ctx, user.ID); !stored.IsPendingVerification() {
+		t.Errorf("status after failed activation = %s, want pending_verification", stored.Status)
+	}
+
+	verified, err := verifier(repositories.NewAuditRepository(client)).Verify(ctx, token)
+	if err != nil {
+		t.Fatalf("Verify() again with the same token error = %v", err)
+	}
+	if verified.Status != string(entities.UserStatusActive) {
+		t.Errorf("Verify() status = %s, want active", verified.Status)
+	}
+}
+
+func TestAuthUseCase_ResendReplacesEarlierToken(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:verificationresend?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	authUseCase, mailer := newTestAuthUseCase(client, 0)
+
+	if _, err := authUseCase.Register(ctx, &dto.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "long enough"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	firstToken := mailer.lastToken(t)
+
+	if err := authUseCase.ResendVerification(ctx, &dto.ResendVerificationRequest{Email: "john@example.com"}); err != nil {
+		t.Fatalf("ResendVerification() error = %v", err)
+	}
+	secondToken := mailer.lastToken(t)
+
+	if _, err := authUseCase.VerifyEmail(ctx, &dto.VerifyEmailRequest{Token: firstToken}); err == nil {
+		t.Error("VerifyEmail() with a replaced token succeeded, want error")
+	}
+	if _, err := authUseCase.VerifyEmail(ctx, &dto.VerifyEmailRequest{Token: secondToken}); err != nil {
+		t.Errorf("VerifyEmail() with the latest token error = %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/repositories"
+	infrarepositories "app-microservice/services/user-service/internal/infrastructure/repositories"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// testLoginProtection is the brute-force protection of newTestAuthUseCase
+var testLoginProtection = usecases.LoginProtectionConfig{
+	Window:            15 * time.Minute,
+	DelayAfter:        3,
+	BaseDelay:         time.Second,
+	MaxDelay:          4 * time.Second,
+	MaxFailures:       6,
+	LockoutDuration:   15 * time.Minute,
+	IPMaxFailures:     10,
+	IPLockoutDuration: 15 * time.Minute,
+}
+
+func TestLoginAttemptRepositories(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:loginattempts?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	stores := map[string]repositories.LoginAttemptRepository{
+		"memory":   infrarepositories.NewMemoryLoginAttemptRepository(),
+		"database": infrarepositories.NewLoginAttemptRepository(client),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now().Truncate(time.Second)
+
+			attempts, err := store.Get(ctx, "account:john@example.com")
+			if err != nil || attempts.Failures != 0 || attempts.IsLocked(now) {
+				t.Fatalf("Get() of a new subject = %+v, %v, want a zero counter", attempts, err)
+			}
+
+			for i := 1; i <= 3; i++ {
+				attempts, err = store.RecordFailure(ctx, "account:john@example.com", now, time.Minute)
+				if err != nil || attempts.Failures != i {
+					t.Fatalf("RecordFailure() #%d = %+v, %v, want %d failures", i, attempts, err, i)
+				}
+			}
+			attempts, _ = store.RecordFailure(ctx, "account:john@example.com", now.Add(2*time.Minute), time.Minute)
+			if attempts.Failures != 1 {
+				t.Errorf("RecordFailure() after the window = %d failures, want counting to start over", attempts.Failures)
+			}
+			if other, _ := store.Get(ctx, "ip:10.0.0.1"); other.Failures != 0 {
+				t.Errorf("Get() of another subject = %d failures, want 0", other.Failures)
+			}
+
+			if err := store.Lock(ctx, "account:john@example.com", now.Add(time.Hour)); err != nil {
+				t.Fatalf("Lock() error = %v", err)
+			}
+			attempts, _ = store.Get(ctx, "account:john@example.com")
+			if !attempts.IsLocked(now) || attempts.IsLocked(now.Add(2*time.Hour)) || attempts.Failures != 0 {
+				t.Errorf("Get() after Lock() = %+v, want locked for an hour with failures reset", attempts)
+			}
+
+			if err := store.Reset(ctx, "account:john@example.com"); err != nil {
+				t.Fatalf("Reset() error = %v", err)
+			}
+			if attempts, _ = store.Get(ctx, "account:john@example.com"); attempts.IsLocked(now) {
+				t.Error("Get() after Reset() is still locked")
+			}
+
+			store.RecordFailure(ctx, "ip:10.0.0.1", now, time.Minute)
+			store.RecordFailure(ctx, "ip:10.0.0.2", now.Add(time.Hour), time.Minute)
+			if n, err := store.DeleteStale(ctx, now.Add(time.Minute)); err != nil || n != 1 {
+				t.Errorf("DeleteStale() = %d, %v, want the one old counter removed", n, err)
+			}
+		})
+	}
+}
+
+func TestAuthUseCase_Lockout(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:lockout?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	authUseCase, mailer := newTestAuthUseCaseWithClock(client, time.Minute, clock)
+
+	session := registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "long enough"})
+	userID := session.User.ID
+	wrong := &dto.LoginRequest{Email: "john@example.com", Password: "wrong password", ClientIP: "10.0.0.1"}
+	right := &dto.LoginRequest{Email: "john@example.com", Password: "long enough", ClientIP: "10.0.0.1"}
+
+	for i := 0; i < testLoginProtection.DelayAfter; i++ {
+		if _, err := authUseCase.Login(ctx, wrong); err == nil || err.Error() != "invalid email or password" {
+			t.Fatalf("Login() with a wrong password error = %v, want invalid email or password", err)
+		}
+	}
+
+	// From now on every failure has to wait, twice as long each time
+	var throttled *usecases.LoginThrottledError
+	if _, err := authUseCase.Login(ctx, right); !errors.As(err, &throttled) || throttled.RetryAfter != time.Second {
+		t.Fatalf("Login() right after %d failures error = %v, want LoginThrottledError with a 1s wait", testLoginProtection.DelayAfter, err)
+	}
+	clock.Advance(time.Second)
+	if _, err := authUseCase.Login(ctx, wrong); err == nil || err.Error() != "invalid email or password" {
+		t.Fatalf("Login() after waiting error = %v, want the password checked", err)
+	}
+	if _, err := authUseCase.Login(ctx, wrong); !errors.As(err, &throttled) || throttled.RetryAfter != 2*time.Second {
+		t.Errorf("Login() after another failure error = %v, want LoginThrottledError with a 2s wait", err)
+	}
+
+	for i := testLoginProtection.DelayAfter + 1; i < testLoginProtection.MaxFailures; i++ {
+		clock.Advance(testLoginProtection.MaxDelay)
+		if _, err := authUseCase.Login(ctx, wrong); err == nil || err.Error() != "invalid email or password" {
+			t.Fatalf("Login() failure #%d error = %v, want invalid email or password", i+1, err)
+		}
+	}
+
+	// The account is locked, even for the right password, but sessions stay
+	clock.Advance(testLoginProtection.MaxDelay)
+	var locked *usecases.AccountLockedError
+	if _, err := authUseCase.Login(ctx, right); !errors.As(err, &locked) {
+		t.Fatalf("Login() after %d failures error = %v, want AccountLockedError", testLoginProtection.MaxFailures, err)
+	}
+	if _, err := authUseCase.Authenticate(ctx, session.AccessToken); err != nil {
+		t.Errorf("Authenticate() while locked error = %v, want the existing session kept", err)
+	}
+	events, err := authUseCase.GetLockoutEvents(ctx, userID)
+	if err != nil || len(events) != 1 || events[0].Action != entities.LockoutActionLocked || events[0].IP != "10.0.0.1" {
+		t.Fatalf("GetLockoutEvents() = %+v, %v, want one lockout from 10.0.0.1", events, err)
+	}
+	if events[0].Failures != testLoginProtection.MaxFailures || events[0].LockedUntil == nil {
+		t.Errorf("GetLockoutEvents() lockout = %+v, want failures and lock end recorded", events[0])
+	}
+
+	// An admin can lift the lockout early
+	if err := authUseCase.UnlockUser(ctx, userID, 42); err != nil {
+		t.Fatalf("UnlockUser() error = %v", err)
+	}
+	if err := authUseCase.UnlockUser(ctx, userID, 42); err == nil || err.Error() != "user is not locked" {
+		t.Errorf("UnlockUser() twice error = %v, want user is not locked", err)
+	}
+	if err := authUseCase.UnlockUser(ctx, 9999, 42); err == nil || err.Error() != "user not found" {
+		t.Errorf("UnlockUser() for unknown user error = %v, want user not found", err)
+	}
+	loggedIn, err := authUseCase.Login(ctx, right)
+	if err != nil || loggedIn.User.Status != string(entities.UserStatusActive) {
+		t.Fatalf("Login() after unlock = %+v, %v, want an active user", loggedIn, err)
+	}
+	events, _ = authUseCase.GetLockoutEvents(ctx, userID)
+	if len(events) != 2 || events[0].Action != entities.LockoutActionUnlocked || events[0].ActorID == nil || *events[0].ActorID != 42 {
+		t.Errorf("GetLockoutEvents() after unlock = %+v, want the unlock by 42 first", events)
+	}
+
+	// A lockout also runs out by itself
+	for i := 0; i < testLoginProtection.MaxFailures; i++ {
+		clock.Advance(testLoginProtection.MaxDelay)
+		authUseCase.Login(ctx, &dto.LoginRequest{Email: "john@example.com", Password: "wrong password", ClientIP: "10.0.0.2"})
+	}
+	if _, err := authUseCase.Login(ctx, right); !errors.As(err, &locked) {
+		t.Fatalf("Login() after failing again error = %v, want AccountLockedError", err)
+	}
+	clock.Advance(testLoginProtection.LockoutDuration)
+	if _, err := authUseCase.Login(ctx, right); err != nil {
+		t.Fatalf("Login() after the lockout ran out error = %v", err)
+	}
+	events, _ = authUseCase.GetLockoutEvents(ctx, userID)
+	if len(events) != 4 || events[0].Reason != "lockout expired" {
+		t.Errorf("GetLockoutEvents() after expiry = %+v, want the expiry recorded", events)
+	}
+}
+
+func TestAuthUseCase_LockoutPerIP(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:lockoutip?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	authUseCase, mailer := newTestAuthUseCaseWithClock(client, time.Minute, clock)
+	registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "long enough"})
+
+	// Guesses spread over many unknown accounts are caught by the IP counter
+	emails := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+	for i := 0; i < testLoginProtection.IPMaxFailures; i++ {
+		clock.Advance(testLoginProtection.MaxDelay)
+		req := &dto.LoginRequest{Email: emails[i%len(emails)], Password: "guess", ClientIP: "10.0.0.1"}
+		if _, err := authUseCase.Login(ctx, req); err == nil || err.Error() != "invalid email or password" {
+			t.Fatalf("Login() guess #%d error = %v, want invalid email or password", i+1, err)
+		}
+	}
+
+	right := &dto.LoginRequest{Email: "john@example.com", Password: "long enough", ClientIP: "10.0.0.1"}
+	var throttled *usecases.LoginThrottledError
+	if _, err := authUseCase.Login(ctx, right); !errors.As(err, &throttled) || throttled.RetryAfter != testLoginProtection.IPLockoutDuration {
+		t.Errorf("Login() from the locked IP error = %v, want LoginThrottledError until the IP lockout ends", err)
+	}
+	right.ClientIP = "10.0.0.2"
+	if _, err := authUseCase.Login(ctx, right); err != nil {
+		t.Errorf("Login() from another IP error = %v", err)
+	}
+}
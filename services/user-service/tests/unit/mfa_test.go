@@ -0,0 +1,310 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/domain/entities"
+	domainrepos "app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/security"
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// fakeClock is a clock tests move by hand
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// rfc6238Secret is the SHA-1 test key of RFC 6238 appendix B in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP_RFC6238Vectors(t *testing.T) {
+	totp := security.NewTOTP(security.TOTPConfig{Issuer: "test", Digits: 8})
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		got, err := totp.GenerateCode(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateCode(%d) error = %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestTOTP_Validate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1111111109, 0)}
+	totp := security.NewTOTP(security.TOTPConfig{Issuer: "test", Clock: clock})
+
+	code, err := totp.GenerateCode(rfc6238Secret, clock.Now())
+	if err != nil {
+		t.Fatalf("GenerateCode() error = %v", err)
+	}
+	if code != "081804" {
+		t.Fatalf("GenerateCode() = %s, want the last six digits of the RFC vector", code)
+	}
+
+	step, ok := totp.Validate(rfc6238Secret, code)
+	if !ok || step != 1111111109/30 {
+		t.Errorf("Validate() = %d, %v, want step %d", step, ok, 1111111109/30)
+	}
+
+	clock.Advance(30 * time.Second)
+	if _, ok := totp.Validate(rfc6238Secret, code); !ok {
+		t.Error("Validate() one step later failed, want one step of drift allowed")
+	}
+	clock.Advance(30 * time.Second)
+	if _, ok := totp.Validate(rfc6238Secret, code); ok {
+		t.Error("Validate() two steps later succeeded, want code expired")
+	}
+	if _, ok := totp.Validate(rfc6238Secret, "12345"); ok {
+		t.Error("Validate() with a short code succeeded")
+	}
+
+	uri, err := url.Parse(totp.ProvisioningURI(rfc6238Secret, "john@example.com"))
+	if err != nil {
+		t.Fatalf("ProvisioningURI() is not a URL: %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/test:john@example.com" {
+		t.Errorf("ProvisioningURI() = %s, want otpauth://totp/test:john@example.com", uri)
+	}
+	if uri.Query().Get("secret") != rfc6238Secret || uri.Query().Get("issuer") != "test" {
+		t.Errorf("ProvisioningURI() query = %s, want secret and issuer", uri.RawQuery)
+	}
+}
+
+func TestSecretBox_SealOpen(t *testing.T) {
+	box, err := security.NewSecretBox("")
+	if err != nil {
+		t.Fatalf("NewSecretBox() error = %v", err)
+	}
+
+	sealed, err := box.Seal(rfc6238Secret)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if strings.Contains(sealed, rfc6238Secret) {
+		t.Error("Seal() output contains the plaintext")
+	}
+	opened, err := box.Open(sealed)
+	if err != nil || opened != rfc6238Secret {
+		t.Errorf("Open() = %q, %v, want the sealed secret", opened, err)
+	}
+
+	other, _ := security.NewSecretBox("")
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("Open() with another key succeeded, want error")
+	}
+	if _, err := security.NewSecretBox("c2hvcnQ="); err == nil {
+		t.Error("NewSecretBox() with a short key succeeded, want error")
+	}
+}
+
+func TestAuthUseCase_MFA(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:mfa?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	authUseCase, mailer := newTestAuthUseCaseWithClock(client, time.Minute, clock)
+	totp := security.NewTOTP(security.TOTPConfig{Issuer: "test"})
+	login := &dto.LoginRequest{Email: "john@example.com", Password: "long enough"}
+
+	session := registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "John Doe", Email: login.Email, Password: login.Password})
+	userID := session.User.ID
+	if session.User.MFAEnabled {
+		t.Fatal("new user has MFA enabled")
+	}
+
+	if _, err := authUseCase.ConfirmMFA(ctx, userID, &dto.ConfirmMFARequest{Code: "000000"}); err == nil || err.Error() != "mfa enrollment not started" {
+		t.Errorf("ConfirmMFA() before enrolling error = %v, want mfa enrollment not started", err)
+	}
+
+	enrolment, err := authUseCase.EnrollMFA(ctx, userID)
+	if err != nil {
+		t.Fatalf("EnrollMFA() error = %v", err)
+	}
+	if !strings.HasPrefix(enrolment.ProvisioningURI, "otpauth://totp/") {
+		t.Errorf("EnrollMFA() provisioning URI = %s, want otpauth://totp/...", enrolment.ProvisioningURI)
+	}
+	codeAt := func(at time.Time) string {
+		code, err := totp.GenerateCode(enrolment.Secret, at)
+		if err != nil {
+			t.Fatalf("GenerateCode() error = %v", err)
+		}
+		return code
+	}
+
+	// Enrolment is not in force until confirmed
+	if response, err := authUseCase.Login(ctx, login); err != nil || response.MFARequired {
+		t.Fatalf("Login() before confirming = %+v, %v, want tokens", response, err)
+	}
+	if _, err := authUseCase.ConfirmMFA(ctx, userID, &dto.ConfirmMFARequest{Code: codeAt(clock.Now().Add(-time.Hour))}); err == nil || err.Error() != "invalid mfa code" {
+		t.Errorf("ConfirmMFA() with an old code error = %v, want invalid mfa code", err)
+	}
+	confirmCode := codeAt(clock.Now())
+	recovery, err := authUseCase.ConfirmMFA(ctx, userID, &dto.ConfirmMFARequest{Code: confirmCode})
+	if err != nil {
+		t.Fatalf("ConfirmMFA() error = %v", err)
+	}
+	if len(recovery.RecoveryCodes) != 4 {
+		t.Fatalf("ConfirmMFA() returned %d recovery codes, want 4", len(recovery.RecoveryCodes))
+	}
+	if _, err := authUseCase.EnrollMFA(ctx, userID); err == nil || err.Error() != "mfa already enabled" {
+		t.Errorf("EnrollMFA() after confirming error = %v, want mfa already enabled", err)
+	}
+
+	challenged, err := authUseCase.Login(ctx, login)
+	if err != nil {
+		t.Fatalf("Login() with MFA error = %v", err)
+	}
+	if !challenged.MFARequired || challenged.ChallengeToken == "" || challenged.AuthResponse != nil {
+		t.Fatalf("Login() with MFA = %+v, want a challenge and no tokens", challenged)
+	}
+
+	// The code used to confirm enrolment cannot be replayed
+	if _, err := authUseCase.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenged.ChallengeToken, Code: confirmCode}); err == nil || err.Error() != "invalid mfa code" {
+		t.Errorf("VerifyMFA() replaying the confirmation code error = %v, want invalid mfa code", err)
+	}
+
+	clock.Advance(30 * time.Second)
+	code := codeAt(clock.Now())
+	verified, err := authUseCase.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenged.ChallengeToken, Code: code})
+	if err != nil {
+		t.Fatalf("VerifyMFA() error = %v", err)
+	}
+	if verified.AccessToken == "" || !verified.User.MFAEnabled {
+		t.Errorf("VerifyMFA() = %+v, want tokens for a user with MFA", verified)
+	}
+	if _, err := authUseCase.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenged.ChallengeToken, Code: code}); err == nil || err.Error() != "invalid mfa challenge" {
+		t.Errorf("VerifyMFA() with a used challenge error = %v, want invalid mfa challenge", err)
+	}
+
+	// A new challenge does not accept a code that was already used
+	challenged, _ = authUseCase.Login(ctx, login)
+	if _, err := authUseCase.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenged.ChallengeToken, Code: code}); err == nil || err.Error() != "invalid mfa code" {
+		t.Errorf("VerifyMFA() replaying a code error = %v, want invalid mfa code", err)
+	}
+
+	// Recovery codes work once and ignore case and dashes
+	recoveryCode := strings.ToUpper(strings.ReplaceAll(recovery.RecoveryCodes[0], "-", ""))
+	if _, err := authUseCase.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenged.ChallengeToken, RecoveryCode: recoveryCode}); err != nil {
+		t.Fatalf("VerifyMFA() with a recovery code error = %v", err)
+	}
+	challenged, _ = authUseCase.Login(ctx, login)
+	if _, err := authUseCase.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenged.ChallengeToken, RecoveryCode: recovery.RecoveryCodes[0]}); err == nil || err.Error() != "invalid mfa code" {
+		t.Errorf("VerifyMFA() reusing a recovery code error = %v, want invalid mfa code", err)
+	}
+
+	// The challenge is retired after MaxAttempts wrong codes, even for a right one
+	for i := 0; i < 2; i++ {
+		if _, err := authUseCase.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenged.ChallengeToken, Code: "000000"}); err == nil || err.Error() != "invalid mfa code" {
+			t.Errorf("VerifyMFA() with a wrong code error = %v, want invalid mfa code", err)
+		}
+	}
+	clock.Advance(30 * time.Second)
+	if _, err := authUseCase.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenged.ChallengeToken, Code: codeAt(clock.Now())}); err == nil || err.Error() != "invalid mfa challenge" {
+		t.Errorf("VerifyMFA() after too many attempts error = %v, want invalid mfa challenge", err)
+	}
+
+	// Challenges expire
+	challenged, _ = authUseCase.Login(ctx, login)
+	clock.Advance(6 * time.Minute)
+	if _, err := authUseCase.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenged.ChallengeToken, Code: codeAt(clock.Now())}); err == nil || err.Error() != "invalid mfa challenge" {
+		t.Errorf("VerifyMFA() with an expired challenge error = %v, want invalid mfa challenge", err)
+	}
+
+	// An admin reset turns MFA off and invalidates open challenges
+	challenged, _ = authUseCase.Login(ctx, login)
+	if err := authUseCase.ResetMFA(ctx, userID); err != nil {
+		t.Fatalf("ResetMFA() error = %v", err)
+	}
+	if _, err := authUseCase.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenged.ChallengeToken, RecoveryCode: recovery.RecoveryCodes[1]}); err == nil || err.Error() != "invalid mfa challenge" {
+		t.Errorf("VerifyMFA() after reset error = %v, want invalid mfa challenge", err)
+	}
+	if response, err := authUseCase.Login(ctx, login); err != nil || response.MFARequired {
+		t.Errorf("Login() after reset = %+v, %v, want tokens", response, err)
+	}
+	if err := authUseCase.ResetMFA(ctx, 9999); err == nil || err.Error() != "user not found" {
+		t.Errorf("ResetMFA() for unknown user error = %v, want user not found", err)
+	}
+}
+
+// failingMFAStepRepository fails to record used MFA codes
+type failingMFAStepRepository struct {
+	domainrepos.UserRepository
+}
+
+func (failingMFAStepRepository) UseMFAStep(ctx context.Context, id int, step int64) error {
+	return errors.New("database unavailable")
+}
+
+func TestMFAManager_ConfirmIsAllOrNothing(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:mfaconfirm?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	userRepo := repositories.NewUserRepository(client)
+	totp := security.NewTOTP(security.TOTPConfig{Issuer: "test", Clock: clock})
+	secretBox, _ := security.NewSecretBox("")
+	manager := func(userRepo domainrepos.UserRepository) *usecases.MFAManager {
+		return usecases.NewMFAManager(userRepo, repositories.NewVerificationTokenRepository(client), repositories.NewRecoveryCodeRepository(client),
+			repositories.NewTxManager(client), totp, secretBox, clock, usecases.MFAConfig{ChallengeTTL: time.Minute, MaxAttempts: 3, RecoveryCodes: 4})
+	}
+
+	user := &entities.User{Name: "John Doe", Email: "john@example.com", Status: string(entities.UserStatusActive)}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	secret, _, err := manager(userRepo).Enroll(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	code, _ := totp.GenerateCode(secret, clock.Now())
+
+	if _, err := manager(failingMFAStepRepository{userRepo}).Confirm(ctx, user.ID, code); err == nil {
+		t.Fatal("Confirm() with a failing write succeeded, want error")
+	}
+	if stored, _ := userRepo.GetByID(ctx, user.ID); stored.MFAEnabled() {
+		t.Error("MFA enabled after a failed confirmation")
+	}
+	if n := client.RecoveryCode.Query().CountX(ctx); n != 0 {
+		t.Errorf("%d recovery codes stored after a failed confirmation, want none", n)
+	}
+
+	codes, err := manager(userRepo).Confirm(ctx, user.ID, code)
+	if err != nil {
+		t.Fatalf("Confirm() again error = %v", err)
+	}
+	if n := client.RecoveryCode.Query().CountX(ctx); n != len(codes) || n != 4 {
+		t.Errorf("%d recovery codes stored, want the 4 returned", n)
+	}
+}
@@ -0,0 +1,303 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/delivery/http/handlers"
+	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/security"
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	testIssuer      = "http://issuer.test"
+	testRedirectURI = "http://localhost:9000/callback"
+)
+
+// pkcePair returns a PKCE verifier and its S256 challenge
+func pkcePair(seed string) (string, string) {
+	verifier := strings.Repeat(seed, 43)[:43]
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestOIDC_AuthorizationCodeFlow(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:oidcflow?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	authUseCase, mailer := newTestAuthUseCaseWithClock(client, time.Minute, clock)
+	oidcUseCase := usecases.NewOIDCUseCase(
+		repositories.NewOAuthClientRepository(client),
+		repositories.NewAuthorizationCodeRepository(client),
+		repositories.NewUserRepository(client),
+		repositories.NewSessionRepository(client),
+		security.NewOIDCTokenIssuer(testKeys, security.OIDCConfig{Issuer: testIssuer, Audience: "api", AccessTokenTTL: time.Minute, IDTokenTTL: time.Minute}),
+		clock,
+		usecases.NewAuditor(repositories.NewAuditRepository(client), clock),
+		newTestOutbox(client, clock),
+		usecases.OIDCConfig{Issuer: testIssuer + "/", CodeTTL: time.Minute},
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	oidcHandler := handlers.NewOIDCHandler(oidcUseCase)
+	router.POST("/oauth2/token", oidcHandler.Token)
+	router.GET("/oauth2/userinfo", oidcHandler.UserInfo)
+	token := func(form url.Values, clientID, secret string) (int, map[string]any) {
+		req := httptest.NewRequest(http.MethodPost, "/oauth2/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if clientID != "" {
+			req.SetBasicAuth(clientID, secret)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var body map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+	userInfo := func(accessToken string) (int, map[string]any) {
+		req := httptest.NewRequest(http.MethodGet, "/oauth2/userinfo", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var body map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+
+	if got := oidcUseCase.Discovery(); got.Issuer != testIssuer || got.TokenEndpoint != testIssuer+"/oauth2/token" {
+		t.Errorf("Discovery() = %+v, want endpoints under %s", got, testIssuer)
+	}
+
+	// Registration
+	app, err := oidcUseCase.RegisterClient(ctx, &dto.CreateOAuthClientRequest{
+		Name:         "Wiki",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{"authorization_code"},
+	})
+	if err != nil || app.ClientSecret == "" || len(app.Scopes) != 3 {
+		t.Fatalf("RegisterClient() = %+v, %v, want a secret and the default scopes", app, err)
+	}
+	invalid := []*dto.CreateOAuthClientRequest{
+		{Name: "No redirect", GrantTypes: []string{"authorization_code"}},
+		{Name: "Plain http", RedirectURIs: []string{"http://wiki.example.com/cb"}, GrantTypes: []string{"authorization_code"}},
+		{Name: "Public service", GrantTypes: []string{"client_credentials"}, Public: true},
+	}
+	for _, req := range invalid {
+		var oauthErr *usecases.OAuthError
+		if _, err := oidcUseCase.RegisterClient(ctx, req); !errors.As(err, &oauthErr) || oauthErr.Code != "invalid_client_metadata" {
+			t.Errorf("RegisterClient(%s) error = %v, want invalid_client_metadata", req.Name, err)
+		}
+	}
+
+	session := registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "long enough"})
+	principal, err := authUseCase.Authenticate(ctx, session.AccessToken)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	verifier, challenge := pkcePair("a")
+	request := func() *dto.AuthorizeRequest {
+		return &dto.AuthorizeRequest{
+			ResponseType:        "code",
+			ClientID:            app.ClientID,
+			RedirectURI:         testRedirectURI,
+			Scope:               "openid email profile",
+			State:               "xyz",
+			Nonce:               "n-0S6",
+			CodeChallenge:       challenge,
+			CodeChallengeMethod: "S256",
+		}
+	}
+	authorize := func() string {
+		t.Helper()
+		response, err := oidcUseCase.Authorize(ctx, request(), principal)
+		if err != nil {
+			t.Fatalf("Authorize() error = %v", err)
+		}
+		redirect, _ := url.Parse(response.RedirectTo)
+		if redirect.Query().Get("state") != "xyz" || redirect.Query().Get("iss") != testIssuer {
+			t.Errorf("Authorize() redirect = %s, want state and iss", response.RedirectTo)
+		}
+		return redirect.Query().Get("code")
+	}
+
+	// Bad requests are reported to the client unless the client or redirect
+	// URI cannot be trusted
+	badRequests := []struct {
+		name     string
+		change   func(*dto.AuthorizeRequest)
+		code     string
+		redirect bool
+	}{
+		{"unknown client", func(r *dto.AuthorizeRequest) { r.ClientID = "nope" }, "invalid_request", false},
+		{"unregistered redirect", func(r *dto.AuthorizeRequest) { r.RedirectURI = "http://localhost:9000/other" }, "invalid_request", false},
+		{"token response", func(r *dto.AuthorizeRequest) { r.ResponseType = "token" }, "unsupported_response_type", true},
+		{"no PKCE", func(r *dto.AuthorizeRequest) { r.CodeChallenge = "" }, "invalid_request", true},
+		{"plain PKCE", func(r *dto.AuthorizeRequest) { r.CodeChallengeMethod = "plain" }, "invalid_request", true},
+		{"unknown scope", func(r *dto.AuthorizeRequest) { r.Scope = "openid admin" }, "invalid_scope", true},
+	}
+	for _, tt := range badRequests {
+		req := request()
+		tt.change(req)
+		_, err := oidcUseCase.ValidateAuthorize(ctx, req)
+		var oauthErr *usecases.OAuthError
+		if !errors.As(err, &oauthErr) || oauthErr.Code != tt.code || (oauthErr.RedirectURI != "") != tt.redirect {
+			t.Errorf("%s: ValidateAuthorize() error = %+v, want %s with redirect %v", tt.name, err, tt.code, tt.redirect)
+		}
+	}
+
+	exchange := func(code, verifier string) url.Values {
+		return url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {testRedirectURI}, "code_verifier": {verifier}}
+	}
+
+	// A wrong verifier burns the code
+	code := authorize()
+	if status, body := token(exchange(code, "wrong-verifier-wrong-verifier-wrong-verifier"), app.ClientID, app.ClientSecret); status != http.StatusBadRequest || body["error"] != "invalid_grant" {
+		t.Errorf("token with wrong verifier = %d %v, want 400 invalid_grant", status, body)
+	}
+	if status, _ := token(exchange(code, verifier), app.ClientID, app.ClientSecret); status != http.StatusBadRequest {
+		t.Errorf("token after failed exchange = %d, want 400", status)
+	}
+
+	// Wrong client credentials
+	code = authorize()
+	if status, body := token(exchange(code, verifier), app.ClientID, "wrong"); status != http.StatusUnauthorized || body["error"] != "invalid_client" {
+		t.Errorf("token with wrong secret = %d %v, want 401 invalid_client", status, body)
+	}
+
+	// Successful exchange
+	status, body := token(exchange(code, verifier), app.ClientID, app.ClientSecret)
+	if status != http.StatusOK || body["token_type"] != "Bearer" || body["id_token"] == nil {
+		t.Fatalf("token = %d %v, want tokens", status, body)
+	}
+	if status, _ := token(exchange(code, verifier), app.ClientID, app.ClientSecret); status != http.StatusBadRequest {
+		t.Errorf("token with reused code = %d, want 400", status)
+	}
+
+	// The ID token verifies against the published keys
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(body["id_token"].(string), claims, func(token *jwt.Token) (interface{}, error) {
+		for _, key := range authUseCase.JWKS().Keys {
+			if key.KeyID == token.Header["kid"] {
+				n, _ := base64.RawURLEncoding.DecodeString(key.N)
+				e, _ := base64.RawURLEncoding.DecodeString(key.E)
+				return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+			}
+		}
+		return nil, errors.New("unknown key")
+	}, jwt.WithIssuer(testIssuer), jwt.WithAudience(app.ClientID))
+	if err != nil {
+		t.Fatalf("ID token does not verify: %v", err)
+	}
+	if claims["sub"] != strconv.Itoa(session.User.ID) || claims["nonce"] != "n-0S6" || claims["email"] != "john@example.com" || claims["email_verified"] != true {
+		t.Errorf("ID token claims = %v, want the user, nonce and email", claims)
+	}
+
+	// UserInfo takes OAuth access tokens only
+	accessToken := body["access_token"].(string)
+	if status, info := userInfo(accessToken); status != http.StatusOK || info["name"] != "John Doe" || info["sub"] != strconv.Itoa(session.User.ID) {
+		t.Errorf("userinfo = %d %v, want the claims of John Doe", status, info)
+	}
+	if status, _ := userInfo(session.AccessToken); status != http.StatusUnauthorized {
+		t.Errorf("userinfo with a user API token = %d, want 401", status)
+	}
+	if _, err := authUseCase.Authenticate(ctx, accessToken); err == nil {
+		t.Error("Authenticate() accepted an OAuth access token")
+	}
+
+	// Codes expire
+	code = authorize()
+	clock.Advance(2 * time.Minute)
+	if status, body := token(exchange(code, verifier), app.ClientID, app.ClientSecret); status != http.StatusBadRequest || body["error"] != "invalid_grant" {
+		t.Errorf("token with expired code = %d %v, want 400 invalid_grant", status, body)
+	}
+	if purged, err := oidcUseCase.PurgeExpiredCodes(ctx); err != nil || purged != 3 {
+		t.Errorf("PurgeExpiredCodes() = %d, %v, want every code issued", purged, err)
+	}
+}
+
+func TestOIDC_ClientCredentials(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:oidcclientcredentials?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	oidcUseCase := usecases.NewOIDCUseCase(
+		repositories.NewOAuthClientRepository(client),
+		repositories.NewAuthorizationCodeRepository(client),
+		repositories.NewUserRepository(client),
+		repositories.NewSessionRepository(client),
+		security.NewOIDCTokenIssuer(testKeys, security.OIDCConfig{Issuer: testIssuer, Audience: "api", AccessTokenTTL: time.Minute, IDTokenTTL: time.Minute}),
+		&fakeClock{now: time.Now()},
+		usecases.NewAuditor(repositories.NewAuditRepository(client), &fakeClock{now: time.Now()}),
+		newTestOutbox(client, &fakeClock{now: time.Now()}),
+		usecases.OIDCConfig{Issuer: testIssuer, CodeTTL: time.Minute},
+	)
+
+	service, err := oidcUseCase.RegisterClient(ctx, &dto.CreateOAuthClientRequest{
+		Name:       "Billing",
+		GrantTypes: []string{"client_credentials"},
+		Scopes:     []string{"openid", "users:read", "users:write"},
+	})
+	if err != nil {
+		t.Fatalf("RegisterClient() error = %v", err)
+	}
+
+	response, err := oidcUseCase.Token(ctx, &dto.TokenRequest{GrantType: "client_credentials", ClientID: service.ClientID, ClientSecret: service.ClientSecret})
+	if err != nil || response.Scope != "users:read users:write" || response.IDToken != "" {
+		t.Fatalf("Token() = %+v, %v, want every scope of the client and no ID token", response, err)
+	}
+	if _, err := oidcUseCase.UserInfo(ctx, response.AccessToken); err == nil {
+		t.Error("UserInfo() accepted a client credentials token")
+	}
+
+	response, err = oidcUseCase.Token(ctx, &dto.TokenRequest{GrantType: "client_credentials", Scope: "users:read", ClientID: service.ClientID, ClientSecret: service.ClientSecret})
+	if err != nil || response.Scope != "users:read" {
+		t.Errorf("Token() with scope = %+v, %v, want users:read", response, err)
+	}
+
+	failures := []struct {
+		name string
+		req  *dto.TokenRequest
+		code string
+	}{
+		{"no secret", &dto.TokenRequest{GrantType: "client_credentials", ClientID: service.ClientID}, "invalid_client"},
+		{"extra scope", &dto.TokenRequest{GrantType: "client_credentials", Scope: "openid", ClientID: service.ClientID, ClientSecret: service.ClientSecret}, "invalid_scope"},
+		{"code grant", &dto.TokenRequest{GrantType: "authorization_code", Code: "x", ClientID: service.ClientID, ClientSecret: service.ClientSecret}, "unauthorized_client"},
+		{"password grant", &dto.TokenRequest{GrantType: "password", ClientID: service.ClientID, ClientSecret: service.ClientSecret}, "unsupported_grant_type"},
+	}
+	for _, tt := range failures {
+		var oauthErr *usecases.OAuthError
+		if _, err := oidcUseCase.Token(ctx, tt.req); !errors.As(err, &oauthErr) || oauthErr.Code != tt.code {
+			t.Errorf("%s: Token() error = %v, want %s", tt.name, err, tt.code)
+		}
+	}
+
+	// Deleted clients can no longer get tokens
+	if err := oidcUseCase.DeleteClient(ctx, service.ClientID); err != nil {
+		t.Fatalf("DeleteClient() error = %v", err)
+	}
+	var oauthErr *usecases.OAuthError
+	if _, err := oidcUseCase.Token(ctx, &dto.TokenRequest{GrantType: "client_credentials", ClientID: service.ClientID, ClientSecret: service.ClientSecret}); !errors.As(err, &oauthErr) || oauthErr.Code != "invalid_client" {
+		t.Errorf("Token() for deleted client error = %v, want invalid_client", err)
+	}
+}
@@ -0,0 +1,311 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent"
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/ent/outboxevent"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/internal/infrastructure/events"
+	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newTestOutbox creates an outbox storing events in client
+func newTestOutbox(client *ent.Client, clock services.Clock) *usecases.Outbox {
+	return usecases.NewOutbox(repositories.NewTxManager(client), repositories.NewOutboxRepository(client), clock)
+}
+
+func TestOutbox_StoresEventsWithChanges(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:outbox?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	ctx := context.Background()
+	userRepo := repositories.NewUserRepository(client)
+	auditor := usecases.NewAuditor(repositories.NewAuditRepository(client), clock)
+	outbox := newTestOutbox(client, clock)
+	emailVerifier := usecases.NewEmailVerifier(userRepo, repositories.NewVerificationTokenRepository(client), &recordingMailer{}, auditor, outbox, clock, usecases.EmailVerificationConfig{
+		TokenTTL:  time.Hour,
+		VerifyURL: "http://localhost/verify-email",
+	})
+	userUseCase := usecases.NewUserUseCase(userRepo, services.NewUserDomainService(userRepo), emailVerifier, auditor, outbox, clock)
+
+	user, err := userUseCase.CreateUser(ctx, &dto.CreateUserRequest{Name: "John Doe", Email: "john@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if _, err := userUseCase.UpdateUserStatus(ctx, user.ID, "", &dto.UpdateUserStatusRequest{Status: "suspended", Reason: "chargeback"}); err != nil {
+		t.Fatalf("UpdateUserStatus() error = %v", err)
+	}
+	if err := userUseCase.DeleteUser(ctx, user.ID, ""); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	stored := client.OutboxEvent.Query().Order(ent.Asc(outboxevent.FieldOccurredAt), ent.Asc(outboxevent.FieldEventType)).AllX(ctx)
+	want := []string{entities.EventUserCreated, entities.EventUserDeleted, entities.EventUserSuspended}
+	if len(stored) != len(want) {
+		t.Fatalf("outbox has %d events, want %d", len(stored), len(want))
+	}
+	for i, event := range stored {
+		if event.EventType != want[i] || event.AggregateType != entities.EventAggregateUser || event.AggregateID != strconv.Itoa(user.ID) {
+			t.Errorf("event %d = %s %s %s, want %s about user %d", i, event.EventType, event.AggregateType, event.AggregateID, want[i], user.ID)
+		}
+		if event.PublishedAt != nil || !event.NextAttemptAt.Equal(event.OccurredAt) {
+			t.Errorf("event %d is not due for publishing", i)
+		}
+	}
+	var data dto.UserResponse
+	if err := json.Unmarshal([]byte(stored[0].Data), &data); err != nil || data.ID != user.ID || data.Email != "john@example.com" {
+		t.Errorf("user.created data = %s, want the created user", stored[0].Data)
+	}
+
+	// A change that fails stores no events, and events that cannot be stored
+	// undo the change
+	err = outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := userRepo.Create(ctx, &entities.User{Name: "Jane Doe", Email: "jane@example.com", Status: string(entities.UserStatusActive)}); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("change failed")
+	})
+	if err == nil || err.Error() != "change failed" {
+		t.Fatalf("Within(failing change) error = %v, want change failed", err)
+	}
+	duplicate := &entities.DomainEvent{ID: stored[0].ID, Type: entities.EventUserCreated, AggregateType: entities.EventAggregateUser, AggregateID: "0", Data: json.RawMessage("{}"), OccurredAt: clock.Now()}
+	err = outbox.Within(ctx, func(ctx context.Context) ([]*entities.DomainEvent, error) {
+		if err := userRepo.Create(ctx, &entities.User{Name: "Jane Doe", Email: "jane@example.com", Status: string(entities.UserStatusActive)}); err != nil {
+			return nil, err
+		}
+		return []*entities.DomainEvent{duplicate}, nil
+	})
+	if err == nil {
+		t.Fatal("Within(duplicate event) succeeded, want an error")
+	}
+	if n := client.OutboxEvent.Query().CountX(ctx); n != len(want) {
+		t.Errorf("outbox has %d events after failed changes, want %d", n, len(want))
+	}
+	if _, err := userRepo.GetByEmail(ctx, "jane@example.com"); err == nil || err.Error() != "user not found" {
+		t.Errorf("GetByEmail() after failed changes error = %v, want user not found", err)
+	}
+}
+
+// flakyBroker fails the first failures publishes and records the rest
+type flakyBroker struct {
+	failures  int
+	published []*entities.DomainEvent
+}
+
+func (b *flakyBroker) Publish(ctx context.Context, event *entities.DomainEvent) error {
+	if b.failures > 0 {
+		b.failures--
+		return errors.New("broker unavailable")
+	}
+	b.published = append(b.published, event)
+	return nil
+}
+
+func TestOutboxRelay_RetriesUntilPublished(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:outboxrelay?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	ctx := context.Background()
+	outboxRepo := repositories.NewOutboxRepository(client)
+	broker := &flakyBroker{failures: 2}
+	relay := usecases.NewOutboxRelay(outboxRepo, broker, clock, usecases.OutboxRelayConfig{
+		BatchSize: 10,
+		Lease:     time.Minute,
+		RetryBase: time.Second,
+		RetryMax:  time.Minute,
+		Retention: time.Hour,
+	})
+
+	event, err := entities.NewDomainEvent(entities.EventUserUpdated, entities.EventAggregateUser, "7", map[string]string{"name": "John"}, clock.Now())
+	if err != nil {
+		t.Fatalf("NewDomainEvent() error = %v", err)
+	}
+	if err := outboxRepo.Add(ctx, event); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	relayDue := func(want int) {
+		t.Helper()
+		published, err := relay.RelayDue(ctx)
+		if err != nil {
+			t.Fatalf("RelayDue() error = %v", err)
+		}
+		if published != want {
+			t.Fatalf("RelayDue() = %d, want %d", published, want)
+		}
+	}
+
+	// Each failure doubles the delay before the next attempt
+	relayDue(0)
+	stored := client.OutboxEvent.GetX(ctx, event.ID)
+	if stored.Attempts != 1 || stored.LastError != "broker unavailable" || !stored.NextAttemptAt.Equal(clock.Now().Add(time.Second)) {
+		t.Fatalf("after first failure = %d attempts, %q, next at %s", stored.Attempts, stored.LastError, stored.NextAttemptAt)
+	}
+	relayDue(0)
+	clock.Advance(time.Second)
+	relayDue(0)
+	stored = client.OutboxEvent.GetX(ctx, event.ID)
+	if stored.Attempts != 2 || !stored.NextAttemptAt.Equal(clock.Now().Add(2*time.Second)) {
+		t.Fatalf("after second failure = %d attempts, next at %s", stored.Attempts, stored.NextAttemptAt)
+	}
+	clock.Advance(2 * time.Second)
+	relayDue(1)
+	relayDue(0)
+
+	if len(broker.published) != 1 || broker.published[0].ID != event.ID || string(broker.published[0].Data) != `{"name":"John"}` {
+		t.Fatalf("published = %v, want the event once", broker.published)
+	}
+	if stored = client.OutboxEvent.GetX(ctx, event.ID); stored.PublishedAt == nil {
+		t.Error("event not marked published")
+	}
+
+	clock.Advance(time.Hour + time.Second)
+	if purged, err := relay.PurgePublished(ctx); err != nil || purged != 1 {
+		t.Errorf("PurgePublished() = %d, %v, want 1", purged, err)
+	}
+}
+
+func TestMemoryBroker_Publish(t *testing.T) {
+	broker := events.NewMemoryBroker()
+	var received []string
+	broker.Subscribe(entities.EventUserCreated, func(ctx context.Context, event *entities.DomainEvent) error {
+		received = append(received, "created:"+event.ID)
+		return nil
+	})
+	broker.Subscribe(events.AllEvents, func(ctx context.Context, event *entities.DomainEvent) error {
+		received = append(received, "all:"+event.ID)
+		if event.Type == entities.EventUserDeleted {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	if err := broker.Publish(ctx, &entities.DomainEvent{ID: "1", Type: entities.EventUserCreated}); err != nil {
+		t.Fatalf("Publish(user.created) error = %v", err)
+	}
+	if err := broker.Publish(ctx, &entities.DomainEvent{ID: "2", Type: entities.EventUserDeleted}); err == nil || !strings.Contains(err.Error(), "handler failed") {
+		t.Fatalf("Publish(user.deleted) error = %v, want the handler error", err)
+	}
+	if got := strings.Join(received, ","); got != "created:1,all:1,all:2" {
+		t.Errorf("received = %s, want created:1,all:1,all:2", got)
+	}
+}
+
+// natsMessage is a message received by fakeNATSServer
+type natsMessage struct {
+	subject string
+	headers string
+	payload string
+}
+
+// fakeNATSServer speaks enough of the NATS protocol to accept HPUB messages.
+// Messages on the subject reject are answered with -ERR.
+func fakeNATSServer(t *testing.T, reject string) (string, <-chan natsMessage) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan natsMessage, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"headers\":true,\"max_payload\":1048576}\r\n")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					fields := strings.Fields(line)
+					switch {
+					case len(fields) == 0:
+					case fields[0] == "PING":
+						fmt.Fprint(conn, "PONG\r\n")
+					case fields[0] == "HPUB" && len(fields) == 4:
+						headerLen, _ := strconv.Atoi(fields[2])
+						totalLen, _ := strconv.Atoi(fields[3])
+						body := make([]byte, totalLen+2)
+						if _, err := io.ReadFull(reader, body); err != nil {
+							return
+						}
+						if fields[1] == reject {
+							fmt.Fprint(conn, "-ERR 'Permissions Violation for Publish'\r\n")
+							continue
+						}
+						messages <- natsMessage{subject: fields[1], headers: string(body[:headerLen]), payload: string(body[headerLen:totalLen])}
+					}
+				}
+			}()
+		}
+	}()
+	return "nats://" + listener.Addr().String(), messages
+}
+
+func TestNATSBroker_Publish(t *testing.T) {
+	url, messages := fakeNATSServer(t, "app.user.deleted")
+	broker, err := events.NewNATSBroker(events.NATSConfig{URL: url, SubjectPrefix: "app", Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("NewNATSBroker() error = %v", err)
+	}
+	defer broker.Close()
+
+	event, _ := entities.NewDomainEvent(entities.EventUserCreated, entities.EventAggregateUser, "7", map[string]string{"email": "john@example.com"}, time.Now())
+	ctx := context.Background()
+	if err := broker.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	msg := <-messages
+	if msg.subject != "app.user.created" {
+		t.Errorf("subject = %s, want app.user.created", msg.subject)
+	}
+	if !strings.HasPrefix(msg.headers, "NATS/1.0\r\n") || !strings.Contains(msg.headers, "Nats-Msg-Id: "+event.ID+"\r\n") {
+		t.Errorf("headers = %q, want Nats-Msg-Id %s", msg.headers, event.ID)
+	}
+	var decoded entities.DomainEvent
+	if err := json.Unmarshal([]byte(msg.payload), &decoded); err != nil || decoded.ID != event.ID || decoded.AggregateID != "7" {
+		t.Errorf("payload = %s, want the event", msg.payload)
+	}
+
+	// A rejected message fails and the next one reconnects
+	if err := broker.Publish(ctx, &entities.DomainEvent{ID: "2", Type: entities.EventUserDeleted}); err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Fatalf("Publish(rejected) error = %v, want the server error", err)
+	}
+	if err := broker.Publish(ctx, &entities.DomainEvent{ID: "3", Type: entities.EventUserUpdated}); err != nil {
+		t.Fatalf("Publish() after error = %v", err)
+	}
+	if msg := <-messages; msg.subject != "app.user.updated" {
+		t.Errorf("subject = %s, want app.user.updated", msg.subject)
+	}
+
+	unreachable, _ := events.NewNATSBroker(events.NATSConfig{URL: "nats://127.0.0.1:1", Timeout: time.Second})
+	if err := unreachable.Publish(ctx, event); err == nil {
+		t.Error("Publish() to an unreachable server succeeded, want an error")
+	}
+}
@@ -0,0 +1,135 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/security"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestAuthUseCase_PasswordReset(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:passwordreset?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	authUseCase, mailer := newTestAuthUseCase(client, time.Hour)
+	session := registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "old password"})
+	sent := len(mailer.sent)
+
+	if err := authUseCase.ForgotPassword(ctx, &dto.ForgotPasswordRequest{Email: "nobody@example.com"}); err != nil {
+		t.Errorf("ForgotPassword() for unknown email error = %v, want nil", err)
+	}
+	if len(mailer.sent) != sent {
+		t.Fatalf("ForgotPassword() for unknown email sent an email")
+	}
+
+	if err := authUseCase.ForgotPassword(ctx, &dto.ForgotPasswordRequest{Email: "john@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	if len(mailer.sent) != sent+1 {
+		t.Fatalf("ForgotPassword() sent %d emails, want 1", len(mailer.sent)-sent)
+	}
+	resetToken := mailer.lastToken(t)
+
+	// A second request within the interval succeeds without sending anything
+	if err := authUseCase.ForgotPassword(ctx, &dto.ForgotPasswordRequest{Email: "john@example.com"}); err != nil {
+		t.Errorf("throttled ForgotPassword() error = %v, want nil", err)
+	}
+	if len(mailer.sent) != sent+1 {
+		t.Errorf("throttled ForgotPassword() sent an email")
+	}
+
+	if err := authUseCase.ResetPassword(ctx, &dto.ResetPasswordRequest{Token: resetToken, Password: "short"}); !errors.Is(err, services.ErrWeakPassword) {
+		t.Errorf("ResetPassword() with weak password error = %v, want ErrWeakPassword", err)
+	}
+	if _, err := authUseCase.Authenticate(ctx, session.AccessToken); err != nil {
+		t.Fatalf("Authenticate() before reset error = %v", err)
+	}
+
+	// Access tokens carry iat in whole seconds; make sure the reset is later
+	time.Sleep(1100 * time.Millisecond)
+	if err := authUseCase.ResetPassword(ctx, &dto.ResetPasswordRequest{Token: resetToken, Password: "new password"}); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if err := authUseCase.ResetPassword(ctx, &dto.ResetPasswordRequest{Token: resetToken, Password: "another password"}); err == nil || err.Error() != "invalid password reset token" {
+		t.Errorf("ResetPassword() with a used token error = %v, want invalid password reset token", err)
+	}
+
+	if _, err := authUseCase.Authenticate(ctx, session.AccessToken); err == nil {
+		t.Error("Authenticate() with an access token from before the reset succeeded, want error")
+	}
+	if _, err := authUseCase.Refresh(ctx, &dto.RefreshTokenRequest{RefreshToken: session.RefreshToken}); err == nil {
+		t.Error("Refresh() with a refresh token from before the reset succeeded, want error")
+	}
+	if _, err := authUseCase.Login(ctx, &dto.LoginRequest{Email: "john@example.com", Password: "old password"}); err == nil {
+		t.Error("Login() with the old password succeeded, want error")
+	}
+	relogin, err := authUseCase.Login(ctx, &dto.LoginRequest{Email: "john@example.com", Password: "new password"})
+	if err != nil {
+		t.Fatalf("Login() with the new password error = %v", err)
+	}
+	if _, err := authUseCase.Authenticate(ctx, relogin.AccessToken); err != nil {
+		t.Errorf("Authenticate() with a new access token error = %v", err)
+	}
+}
+
+// blockingMailer holds every email until released
+type blockingMailer struct {
+	release chan struct{}
+	sent    chan services.EmailMessage
+}
+
+func (m *blockingMailer) Send(ctx context.Context, message services.EmailMessage) error {
+	<-m.release
+	m.sent <- message
+	return nil
+}
+
+func TestPasswordResetter_RequestTakesTheSameTimeForUnknownEmails(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:passwordresetrequest?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	userRepo := repositories.NewUserRepository(client)
+	if err := userRepo.Create(ctx, &entities.User{Name: "John Doe", Email: "john@example.com", Status: string(entities.UserStatusActive)}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	mailer := &blockingMailer{release: make(chan struct{}), sent: make(chan services.EmailMessage, 2)}
+	resetter := usecases.NewPasswordResetter(userRepo, repositories.NewVerificationTokenRepository(client), repositories.NewSessionRepository(client),
+		mailer, security.NewArgon2Hasher(testArgon2Params), services.PasswordPolicy{MinLength: 8},
+		usecases.NewAuditor(repositories.NewAuditRepository(client), clock), newTestOutbox(client, clock), clock,
+		usecases.PasswordResetConfig{TokenTTL: time.Hour, ResetURL: "http://localhost/reset-password"})
+
+	// Neither request waits for the mailer, which holds the email to john
+	for _, email := range []string{"john@example.com", "nobody@example.com"} {
+		returned := make(chan error, 1)
+		go func() { returned <- resetter.Request(ctx, email) }()
+		select {
+		case err := <-returned:
+			if err != nil {
+				t.Errorf("Request(%s) error = %v, want nil", email, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Request(%s) waited for the email to be sent", email)
+		}
+	}
+
+	close(mailer.release)
+	resetter.Wait()
+	if len(mailer.sent) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(mailer.sent))
+	}
+	if message := <-mailer.sent; message.To != "john@example.com" {
+		t.Errorf("email sent to %s, want john@example.com", message.To)
+	}
+}
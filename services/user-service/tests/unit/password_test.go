@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/internal/infrastructure/security"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testArgon2Params = security.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestArgon2Hasher_HashAndVerify(t *testing.T) {
+	hasher := security.NewArgon2Hasher(testArgon2Params)
+
+	encoded, err := hasher.Hash("Correct horse 1")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Hash() = %q, want a PHC encoded argon2id hash", encoded)
+	}
+	if again, _ := hasher.Hash("Correct horse 1"); again == encoded {
+		t.Error("Hash() returned the same encoding twice, want a random salt")
+	}
+
+	if match, rehash, err := hasher.Verify("Correct horse 1", encoded); err != nil || !match || rehash {
+		t.Errorf("Verify(correct) = %v, %v, %v, want match without rehash", match, rehash, err)
+	}
+	if match, _, err := hasher.Verify("correct horse 1", encoded); err != nil || match {
+		t.Errorf("Verify(wrong) = %v, %v, want no match", match, err)
+	}
+	if _, _, err := hasher.Verify("Correct horse 1", "$2a$10$notargon"); err == nil {
+		t.Error("Verify() of a foreign hash succeeded, want error")
+	}
+
+	stronger := security.NewArgon2Hasher(security.Argon2Params{Memory: 2048, Iterations: 1, Parallelism: 1})
+	if match, rehash, err := stronger.Verify("Correct horse 1", encoded); err != nil || !match || !rehash {
+		t.Errorf("Verify() with new parameters = %v, %v, %v, want match with rehash", match, rehash, err)
+	}
+}
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := services.PasswordPolicy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true}
+
+	tests := []struct {
+		password string
+		wantErr  bool
+	}{
+		{"Sup3rsecret", false},
+		{"Short1A", true},
+		{"alllowercase1", true},
+		{"ALLUPPERCASE1", true},
+		{"NoDigitsHere", true},
+		{strings.Repeat("Aa1", 50), true},
+	}
+
+	for _, tt := range tests {
+		err := policy.Validate(tt.password)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.password, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, services.ErrWeakPassword) {
+			t.Errorf("Validate(%q) error = %v, want ErrWeakPassword", tt.password, err)
+		}
+	}
+}
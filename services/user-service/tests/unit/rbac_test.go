@@ -0,0 +1,143 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/delivery/http/middleware"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestRoleRepository_AssignAndResolvePermissions(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:rbac?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	roleRepo := repositories.NewRoleRepository(client)
+	userRepo := repositories.NewUserRepository(client)
+
+	// Running twice must not duplicate roles or permissions
+	for i := 0; i < 2; i++ {
+		if err := roleRepo.EnsureRoles(ctx, entities.DefaultRoles, entities.PermissionDescriptions); err != nil {
+			t.Fatalf("EnsureRoles() error = %v", err)
+		}
+	}
+	roles, err := roleRepo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if len(roles) != len(entities.DefaultRoles) {
+		t.Fatalf("GetAll() returned %d roles, want %d", len(roles), len(entities.DefaultRoles))
+	}
+
+	user := &entities.User{Name: "John Doe", Email: "john@example.com", Status: string(entities.UserStatusActive)}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := roleRepo.AssignRole(ctx, user.ID, "missing"); err == nil || err.Error() != "role not found" {
+		t.Errorf("AssignRole() with unknown role error = %v, want role not found", err)
+	}
+	if err := roleRepo.AssignRole(ctx, user.ID+100, "support"); err == nil || err.Error() != "user not found" {
+		t.Errorf("AssignRole() with unknown user error = %v, want user not found", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := roleRepo.AssignRole(ctx, user.ID, "support"); err != nil {
+			t.Fatalf("AssignRole() error = %v", err)
+		}
+	}
+	permissions, err := roleRepo.GetUserPermissions(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserPermissions() error = %v", err)
+	}
+	want := []string{entities.PermissionUsersRead, entities.PermissionUsersStatus}
+	if len(permissions) != len(want) || permissions[0] != want[0] || permissions[1] != want[1] {
+		t.Errorf("GetUserPermissions() = %v, want %v", permissions, want)
+	}
+
+	if err := roleRepo.RevokeRole(ctx, user.ID, "support"); err != nil {
+		t.Fatalf("RevokeRole() error = %v", err)
+	}
+	userRoles, err := roleRepo.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserRoles() error = %v", err)
+	}
+	if len(userRoles) != 0 {
+		t.Errorf("GetUserRoles() after revoke = %v, want none", userRoles)
+	}
+}
+
+func TestAuthMiddleware_PermissionChecks(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:rbacmiddleware?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	authUseCase, mailer := newTestAuthUseCase(client, time.Minute)
+	roleUseCase := usecases.NewRoleUseCase(repositories.NewRoleRepository(client), repositories.NewUserRepository(client), usecases.NewAuditor(repositories.NewAuditRepository(client), &fakeClock{now: time.Now()}), newTestOutbox(client, &fakeClock{now: time.Now()}))
+	if err := roleUseCase.EnsureDefaultRoles(ctx); err != nil {
+		t.Fatalf("EnsureDefaultRoles() error = %v", err)
+	}
+
+	alice := registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "Alice", Email: "alice@example.com", Password: "long enough"})
+	bob := registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "Bob", Email: "bob@example.com", Password: "long enough"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	users := router.Group("/users", middleware.AuthMiddleware(authUseCase))
+	users.GET("/:id", middleware.RequirePermissionOrSelf(entities.PermissionUsersRead), ok)
+	users.DELETE("/:id", middleware.RequirePermission(entities.PermissionUsersDelete), ok)
+
+	request := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	alicePath := "/users/" + strconv.Itoa(alice.User.ID)
+	bobPath := "/users/" + strconv.Itoa(bob.User.ID)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"no token", http.MethodGet, alicePath, "", http.StatusUnauthorized},
+		{"bad token", http.MethodGet, alicePath, "garbage", http.StatusUnauthorized},
+		{"own profile", http.MethodGet, alicePath, alice.AccessToken, http.StatusOK},
+		{"other profile", http.MethodGet, bobPath, alice.AccessToken, http.StatusForbidden},
+		{"delete without permission", http.MethodDelete, bobPath, alice.AccessToken, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if got := request(tt.method, tt.path, tt.token); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// Roles take effect on the next request without issuing a new token
+	if err := roleUseCase.BootstrapAdmin(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("BootstrapAdmin() error = %v", err)
+	}
+	if got := request(http.MethodGet, bobPath, alice.AccessToken); got != http.StatusOK {
+		t.Errorf("admin reading other profile: status = %d, want 200", got)
+	}
+	if got := request(http.MethodDelete, bobPath, alice.AccessToken); got != http.StatusOK {
+		t.Errorf("admin deleting: status = %d, want 200", got)
+	}
+}
@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"app-microservice/shared/patch"
+	"context"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestUserUseCase_SelfServiceCannotChangeEmail(t *testing.T) {
+	const dsn = "file:self_service?mode=memory&cache=shared&_fk=1"
+	client := enttest.Open(t, "sqlite3", dsn)
+	defer client.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	userRepo := repositories.NewUserRepository(client)
+	auditor := usecases.NewAuditor(repositories.NewAuditRepository(client), clock)
+	outbox := newTestOutbox(client, clock)
+	emailVerifier := usecases.NewEmailVerifier(userRepo, repositories.NewVerificationTokenRepository(client), &recordingMailer{}, auditor, outbox, clock, usecases.EmailVerificationConfig{
+		TokenTTL:  time.Hour,
+		VerifyURL: "http://localhost/verify-email",
+	})
+	userUseCase := usecases.NewUserUseCase(userRepo, services.NewUserDomainService(userRepo), emailVerifier, auditor, outbox, clock)
+
+	user, err := userUseCase.CreateUser(context.Background(), &dto.CreateUserRequest{Name: "John Doe", Email: "john@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	self := usecases.WithSelfService(context.Background())
+
+	if _, err := userUseCase.UpdateUser(self, user.ID, "", &dto.UpdateUserRequest{Name: "Johnny", Email: "john@example.com"}); err != nil {
+		t.Errorf("UpdateUser() keeping email error = %v", err)
+	}
+	if _, err := userUseCase.UpdateUser(self, user.ID, "", &dto.UpdateUserRequest{Email: "boss@example.com"}); err == nil || err.Error() != "email can only be changed by an administrator" {
+		t.Errorf("UpdateUser() changing email error = %v, want rejection", err)
+	}
+	if _, err := userUseCase.PatchUser(self, user.ID, "", patch.MergePatchType, []byte(`{"email": "boss@example.com"}`)); err == nil || err.Error() != "email can only be changed by an administrator" {
+		t.Errorf("PatchUser() changing email error = %v, want rejection", err)
+	}
+
+	stored, err := userRepo.GetByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.Email != "john@example.com" || stored.Name != "Johnny" {
+		t.Errorf("user = %s <%s>, want Johnny <john@example.com>", stored.Name, stored.Email)
+	}
+
+	if _, err := userUseCase.UpdateUser(context.Background(), user.ID, "", &dto.UpdateUserRequest{Email: "johnny@example.com"}); err != nil {
+		t.Errorf("UpdateUser() by administrator error = %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/application/dto"
+	"context"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestAuthUseCase_Sessions(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:sessions?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	authUseCase, mailer := newTestAuthUseCaseWithClock(client, time.Minute, clock)
+
+	laptop := registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "long enough"})
+	userID := laptop.User.ID
+	clock.Advance(time.Minute)
+	phoneLogin, err := authUseCase.Login(ctx, &dto.LoginRequest{Email: "john@example.com", Password: "long enough", ClientIP: "10.0.0.2", UserAgent: "Phone"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	phone := phoneLogin.AuthResponse
+	other := registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "Jane Doe", Email: "jane@example.com", Password: "long enough"})
+
+	principal, err := authUseCase.Authenticate(ctx, phone.AccessToken)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	sessions, err := authUseCase.ListSessions(ctx, userID, principal.SessionID)
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("ListSessions() = %+v, %v, want two sessions", sessions, err)
+	}
+	if sessions[0].ID != principal.SessionID || !sessions[0].Current || sessions[1].Current {
+		t.Errorf("ListSessions() = %+v, want the phone session first and marked current", sessions)
+	}
+	if sessions[0].UserAgent != "Phone" || sessions[0].IP != "10.0.0.2" {
+		t.Errorf("ListSessions() phone session = %+v, want its user agent and IP", sessions[0])
+	}
+	laptopSessionID := sessions[1].ID
+
+	// Using a session moves it to the top, but not on every request
+	clock.Advance(time.Minute)
+	if _, err := authUseCase.Authenticate(ctx, laptop.AccessToken); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	sessions, _ = authUseCase.ListSessions(ctx, userID, "")
+	if sessions[0].ID != laptopSessionID || !sessions[0].LastUsedAt.Equal(clock.Now()) {
+		t.Errorf("ListSessions() after use = %+v, want the laptop session used just now first", sessions)
+	}
+
+	// Sessions of other users cannot be revoked through this user
+	if err := authUseCase.RevokeSession(ctx, other.User.ID, laptopSessionID); err == nil || err.Error() != "session not found" {
+		t.Errorf("RevokeSession() for another user error = %v, want session not found", err)
+	}
+	if err := authUseCase.RevokeSession(ctx, 9999, laptopSessionID); err == nil || err.Error() != "user not found" {
+		t.Errorf("RevokeSession() for unknown user error = %v, want user not found", err)
+	}
+
+	// Revoking a session stops its access and refresh tokens at once
+	if err := authUseCase.RevokeSession(ctx, userID, laptopSessionID); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if _, err := authUseCase.Authenticate(ctx, laptop.AccessToken); err == nil || err.Error() != "session revoked" {
+		t.Errorf("Authenticate() with revoked session error = %v, want session revoked", err)
+	}
+	if _, err := authUseCase.Refresh(ctx, &dto.RefreshTokenRequest{RefreshToken: laptop.RefreshToken}); err == nil {
+		t.Error("Refresh() of revoked session succeeded")
+	}
+	if err := authUseCase.RevokeSession(ctx, userID, laptopSessionID); err == nil || err.Error() != "session not found" {
+		t.Errorf("RevokeSession() twice error = %v, want session not found", err)
+	}
+
+	// Refreshing keeps the session
+	refreshed, err := authUseCase.Refresh(ctx, &dto.RefreshTokenRequest{RefreshToken: phone.RefreshToken})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if principal, err := authUseCase.Authenticate(ctx, refreshed.AccessToken); err != nil || principal.SessionID != sessions[1].ID {
+		t.Errorf("Authenticate() after refresh = %+v, %v, want the phone session", principal, err)
+	}
+
+	// Logging out everywhere ends every session of the user only
+	revoked, err := authUseCase.RevokeAllSessions(ctx, userID)
+	if err != nil || revoked.Revoked != 1 {
+		t.Fatalf("RevokeAllSessions() = %+v, %v, want the phone session revoked", revoked, err)
+	}
+	if _, err := authUseCase.Authenticate(ctx, refreshed.AccessToken); err == nil || err.Error() != "session revoked" {
+		t.Errorf("Authenticate() after logging out everywhere error = %v, want session revoked", err)
+	}
+	if sessions, _ := authUseCase.ListSessions(ctx, userID, ""); len(sessions) != 0 {
+		t.Errorf("ListSessions() after logging out everywhere = %+v, want none", sessions)
+	}
+	if _, err := authUseCase.Authenticate(ctx, other.AccessToken); err != nil {
+		t.Errorf("Authenticate() of another user error = %v", err)
+	}
+}
+
+func TestAuthUseCase_LogoutEndsSession(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:sessionlogout?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	authUseCase, mailer := newTestAuthUseCase(client, time.Minute)
+	session := registerVerified(t, authUseCase, mailer, &dto.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "long enough"})
+
+	if err := authUseCase.Logout(ctx, &dto.RefreshTokenRequest{RefreshToken: session.RefreshToken}); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if _, err := authUseCase.Authenticate(ctx, session.AccessToken); err == nil || err.Error() != "session revoked" {
+		t.Errorf("Authenticate() after logout error = %v, want session revoked", err)
+	}
+	if sessions, _ := authUseCase.ListSessions(ctx, session.User.ID, ""); len(sessions) != 0 {
+		t.Errorf("ListSessions() after logout = %+v, want none", sessions)
+	}
+}
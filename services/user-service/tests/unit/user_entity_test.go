@@ -3,6 +3,7 @@ package entities
 import (
 	"app-microservice/services/user-service/internal/domain/entities"
 	"testing"
+	"time"
 )
 
 func TestUser_Validate(t *testing.T) {
@@ -149,3 +150,57 @@ func TestUser_UpdateProfile(t *testing.T) {
 		t.Errorf("User.UpdateProfile() phone = %v, want %v", user.Phone, "+9876543210")
 	}
 }
+
+func TestUser_ChangeStatus(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+	tests := []struct {
+		name    string
+		from    entities.UserStatus
+		to      entities.UserStatus
+		reason  string
+		until   *time.Time
+		wantErr string
+	}{
+		{name: "activate pending user", from: entities.UserStatusPendingVerification, to: entities.UserStatusActive},
+		{name: "deactivate active user", from: entities.UserStatusActive, to: entities.UserStatusInactive},
+		{name: "suspend for a while", from: entities.UserStatusActive, to: entities.UserStatusSuspended, reason: "chargeback", until: &later},
+		{name: "extend suspension", from: entities.UserStatusSuspended, to: entities.UserStatusSuspended, reason: "still under review"},
+		{name: "reactivate locked user", from: entities.UserStatusLocked, to: entities.UserStatusActive},
+		{name: "suspend without reason", from: entities.UserStatusActive, to: entities.UserStatusSuspended, wantErr: "suspension reason is required"},
+		{name: "suspend until the past", from: entities.UserStatusActive, to: entities.UserStatusSuspended, reason: "spam", until: &earlier, wantErr: "suspension end must be in the future"},
+		{name: "deactivate until later", from: entities.UserStatusActive, to: entities.UserStatusInactive, until: &later, wantErr: "only suspensions can have an end"},
+		{name: "unknown status", from: entities.UserStatusActive, to: "banned", wantErr: "invalid user status"},
+		{name: "back to pending", from: entities.UserStatusActive, to: entities.UserStatusPendingVerification, wantErr: "cannot change user status from active to pending_verification"},
+		{name: "lock inactive user", from: entities.UserStatusInactive, to: entities.UserStatusLocked, wantErr: "cannot change user status from inactive to locked"},
+		{name: "activate active user", from: entities.UserStatusActive, to: entities.UserStatusActive, wantErr: "user is already active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &entities.User{ID: 7, Status: string(tt.from), LockedUntil: &later}
+			err := user.ChangeStatus(tt.to, tt.reason, tt.until, now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("User.ChangeStatus() error = %v, want %s", err, tt.wantErr)
+				}
+				if user.Status != string(tt.from) || user.StatusChange != nil {
+					t.Errorf("User.ChangeStatus() changed status to %s after an error", user.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("User.ChangeStatus() error = %v", err)
+			}
+			if user.Status != string(tt.to) || user.LockedUntil != nil || user.SuspendedUntil != tt.until {
+				t.Errorf("User.ChangeStatus() status = %s until %v, want %s until %v", user.Status, user.SuspendedUntil, tt.to, tt.until)
+			}
+			change := user.StatusChange
+			if change == nil || change.UserID != 7 || change.FromStatus != tt.from || change.ToStatus != tt.to ||
+				change.Reason != tt.reason || !change.CreatedAt.Equal(now) {
+				t.Errorf("User.ChangeStatus() change = %+v, want %s -> %s", change, tt.from, tt.to)
+			}
+		})
+	}
+}
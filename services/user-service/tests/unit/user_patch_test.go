@@ -0,0 +1,69 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/shared/patch"
+	"encoding/json"
+	"testing"
+)
+
+func TestUserPatchDocument_ClearsPhone(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantName    string
+		wantPhone   string
+	}{
+		{
+			name:        "merge patch null clears phone",
+			contentType: patch.MergePatchType,
+			body:        `{"phone": null}`,
+			wantName:    "John Doe",
+			wantPhone:   "",
+		},
+		{
+			name:        "merge patch keeps omitted fields",
+			contentType: patch.MergePatchType,
+			body:        `{"name": "Jane Doe"}`,
+			wantName:    "Jane Doe",
+			wantPhone:   "+1234567890",
+		},
+		{
+			name:        "json patch removes phone",
+			contentType: patch.JSONPatchType,
+			body:        `[{"op": "remove", "path": "/phone"}]`,
+			wantName:    "John Doe",
+			wantPhone:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &entities.User{Name: "John Doe", Email: "john@example.com", Phone: "+1234567890"}
+
+			doc, err := json.Marshal(dto.NewUserPatchDocument(user))
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			patched, err := patch.Apply(tt.contentType, doc, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("patch.Apply() error = %v", err)
+			}
+
+			var result dto.UserPatchDocument
+			if err := json.Unmarshal(patched, &result); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			result.ApplyToEntity(user)
+
+			if user.Name != tt.wantName || user.Phone != tt.wantPhone {
+				t.Errorf("user = {name: %q, phone: %q}, want {name: %q, phone: %q}", user.Name, user.Phone, tt.wantName, tt.wantPhone)
+			}
+			if err := user.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
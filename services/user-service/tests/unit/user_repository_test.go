@@ -0,0 +1,114 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/domain/entities"
+	domainrepos "app-microservice/services/user-service/internal/domain/repositories"
+	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"context"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestUserRepository_UpdateRejectsStaleVersion(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:version?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := repositories.NewUserRepository(client)
+
+	user := &entities.User{Name: "John Doe", Email: "john@example.com", Status: string(entities.UserStatusActive)}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if user.Version != 1 {
+		t.Fatalf("Version after create = %d, want 1", user.Version)
+	}
+
+	first, _ := repo.GetByID(ctx, user.ID)
+	second, _ := repo.GetByID(ctx, user.ID)
+
+	first.Name = "First Writer"
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Version after update = %d, want 2", first.Version)
+	}
+
+	second.Name = "Second Writer"
+	if err := repo.Update(ctx, second); err == nil || err.Error() != "user version conflict" {
+		t.Errorf("Update() with stale version error = %v, want user version conflict", err)
+	}
+	if err := repo.Delete(ctx, user.ID, 1); err == nil || err.Error() != "user version conflict" {
+		t.Errorf("Delete() with stale version error = %v, want user version conflict", err)
+	}
+
+	stored, _ := repo.GetByID(ctx, user.ID)
+	if stored.Name != "First Writer" {
+		t.Errorf("stored name = %q, want %q", stored.Name, "First Writer")
+	}
+
+	if err := repo.Delete(ctx, user.ID, 2); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err := repo.Update(ctx, stored); err == nil || err.Error() != "user not found" {
+		t.Errorf("Update() after delete error = %v, want user not found", err)
+	}
+}
+
+func TestUserRepository_SoftDeleteRestoreAndPurge(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:softdelete?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := repositories.NewUserRepository(client)
+
+	user := &entities.User{Name: "John Doe", Email: "john@example.com", Status: string(entities.UserStatusActive)}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.Restore(ctx, user.ID); err == nil || err.Error() != "user is not deleted" {
+		t.Errorf("Restore() of live user error = %v, want user is not deleted", err)
+	}
+	if err := repo.Delete(ctx, user.ID, user.Version); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := repo.GetByID(ctx, user.ID); err == nil || err.Error() != "user not found" {
+		t.Errorf("GetByID() after delete error = %v, want user not found", err)
+	}
+	if count, _ := repo.Count(ctx); count != 0 {
+		t.Errorf("Count() after delete = %d, want 0", count)
+	}
+	deleted, err := repo.GetByID(domainrepos.WithDeleted(ctx), user.ID)
+	if err != nil || !deleted.IsDeleted() {
+		t.Fatalf("GetByID(WithDeleted) = %+v, %v, want the deleted user", deleted, err)
+	}
+	if exists, _ := repo.EmailExists(ctx, user.Email); !exists {
+		t.Error("EmailExists() = false for a deleted user, want the address to stay reserved")
+	}
+
+	if err := repo.Restore(ctx, user.ID); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	restored, err := repo.GetByID(ctx, user.ID)
+	if err != nil || restored.IsDeleted() || restored.Version != 2 {
+		t.Fatalf("GetByID() after restore = %+v, %v, want a live user at version 2", restored, err)
+	}
+
+	if err := repo.Delete(ctx, user.ID, restored.Version); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+		t.Errorf("PurgeDeleted() inside retention = %d, %v, want 0", purged, err)
+	}
+	if purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Second)); err != nil || purged != 1 {
+		t.Errorf("PurgeDeleted() = %d, %v, want 1", purged, err)
+	}
+	if err := repo.Restore(ctx, user.ID); err == nil || err.Error() != "user not found" {
+		t.Errorf("Restore() after purge error = %v, want user not found", err)
+	}
+}
@@ -0,0 +1,120 @@
+package entities
+
+import (
+	"app-microservice/services/user-service/ent/enttest"
+	"app-microservice/services/user-service/internal/application/dto"
+	"app-microservice/services/user-service/internal/application/usecases"
+	"app-microservice/services/user-service/internal/domain/entities"
+	"app-microservice/services/user-service/internal/domain/services"
+	"app-microservice/services/user-service/internal/infrastructure/repositories"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestUserUseCase_StatusTransitions(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:userstatus?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	userRepo := repositories.NewUserRepository(client)
+	auditor := usecases.NewAuditor(repositories.NewAuditRepository(client), clock)
+	outbox := newTestOutbox(client, clock)
+	emailVerifier := usecases.NewEmailVerifier(userRepo, repositories.NewVerificationTokenRepository(client), &recordingMailer{}, auditor, outbox, clock, usecases.EmailVerificationConfig{
+		TokenTTL:  time.Hour,
+		VerifyURL: "http://localhost/verify-email",
+	})
+	userUseCase := usecases.NewUserUseCase(userRepo, services.NewUserDomainService(userRepo), emailVerifier, auditor, outbox, clock)
+
+	adminID := 1
+	ctx := usecases.WithAuditActor(context.Background(), entities.AuditActor{UserID: &adminID})
+	user, err := userUseCase.CreateUser(ctx, &dto.CreateUserRequest{Name: "John Doe", Email: "john@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	if _, err := userUseCase.UpdateUserStatus(ctx, user.ID, "", &dto.UpdateUserStatusRequest{Status: "suspended"}); err == nil || err.Error() != "suspension reason is required" {
+		t.Errorf("UpdateUserStatus(suspended) without reason error = %v, want suspension reason is required", err)
+	}
+	if _, err := userUseCase.UpdateUserStatus(ctx, user.ID, "", &dto.UpdateUserStatusRequest{Status: "paused"}); err == nil || err.Error() != "invalid user status" {
+		t.Errorf("UpdateUserStatus(paused) error = %v, want invalid user status", err)
+	}
+
+	until := clock.Now().Add(time.Hour)
+	suspended, err := userUseCase.UpdateUserStatus(ctx, user.ID, "", &dto.UpdateUserStatusRequest{Status: "suspended", Reason: "chargeback", SuspendedUntil: &until})
+	if err != nil {
+		t.Fatalf("UpdateUserStatus(suspended) error = %v", err)
+	}
+	if suspended.Status != "suspended" || suspended.SuspendedUntil == nil || !suspended.SuspendedUntil.Equal(until) {
+		t.Errorf("suspended user = %s until %v, want suspended until %v", suspended.Status, suspended.SuspendedUntil, until)
+	}
+
+	// Nothing is lifted before the suspension ends
+	if lifted, err := userUseCase.LiftExpiredSuspensions(context.Background()); err != nil || lifted != 0 {
+		t.Errorf("LiftExpiredSuspensions() before the end = %d, %v, want 0", lifted, err)
+	}
+	clock.Advance(time.Hour)
+	if lifted, err := userUseCase.LiftExpiredSuspensions(context.Background()); err != nil || lifted != 1 {
+		t.Fatalf("LiftExpiredSuspensions() after the end = %d, %v, want 1", lifted, err)
+	}
+	active, err := userUseCase.GetUserByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	if active.Status != "active" || active.SuspendedUntil != nil {
+		t.Errorf("user after the suspension = %s until %v, want active", active.Status, active.SuspendedUntil)
+	}
+
+	// Locking is left to login protection
+	if err := userRepo.Lock(context.Background(), user.ID, clock.Now().Add(time.Minute), "too many failed logins"); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	var transitionErr *entities.StatusTransitionError
+	if _, err := userUseCase.UpdateUserStatus(ctx, user.ID, "", &dto.UpdateUserStatusRequest{Status: "suspended", Reason: "abuse"}); err != nil {
+		t.Fatalf("UpdateUserStatus(suspended) of locked user error = %v", err)
+	}
+	if err := userRepo.Lock(context.Background(), user.ID, clock.Now().Add(time.Minute), "too many failed logins"); err == nil || err.Error() != "user is not active" {
+		t.Errorf("Lock() of suspended user error = %v, want user is not active", err)
+	}
+	if _, err := userUseCase.UpdateUserStatus(ctx, user.ID, "", &dto.UpdateUserStatusRequest{Status: "inactive"}); err != nil {
+		t.Fatalf("UpdateUserStatus(inactive) error = %v", err)
+	}
+	if _, err := userUseCase.UpdateUserStatus(ctx, user.ID, "", &dto.UpdateUserStatusRequest{Status: "inactive"}); !errors.As(err, &transitionErr) {
+		t.Errorf("UpdateUserStatus(inactive) of inactive user error = %v, want a StatusTransitionError", err)
+	}
+
+	history, err := userUseCase.GetStatusHistory(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("GetStatusHistory() error = %v", err)
+	}
+	want := []struct {
+		from, to entities.UserStatus
+		reason   string
+		byAdmin  bool
+	}{
+		{entities.UserStatusSuspended, entities.UserStatusInactive, "", true},
+		{entities.UserStatusLocked, entities.UserStatusSuspended, "abuse", true},
+		{entities.UserStatusActive, entities.UserStatusLocked, "too many failed logins", false},
+		{entities.UserStatusSuspended, entities.UserStatusActive, "suspension ended", false},
+		{entities.UserStatusPendingVerification, entities.UserStatusSuspended, "chargeback", true},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("GetStatusHistory() = %d changes, want %d", len(history), len(want))
+	}
+	for i, w := range want {
+		change := history[i]
+		if change.FromStatus != w.from || change.ToStatus != w.to || change.Reason != w.reason || (change.ActorID != nil) != w.byAdmin {
+			t.Errorf("history[%d] = %s -> %s %q by %v, want %s -> %s %q", i, change.FromStatus, change.ToStatus, change.Reason, change.ActorID, w.from, w.to, w.reason)
+		}
+	}
+	if history[4].SuspendedUntil == nil || !history[4].SuspendedUntil.Equal(until) {
+		t.Errorf("history of the suspension ends at %v, want %v", history[4].SuspendedUntil, until)
+	}
+
+	if _, err := userUseCase.GetStatusHistory(context.Background(), user.ID+100); err == nil || err.Error() != "user not found" {
+		t.Errorf("GetStatusHistory() of unknown user error = %v, want user not found", err)
+	}
+}
@@ -0,0 +1,31 @@
+// Package etag formats version based entity tags and evaluates If-Match
+// preconditions for optimistic concurrency control.
+package etag
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format returns the strong entity tag for a resource version
+func Format(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Match reports whether an If-Match header is satisfied by the current version.
+// An empty header always matches and "*" matches any existing resource. Weak
+// tags never match because If-Match uses strong comparison (RFC 9110 13.1.1).
+func Match(ifMatch string, version int) bool {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return true
+	}
+
+	current := Format(version)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(tag) == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package etag
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		ifMatch string
+		version int
+		want    bool
+	}{
+		{"", 3, true},
+		{"*", 3, true},
+		{`"3"`, 3, true},
+		{`"2"`, 3, false},
+		{`"1", "3"`, 3, true},
+		{`W/"3"`, 3, false},
+		{`3`, 3, false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.ifMatch, tt.version); got != tt.want {
+			t.Errorf("Match(%q, %d) = %v, want %v", tt.ifMatch, tt.version, got, tt.want)
+		}
+	}
+}
@@ -15,15 +15,158 @@ type User struct {
 // Product represents a product in the system
 // @Description Product information
 type Product struct {
+	ID          int              `json:"id" example:"1"`
+	SKU         string           `json:"sku,omitempty" example:"DELL-INS15"`
+	Name        string           `json:"name" example:"Laptop Dell"`
+	Description string           `json:"description" example:"Laptop Dell Inspiron 15"`
+	Price       float64          `json:"price" example:"15000000"`
+	Stock       int              `json:"stock" example:"10"`
+	CategoryIDs []int            `json:"category_ids,omitempty" example:"1,2"`
+	Variants    []ProductVariant `json:"variants,omitempty"`
+	Version     int              `json:"version" example:"1"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	DeletedAt   *time.Time       `json:"deleted_at,omitempty"`
+}
+
+// ProductImage represents an uploaded product image and its thumbnails
+// @Description Product image information
+type ProductImage struct {
+	ID          int               `json:"id" example:"1"`
+	ProductID   int               `json:"product_id" example:"1"`
+	URL         string            `json:"url" example:"/media/products/1/9f86d081/original.jpg"`
+	ContentType string            `json:"content_type" example:"image/jpeg"`
+	Size        int64             `json:"size" example:"204800"`
+	Width       int               `json:"width" example:"1200"`
+	Height      int               `json:"height" example:"800"`
+	Position    int               `json:"position" example:"0"`
+	IsPrimary   bool              `json:"is_primary" example:"true"`
+	Thumbnails  map[string]string `json:"thumbnails" swaggertype:"object,string" example:"small:/media/products/1/9f86d081/small.jpg"`
+	StorageKeys []string          `json:"-"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ProductListResponse represents a paginated list of products
+// @Description Paginated product list
+type ProductListResponse struct {
+	Products   []Product `json:"products"`
+	Total      int64     `json:"total" example:"2"`
+	Page       int       `json:"page" example:"1"`
+	PageSize   int       `json:"page_size" example:"10"`
+	TotalPages int       `json:"total_pages" example:"1"`
+}
+
+// ProductVariant represents a sellable variant (SKU) of a product
+// @Description Product variant information
+type ProductVariant struct {
+	ID        int               `json:"id" example:"1"`
+	ProductID int               `json:"product_id" example:"2"`
+	SKU       string            `json:"sku" example:"IP14P-128-BLK"`
+	Options   map[string]string `json:"options" swaggertype:"object,string" example:"color:Black,capacity:128GB"`
+	Price     *float64          `json:"price,omitempty" example:"25000000"`
+	Stock     int               `json:"stock" example:"3"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// ImportJob tracks the progress and outcome of a bulk product import
+// @Description Bulk product import job
+type ImportJob struct {
+	ID         string           `json:"id" example:"9f86d081884c7d65"`
+	Status     string           `json:"status" example:"completed" enums:"pending,running,completed"`
+	Format     string           `json:"format" example:"csv" enums:"csv,ndjson"`
+	DryRun     bool             `json:"dry_run" example:"false"`
+	Total      int              `json:"total" example:"120"`
+	Processed  int              `json:"processed" example:"120"`
+	Created    int              `json:"created" example:"100"`
+	Updated    int              `json:"updated" example:"18"`
+	Failed     int              `json:"failed" example:"2"`
+	Errors     []ImportRowError `json:"errors"`
+	CreatedAt  time.Time        `json:"created_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
+
+// ImportRowError describes why a single row of an import file was rejected
+// @Description Import row error
+type ImportRowError struct {
+	Line  int    `json:"line" example:"7"`
+	SKU   string `json:"sku,omitempty" example:"DELL-INS15"`
+	Error string `json:"error" example:"price must not be negative"`
+}
+
+// Category represents a product category in the system
+// @Description Product category information
+type Category struct {
 	ID          int       `json:"id" example:"1"`
-	Name        string    `json:"name" example:"Laptop Dell"`
-	Description string    `json:"description" example:"Laptop Dell Inspiron 15"`
-	Price       float64   `json:"price" example:"15000000"`
-	Stock       int       `json:"stock" example:"10"`
+	Name        string    `json:"name" example:"Laptop"`
+	Slug        string    `json:"slug" example:"laptop"`
+	Description string    `json:"description" example:"Máy tính xách tay"`
+	ParentID    *int      `json:"parent_id,omitempty" example:"1"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// CategoryNode represents a category together with its nested children
+// @Description Category tree node
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// StockMovement represents a single append-only entry in the inventory ledger
+// @Description Inventory stock movement
+type StockMovement struct {
+	ID            int       `json:"id" example:"1"`
+	ProductID     int       `json:"product_id" example:"2"`
+	VariantID     *int      `json:"variant_id,omitempty" example:"1"`
+	Type          string    `json:"type" example:"receipt" enums:"receipt,adjustment,reservation,release,fulfilment"`
+	Quantity      int       `json:"quantity" example:"5"`
+	OnHand        int       `json:"on_hand" example:"10"`
+	Reserved      int       `json:"reserved" example:"2"`
+	ReservationID *int      `json:"reservation_id,omitempty" example:"1"`
+	Note          string    `json:"note,omitempty" example:"Nhập kho đầu kỳ"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockReservation represents stock held for a pending order
+// @Description Inventory stock reservation
+type StockReservation struct {
+	ID        int       `json:"id" example:"1"`
+	ProductID int       `json:"product_id" example:"2"`
+	VariantID *int      `json:"variant_id,omitempty" example:"1"`
+	Quantity  int       `json:"quantity" example:"2"`
+	Status    string    `json:"status" example:"active" enums:"active,committed,released,expired"`
+	Reference string    `json:"reference,omitempty" example:"order-1001"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StockLevel represents the current stock position of a product or variant
+// @Description Inventory stock level
+type StockLevel struct {
+	ProductID int  `json:"product_id" example:"2"`
+	VariantID *int `json:"variant_id,omitempty" example:"1"`
+	OnHand    int  `json:"on_hand" example:"10"`
+	Reserved  int  `json:"reserved" example:"2"`
+	Available int  `json:"available" example:"8"`
+}
+
+// PriceChange represents an entry in a product's price history
+// @Description Product price change
+type PriceChange struct {
+	ID            int        `json:"id" example:"1"`
+	ProductID     int        `json:"product_id" example:"1"`
+	OldPrice      float64    `json:"old_price" example:"15000000"`
+	Price         float64    `json:"price" example:"14000000"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	Status        string     `json:"status" example:"applied" enums:"scheduled,applied,cancelled"`
+	ChangedBy     string     `json:"changed_by" example:"admin"`
+	Reason        string     `json:"reason,omitempty" example:"Khuyến mãi cuối tuần"`
+	AppliedAt     *time.Time `json:"applied_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
 // Response represents a successful API response
 // @Description Successful response
 type Response struct {
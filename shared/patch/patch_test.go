@@ -0,0 +1,99 @@
+package patch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, want string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null removes", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"array replaced", `{"a":["b"]}`, `{"a":["c"]}`, `{"a":["c"]}`},
+		{"nested merge", `{"a":{"b":"c","d":1}}`, `{"a":{"b":"x","d":null}}`, `{"a":{"b":"x"}}`},
+		{"non-object patch", `{"a":"foo"}`, `["bar"]`, `["bar"]`},
+		{"object into scalar", `{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{"large number kept", `{"n":1}`, `{"n":12345678901234567890}`, `{"n":12345678901234567890}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("MergePatch() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MergePatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, want string
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"insert into array", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"append to array", `{"foo":[1]}`, `[{"op":"add","path":"/foo/-","value":2}]`, `{"foo":[1,2]}`},
+		{"remove element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace member", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"move member", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"copy member", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		{"escaped pointer", `{"a/b":1,"m~n":2}`, `[{"op":"test","path":"/a~1b","value":1.0},{"op":"remove","path":"/m~0n"}]`, `{"a/b":1}`},
+		{"replace with null", `{"phone":"123"}`, `[{"op":"replace","path":"/phone","value":null}]`, `{"phone":null}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("JSONPatch() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("JSONPatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name, patch string
+		want        error
+	}{
+		{"failed test", `[{"op":"test","path":"/foo","value":"nope"}]`, ErrTestFailed},
+		{"missing path", `[{"op":"replace","path":"/missing","value":1}]`, ErrInvalidPatch},
+		{"remove missing", `[{"op":"remove","path":"/list/5"}]`, ErrInvalidPatch},
+		{"unknown op", `[{"op":"frobnicate","path":"/foo"}]`, ErrInvalidPatch},
+		{"missing value", `[{"op":"add","path":"/x"}]`, ErrInvalidPatch},
+		{"move into child", `[{"op":"move","from":"/obj","path":"/obj/child"}]`, ErrInvalidPatch},
+		{"not an array", `{"op":"add"}`, ErrInvalidPatch},
+	}
+
+	doc := []byte(`{"foo":"bar","list":[1],"obj":{}}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := JSONPatch(doc, []byte(tt.patch)); !errors.Is(err, tt.want) {
+				t.Errorf("JSONPatch() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply_MediaTypes(t *testing.T) {
+	doc := []byte(`{"a":1}`)
+
+	if got, err := Apply("application/merge-patch+json; charset=utf-8", doc, []byte(`{"a":null}`)); err != nil || string(got) != `{}` {
+		t.Errorf("Apply(merge) = %s, %v", got, err)
+	}
+	if got, err := Apply(JSONPatchType, doc, []byte(`[{"op":"remove","path":"/a"}]`)); err != nil || string(got) != `{}` {
+		t.Errorf("Apply(json patch) = %s, %v", got, err)
+	}
+	if _, err := Apply("text/plain", doc, []byte(`{}`)); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Apply(text/plain) error = %v, want %v", err, ErrUnsupportedMediaType)
+	}
+}
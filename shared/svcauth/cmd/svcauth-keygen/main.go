@@ -0,0 +1,60 @@
+// Command svcauth-keygen generates the Ed25519 key pairs services sign their
+// calls to each other with, for local development:
+//
+//	go run ./shared/svcauth/cmd/svcauth-keygen -out keys api-gateway user-service product-service
+//
+// writes keys/<service>.key and keys/<service>.pub for every service. Give
+// each service its own .key and the .pub files of the peers it trusts.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"app-microservice/shared/svcauth"
+)
+
+func main() {
+	out := flag.String("out", "keys", "directory to write the keys to")
+	force := flag.Bool("force", false, "replace existing keys")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: svcauth-keygen [-out dir] [-force] service...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("Failed to create %s: %v", *out, err)
+	}
+
+	for _, service := range flag.Args() {
+		keyFile := filepath.Join(*out, service+".key")
+		if _, err := os.Stat(keyFile); err == nil && !*force {
+			log.Printf("Keeping existing key %s", keyFile)
+			continue
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Fatalf("Failed to check %s: %v", keyFile, err)
+		}
+
+		privateKey, publicKey, err := svcauth.GenerateKey()
+		if err != nil {
+			log.Fatalf("Failed to generate key for %s: %v", service, err)
+		}
+		if err := os.WriteFile(keyFile, privateKey, 0o600); err != nil {
+			log.Fatalf("Failed to write %s: %v", keyFile, err)
+		}
+		if err := os.WriteFile(filepath.Join(*out, service+".pub"), publicKey, 0o644); err != nil {
+			log.Fatalf("Failed to write public key of %s: %v", service, err)
+		}
+		log.Printf("Generated %s and %s.pub", keyFile, service)
+	}
+}
@@ -0,0 +1,390 @@
+// Package svcauth authenticates calls between services with short-lived
+// tokens. The calling service signs a token for the service it calls with its
+// Ed25519 key; the called service only accepts tokens of the peers whose
+// public keys it was given, and answers with a token of its own bound to the
+// request, so the caller knows which service responded.
+package svcauth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"app-microservice/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// Header carries the token of a request, and of its response
+	Header = "X-Service-Token"
+
+	// TokenTTL is how long a token is accepted. Tokens are signed for every
+	// request, so this only has to cover clock skew and transit.
+	TokenTTL = time.Minute
+
+	// leeway tolerates clocks of services that are slightly apart
+	leeway = 30 * time.Second
+
+	// callerKey is the gin context key the calling service is stored under
+	callerKey = "ServiceCaller"
+)
+
+var (
+	// ErrInvalidToken is returned for tokens that are malformed, expired,
+	// signed by an unknown service or meant for another service
+	ErrInvalidToken = errors.New("invalid service token")
+	// ErrReplayed is returned for a token that was already accepted
+	ErrReplayed = errors.New("service token already used")
+)
+
+// claims is the JWT payload of a service token. Response tokens also carry
+// the ID of the request token they answer.
+type claims struct {
+	RequestID string `json:"rid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Authenticator signs the tokens of a service and verifies those of its peers
+type Authenticator struct {
+	service string
+	key     ed25519.PrivateKey
+	peers   map[string]ed25519.PublicKey
+	parser  *jwt.Parser
+	seen    *replayCache
+}
+
+// New creates an authenticator for service, signing with key and accepting
+// the peers, by service name
+func New(service string, key ed25519.PrivateKey, peers map[string]ed25519.PublicKey) *Authenticator {
+	return &Authenticator{
+		service: service,
+		key:     key,
+		peers:   peers,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
+			jwt.WithAudience(service),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+			jwt.WithLeeway(leeway),
+		),
+		seen: newReplayCache(),
+	}
+}
+
+// Load creates an authenticator from a PEM private key file and a comma
+// separated list of name=public-key-file peers, as in
+// "api-gateway=keys/api-gateway.pub,product-service=keys/product-service.pub"
+func Load(service, keyFile, peerList string) (*Authenticator, error) {
+	if service == "" {
+		return nil, errors.New("service name is required")
+	}
+	key, err := readKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make(map[string]ed25519.PublicKey)
+	for _, entry := range strings.Split(peerList, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		name, file, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || file == "" {
+			return nil, fmt.Errorf("invalid peer %q, expected name=public-key-file", entry)
+		}
+		publicKey, err := readPublicKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("peer %s: %w", name, err)
+		}
+		peers[name] = publicKey
+	}
+	return New(service, key, peers), nil
+}
+
+// Service returns the name of the service the authenticator signs for
+func (a *Authenticator) Service() string {
+	return a.service
+}
+
+// Sign returns a token for a request to the service audience, and its ID,
+// which the response token must answer
+func (a *Authenticator) Sign(audience string) (string, string, error) {
+	return a.sign(audience, "")
+}
+
+// Verify checks a request token and returns its claims: the calling service
+// as Issuer and the token ID to bind the response to. A token is accepted
+// only once.
+func (a *Authenticator) Verify(token string) (*jwt.RegisteredClaims, error) {
+	payload, err := a.parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if payload.RequestID != "" {
+		return nil, fmt.Errorf("%w: response token used for a request", ErrInvalidToken)
+	}
+	if !a.seen.add(payload.ID, payload.ExpiresAt.Add(leeway)) {
+		return nil, ErrReplayed
+	}
+	return &payload.RegisteredClaims, nil
+}
+
+// SignResponse returns a token that answers the request token requestID of
+// the service caller
+func (a *Authenticator) SignResponse(caller, requestID string) (string, error) {
+	token, _, err := a.sign(caller, requestID)
+	return token, err
+}
+
+// VerifyResponse checks that a response token was signed by service and
+// answers the request token requestID
+func (a *Authenticator) VerifyResponse(token, service, requestID string) error {
+	payload, err := a.parse(token)
+	if err != nil {
+		return err
+	}
+	if payload.Issuer != service || payload.RequestID == "" || payload.RequestID != requestID {
+		return fmt.Errorf("%w: response not from %s for this request", ErrInvalidToken, service)
+	}
+	return nil
+}
+
+// Middleware rejects requests without a valid token of a peer with 401,
+// except for the exempt paths, such as health checks. Accepted requests are
+// answered with a response token.
+func (a *Authenticator) Middleware(exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, path := range exempt {
+			if c.Request.URL.Path == path {
+				c.Next()
+				return
+			}
+		}
+
+		payload, err := a.Verify(c.GetHeader(Header))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Status:  "error",
+				Message: "Service authentication failed",
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		response, err := a.SignResponse(payload.Issuer, payload.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Status:  "error",
+				Message: "Service authentication failed",
+				Error:   err.Error(),
+			})
+			return
+		}
+		c.Header(Header, response)
+		c.Set(callerKey, payload.Issuer)
+		c.Next()
+	}
+}
+
+// Caller returns the service that made the request, as stored by Middleware
+func Caller(c *gin.Context) string {
+	return c.GetString(callerKey)
+}
+
+// Transport returns a RoundTripper that signs every request for the service
+// target and fails for responses that do not carry target's token
+func (a *Authenticator) Transport(target string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{auth: a, target: target, base: base}
+}
+
+type transport struct {
+	auth   *Authenticator
+	target string
+	base   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, requestID, err := t.auth.Sign(t.target)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(Header, token)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.auth.VerifyResponse(resp.Header.Get(Header), t.target, requestID); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	resp.Header.Del(Header)
+	return resp, nil
+}
+
+func (a *Authenticator) sign(audience, requestID string) (string, string, error) {
+	id, err := randomID()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims{
+		RequestID: requestID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    a.service,
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenTTL)),
+			ID:        id,
+		},
+	})
+	signed, err := token.SignedString(a.key)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign service token: %w", err)
+	}
+	return signed, id, nil
+}
+
+// parse checks the signature, audience and lifetime of a token of a peer
+func (a *Authenticator) parse(token string) (*claims, error) {
+	if token == "" {
+		return nil, fmt.Errorf("%w: %s header is missing", ErrInvalidToken, Header)
+	}
+
+	var payload claims
+	_, err := a.parser.ParseWithClaims(token, &payload, func(t *jwt.Token) (interface{}, error) {
+		issuer, _ := t.Claims.GetIssuer()
+		key, ok := a.peers[issuer]
+		if !ok {
+			return nil, fmt.Errorf("unknown service %q", issuer)
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if payload.ID == "" || payload.ExpiresAt.Sub(payload.IssuedAt.Time) > TokenTTL {
+		return nil, fmt.Errorf("%w: missing ID or lifetime too long", ErrInvalidToken)
+	}
+	return &payload, nil
+}
+
+// replayCache remembers the IDs of accepted tokens until they expire
+type replayCache struct {
+	mu        sync.Mutex
+	expiries  map[string]time.Time
+	lastSweep time.Time
+}
+
+func newReplayCache() *replayCache {
+	return &replayCache{expiries: make(map[string]time.Time)}
+}
+
+// add records id and reports whether it was new
+func (c *replayCache) add(id string, expiresAt time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > TokenTTL {
+		for seen, expiry := range c.expiries {
+			if now.After(expiry) {
+				delete(c.expiries, seen)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if _, ok := c.expiries[id]; ok {
+		return false
+	}
+	c.expiries[id] = expiresAt
+	return true
+}
+
+// GenerateKey returns a new key pair PEM encoded: the private key as PKCS #8
+// and the public key as PKIX
+func GenerateKey() ([]byte, []byte, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), nil
+}
+
+func readKey(file string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 private key", file)
+	}
+	return privateKey, nil
+}
+
+func readPublicKey(file string) (ed25519.PublicKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 public key", file)
+	}
+	return publicKey, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s contains no PEM data", file)
+	}
+	return block, nil
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
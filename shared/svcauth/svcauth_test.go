@@ -0,0 +1,170 @@
+package svcauth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	return publicKey, privateKey
+}
+
+// newService serves a service that requires tokens of its peers
+func newService(t *testing.T, auth *Authenticator) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(auth.Middleware("/health"))
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/whoami", func(c *gin.Context) { c.String(http.StatusOK, Caller(c)) })
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAuthenticator_Calls(t *testing.T) {
+	gatewayPublic, gatewayKey := newKey(t)
+	userPublic, userKey := newKey(t)
+	_, strangerKey := newKey(t)
+
+	gateway := New("api-gateway", gatewayKey, map[string]ed25519.PublicKey{"user-service": userPublic})
+	userService := New("user-service", userKey, map[string]ed25519.PublicKey{"api-gateway": gatewayPublic})
+	server := newService(t, userService)
+
+	client := &http.Client{Transport: gateway.Transport("user-service", nil)}
+	resp, err := client.Get(server.URL + "/whoami")
+	if err != nil {
+		t.Fatalf("call from gateway error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(Header) != "" {
+		t.Errorf("call from gateway = %d with token header %q, want 200 without", resp.StatusCode, resp.Header.Get(Header))
+	}
+
+	// Direct traffic and tokens of anyone else are rejected
+	request := func(token string) int {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/whoami", nil)
+		if token != "" {
+			req.Header.Set(Header, token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request error = %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	stranger := New("api-gateway", strangerKey, nil)
+	strangerToken, _, _ := stranger.Sign("user-service")
+	otherAudience, _, _ := gateway.Sign("product-service")
+	token, _, _ := gateway.Sign("user-service")
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"garbage", "garbage", http.StatusUnauthorized},
+		{"unknown key", strangerToken, http.StatusUnauthorized},
+		{"other audience", otherAudience, http.StatusUnauthorized},
+		{"valid", token, http.StatusOK},
+		{"replayed", token, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := request(tt.token); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	health, err := http.Get(server.URL + "/health")
+	if err != nil {
+		t.Fatalf("health check error = %v", err)
+	}
+	health.Body.Close()
+	if health.StatusCode != http.StatusOK {
+		t.Errorf("health check = %d, want 200 without a token", health.StatusCode)
+	}
+}
+
+func TestAuthenticator_VerifiesResponder(t *testing.T) {
+	gatewayPublic, gatewayKey := newKey(t)
+	userPublic, _ := newKey(t)
+	_, impostorKey := newKey(t)
+
+	// A service answering for user-service without its key is not trusted
+	gateway := New("api-gateway", gatewayKey, map[string]ed25519.PublicKey{"user-service": userPublic})
+	impostor := New("user-service", impostorKey, map[string]ed25519.PublicKey{"api-gateway": gatewayPublic})
+	server := newService(t, impostor)
+
+	client := &http.Client{Transport: gateway.Transport("user-service", nil)}
+	if _, err := client.Get(server.URL + "/whoami"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("call to impostor error = %v, want ErrInvalidToken", err)
+	}
+
+	// Nor is a response token of one request for another
+	_, requestID, _ := gateway.Sign("user-service")
+	_, otherID, _ := gateway.Sign("user-service")
+	responder := New("user-service", impostorKey, nil)
+	response, _ := responder.SignResponse("api-gateway", otherID)
+	trusting := New("api-gateway", gatewayKey, map[string]ed25519.PublicKey{"user-service": impostorKey.Public().(ed25519.PublicKey)})
+	if err := trusting.VerifyResponse(response, "user-service", requestID); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("VerifyResponse() for another request error = %v, want ErrInvalidToken", err)
+	}
+	if err := trusting.VerifyResponse(response, "user-service", otherID); err != nil {
+		t.Errorf("VerifyResponse() error = %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	for _, service := range []string{"api-gateway", "user-service"} {
+		privateKey, publicKey, err := GenerateKey()
+		if err != nil {
+			t.Fatalf("GenerateKey() error = %v", err)
+		}
+		os.WriteFile(filepath.Join(dir, service+".key"), privateKey, 0o600)
+		os.WriteFile(filepath.Join(dir, service+".pub"), publicKey, 0o644)
+	}
+
+	gateway, err := Load("api-gateway", filepath.Join(dir, "api-gateway.key"), "user-service="+filepath.Join(dir, "user-service.pub"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	userService, err := Load("user-service", filepath.Join(dir, "user-service.key"), " api-gateway="+filepath.Join(dir, "api-gateway.pub")+", ")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	token, requestID, _ := gateway.Sign("user-service")
+	claims, err := userService.Verify(token)
+	if err != nil || claims.Issuer != "api-gateway" || claims.ID != requestID {
+		t.Fatalf("Verify() = %+v, %v, want the gateway", claims, err)
+	}
+	response, _ := userService.SignResponse(claims.Issuer, claims.ID)
+	if err := gateway.VerifyResponse(response, "user-service", requestID); err != nil {
+		t.Errorf("VerifyResponse() error = %v", err)
+	}
+
+	invalid := []string{"user-service", "=" + filepath.Join(dir, "user-service.pub"), "user-service=" + filepath.Join(dir, "missing.pub"), "user-service=" + filepath.Join(dir, "user-service.key")}
+	for _, peers := range invalid {
+		if _, err := Load("api-gateway", filepath.Join(dir, "api-gateway.key"), peers); err == nil {
+			t.Errorf("Load() with peers %q succeeded", peers)
+		}
+	}
+	if _, err := Load("api-gateway", filepath.Join(dir, "api-gateway.pub"), ""); err == nil {
+		t.Error("Load() with a public key as private key succeeded")
+	}
+}
@@ -0,0 +1,105 @@
+// Command devcerts generates a self-signed CA and certificates signed by it,
+// for trying out TLS locally:
+//
+//	go run ./shared/tlsconfig/cmd/devcerts -out certs api-gateway user-service product-service
+//
+// writes certs/ca.pem with its key and certs/<service>.pem and
+// certs/<service>-key.pem for every service, valid for the service name,
+// localhost, 127.0.0.1 and ::1. An existing CA is reused, so certificates can
+// be reissued without trusting a new CA. Trust certs/ca.pem in the clients,
+// for instance with TLS_CA_FILE in the gateway or curl --cacert. Never use
+// these certificates in production.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"app-microservice/shared/tlsconfig"
+)
+
+func main() {
+	out := flag.String("out", "certs", "directory to write the certificates to")
+	hosts := flag.String("hosts", "localhost,127.0.0.1,::1", "comma separated hosts every certificate is valid for, besides the service name")
+	days := flag.Int("days", 365, "days the certificates are valid for")
+	force := flag.Bool("force", false, "replace the existing CA")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: devcerts [-out dir] [-hosts list] [-days n] [-force] service...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("Failed to create %s: %v", *out, err)
+	}
+	validFor := time.Duration(*days) * 24 * time.Hour
+
+	ca, err := loadOrCreateCA(*out, validFor, *force)
+	if err != nil {
+		log.Fatalf("Failed to set up CA: %v", err)
+	}
+
+	for _, service := range flag.Args() {
+		names := []string{service}
+		for _, host := range strings.Split(*hosts, ",") {
+			if host = strings.TrimSpace(host); host != "" && host != service {
+				names = append(names, host)
+			}
+		}
+		cert, err := ca.Issue(names, validFor)
+		if err != nil {
+			log.Fatalf("Failed to issue certificate for %s: %v", service, err)
+		}
+		if err := write(*out, service, cert); err != nil {
+			log.Fatalf("Failed to write certificate for %s: %v", service, err)
+		}
+		log.Printf("Issued %s for %s", filepath.Join(*out, service+".pem"), strings.Join(names, ", "))
+	}
+}
+
+// loadOrCreateCA reads the CA in dir, creating it when there is none or when
+// force is set
+func loadOrCreateCA(dir string, validFor time.Duration, force bool) (*tlsconfig.Certificate, error) {
+	if !force {
+		certPEM, certErr := os.ReadFile(filepath.Join(dir, "ca.pem"))
+		keyPEM, keyErr := os.ReadFile(filepath.Join(dir, "ca-key.pem"))
+		if certErr == nil && keyErr == nil {
+			log.Printf("Using existing CA %s", filepath.Join(dir, "ca.pem"))
+			return &tlsconfig.Certificate{CertPEM: certPEM, KeyPEM: keyPEM}, nil
+		}
+		for _, err := range []error{certErr, keyErr} {
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	ca, err := tlsconfig.NewCA("app-microservice development CA", validFor)
+	if err != nil {
+		return nil, err
+	}
+	if err := write(dir, "ca", ca); err != nil {
+		return nil, err
+	}
+	log.Printf("Created CA %s", filepath.Join(dir, "ca.pem"))
+	return ca, nil
+}
+
+// write stores a certificate as <name>.pem and its key as <name>-key.pem
+func write(dir, name string, cert *tlsconfig.Certificate) error {
+	if err := os.WriteFile(filepath.Join(dir, name+"-key.pem"), cert.KeyPEM, 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+".pem"), cert.CertPEM, 0o644)
+}
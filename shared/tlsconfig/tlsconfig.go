@@ -0,0 +1,142 @@
+// Package tlsconfig builds the TLS configurations services listen and call
+// each other with. Server certificates are read from PEM files and reloaded
+// when the files change, so renewed certificates are picked up without a
+// restart.
+package tlsconfig
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadCheckInterval is how often the certificate files are checked for
+// changes, at most, when a client connects
+const reloadCheckInterval = 10 * time.Second
+
+// ParseVersion returns the TLS version of "1.2" or "1.3", and TLS 1.2 for ""
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q, expected 1.2 or 1.3", version)
+	}
+}
+
+// Server returns a configuration serving the certificate chain in certFile
+// with the key in keyFile, accepting minVersion and up
+func Server(certFile, keyFile, minVersion string) (*tls.Config, error) {
+	version, err := ParseVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:     version,
+		GetCertificate: reloader.GetCertificate,
+	}, nil
+}
+
+// Client returns a configuration for calling services, trusting the CA
+// certificates in caFile as well as the system roots. Without a caFile only
+// the system roots are trusted.
+func Client(caFile, minVersion string) (*tls.Config, error) {
+	version, err := ParseVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{MinVersion: version}
+	if caFile == "" {
+		return config, nil
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s contains no PEM certificates", caFile)
+	}
+	config.RootCAs = roots
+	return config, nil
+}
+
+// certReloader serves a certificate from files, reloading it when their
+// contents change. A change that fails to load keeps the last good
+// certificate, so a renewal caught between writing the two files does no
+// harm.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certPEM   []byte
+	keyPEM    []byte
+	lastCheck time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: reloadCheckInterval}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	r.lastCheck = time.Now()
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, checking the files first
+// when the last check is older than the interval
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) >= r.interval {
+		r.lastCheck = time.Now()
+		if changed, err := r.reload(); err != nil {
+			log.Printf("Keeping current TLS certificate, failed to reload %s: %v", r.certFile, err)
+		} else if changed {
+			log.Printf("Reloaded TLS certificate %s", r.certFile)
+		}
+	}
+	return r.cert, nil
+}
+
+// reload loads the certificate if the files differ from the loaded ones and
+// reports whether it did
+func (r *certReloader) reload() (bool, error) {
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+	if r.cert != nil && bytes.Equal(certPEM, r.certPEM) && bytes.Equal(keyPEM, r.keyPEM) {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("failed to load %s and %s: %w", r.certFile, r.keyFile, err)
+	}
+	r.cert, r.certPEM, r.keyPEM = &cert, certPEM, keyPEM
+	return true, nil
+}
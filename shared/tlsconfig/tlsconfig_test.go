@@ -0,0 +1,159 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    uint16
+		wantErr bool
+	}{
+		{"", tls.VersionTLS12, false},
+		{"1.2", tls.VersionTLS12, false},
+		{"1.3", tls.VersionTLS13, false},
+		{"1.1", 0, true},
+		{"tls1.3", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.version)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseVersion(%q) = %v, %v, want %v, error %v", tt.version, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// writeCert writes cert to dir as name.pem and name-key.pem
+func writeCert(t *testing.T, dir, name string, cert *Certificate) (string, string) {
+	t.Helper()
+	certFile, keyFile := filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+	if err := os.WriteFile(certFile, cert.CertPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, cert.KeyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func issue(t *testing.T, ca *Certificate, hosts ...string) *Certificate {
+	t.Helper()
+	cert, err := ca.Issue(hosts, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	return cert
+}
+
+func TestServerAndClient(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := NewCA("test CA", time.Hour)
+	if err != nil {
+		t.Fatalf("NewCA() error = %v", err)
+	}
+	caFile, _ := writeCert(t, dir, "ca", ca)
+	certFile, keyFile := writeCert(t, dir, "service", issue(t, ca, "service", "127.0.0.1"))
+
+	serverConfig, err := Server(certFile, keyFile, "1.3")
+	if err != nil {
+		t.Fatalf("Server() error = %v", err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{
+		Handler:  http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		ErrorLog: log.New(io.Discard, "", 0),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+	url := "https://" + listener.Addr().String()
+
+	get := func(config *tls.Config) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	clientConfig, err := Client(caFile, "")
+	if err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+	if err := get(clientConfig); err != nil {
+		t.Errorf("call trusting the CA error = %v", err)
+	}
+
+	untrusting, _ := Client("", "")
+	if err := get(untrusting); err == nil {
+		t.Error("call without trusting the CA succeeded")
+	}
+	if err := get(&tls.Config{RootCAs: clientConfig.RootCAs, MaxVersion: tls.VersionTLS12}); err == nil {
+		t.Error("call with TLS 1.2 succeeded against a TLS 1.3 minimum")
+	}
+
+	if _, err := Client(certFile+".missing", ""); err == nil {
+		t.Error("Client() with a missing CA file succeeded")
+	}
+	if _, err := Server(certFile, certFile, ""); err == nil {
+		t.Error("Server() with the certificate as key succeeded")
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca, _ := NewCA("test CA", time.Hour)
+	certFile, keyFile := writeCert(t, dir, "service", issue(t, ca, "first"))
+
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("newCertReloader() error = %v", err)
+	}
+	commonName := func() string {
+		t.Helper()
+		cert, err := reloader.GetCertificate(nil)
+		if err != nil {
+			t.Fatalf("GetCertificate() error = %v", err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+
+	// Changes are only noticed once the check interval has passed
+	writeCert(t, dir, "service", issue(t, ca, "second"))
+	if got := commonName(); got != "first" {
+		t.Errorf("certificate before the interval = %q, want first", got)
+	}
+	reloader.interval = 0
+	if got := commonName(); got != "second" {
+		t.Errorf("certificate after the interval = %q, want second", got)
+	}
+
+	// A half-written renewal keeps the last good certificate
+	third := issue(t, ca, "third")
+	if err := os.WriteFile(certFile, third.CertPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := commonName(); got != "second" {
+		t.Errorf("certificate with mismatched key = %q, want second", got)
+	}
+	writeCert(t, dir, "service", third)
+	if got := commonName(); got != "third" {
+		t.Errorf("certificate after renewal = %q, want third", got)
+	}
+}
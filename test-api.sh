@@ -5,6 +5,10 @@ echo ""
 
 BASE_URL="http://localhost:8080"
 
+# User endpoints need an access token of a user with the admin role, e.g. the
+# user named by RBAC_BOOTSTRAP_ADMIN_EMAIL: export ACCESS_TOKEN=<access_token>
+AUTH_HEADER="Authorization: Bearer $ACCESS_TOKEN"
+
 # Test Gateway Health
 echo "🔍 Testing Gateway Health..."
 curl -s "$BASE_URL/health" | python3 -m json.tool
@@ -18,11 +22,11 @@ echo ""
 # Test User Service
 echo "👤 Testing User Service..."
 echo "GET /users:"
-curl -s "$BASE_URL/users" | python3 -m json.tool
+curl -s "$BASE_URL/users" -H "$AUTH_HEADER" | python3 -m json.tool
 echo ""
 
 echo "GET /users/1:"
-curl -s "$BASE_URL/users/1" | python3 -m json.tool
+curl -s "$BASE_URL/users/1" -H "$AUTH_HEADER" | python3 -m json.tool
 echo ""
 
 # Test Product Service
@@ -38,6 +42,7 @@ echo ""
 # Test Create User
 echo "POST /users (Create new user):"
 curl -s -X POST "$BASE_URL/users" \
+  -H "$AUTH_HEADER" \
   -H "Content-Type: application/json" \
   -d '{"name":"Test User","email":"test@example.com"}' | python3 -m json.tool
 echo ""